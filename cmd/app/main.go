@@ -1,40 +1,328 @@
 package main
 
 import (
+	"avito-tech/internal/app/apikey"
+	"avito-tech/internal/app/auth"
+	"avito-tech/internal/app/availability"
 	"avito-tech/internal/app/core"
+	"avito-tech/internal/app/escalation"
+	"avito-tech/internal/app/forge"
+	"avito-tech/internal/app/idempotency"
+	"avito-tech/internal/app/integration"
+	"avito-tech/internal/app/outbox"
 	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/ratelimit"
 	"avito-tech/internal/app/routing"
+	"avito-tech/internal/app/scheduler"
+	"avito-tech/internal/app/stream"
 	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/tenant"
 	"avito-tech/internal/app/user"
+	"avito-tech/internal/app/webhook"
+	"avito-tech/internal/clock"
 	"avito-tech/internal/db"
+	"avito-tech/internal/db/memory"
+	"avito-tech/internal/db/sqlite"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/gorilla/mux"
 )
 
-const port = ":8080"
+const (
+	port            = ":8080"
+	shutdownTimeout = 10 * time.Second
+)
 
 func main() {
-	ctx := context.Background()
+	outboxSinks := flag.String("outbox-sinks", "webhook", "comma-separated outbox sinks: webhook, stdout, file")
+	outboxFile := flag.String("outbox-file", "events.jsonl", "path for the file outbox sink")
+	integrationsConfig := flag.String("integrations-config", "", "path to JSON config with GitHub/GitLab webhook secrets and login mapping")
+	authConfig := flag.String("auth-config", "", "path to JSON config with static API tokens and JWT keys (API keys are always accepted)")
+	noAuth := flag.Bool("insecure-no-auth", false, "disable authentication, every request runs as admin (local use only)")
+	readRate := flag.Float64("rate-read", 20, "read requests per second per client, 0 disables")
+	readBurst := flag.Int("rate-read-burst", 40, "read burst per client")
+	writeRate := flag.Float64("rate-write", 5, "write requests per second per client, 0 disables")
+	writeBurst := flag.Int("rate-write-burst", 10, "write burst per client")
+	ipRate := flag.Float64("rate-ip", 50, "requests per second per client IP before authentication, 0 disables")
+	ipBurst := flag.Int("rate-ip-burst", 100, "per-IP burst before authentication")
+	trustForwardedFor := flag.Bool("trust-forwarded-for", false, "use X-Forwarded-For as the client IP for rate limiting")
+	maxInflight := flag.Int("max-inflight", 64, "max concurrently served requests before shedding")
+	maxQueueWait := flag.Duration("max-queue-wait", 100*time.Millisecond, "how long a request may wait for a free slot before 503")
+	storage := flag.String("storage", "postgres", "storage backend: postgres, sqlite or memory (sqlite and memory have no events, webhooks, API keys or idempotency)")
+	sqlitePath := flag.String("sqlite-path", "avito.db", "database file for the sqlite storage")
+	requiredApprovals := flag.Int("required-approvals", 0, "approvals required to merge a PR, and no outstanding change requests; 0 disables the merge gate")
+	idempotencyTTL := flag.Duration("idempotency-ttl", 24*time.Hour, "how long responses to Idempotency-Key requests are kept")
+	strategy := flag.String("assignment-strategy", pullrequest.StrategyDefault, "reviewer assignment strategy: default, or working-hours to prefer reviewers who are at work now")
+	handoverInterval := flag.Duration("handover-interval", time.Minute, "how often started unavailability windows hand over open reviews, 0 disables")
+	escalationInterval := flag.Duration("escalation-interval", time.Minute, "how often reviewers past their team's review SLA are escalated, 0 disables")
+	jobSchedules := flag.String("job-schedules", "", `background job schedule overrides "job=schedule;...", a schedule is an interval, @every, @hourly/@daily or a 5-field cron expression`)
+	flag.Parse()
 
-	db, err := db.CreateDB(ctx)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
+	scheduleOverrides, err := parseJobSchedules(*jobSchedules)
 	if err != nil {
-		fmt.Println("Failed to create DB")
+		fmt.Printf("Invalid job schedules: %v\n", err)
+		return
+	}
+
+	if *strategy != pullrequest.StrategyDefault && *strategy != pullrequest.StrategyWorkingHours {
+		fmt.Printf("Unknown assignment strategy %q\n", *strategy)
+		return
+	}
+	systemClock := clock.System{}
+	assignment := pullrequest.Assignment{Strategy: *strategy, Clock: systemClock}
+
+	integrationCfg := &integration.Config{}
+	if *integrationsConfig != "" {
+		cfg, err := integration.LoadConfig(*integrationsConfig)
+		if err != nil {
+			fmt.Println("Failed to load integrations config")
+			return
+		}
+		integrationCfg = cfg
+	}
+	integration := integration.NewIntegration(*integrationCfg)
+
+	// компоненты, которым нужен Postgres, остаются nil в режимах memory и sqlite
+	var (
+		teams            *team.Team
+		users            core.User
+		pullRequests     core.PullRequest
+		available        *availability.Availability
+		escalations      *escalation.Escalation
+		txManager        core.TxManager
+		webhooks         core.Webhook
+		forgeSyncs       core.Forge
+		streams          core.Stream
+		apiKeys          core.APIKey
+		keyAuth          auth.Source
+		idempotencyStore *idempotency.Store
+		// в режимах memory и sqlite реплика одна и всегда выполняет задачи сама
+		locker         scheduler.Locker = scheduler.LocalLocker{}
+		backgroundJobs []scheduler.Job
+	)
+
+	switch *storage {
+	case "memory":
+		store := memory.NewStore()
+		teams = team.NewTeam(team.NewTeamMemoryRepo(store))
+		users = user.NewUser(user.NewUserMemoryRepo(store))
+		pullRequests = pullrequest.NewPullRequest(pullrequest.NewMemoryRepo(store), assignment)
+		available = availability.NewAvailability(availability.NewAvailabilityMemoryRepo(store))
+		escalations = escalation.NewEscalation(escalation.NewEscalationMemoryRepo(store))
+		txManager = store
+	case "sqlite":
+		database, err := sqlite.Open(ctx, *sqlitePath)
+		if err != nil {
+			fmt.Println("Failed to open SQLite database")
+			return
+		}
+		teams = team.NewTeam(team.NewTeamSQLiteRepo(database))
+		users = user.NewUser(user.NewUserSQLiteRepo(database))
+		pullRequests = pullrequest.NewPullRequest(pullrequest.NewSQLiteRepo(database), assignment)
+		available = availability.NewAvailability(availability.NewAvailabilitySQLiteRepo(database))
+		escalations = escalation.NewEscalation(escalation.NewEscalationSQLiteRepo(database))
+		txManager = database
+	case "postgres":
+		db, err := db.CreateDB(ctx)
+		if err != nil {
+			fmt.Println("Failed to create DB")
+			return
+		}
+
+		teams = team.NewTeam(team.NewTeamRepo(db))
+		users = user.NewUser(user.NewUserRepo(db))
+		pullRequests = pullrequest.NewPullRequest(pullrequest.NewRepo(db), assignment)
+		available = availability.NewAvailability(availability.NewAvailabilityRepo(db))
+		escalations = escalation.NewEscalation(escalation.NewEscalationRepo(db))
+		txManager = db
+		advisoryLocker := scheduler.NewAdvisoryLocker(db.GetPool(ctx))
+		defer advisoryLocker.Close()
+		locker = advisoryLocker
+		webhook := webhook.NewWebhook(webhook.NewWebhookRepo(db), nil, webhook.DefaultRetryPolicy())
+		webhooks = webhook
+		backgroundJobs = append(backgroundJobs, scheduler.Job{Name: "webhook-delivery", Schedule: scheduler.Every(time.Second), Run: webhook.DeliverDue})
+
+		var sinks []outbox.Sink
+		for _, name := range strings.Split(*outboxSinks, ",") {
+			switch strings.TrimSpace(name) {
+			case "webhook":
+				sinks = append(sinks, outbox.NewFuncSink("webhook", webhook.Publish))
+			case "stdout":
+				sinks = append(sinks, outbox.NewStdoutSink())
+			case "file":
+				sink, err := outbox.NewFileSink(*outboxFile)
+				if err != nil {
+					fmt.Println("Failed to open outbox file sink")
+					return
+				}
+				sinks = append(sinks, sink)
+			case "":
+			default:
+				fmt.Printf("Unknown outbox sink %q\n", name)
+				return
+			}
+		}
+
+		forgeSync := forge.NewForge(forge.NewForgeRepo(db))
+		if integrationCfg.GitHub.APIToken != "" {
+			forgeSync.Register("github", forge.NewGitHubAdapter(integrationCfg.GitHub.APIURL, integrationCfg.GitHub.APIToken, nil), integrationCfg.GitHub.Users)
+			sinks = append(sinks, outbox.NewFuncSink("forge", forgeSync.Deliver))
+		}
+		forgeSyncs = forgeSync
+
+		broker := stream.NewBroker(stream.NewStreamRepo(db))
+		sinks = append(sinks, broker)
+		streams = broker
+
+		relay := outbox.NewRelay(outbox.NewOutboxRepo(db), 100, sinks...)
+		backgroundJobs = append(backgroundJobs, scheduler.Job{Name: "outbox-relay", Schedule: scheduler.Every(time.Second), Run: relay.RunOnce})
+
+		apiKey := apikey.NewAPIKey(apikey.NewAPIKeyRepo(db))
+		apiKeys = apiKey
+		keyAuth = apiKey
+
+		idempotencyStore = idempotency.NewStore(idempotency.NewIdempotencyRepo(db), *idempotencyTTL)
+		backgroundJobs = append(backgroundJobs, scheduler.Job{Name: "idempotency-purge", Schedule: scheduler.Every(time.Hour), Run: idempotencyStore.PurgeExpired})
+	default:
+		fmt.Printf("Unknown storage %q\n", *storage)
 		return
 	}
 
-	user := user.NewUser(user.NewUserRepo(db))
-	team := team.NewTeam(team.NewTeamRepo(db))
-	pull_request := pullrequest.NewPullRequest(pullrequest.NewRepo(db))
+	// передача ревью и эскалация работают с данными одного арендатора и обходят всех по очереди
+	if *handoverInterval > 0 {
+		handover := availability.NewHandover(available, users, pullRequests, systemClock, 100)
+		backgroundJobs = append(backgroundJobs, scheduler.Job{Name: "handover", Schedule: scheduler.Every(*handoverInterval), Run: tenant.Each(teams, handover.RunOnce)})
+	}
+	if *escalationInterval > 0 {
+		escalator := escalation.NewEscalator(escalations, pullRequests, systemClock, 100)
+		backgroundJobs = append(backgroundJobs, scheduler.Job{Name: "escalation", Schedule: scheduler.Every(*escalationInterval), Run: tenant.Each(teams, escalator.RunOnce)})
+	}
 
-	service := core.NewService(team, user, pull_request)
+	jobs := scheduler.New(locker)
+	for _, job := range backgroundJobs {
+		if schedule, ok := scheduleOverrides[job.Name]; ok {
+			job.Schedule = schedule
+			delete(scheduleOverrides, job.Name)
+		}
+		if err := jobs.Register(job); err != nil {
+			fmt.Printf("Failed to register job: %v\n", err)
+			return
+		}
+	}
+	for name := range scheduleOverrides {
+		fmt.Printf("Job %q from -job-schedules is not registered\n", name)
+		return
+	}
+
+	var mergePolicy *pullrequest.MergePolicy
+	if *requiredApprovals > 0 {
+		mergePolicy = &pullrequest.MergePolicy{RequiredApprovals: *requiredApprovals}
+	}
+
+	service := core.NewService(teams, users, pullRequests, webhooks, integration, forgeSyncs, streams, apiKeys, available, escalations, jobs, txManager, mergePolicy)
 
 	server := routing.NewServer(service)
 
-	router := routing.NewRouter(server)
+	authMiddleware := server.NoAuthMiddleware()
+	if !*noAuth {
+		chain := auth.Chain{}
+		if *authConfig != "" {
+			cfg, err := auth.LoadConfig(*authConfig)
+			if err != nil {
+				fmt.Println("Failed to load auth config")
+				return
+			}
+			authenticator, err := auth.NewAuthenticator(*cfg)
+			if err != nil {
+				fmt.Printf("Invalid auth config: %v\n", err)
+				return
+			}
+			chain = append(chain, authenticator)
+		}
+		if keyAuth != nil {
+			chain = append(chain, keyAuth)
+		}
+		authMiddleware = server.AuthMiddleware(chain)
+	}
+
+	limiter := ratelimit.NewLimiter(ratelimit.Config{
+		Read:  ratelimit.Budget{Rate: *readRate, Burst: *readBurst},
+		Write: ratelimit.Budget{Rate: *writeRate, Burst: *writeBurst},
+		IP:    ratelimit.Budget{Rate: *ipRate, Burst: *ipBurst},
+	})
+	shedder := ratelimit.NewShedder(*maxInflight, *maxQueueWait)
+
+	middlewares := []mux.MiddlewareFunc{
+		server.IPRateLimitMiddleware(limiter, *trustForwardedFor),
+		server.LoadSheddingMiddleware(shedder),
+		authMiddleware,
+		server.TenantMiddleware(),
+		server.RateLimitMiddleware(limiter, *trustForwardedFor),
+	}
+	if idempotencyStore != nil {
+		middlewares = append(middlewares, server.IdempotencyMiddleware(idempotencyStore))
+	}
+	router := routing.NewRouter(server, middlewares...)
+
+	// задачи и HTTP-сервер останавливаются по SIGINT/SIGTERM: задачи получают отменённый ctx,
+	// сервер дожидается текущих запросов
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		jobs.Run(ctx)
+	}()
 
-	if err := http.ListenAndServe(port, router); err != nil {
+	httpServer := &http.Server{Addr: port, Handler: router}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			fmt.Printf("Failed to shut down server: %v\n", err)
+		}
+	}()
+
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		fmt.Println("Failed to Run server")
+		stop()
+	}
+	wg.Wait()
+}
+
+// parseJobSchedules разбирает переопределения расписаний вида "job=schedule;job=schedule"
+func parseJobSchedules(spec string) (map[string]scheduler.Schedule, error) {
+	schedules := map[string]scheduler.Schedule{}
+	for _, entry := range strings.Split(spec, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, expr, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected job=schedule, got %q", entry)
+		}
+		schedule, err := scheduler.ParseSchedule(expr)
+		if err != nil {
+			return nil, fmt.Errorf("job %q: %w", name, err)
+		}
+		schedules[name] = schedule
 	}
+	return schedules, nil
 }
@@ -0,0 +1,196 @@
+package apikey
+
+import (
+	"avito-tech/internal/app/auth"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	keyPrefix = "avk_"
+
+	cacheTTL      = 30 * time.Second
+	touchInterval = time.Minute
+)
+
+type Repo interface {
+	create(ctx context.Context, entity *APIKeyEntity) (*APIKeyEntity, error)
+	list(ctx context.Context) ([]*APIKeyEntity, error)
+	revoke(ctx context.Context, id uint64) (*APIKeyEntity, error)
+	getByHash(ctx context.Context, keyHash string) (*APIKeyEntity, error)
+	touch(ctx context.Context, id uint64) error
+}
+
+type cacheEntry struct {
+	entity    *APIKeyEntity
+	loadedAt  time.Time
+	touchedAt time.Time
+}
+
+type APIKey struct {
+	repo Repo
+	now  func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*cacheEntry
+}
+
+func NewAPIKey(repo Repo) *APIKey {
+	return &APIKey{
+		repo:  repo,
+		now:   time.Now,
+		cache: map[string]*cacheEntry{},
+	}
+}
+
+func (a *APIKey) Create(ctx context.Context, dto *APIKeyDTO) (*APIKeyDTO, error) {
+	if dto.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", apperrors.ErrBadRequest)
+	}
+	if dto.Role == "" {
+		dto.Role = auth.RoleMember
+	}
+	if !auth.IsKnownRole(dto.Role) {
+		return nil, fmt.Errorf("%w: unknown role '%s'", apperrors.ErrBadRequest, dto.Role)
+	}
+	if dto.Scope != ScopeRead && dto.Scope != ScopeWrite {
+		return nil, fmt.Errorf("%w: scope must be '%s' or '%s'", apperrors.ErrBadRequest, ScopeRead, ScopeWrite)
+	}
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(a.now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", apperrors.ErrBadRequest)
+	}
+	if dto.Teams == nil {
+		dto.Teams = []string{}
+	}
+
+	key, prefix := generateKey()
+	entity := dto.MapToModel()
+	entity.Prefix = prefix
+	entity.KeyHash = hashKey(key)
+	entity.CreatedBy = auth.Actor(ctx)
+	// ключ привязывается к арендатору, в котором выпущен
+	tenantID := tenant.FromContext(ctx)
+	entity.TenantID = &tenantID
+
+	entity, err := a.repo.create(ctx, entity)
+	if err != nil {
+		return nil, err
+	}
+	var answer APIKeyDTO
+	answer.MapFromModel(entity)
+	answer.Key = key
+	return &answer, nil
+}
+
+func (a *APIKey) List(ctx context.Context) ([]*APIKeyDTO, error) {
+	entities, err := a.repo.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return MapFromModels(entities), nil
+}
+
+func (a *APIKey) Revoke(ctx context.Context, id uint64) (*APIKeyDTO, error) {
+	entity, err := a.repo.revoke(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// на этой реплике ключ перестаёт работать сразу, на остальных - после истечения кэша
+	a.mu.Lock()
+	delete(a.cache, entity.KeyHash)
+	a.mu.Unlock()
+
+	var answer APIKeyDTO
+	answer.MapFromModel(entity)
+	return &answer, nil
+}
+
+// Authenticate проверяет API-ключ. Результат поиска кэшируется на cacheTTL,
+// чтобы не ходить в БД на каждый запрос; last_used_at обновляется не чаще touchInterval.
+func (a *APIKey) Authenticate(ctx context.Context, key string) (*auth.Principal, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return nil, fmt.Errorf("%w: not an api key", apperrors.ErrUnauthorized)
+	}
+	hash := hashKey(key)
+	now := a.now()
+
+	a.mu.Lock()
+	entry, ok := a.cache[hash]
+	a.mu.Unlock()
+
+	if !ok || now.Sub(entry.loadedAt) > cacheTTL {
+		entity, err := a.repo.getByHash(ctx, hash)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown api key", apperrors.ErrUnauthorized)
+		}
+		if err != nil {
+			return nil, err
+		}
+		reloaded := &cacheEntry{entity: entity, loadedAt: now}
+		a.mu.Lock()
+		// перезагрузка кэша не сбрасывает отметку touch, иначе last_used_at писался бы раз в cacheTTL
+		if ok {
+			reloaded.touchedAt = entry.touchedAt
+		}
+		a.cache[hash] = reloaded
+		a.mu.Unlock()
+		entry = reloaded
+	}
+
+	entity := entry.entity
+	if entity.RevokedAt != nil {
+		return nil, fmt.Errorf("%w: api key is revoked", apperrors.ErrUnauthorized)
+	}
+	if entity.ExpiresAt != nil && !now.Before(*entity.ExpiresAt) {
+		return nil, fmt.Errorf("%w: api key is expired", apperrors.ErrUnauthorized)
+	}
+
+	a.mu.Lock()
+	needTouch := now.Sub(entry.touchedAt) > touchInterval
+	if needTouch {
+		entry.touchedAt = now
+	}
+	a.mu.Unlock()
+	if needTouch {
+		go func(id uint64) {
+			if err := a.repo.touch(context.Background(), id); err != nil {
+				log.Printf("[APIKey.Authenticate] failed to update last_used_at of key %d: %v", id, err)
+			}
+		}(entity.ID)
+	}
+
+	principal := &auth.Principal{
+		Subject:  fmt.Sprintf("apikey:%d:%s", entity.ID, entity.Name),
+		Role:     entity.Role,
+		Teams:    entity.Teams,
+		ReadOnly: entity.Scope == ScopeRead,
+	}
+	if entity.TenantID != nil {
+		principal.Tenant = *entity.TenantID
+	}
+	return principal, nil
+}
+
+func generateKey() (string, string) {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	secret := base64.RawURLEncoding.EncodeToString(b)
+	key := keyPrefix + secret
+	return key, key[:len(keyPrefix)+6]
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,289 @@
+package apikey
+
+import (
+	"avito-tech/internal/app/auth"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeRepo хранит ключи в памяти и считает обращения к БД
+type fakeRepo struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	keys    []*APIKeyEntity
+	lookups int
+	touched chan uint64
+}
+
+func newFakeRepo(now func() time.Time) *fakeRepo {
+	return &fakeRepo{now: now, touched: make(chan uint64, 10)}
+}
+
+func (f *fakeRepo) create(_ context.Context, entity *APIKeyEntity) (*APIKeyEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entity.ID = uint64(len(f.keys) + 1)
+	entity.CreatedAt = f.now()
+	f.keys = append(f.keys, entity)
+	copied := *entity
+	return &copied, nil
+}
+
+func (f *fakeRepo) list(context.Context) ([]*APIKeyEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.keys, nil
+}
+
+func (f *fakeRepo) revoke(_ context.Context, id uint64) (*APIKeyEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if id == 0 || int(id) > len(f.keys) {
+		return nil, apperrors.ErrNotFound
+	}
+	now := f.now()
+	f.keys[id-1].RevokedAt = &now
+	copied := *f.keys[id-1]
+	return &copied, nil
+}
+
+func (f *fakeRepo) getByHash(_ context.Context, keyHash string) (*APIKeyEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups++
+	for _, k := range f.keys {
+		if k.KeyHash == keyHash {
+			copied := *k
+			return &copied, nil
+		}
+	}
+	return nil, apperrors.ErrNotFound
+}
+
+func (f *fakeRepo) touch(_ context.Context, id uint64) error {
+	f.touched <- id
+	return nil
+}
+
+func (f *fakeRepo) lookupCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lookups
+}
+
+// expectTouch ждёт обновления last_used_at: оно пишется в фоне
+func (f *fakeRepo) expectTouch(t *testing.T, id uint64) {
+	t.Helper()
+	select {
+	case got := <-f.touched:
+		if got != id {
+			t.Fatalf("expected key %d touched, got %d", id, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("key %d was not touched", id)
+	}
+}
+
+func (f *fakeRepo) expectNoTouch(t *testing.T) {
+	t.Helper()
+	select {
+	case id := <-f.touched:
+		t.Fatalf("key %d touched again within the interval", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func setup(t *testing.T) (*APIKey, *fakeRepo, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	repo := newFakeRepo(clock)
+	a := NewAPIKey(repo)
+	a.now = clock
+	return a, repo, &now
+}
+
+func create(t *testing.T, a *APIKey, dto *APIKeyDTO) *APIKeyDTO {
+	t.Helper()
+	ctx := auth.WithPrincipal(tenant.WithTenant(t.Context(), "acme"), &auth.Principal{Subject: "admin", Role: auth.RoleAdmin})
+	created, err := a.Create(ctx, dto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return created
+}
+
+func TestCreate(t *testing.T) {
+	a, repo, _ := setup(t)
+	created := create(t, a, &APIKeyDTO{Name: "ci", Scope: ScopeWrite})
+
+	if !strings.HasPrefix(created.Key, keyPrefix) || !strings.HasPrefix(created.Key, created.Prefix) || len(created.Prefix) != len(keyPrefix)+6 {
+		t.Fatalf("unexpected key %q with prefix %q", created.Key, created.Prefix)
+	}
+	if created.Role != auth.RoleMember || created.CreatedBy != "admin" || created.Tenant != "acme" || created.Teams == nil {
+		t.Fatalf("unexpected defaults %+v", created)
+	}
+	// в хранилище только хэш ключа
+	stored := repo.keys[0]
+	if stored.KeyHash != hashKey(created.Key) || strings.Contains(stored.KeyHash, created.Key) {
+		t.Fatalf("key must be stored as its sha256, got %q", stored.KeyHash)
+	}
+	listed, err := a.List(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || listed[0].Key != "" {
+		t.Fatalf("list must not return the key itself, got %+v", listed)
+	}
+	if other := create(t, a, &APIKeyDTO{Name: "ci", Scope: ScopeWrite}); other.Key == created.Key {
+		t.Fatal("keys must be random")
+	}
+}
+
+func TestCreateValidated(t *testing.T) {
+	a, _, now := setup(t)
+	past := now.Add(-time.Minute)
+	invalid := []*APIKeyDTO{
+		{Scope: ScopeRead},
+		{Name: "ci", Scope: "admin"},
+		{Name: "ci", Scope: ScopeRead, Role: "root"},
+		{Name: "ci", Scope: ScopeRead, ExpiresAt: &past},
+		{Name: "ci", Scope: ScopeRead, ExpiresAt: now},
+	}
+	for _, dto := range invalid {
+		if _, err := a.Create(t.Context(), dto); !errors.Is(err, apperrors.ErrBadRequest) {
+			t.Fatalf("%+v: expected ErrBadRequest, got %v", dto, err)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	a, repo, _ := setup(t)
+	read := create(t, a, &APIKeyDTO{Name: "dash", Scope: ScopeRead, Role: auth.RoleTeamLead, Teams: []string{"backend"}})
+	write := create(t, a, &APIKeyDTO{Name: "ci", Scope: ScopeWrite})
+
+	p, err := a.Authenticate(t.Context(), read.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.ReadOnly || p.Role != auth.RoleTeamLead || p.Subject != "apikey:1:dash" || p.Tenant != "acme" || len(p.Teams) != 1 || p.Teams[0] != "backend" {
+		t.Fatalf("unexpected principal of a read key %+v", p)
+	}
+	repo.expectTouch(t, read.ID)
+	if p, err = a.Authenticate(t.Context(), write.Key); err != nil || p.ReadOnly {
+		t.Fatalf("write key should not be read-only: %+v, %v", p, err)
+	}
+	repo.expectTouch(t, write.ID)
+
+	for _, key := range []string{"", "avk_unknown", "Bearer " + write.Key, strings.TrimPrefix(write.Key, keyPrefix)} {
+		if _, err := a.Authenticate(t.Context(), key); !errors.Is(err, apperrors.ErrUnauthorized) {
+			t.Fatalf("key %q: expected ErrUnauthorized, got %v", key, err)
+		}
+	}
+}
+
+func TestAuthenticateExpired(t *testing.T) {
+	a, _, now := setup(t)
+	expiresAt := now.Add(time.Hour)
+	created := create(t, a, &APIKeyDTO{Name: "tmp", Scope: ScopeRead, ExpiresAt: &expiresAt})
+
+	if _, err := a.Authenticate(t.Context(), created.Key); err != nil {
+		t.Fatal(err)
+	}
+	*now = expiresAt
+	if _, err := a.Authenticate(t.Context(), created.Key); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Fatalf("expected an expired key to be rejected, got %v", err)
+	}
+}
+
+func TestAuthenticateCache(t *testing.T) {
+	a, repo, now := setup(t)
+	created := create(t, a, &APIKeyDTO{Name: "ci", Scope: ScopeWrite})
+
+	for range 3 {
+		if _, err := a.Authenticate(t.Context(), created.Key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := repo.lookupCount(); n != 1 {
+		t.Fatalf("expected 1 lookup within the cache TTL, got %d", n)
+	}
+
+	// отзыв на другой реплике виден здесь только после истечения кэша
+	if _, err := repo.revoke(t.Context(), created.ID); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(cacheTTL)
+	if _, err := a.Authenticate(t.Context(), created.Key); err != nil {
+		t.Fatalf("cached key should still be accepted: %v", err)
+	}
+	*now = now.Add(time.Second)
+	if _, err := a.Authenticate(t.Context(), created.Key); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Fatalf("expected the revoked key to be rejected after the cache TTL, got %v", err)
+	}
+	if n := repo.lookupCount(); n != 2 {
+		t.Fatalf("expected a second lookup after the cache TTL, got %d", n)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	a, _, _ := setup(t)
+	created := create(t, a, &APIKeyDTO{Name: "ci", Scope: ScopeWrite})
+	if _, err := a.Authenticate(t.Context(), created.Key); err != nil {
+		t.Fatal(err)
+	}
+
+	revoked, err := a.Revoke(t.Context(), created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked.RevokedAt == nil {
+		t.Fatalf("expected revoked_at, got %+v", revoked)
+	}
+	// на этой реплике отзыв действует сразу, без ожидания кэша
+	if _, err := a.Authenticate(t.Context(), created.Key); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Fatalf("expected the revoked key to be rejected, got %v", err)
+	}
+	if _, err := a.Revoke(t.Context(), 42); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAuthenticateTouchThrottled(t *testing.T) {
+	a, repo, now := setup(t)
+	created := create(t, a, &APIKeyDTO{Name: "ci", Scope: ScopeWrite})
+
+	if _, err := a.Authenticate(t.Context(), created.Key); err != nil {
+		t.Fatal(err)
+	}
+	repo.expectTouch(t, created.ID)
+	*now = now.Add(touchInterval)
+	if _, err := a.Authenticate(t.Context(), created.Key); err != nil {
+		t.Fatal(err)
+	}
+	repo.expectNoTouch(t)
+
+	*now = now.Add(time.Second)
+	if _, err := a.Authenticate(t.Context(), created.Key); err != nil {
+		t.Fatal(err)
+	}
+	repo.expectTouch(t, created.ID)
+}
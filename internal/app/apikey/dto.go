@@ -0,0 +1,57 @@
+package apikey
+
+import "time"
+
+type APIKeyDTO struct {
+	ID         uint64     `json:"id"`
+	Tenant     string     `json:"tenant,omitempty"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Role       string     `json:"role"`
+	Scope      string     `json:"scope"`
+	Teams      []string   `json:"teams"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	// Key - сам ключ, возвращается только при создании
+	Key string `json:"key,omitempty"`
+}
+
+func (k *APIKeyDTO) MapToModel() *APIKeyEntity {
+	return &APIKeyEntity{
+		Name:      k.Name,
+		Role:      k.Role,
+		Scope:     k.Scope,
+		Teams:     k.Teams,
+		ExpiresAt: k.ExpiresAt,
+	}
+}
+
+func (k *APIKeyDTO) MapFromModel(entity *APIKeyEntity) {
+	k.ID = entity.ID
+	if entity.TenantID != nil {
+		k.Tenant = *entity.TenantID
+	}
+	k.Name = entity.Name
+	k.Prefix = entity.Prefix
+	k.Role = entity.Role
+	k.Scope = entity.Scope
+	k.Teams = entity.Teams
+	k.CreatedBy = entity.CreatedBy
+	k.CreatedAt = entity.CreatedAt
+	k.ExpiresAt = entity.ExpiresAt
+	k.LastUsedAt = entity.LastUsedAt
+	k.RevokedAt = entity.RevokedAt
+}
+
+func MapFromModels(entities []*APIKeyEntity) []*APIKeyDTO {
+	dto := make([]*APIKeyDTO, len(entities))
+	for i, v := range entities {
+		var k APIKeyDTO
+		k.MapFromModel(v)
+		dto[i] = &k
+	}
+	return dto
+}
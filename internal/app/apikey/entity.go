@@ -0,0 +1,25 @@
+package apikey
+
+import "time"
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+)
+
+type APIKeyEntity struct {
+	ID         uint64     `db:"id"`
+	Name       string     `db:"name"`
+	Prefix     string     `db:"prefix"`
+	KeyHash    string     `db:"key_hash"`
+	Role       string     `db:"role"`
+	Scope      string     `db:"scope"`
+	Teams      []string   `db:"teams"`
+	CreatedBy  string     `db:"created_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	// TenantID - арендатор ключа; nil у ключей, выпущенных до появления арендаторов
+	TenantID *string `db:"tenant_id"`
+}
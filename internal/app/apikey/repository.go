@@ -0,0 +1,101 @@
+package apikey
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type DB interface {
+	Get(ctx context.Context, dest any, query string, args ...any) error
+	Select(ctx context.Context, dest any, query string, args ...any) error
+	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+	ExecQueryRow(ctx context.Context, query string, args ...any) pgx.Row
+}
+
+type APIKeyRepo struct {
+	db DB
+}
+
+func NewAPIKeyRepo(db DB) *APIKeyRepo {
+	return &APIKeyRepo{db: db}
+}
+
+const apiKeyColumns = `id, tenant_id, name, prefix, key_hash, role, scope, teams, created_by, created_at, expires_at, last_used_at, revoked_at`
+
+func (a *APIKeyRepo) create(ctx context.Context, entity *APIKeyEntity) (*APIKeyEntity, error) {
+	err := a.db.ExecQueryRow(ctx, `
+		INSERT INTO api_key (tenant_id, name, prefix, key_hash, role, scope, teams, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`, entity.TenantID, entity.Name, entity.Prefix, entity.KeyHash, entity.Role, entity.Scope, entity.Teams, entity.CreatedBy, entity.ExpiresAt).Scan(
+		&entity.ID,
+		&entity.CreatedAt,
+	)
+	if err != nil {
+		log.Printf("[APIKeyRepo.create] db error inserting key '%s': %v", entity.Name, err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[APIKeyRepo.create] key %d '%s' created by '%s'", entity.ID, entity.Name, entity.CreatedBy)
+	return entity, nil
+}
+
+// ownedBy - ключи арендатора; непривязанными ключами, выпущенными до появления арендаторов,
+// управляют из арендатора по умолчанию
+const ownedBy = "COALESCE(tenant_id, '" + tenant.Default + "') = $1"
+
+func (a *APIKeyRepo) list(ctx context.Context) ([]*APIKeyEntity, error) {
+	var entities []*APIKeyEntity
+	err := a.db.Select(ctx, &entities, "SELECT "+apiKeyColumns+" FROM api_key WHERE "+ownedBy+" ORDER BY id", tenant.FromContext(ctx))
+	if err != nil {
+		log.Printf("[APIKeyRepo.list] db error fetching keys: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	return entities, nil
+}
+
+func (a *APIKeyRepo) revoke(ctx context.Context, id uint64) (*APIKeyEntity, error) {
+	var entity APIKeyEntity
+	err := a.db.Get(ctx, &entity, `
+		UPDATE api_key
+		SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE `+ownedBy+` AND id = $2
+		RETURNING `+apiKeyColumns, tenant.FromContext(ctx), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[APIKeyRepo.revoke] key %d not found", id)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[APIKeyRepo.revoke] db error revoking key %d: %v", id, err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[APIKeyRepo.revoke] key %d revoked", id)
+	return &entity, nil
+}
+
+func (a *APIKeyRepo) getByHash(ctx context.Context, keyHash string) (*APIKeyEntity, error) {
+	var entity APIKeyEntity
+	err := a.db.Get(ctx, &entity, "SELECT "+apiKeyColumns+" FROM api_key WHERE key_hash = $1", keyHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[APIKeyRepo.getByHash] db error fetching key: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	return &entity, nil
+}
+
+func (a *APIKeyRepo) touch(ctx context.Context, id uint64) error {
+	_, err := a.db.Exec(ctx, "UPDATE api_key SET last_used_at = NOW() WHERE id = $1", id)
+	if err != nil {
+		log.Printf("[APIKeyRepo.touch] db error updating last_used_at of key %d: %v", id, err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
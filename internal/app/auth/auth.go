@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config описывает статические токены и ключи для проверки JWT.
+//
+//	{
+//	  "tokens": [{"token": "...", "subject": "ci", "role": "member", "tenant": "acme"}],
+//	  "jwt": {"hs256_secret": "...", "rs256_public_key_file": "jwt.pub", "issuer": "", "audience": ""}
+//	}
+type Config struct {
+	Tokens []StaticToken `json:"tokens"`
+	JWT    JWTConfig     `json:"jwt"`
+}
+
+type StaticToken struct {
+	Token   string `json:"token"`
+	Subject string `json:"subject"`
+	Role    string `json:"role"`
+	Team    string `json:"team"`
+	Tenant  string `json:"tenant"`
+}
+
+type JWTConfig struct {
+	HS256Secret        string `json:"hs256_secret"`
+	RS256PublicKeyFile string `json:"rs256_public_key_file"`
+	Issuer             string `json:"issuer"`
+	Audience           string `json:"audience"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+type Authenticator struct {
+	tokens map[[sha256.Size]byte]*Principal
+	jwt    jwtVerifier
+}
+
+func NewAuthenticator(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{
+		tokens: make(map[[sha256.Size]byte]*Principal, len(cfg.Tokens)),
+		jwt: jwtVerifier{
+			hsSecret: []byte(cfg.JWT.HS256Secret),
+			issuer:   cfg.JWT.Issuer,
+			audience: cfg.JWT.Audience,
+			now:      time.Now,
+		},
+	}
+	for _, t := range cfg.Tokens {
+		if t.Token == "" || t.Subject == "" || !IsKnownRole(t.Role) {
+			return nil, fmt.Errorf("static token for '%s' must have token, subject and a known role", t.Subject)
+		}
+		if t.Tenant != "" {
+			if err := tenant.Validate(t.Tenant); err != nil {
+				return nil, fmt.Errorf("static token for '%s': %w", t.Subject, err)
+			}
+		}
+		a.tokens[sha256.Sum256([]byte(t.Token))] = &Principal{
+			Subject: t.Subject,
+			Role:    t.Role,
+			Team:    t.Team,
+			Tenant:  t.Tenant,
+		}
+	}
+	if cfg.JWT.RS256PublicKeyFile != "" {
+		pemData, err := os.ReadFile(cfg.JWT.RS256PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		key, err := parseRSAPublicKey(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("rs256 public key: %w", err)
+		}
+		a.jwt.rsKey = key
+	}
+	return a, nil
+}
+
+// Authenticate проверяет bearer-токен: сначала статические токены, затем JWT
+func (a *Authenticator) Authenticate(_ context.Context, token string) (*Principal, error) {
+	if p, ok := a.tokens[sha256.Sum256([]byte(token))]; ok {
+		return p, nil
+	}
+	if a.jwt.enabled() && strings.Count(token, ".") == 2 {
+		p, err := a.jwt.verify(token)
+		if err != nil {
+			log.Printf("[Authenticator.Authenticate] jwt rejected: %v", err)
+			return nil, fmt.Errorf("%w: %v", apperrors.ErrUnauthorized, err)
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("%w: invalid token", apperrors.ErrUnauthorized)
+}
+
+type Source interface {
+	Authenticate(ctx context.Context, token string) (*Principal, error)
+}
+
+// Chain пробует источники по очереди, пока один из них не примет токен
+type Chain []Source
+
+func (c Chain) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	err := fmt.Errorf("%w: invalid token", apperrors.ErrUnauthorized)
+	for _, source := range c {
+		var p *Principal
+		p, err = source.Authenticate(ctx, token)
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, apperrors.ErrUnauthorized) {
+			return nil, err
+		}
+	}
+	return nil, err
+}
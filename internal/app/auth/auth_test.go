@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+func TestStaticTokens(t *testing.T) {
+	a, err := NewAuthenticator(Config{Tokens: []StaticToken{
+		{Token: "adm", Subject: "admin", Role: RoleAdmin},
+		{Token: "lead", Subject: "bob", Role: RoleTeamLead, Team: "backend", Tenant: "acme"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := a.Authenticate(t.Context(), "lead")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Subject != "bob" || p.Role != RoleTeamLead || p.Team != "backend" || p.Tenant != "acme" {
+		t.Fatalf("unexpected principal %+v", p)
+	}
+	// без JWT-ключей похожая на JWT строка тоже просто неизвестный токен
+	for _, token := range []string{"", "unknown", "a.b.c"} {
+		if _, err := a.Authenticate(t.Context(), token); !errors.Is(err, apperrors.ErrUnauthorized) {
+			t.Fatalf("token %q: expected ErrUnauthorized, got %v", token, err)
+		}
+	}
+}
+
+func TestStaticTokensValidated(t *testing.T) {
+	invalid := []StaticToken{
+		{Subject: "ci", Role: RoleMember},
+		{Token: "t", Role: RoleMember},
+		{Token: "t", Subject: "ci", Role: "lead"},
+		{Token: "t", Subject: "ci", Role: RoleMember, Tenant: "no spaces"},
+	}
+	for _, token := range invalid {
+		if _, err := NewAuthenticator(Config{Tokens: []StaticToken{token}}); err == nil {
+			t.Fatalf("expected %+v to be rejected", token)
+		}
+	}
+}
+
+func TestAuthenticateJWT(t *testing.T) {
+	key := rsaKey(t)
+	keyFile := filepath.Join(t.TempDir(), "jwt.pub")
+	if err := os.WriteFile(keyFile, publicPEM(t, key), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	a, err := NewAuthenticator(Config{
+		Tokens: []StaticToken{{Token: "adm", Subject: "admin", Role: RoleAdmin}},
+		JWT:    JWTConfig{RS256PublicKeyFile: keyFile, Issuer: "idp", Audience: "reviewers"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.jwt.now = func() time.Time { return testNow }
+
+	p, err := a.Authenticate(t.Context(), signRS256(t, key, map[string]string{"alg": "RS256"}, claims(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Subject != "alice" {
+		t.Fatalf("unexpected principal %+v", p)
+	}
+	expired := signRS256(t, key, map[string]string{"alg": "RS256"}, claims(func(c map[string]any) { c["exp"] = testNow.Add(-time.Second).Unix() }))
+	if _, err := a.Authenticate(t.Context(), expired); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Fatalf("expired jwt: expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := a.Authenticate(t.Context(), "adm"); err != nil {
+		t.Fatalf("static token should still work with JWT enabled: %v", err)
+	}
+
+	if _, err := NewAuthenticator(Config{JWT: JWTConfig{RS256PublicKeyFile: filepath.Join(t.TempDir(), "missing.pub")}}); err == nil {
+		t.Fatal("expected an error for a missing public key file")
+	}
+}
+
+// source отвечает заранее заданным результатом
+type source struct {
+	p   *Principal
+	err error
+}
+
+func (s source) Authenticate(context.Context, string) (*Principal, error) {
+	return s.p, s.err
+}
+
+func TestChain(t *testing.T) {
+	unauthorized := source{err: apperrors.ErrUnauthorized}
+	alice := source{p: &Principal{Subject: "alice", Role: RoleMember}}
+
+	p, err := Chain{unauthorized, alice}.Authenticate(t.Context(), "t")
+	if err != nil || p.Subject != "alice" {
+		t.Fatalf("expected the second source to accept, got %+v, %v", p, err)
+	}
+	if _, err := (Chain{unauthorized, unauthorized}).Authenticate(t.Context(), "t"); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+	if _, err := (Chain{}).Authenticate(t.Context(), "t"); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Fatalf("empty chain: expected ErrUnauthorized, got %v", err)
+	}
+	// ошибка, отличная от ErrUnauthorized (например, БД), прерывает цепочку
+	if _, err := (Chain{source{err: apperrors.ErrDB}, alice}).Authenticate(t.Context(), "t"); !errors.Is(err, apperrors.ErrDB) {
+		t.Fatalf("expected ErrDB to stop the chain, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ"`
+}
+
+type jwtClaims struct {
+	Subject   string          `json:"sub"`
+	Role      string          `json:"role"`
+	Team      string          `json:"team"`
+	Tenant    string          `json:"tenant"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+}
+
+type jwtVerifier struct {
+	hsSecret []byte
+	rsKey    *rsa.PublicKey
+	issuer   string
+	audience string
+	now      func() time.Time
+}
+
+func (v *jwtVerifier) enabled() bool {
+	return len(v.hsSecret) > 0 || v.rsKey != nil
+}
+
+func (v *jwtVerifier) verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed jwt")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("jwt header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("jwt signature: %w", err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+
+	// алгоритм разрешён, только если для него настроен ключ: защита от подмены alg
+	switch header.Alg {
+	case "HS256":
+		if len(v.hsSecret) == 0 {
+			return nil, errors.New("HS256 tokens are not accepted")
+		}
+		mac := hmac.New(sha256.New, v.hsSecret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, errors.New("invalid jwt signature")
+		}
+	case "RS256":
+		if v.rsKey == nil {
+			return nil, errors.New("RS256 tokens are not accepted")
+		}
+		digest := sha256.Sum256(signed)
+		if err := rsa.VerifyPKCS1v15(v.rsKey, crypto.SHA256, digest[:], signature); err != nil {
+			return nil, errors.New("invalid jwt signature")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported jwt alg '%s'", header.Alg)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("jwt claims: %w", err)
+	}
+
+	now := v.now().Unix()
+	if claims.ExpiresAt == nil || now >= *claims.ExpiresAt {
+		return nil, errors.New("jwt expired")
+	}
+	if claims.NotBefore != nil && now < *claims.NotBefore {
+		return nil, errors.New("jwt not valid yet")
+	}
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return nil, errors.New("unexpected jwt issuer")
+	}
+	if v.audience != "" && !hasAudience(claims.Audience, v.audience) {
+		return nil, errors.New("unexpected jwt audience")
+	}
+	if claims.Subject == "" || !IsKnownRole(claims.Role) {
+		return nil, errors.New("jwt must carry sub and a known role")
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		Role:    claims.Role,
+		Team:    claims.Team,
+		Tenant:  claims.Tenant,
+	}, nil
+}
+
+func decodeSegment(segment string, dest any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+// aud по RFC 7519 может быть строкой или массивом строк
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == audience
+	}
+	var many []string
+	if err := json.Unmarshal(raw, &many); err == nil {
+		for _, a := range many {
+			if a == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("public key is not RSA")
+		}
+		return rsaKey, nil
+	}
+	return x509.ParsePKCS1PublicKey(block.Bytes)
+}
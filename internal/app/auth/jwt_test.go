@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+func segment(t *testing.T, v any) string {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func signHS256(t *testing.T, secret []byte, header, claims any) string {
+	t.Helper()
+	signed := segment(t, header) + "." + segment(t, claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, header, claims any) string {
+	t.Helper()
+	signed := segment(t, header) + "." + segment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func rsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func publicPEM(t *testing.T, key *rsa.PrivateKey) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// claims - валидные claims; mutate портит нужное поле
+func claims(mutate func(c map[string]any)) map[string]any {
+	c := map[string]any{
+		"sub":    "alice",
+		"role":   RoleTeamLead,
+		"team":   "backend",
+		"tenant": "acme",
+		"iss":    "idp",
+		"aud":    "reviewers",
+		"exp":    testNow.Add(time.Hour).Unix(),
+		"nbf":    testNow.Add(-time.Minute).Unix(),
+	}
+	if mutate != nil {
+		mutate(c)
+	}
+	return c
+}
+
+func TestVerifyHS256(t *testing.T) {
+	secret := []byte("hs-secret")
+	v := &jwtVerifier{hsSecret: secret, issuer: "idp", audience: "reviewers", now: func() time.Time { return testNow }}
+	hs := map[string]string{"alg": "HS256", "typ": "JWT"}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "valid", token: signHS256(t, secret, hs, claims(nil))},
+		{name: "audience in array", token: signHS256(t, secret, hs, claims(func(c map[string]any) { c["aud"] = []string{"other", "reviewers"} }))},
+		{name: "no nbf", token: signHS256(t, secret, hs, claims(func(c map[string]any) { delete(c, "nbf") }))},
+		{name: "wrong secret", token: signHS256(t, []byte("other"), hs, claims(nil)), wantErr: "invalid jwt signature"},
+		{name: "tampered claims", token: func() string {
+			parts := strings.Split(signHS256(t, secret, hs, claims(nil)), ".")
+			parts[1] = segment(t, claims(func(c map[string]any) { c["role"] = RoleAdmin }))
+			return strings.Join(parts, ".")
+		}(), wantErr: "invalid jwt signature"},
+		{name: "alg none", token: segment(t, map[string]string{"alg": "none"}) + "." + segment(t, claims(nil)) + ".", wantErr: "unsupported jwt alg"},
+		{name: "RS256 not configured", token: signRS256(t, rsaKey(t), map[string]string{"alg": "RS256"}, claims(nil)), wantErr: "RS256 tokens are not accepted"},
+		{name: "missing exp", token: signHS256(t, secret, hs, claims(func(c map[string]any) { delete(c, "exp") })), wantErr: "jwt expired"},
+		{name: "expired", token: signHS256(t, secret, hs, claims(func(c map[string]any) { c["exp"] = testNow.Unix() })), wantErr: "jwt expired"},
+		{name: "not valid yet", token: signHS256(t, secret, hs, claims(func(c map[string]any) { c["nbf"] = testNow.Add(time.Minute).Unix() })), wantErr: "jwt not valid yet"},
+		{name: "wrong issuer", token: signHS256(t, secret, hs, claims(func(c map[string]any) { c["iss"] = "evil" })), wantErr: "unexpected jwt issuer"},
+		{name: "wrong audience", token: signHS256(t, secret, hs, claims(func(c map[string]any) { c["aud"] = "billing" })), wantErr: "unexpected jwt audience"},
+		{name: "missing audience", token: signHS256(t, secret, hs, claims(func(c map[string]any) { delete(c, "aud") })), wantErr: "unexpected jwt audience"},
+		{name: "unknown role", token: signHS256(t, secret, hs, claims(func(c map[string]any) { c["role"] = "root" })), wantErr: "known role"},
+		{name: "missing sub", token: signHS256(t, secret, hs, claims(func(c map[string]any) { delete(c, "sub") })), wantErr: "known role"},
+		{name: "malformed", token: "a.b", wantErr: "malformed jwt"},
+		{name: "bad signature encoding", token: segment(t, hs) + "." + segment(t, claims(nil)) + ".!!", wantErr: "jwt signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := v.verify(tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Subject != "alice" || p.Role != RoleTeamLead || p.Team != "backend" || p.Tenant != "acme" {
+				t.Fatalf("unexpected principal %+v", p)
+			}
+		})
+	}
+}
+
+func TestVerifyRS256(t *testing.T) {
+	key := rsaKey(t)
+	pub, err := parseRSAPublicKey(publicPEM(t, key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := &jwtVerifier{rsKey: pub, now: func() time.Time { return testNow }}
+	rs := map[string]string{"alg": "RS256"}
+
+	if _, err := v.verify(signRS256(t, key, rs, claims(nil))); err != nil {
+		t.Fatalf("valid RS256 token rejected: %v", err)
+	}
+	if _, err := v.verify(signRS256(t, rsaKey(t), rs, claims(nil))); err == nil || !strings.Contains(err.Error(), "invalid jwt signature") {
+		t.Fatalf("token signed by another key: expected invalid signature, got %v", err)
+	}
+	// подмена alg: HS256, подписанный публичным ключом как секретом, не принимается
+	confused := signHS256(t, publicPEM(t, key), map[string]string{"alg": "HS256"}, claims(nil))
+	if _, err := v.verify(confused); err == nil || !strings.Contains(err.Error(), "HS256 tokens are not accepted") {
+		t.Fatalf("alg confusion: expected HS256 to be rejected, got %v", err)
+	}
+}
+
+func TestParseRSAPublicKey(t *testing.T) {
+	key := rsaKey(t)
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})
+	for name, data := range map[string][]byte{"pkix": publicPEM(t, key), "pkcs1": pkcs1} {
+		got, err := parseRSAPublicKey(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !got.Equal(&key.PublicKey) {
+			t.Fatalf("%s: parsed a different key", name)
+		}
+	}
+	if _, err := parseRSAPublicKey([]byte("not a pem")); err == nil {
+		t.Fatal("expected an error for data without a PEM block")
+	}
+}
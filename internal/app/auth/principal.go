@@ -0,0 +1,69 @@
+package auth
+
+import "context"
+
+const (
+	RoleAdmin    = "admin"
+	RoleTeamLead = "team-lead"
+	RoleMember   = "member"
+)
+
+// Principal - аутентифицированный субъект запроса
+type Principal struct {
+	Subject string `json:"subject"`
+	Role    string `json:"role"`
+	// Team - команда, которой руководит team-lead
+	Team string `json:"team,omitempty"`
+	// Teams - если задано, доступ ограничен этими командами (API-ключи)
+	Teams []string `json:"teams,omitempty"`
+	// ReadOnly - только чтение (API-ключи со scope read)
+	ReadOnly bool `json:"read_only,omitempty"`
+	// Tenant - арендатор, к которому привязаны учётные данные; пусто - не привязаны
+	Tenant string `json:"tenant,omitempty"`
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p.Role == RoleAdmin
+}
+
+func (p *Principal) LeadsTeam(teamName string) bool {
+	if p.Role != RoleTeamLead || !p.CanAccessTeam(teamName) {
+		return false
+	}
+	return (p.Team != "" && p.Team == teamName) || len(p.Teams) > 0
+}
+
+func (p *Principal) CanAccessTeam(teamName string) bool {
+	if len(p.Teams) == 0 {
+		return true
+	}
+	for _, t := range p.Teams {
+		if t == teamName {
+			return true
+		}
+	}
+	return false
+}
+
+func IsKnownRole(role string) bool {
+	return role == RoleAdmin || role == RoleTeamLead || role == RoleMember
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Actor - строка для аудита: кто выполнил операцию
+func Actor(ctx context.Context) string {
+	if p := FromContext(ctx); p != nil {
+		return p.Subject
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package availability
+
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"time"
+)
+
+type Repo interface {
+	create(ctx context.Context, entity *WindowEntity) (*WindowEntity, error)
+	getByID(ctx context.Context, id uint64) (*WindowEntity, error)
+	listByUser(ctx context.Context, userID string) ([]*WindowEntity, error)
+	delete(ctx context.Context, id uint64) error
+	// listStarted возвращает ещё не переданные окна с handover, начавшиеся к now
+	listStarted(ctx context.Context, now time.Time, limit int) ([]*WindowEntity, error)
+	// markHandedOver отмечает окно переданным, после этого оно больше не обрабатывается
+	markHandedOver(ctx context.Context, id uint64, at time.Time) error
+}
+
+type Availability struct {
+	repo Repo
+}
+
+func NewAvailability(repo Repo) *Availability {
+	return &Availability{
+		repo: repo,
+	}
+}
+
+func (a *Availability) Create(ctx context.Context, dto *WindowDTO) (*WindowDTO, error) {
+	if dto.StartsAt.IsZero() || dto.EndsAt.IsZero() {
+		return nil, fmt.Errorf("%w: starts_at and ends_at are required", apperrors.ErrBadRequest)
+	}
+	if !dto.EndsAt.After(dto.StartsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", apperrors.ErrBadRequest)
+	}
+	entity, err := a.repo.create(ctx, dto.MapToModel())
+	if err != nil {
+		return nil, err
+	}
+	var answer WindowDTO
+	answer.MapFromModel(entity)
+	return &answer, nil
+}
+
+func (a *Availability) GetByID(ctx context.Context, id uint64) (*WindowDTO, error) {
+	entity, err := a.repo.getByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var answer WindowDTO
+	answer.MapFromModel(entity)
+	return &answer, nil
+}
+
+// List возвращает окна пользователя по времени начала, включая прошедшие
+func (a *Availability) List(ctx context.Context, userID string) ([]*WindowDTO, error) {
+	entities, err := a.repo.listByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return MapFromModels(entities), nil
+}
+
+func (a *Availability) Delete(ctx context.Context, id uint64) error {
+	return a.repo.delete(ctx, id)
+}
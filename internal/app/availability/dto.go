@@ -0,0 +1,46 @@
+package availability
+
+import "time"
+
+// WindowDTO - окно недоступности [starts_at, ends_at); handover - передать открытые ревью при начале окна
+type WindowDTO struct {
+	ID           uint64     `json:"id"`
+	UserID       string     `json:"user_id"`
+	StartsAt     time.Time  `json:"starts_at"`
+	EndsAt       time.Time  `json:"ends_at"`
+	Reason       string     `json:"reason"`
+	Handover     bool       `json:"handover"`
+	HandedOverAt *time.Time `json:"handed_over_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+func (w *WindowDTO) MapToModel() *WindowEntity {
+	return &WindowEntity{
+		UserID:   w.UserID,
+		StartsAt: w.StartsAt.UTC(),
+		EndsAt:   w.EndsAt.UTC(),
+		Reason:   w.Reason,
+		Handover: w.Handover,
+	}
+}
+
+func (w *WindowDTO) MapFromModel(entity *WindowEntity) {
+	w.ID = entity.ID
+	w.UserID = entity.UserID
+	w.StartsAt = entity.StartsAt
+	w.EndsAt = entity.EndsAt
+	w.Reason = entity.Reason
+	w.Handover = entity.Handover
+	w.HandedOverAt = entity.HandedOverAt
+	w.CreatedAt = entity.CreatedAt
+}
+
+func MapFromModels(entities []*WindowEntity) []*WindowDTO {
+	dto := make([]*WindowDTO, len(entities))
+	for i, v := range entities {
+		var w WindowDTO
+		w.MapFromModel(v)
+		dto[i] = &w
+	}
+	return dto
+}
@@ -0,0 +1,14 @@
+package availability
+
+import "time"
+
+type WindowEntity struct {
+	ID           uint64     `db:"id"`
+	UserID       string     `db:"user_id"`
+	StartsAt     time.Time  `db:"starts_at"`
+	EndsAt       time.Time  `db:"ends_at"`
+	Reason       string     `db:"reason"`
+	Handover     bool       `db:"handover"`
+	HandedOverAt *time.Time `db:"handed_over_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
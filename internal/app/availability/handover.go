@@ -0,0 +1,90 @@
+package availability
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/clock"
+	"context"
+	"errors"
+	"log"
+)
+
+type Reviews interface {
+	GetReview(ctx context.Context, userID string) ([]*pullrequest.PullRequestShortDTOFromHttp, error)
+}
+
+type Reassigner interface {
+	Reassign(ctx context.Context, prID string, oldUserID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, string, error)
+}
+
+// Handover передаёт открытые ревью пользователя, когда начинается его окно недоступности с handover.
+// Окно отмечается переданным только после обработки всех его ревью. Ревью, для которых не нашлось
+// замены (NO_CANDIDATE), остаются за пользователем; при других ошибках окно не отмечается и
+// повторяется на следующем запуске, пока не закончится. Уже переданные ревью повтор не трогает.
+type Handover struct {
+	repo      Repo
+	reviews   Reviews
+	prs       Reassigner
+	clock     clock.Clock
+	batchSize int
+}
+
+func NewHandover(a *Availability, reviews Reviews, prs Reassigner, clk clock.Clock, batchSize int) *Handover {
+	return &Handover{
+		repo:      a.repo,
+		reviews:   reviews,
+		prs:       prs,
+		clock:     clk,
+		batchSize: batchSize,
+	}
+}
+
+// RunOnce обрабатывает начавшиеся окна и возвращает число переданных ревью
+func (h *Handover) RunOnce(ctx context.Context) (int, error) {
+	now := h.clock.Now()
+	windows, err := h.repo.listStarted(ctx, now, h.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	handed := 0
+	for _, w := range windows {
+		n, done := h.handOver(ctx, w)
+		handed += n
+		if !done {
+			continue
+		}
+		if err := h.repo.markHandedOver(ctx, w.ID, now); err != nil {
+			return handed, err
+		}
+	}
+	return handed, nil
+}
+
+// handOver переназначает открытые ревью окна. done - false, если что-то сорвалось и окно надо повторить
+func (h *Handover) handOver(ctx context.Context, w *WindowEntity) (handed int, done bool) {
+	prs, err := h.reviews.GetReview(ctx, w.UserID)
+	if err != nil {
+		log.Printf("[Handover.RunOnce] failed to list reviews of user '%s' for window %d, will retry: %v", w.UserID, w.ID, err)
+		return 0, false
+	}
+	done = true
+	for _, pr := range prs {
+		if pr.Status != pullrequest.StatusOpen {
+			continue
+		}
+		_, replacedBy, err := h.prs.Reassign(ctx, pr.PullRequestID, w.UserID, nil)
+		if err != nil {
+			// без замены или PR уже изменился - окончательный исход, остальное повторяем
+			final := errors.Is(err, apperrors.ErrNoCandidate) || errors.Is(err, apperrors.ErrNotAssigned) ||
+				errors.Is(err, apperrors.ErrPRMerged) || errors.Is(err, apperrors.ErrNotFound)
+			if !final {
+				done = false
+			}
+			log.Printf("[Handover.RunOnce] PR '%s' stays with user '%s' (retry: %v): %v", pr.PullRequestID, w.UserID, !final, err)
+			continue
+		}
+		log.Printf("[Handover.RunOnce] PR '%s' handed over from '%s' to '%s' (window %d)", pr.PullRequestID, w.UserID, replacedBy, w.ID)
+		handed++
+	}
+	return handed, done
+}
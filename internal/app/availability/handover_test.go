@@ -0,0 +1,138 @@
+package availability
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/clock"
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeRepo - одно начавшееся окно пользователя u1
+type fakeRepo struct {
+	Repo
+	window *WindowEntity
+}
+
+func (f *fakeRepo) listStarted(context.Context, time.Time, int) ([]*WindowEntity, error) {
+	if f.window.HandedOverAt != nil {
+		return nil, nil
+	}
+	return []*WindowEntity{f.window}, nil
+}
+
+func (f *fakeRepo) markHandedOver(_ context.Context, _ uint64, at time.Time) error {
+	f.window.HandedOverAt = &at
+	return nil
+}
+
+// fakePRs - открытые ревью u1; Reassign отвечает ошибками из errs по очереди, затем успехом
+type fakePRs struct {
+	open     []string
+	errs     map[string][]error
+	reviewFn func() error
+}
+
+func (f *fakePRs) GetReview(context.Context, string) ([]*pullrequest.PullRequestShortDTOFromHttp, error) {
+	if f.reviewFn != nil {
+		if err := f.reviewFn(); err != nil {
+			return nil, err
+		}
+	}
+	var prs []*pullrequest.PullRequestShortDTOFromHttp
+	for _, id := range f.open {
+		prs = append(prs, &pullrequest.PullRequestShortDTOFromHttp{PullRequestID: id, Status: pullrequest.StatusOpen})
+	}
+	return prs, nil
+}
+
+func (f *fakePRs) Reassign(_ context.Context, prID string, _ string, _ *uint64) (*pullrequest.PullRequestDTOFromHttp, string, error) {
+	if errs := f.errs[prID]; len(errs) > 0 {
+		f.errs[prID] = errs[1:]
+		return nil, "", errs[0]
+	}
+	f.open = slices.DeleteFunc(f.open, func(id string) bool { return id == prID })
+	return &pullrequest.PullRequestDTOFromHttp{}, "u2", nil
+}
+
+func setup(prs *fakePRs) (*Handover, *fakeRepo) {
+	repo := &fakeRepo{window: &WindowEntity{ID: 1, UserID: "u1", Handover: true}}
+	clk := clock.NewManual(time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC))
+	return NewHandover(NewAvailability(repo), prs, prs, clk, 10), repo
+}
+
+func runOnce(t *testing.T, h *Handover) int {
+	t.Helper()
+	n, err := h.RunOnce(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestHandoverRetriesWindowAfterTransientError(t *testing.T) {
+	prs := &fakePRs{open: []string{"pr1", "pr2"}, errs: map[string][]error{"pr2": {apperrors.ErrDB}}}
+	h, repo := setup(prs)
+
+	if n := runOnce(t, h); n != 1 {
+		t.Fatalf("expected 1 handed over, got %d", n)
+	}
+	if repo.window.HandedOverAt != nil {
+		t.Fatal("window with a failed reassign must not be marked")
+	}
+
+	// повтор передаёт оставшееся ревью и отмечает окно
+	if n := runOnce(t, h); n != 1 || repo.window.HandedOverAt == nil {
+		t.Fatalf("expected retry to hand over pr2 and mark the window, got %d, marked %v", n, repo.window.HandedOverAt)
+	}
+	if n := runOnce(t, h); n != 0 {
+		t.Fatalf("marked window handed over again: %d", n)
+	}
+}
+
+func TestHandoverRetriesWindowWhenReviewsFail(t *testing.T) {
+	calls := 0
+	prs := &fakePRs{open: []string{"pr1"}, errs: map[string][]error{}, reviewFn: func() error {
+		calls++
+		if calls == 1 {
+			return apperrors.ErrDB
+		}
+		return nil
+	}}
+	h, repo := setup(prs)
+
+	runOnce(t, h)
+	if repo.window.HandedOverAt != nil {
+		t.Fatal("window must not be marked when reviews could not be listed")
+	}
+	if n := runOnce(t, h); n != 1 || repo.window.HandedOverAt == nil {
+		t.Fatalf("expected retry to hand over pr1, got %d", n)
+	}
+}
+
+func TestHandoverMarksWindowWithoutCandidate(t *testing.T) {
+	prs := &fakePRs{open: []string{"pr1"}, errs: map[string][]error{"pr1": {apperrors.ErrNoCandidate}}}
+	h, repo := setup(prs)
+
+	if n := runOnce(t, h); n != 0 {
+		t.Fatalf("expected nothing handed over, got %d", n)
+	}
+	// ревью без замены остаётся за пользователем, окно не повторяется
+	if repo.window.HandedOverAt == nil || !slices.Equal(prs.open, []string{"pr1"}) {
+		t.Fatalf("expected window marked with pr1 kept, marked %v, open %v", repo.window.HandedOverAt, prs.open)
+	}
+}
@@ -0,0 +1,156 @@
+package availability
+
+import (
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/memory"
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"time"
+)
+
+// AvailabilityMemoryRepo - реализация Repo поверх memory.Store с той же семантикой, что у AvailabilityRepo
+type AvailabilityMemoryRepo struct {
+	store *memory.Store
+}
+
+func NewAvailabilityMemoryRepo(store *memory.Store) *AvailabilityMemoryRepo {
+	return &AvailabilityMemoryRepo{store: store}
+}
+
+func toEntity(w *memory.Window) *WindowEntity {
+	return &WindowEntity{
+		ID:           w.ID,
+		UserID:       w.UserID,
+		StartsAt:     w.StartsAt,
+		EndsAt:       w.EndsAt,
+		Reason:       w.Reason,
+		Handover:     w.Handover,
+		HandedOverAt: w.HandedOverAt,
+		CreatedAt:    w.CreatedAt,
+	}
+}
+
+func (a *AvailabilityMemoryRepo) create(ctx context.Context, entity *WindowEntity) (*WindowEntity, error) {
+	var created *WindowEntity
+	err := a.store.Write(ctx, func(d *memory.Data) error {
+		// user_id ссылается на users, как внешний ключ в Postgres
+		if _, ok := d.Users[entity.UserID]; !ok {
+			log.Printf("[AvailabilityMemoryRepo.create] user '%s' not found", entity.UserID)
+			return apperrors.ErrNotFound
+		}
+		w := &memory.Window{
+			ID:        d.NextWindowID(),
+			UserID:    entity.UserID,
+			StartsAt:  entity.StartsAt,
+			EndsAt:    entity.EndsAt,
+			Reason:    entity.Reason,
+			Handover:  entity.Handover,
+			CreatedAt: time.Now().UTC(),
+		}
+		d.Windows[w.ID] = w
+		created = toEntity(w)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[AvailabilityMemoryRepo.create] window %d created for user '%s'", created.ID, created.UserID)
+	return created, nil
+}
+
+func (a *AvailabilityMemoryRepo) getByID(ctx context.Context, id uint64) (*WindowEntity, error) {
+	var entity *WindowEntity
+	err := a.store.Read(ctx, func(d *memory.Data) error {
+		w, ok := d.Windows[id]
+		if !ok {
+			log.Printf("[AvailabilityMemoryRepo.getByID] window %d not found", id)
+			return apperrors.ErrNotFound
+		}
+		entity = toEntity(w)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (a *AvailabilityMemoryRepo) listByUser(ctx context.Context, userID string) ([]*WindowEntity, error) {
+	var entities []*WindowEntity
+	err := a.store.Read(ctx, func(d *memory.Data) error {
+		if _, ok := d.Users[userID]; !ok {
+			log.Printf("[AvailabilityMemoryRepo.listByUser] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		for _, w := range d.Windows {
+			if w.UserID == userID {
+				entities = append(entities, toEntity(w))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortWindows(entities)
+	return entities, nil
+}
+
+func (a *AvailabilityMemoryRepo) delete(ctx context.Context, id uint64) error {
+	err := a.store.Write(ctx, func(d *memory.Data) error {
+		if _, ok := d.Windows[id]; !ok {
+			log.Printf("[AvailabilityMemoryRepo.delete] window %d not found", id)
+			return apperrors.ErrNotFound
+		}
+		delete(d.Windows, id)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[AvailabilityMemoryRepo.delete] window %d deleted", id)
+	return nil
+}
+
+func (a *AvailabilityMemoryRepo) listStarted(ctx context.Context, now time.Time, limit int) ([]*WindowEntity, error) {
+	var entities []*WindowEntity
+	err := a.store.Read(ctx, func(d *memory.Data) error {
+		var started []*memory.Window
+		for _, w := range d.Windows {
+			if w.Handover && w.HandedOverAt == nil && !now.Before(w.StartsAt) && now.Before(w.EndsAt) {
+				started = append(started, w)
+			}
+		}
+		slices.SortFunc(started, func(x, y *memory.Window) int {
+			return x.StartsAt.Compare(y.StartsAt)
+		})
+		for _, w := range started[:min(limit, len(started))] {
+			entities = append(entities, toEntity(w))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (a *AvailabilityMemoryRepo) markHandedOver(ctx context.Context, id uint64, at time.Time) error {
+	return a.store.Write(ctx, func(d *memory.Data) error {
+		if w, ok := d.Windows[id]; ok && w.HandedOverAt == nil {
+			w.HandedOverAt = &at
+		}
+		return nil
+	})
+}
+
+func sortWindows(entities []*WindowEntity) {
+	slices.SortFunc(entities, func(x, y *WindowEntity) int {
+		if c := x.StartsAt.Compare(y.StartsAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.ID, y.ID)
+	})
+}
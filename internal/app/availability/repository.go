@@ -0,0 +1,141 @@
+package availability
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type DB interface {
+	Get(ctx context.Context, dest any, query string, args ...any) error
+	Select(ctx context.Context, dest any, query string, args ...any) error
+	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+	ExecQueryRow(ctx context.Context, query string, args ...any) pgx.Row
+}
+
+type AvailabilityRepo struct {
+	db DB
+}
+
+func NewAvailabilityRepo(db DB) *AvailabilityRepo {
+	return &AvailabilityRepo{db: db}
+}
+
+func (a *AvailabilityRepo) create(ctx context.Context, entity *WindowEntity) (*WindowEntity, error) {
+	err := a.db.ExecQueryRow(ctx, `
+		INSERT INTO user_unavailability (tenant_id, user_id, starts_at, ends_at, reason, handover)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, tenant.FromContext(ctx), entity.UserID, entity.StartsAt, entity.EndsAt, entity.Reason, entity.Handover).Scan(&entity.ID, &entity.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			log.Printf("[AvailabilityRepo.create] user '%s' not found", entity.UserID)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[AvailabilityRepo.create] db error inserting window for user '%s': %v", entity.UserID, err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[AvailabilityRepo.create] window %d created for user '%s'", entity.ID, entity.UserID)
+	return entity, nil
+}
+
+func (a *AvailabilityRepo) getByID(ctx context.Context, id uint64) (*WindowEntity, error) {
+	var entity WindowEntity
+	err := a.db.Get(ctx, &entity, `
+		SELECT id, user_id, starts_at, ends_at, reason, handover, handed_over_at, created_at
+		FROM user_unavailability
+		WHERE tenant_id = $1 AND id = $2
+	`, tenant.FromContext(ctx), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[AvailabilityRepo.getByID] window %d not found", id)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[AvailabilityRepo.getByID] db error fetching window %d: %v", id, err)
+		return nil, apperrors.ErrDB
+	}
+	return &entity, nil
+}
+
+func (a *AvailabilityRepo) listByUser(ctx context.Context, userID string) ([]*WindowEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var exists bool
+	err := a.db.Get(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE tenant_id=$1 AND user_id=$2)", tenantID, userID)
+	if err != nil {
+		log.Printf("[AvailabilityRepo.listByUser] db error checking existence of user '%s': %v", userID, err)
+		return nil, apperrors.ErrDB
+	}
+	if !exists {
+		log.Printf("[AvailabilityRepo.listByUser] user '%s' not found", userID)
+		return nil, apperrors.ErrNotFound
+	}
+
+	var entities []*WindowEntity
+	err = a.db.Select(ctx, &entities, `
+		SELECT id, user_id, starts_at, ends_at, reason, handover, handed_over_at, created_at
+		FROM user_unavailability
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY starts_at, id
+	`, tenantID, userID)
+	if err != nil {
+		log.Printf("[AvailabilityRepo.listByUser] db error fetching windows of user '%s': %v", userID, err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[AvailabilityRepo.listByUser] fetched %d windows of user '%s'", len(entities), userID)
+	return entities, nil
+}
+
+func (a *AvailabilityRepo) delete(ctx context.Context, id uint64) error {
+	tag, err := a.db.Exec(ctx, "DELETE FROM user_unavailability WHERE tenant_id=$1 AND id=$2", tenant.FromContext(ctx), id)
+	if err != nil {
+		log.Printf("[AvailabilityRepo.delete] db error deleting window %d: %v", id, err)
+		return apperrors.ErrDB
+	}
+	if tag.RowsAffected() == 0 {
+		log.Printf("[AvailabilityRepo.delete] window %d not found", id)
+		return apperrors.ErrNotFound
+	}
+	log.Printf("[AvailabilityRepo.delete] window %d deleted", id)
+	return nil
+}
+
+// listStarted только читает окна: отметка ставится после передачи, и окно, передача которого сорвалась,
+// повторяется на следующем запуске. Одновременно задачу выполняет одна реплика (лидерство планировщика).
+func (a *AvailabilityRepo) listStarted(ctx context.Context, now time.Time, limit int) ([]*WindowEntity, error) {
+	var entities []*WindowEntity
+	err := a.db.Select(ctx, &entities, `
+		SELECT id, user_id, starts_at, ends_at, reason, handover, handed_over_at, created_at
+		FROM user_unavailability
+		WHERE tenant_id = $3
+		  AND handover
+		  AND handed_over_at IS NULL
+		  AND starts_at <= $1
+		  AND ends_at > $1
+		ORDER BY starts_at
+		LIMIT $2
+	`, now, limit, tenant.FromContext(ctx))
+	if err != nil {
+		log.Printf("[AvailabilityRepo.listStarted] db error fetching started windows: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	return entities, nil
+}
+
+func (a *AvailabilityRepo) markHandedOver(ctx context.Context, id uint64, at time.Time) error {
+	_, err := a.db.Exec(ctx, `
+		UPDATE user_unavailability SET handed_over_at = $3
+		WHERE tenant_id = $1 AND id = $2 AND handed_over_at IS NULL
+	`, tenant.FromContext(ctx), id, at)
+	if err != nil {
+		log.Printf("[AvailabilityRepo.markHandedOver] db error marking window %d: %v", id, err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package availability
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/sqlite"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// AvailabilitySQLiteRepo - реализация Repo поверх встроенного SQLite с той же семантикой, что у AvailabilityRepo.
+// Время хранится в UTC.
+type AvailabilitySQLiteRepo struct {
+	db *sqlite.DB
+}
+
+func NewAvailabilitySQLiteRepo(db *sqlite.DB) *AvailabilitySQLiteRepo {
+	return &AvailabilitySQLiteRepo{db: db}
+}
+
+const windowColumns = "id, user_id, starts_at, ends_at, reason, handover, handed_over_at, created_at"
+
+func scanWindow(row interface{ Scan(dest ...any) error }) (*WindowEntity, error) {
+	var e WindowEntity
+	err := row.Scan(&e.ID, &e.UserID, &e.StartsAt, &e.EndsAt, &e.Reason, &e.Handover, &e.HandedOverAt, &e.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (a *AvailabilitySQLiteRepo) create(ctx context.Context, entity *WindowEntity) (*WindowEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	err := a.db.Write(ctx, func(q sqlite.Querier) error {
+		var exists bool
+		if err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE tenant_id = ? AND user_id = ?)", tenantID, entity.UserID).Scan(&exists); err != nil {
+			log.Printf("[AvailabilitySQLiteRepo.create] db error checking existence of user '%s': %v", entity.UserID, err)
+			return apperrors.ErrDB
+		}
+		if !exists {
+			log.Printf("[AvailabilitySQLiteRepo.create] user '%s' not found", entity.UserID)
+			return apperrors.ErrNotFound
+		}
+
+		entity.CreatedAt = time.Now().UTC()
+		err := q.QueryRowContext(ctx, `
+			INSERT INTO user_unavailability (tenant_id, user_id, starts_at, ends_at, reason, handover, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			RETURNING id
+		`, tenantID, entity.UserID, entity.StartsAt.UTC(), entity.EndsAt.UTC(), entity.Reason, entity.Handover, entity.CreatedAt).Scan(&entity.ID)
+		if err != nil {
+			log.Printf("[AvailabilitySQLiteRepo.create] db error inserting window for user '%s': %v", entity.UserID, err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[AvailabilitySQLiteRepo.create] window %d created for user '%s'", entity.ID, entity.UserID)
+	return entity, nil
+}
+
+func (a *AvailabilitySQLiteRepo) getByID(ctx context.Context, id uint64) (*WindowEntity, error) {
+	var entity *WindowEntity
+	err := a.db.Read(ctx, func(q sqlite.Querier) error {
+		var err error
+		entity, err = scanWindow(q.QueryRowContext(ctx, "SELECT "+windowColumns+" FROM user_unavailability WHERE tenant_id = ? AND id = ?", tenant.FromContext(ctx), id))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[AvailabilitySQLiteRepo.getByID] window %d not found", id)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[AvailabilitySQLiteRepo.getByID] db error fetching window %d: %v", id, err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (a *AvailabilitySQLiteRepo) listByUser(ctx context.Context, userID string) ([]*WindowEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var entities []*WindowEntity
+	err := a.db.Read(ctx, func(q sqlite.Querier) error {
+		var exists bool
+		if err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE tenant_id = ? AND user_id = ?)", tenantID, userID).Scan(&exists); err != nil {
+			log.Printf("[AvailabilitySQLiteRepo.listByUser] db error checking existence of user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		if !exists {
+			log.Printf("[AvailabilitySQLiteRepo.listByUser] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+
+		rows, err := q.QueryContext(ctx, "SELECT "+windowColumns+" FROM user_unavailability WHERE tenant_id = ? AND user_id = ? ORDER BY starts_at, id", tenantID, userID)
+		if err != nil {
+			log.Printf("[AvailabilitySQLiteRepo.listByUser] db error fetching windows of user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+		for rows.Next() {
+			e, err := scanWindow(rows)
+			if err != nil {
+				log.Printf("[AvailabilitySQLiteRepo.listByUser] failed to scan window of user '%s': %v", userID, err)
+				return apperrors.ErrDB
+			}
+			entities = append(entities, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (a *AvailabilitySQLiteRepo) delete(ctx context.Context, id uint64) error {
+	err := a.db.Write(ctx, func(q sqlite.Querier) error {
+		res, err := q.ExecContext(ctx, "DELETE FROM user_unavailability WHERE tenant_id = ? AND id = ?", tenant.FromContext(ctx), id)
+		if err != nil {
+			log.Printf("[AvailabilitySQLiteRepo.delete] db error deleting window %d: %v", id, err)
+			return apperrors.ErrDB
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			log.Printf("[AvailabilitySQLiteRepo.delete] window %d not found", id)
+			return apperrors.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[AvailabilitySQLiteRepo.delete] window %d deleted", id)
+	return nil
+}
+
+func (a *AvailabilitySQLiteRepo) listStarted(ctx context.Context, now time.Time, limit int) ([]*WindowEntity, error) {
+	var entities []*WindowEntity
+	now = now.UTC()
+	err := a.db.Read(ctx, func(q sqlite.Querier) error {
+		rows, err := q.QueryContext(ctx, `
+			SELECT `+windowColumns+`
+			FROM user_unavailability
+			WHERE tenant_id = ?
+			  AND handover
+			  AND handed_over_at IS NULL
+			  AND starts_at <= ?
+			  AND ends_at > ?
+			ORDER BY starts_at
+			LIMIT ?
+		`, tenant.FromContext(ctx), now, now, limit)
+		if err != nil {
+			log.Printf("[AvailabilitySQLiteRepo.listStarted] db error fetching started windows: %v", err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+		for rows.Next() {
+			e, err := scanWindow(rows)
+			if err != nil {
+				log.Printf("[AvailabilitySQLiteRepo.listStarted] failed to scan window: %v", err)
+				return apperrors.ErrDB
+			}
+			entities = append(entities, e)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func (a *AvailabilitySQLiteRepo) markHandedOver(ctx context.Context, id uint64, at time.Time) error {
+	return a.db.Write(ctx, func(q sqlite.Querier) error {
+		_, err := q.ExecContext(ctx, `
+			UPDATE user_unavailability SET handed_over_at = ?
+			WHERE tenant_id = ? AND id = ? AND handed_over_at IS NULL
+		`, at.UTC(), tenant.FromContext(ctx), id)
+		if err != nil {
+			log.Printf("[AvailabilitySQLiteRepo.markHandedOver] db error marking window %d: %v", id, err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+}
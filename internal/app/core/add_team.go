@@ -6,8 +6,10 @@ import (
 )
 
 type AddTeamRequest struct {
-	TeamName string               `json:"team_name"`
-	Members  []team.TeamMemberDTO `json:"members"`
+	TeamName string `json:"team_name"`
+	// MaxOpenReviews - лимит открытых ревью по умолчанию для участников команды
+	MaxOpenReviews *int                 `json:"max_open_reviews,omitempty"`
+	Members        []team.TeamMemberDTO `json:"members"`
 }
 
 type AddTeamResponse struct {
@@ -15,15 +17,27 @@ type AddTeamResponse struct {
 }
 
 func (s *Service) AddTeam(ctx context.Context, req *AddTeamRequest) (*AddTeamResponse, error) {
-	dto := &team.TeamDTO{
-		TeamName: req.TeamName,
-		Members:  req.Members,
-	}
-	err := s.team.Create(ctx, dto)
-	if err != nil {
+	if err := requireTeamLead(ctx, req.TeamName); err != nil {
 		return nil, err
 	}
-	createdTeam, err := s.team.GetByTeamName(ctx, req.TeamName)
+	dto := &team.TeamDTO{
+		TeamName:       req.TeamName,
+		MaxOpenReviews: req.MaxOpenReviews,
+		Members:        req.Members,
+	}
+	// ответ читается в той же транзакции, чтобы не увидеть чужие изменения между вызовами
+	var createdTeam *team.TeamDTO
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.requireOwnMembers(ctx, req.TeamName, req.Members); err != nil {
+			return err
+		}
+		if err := s.team.Create(ctx, dto); err != nil {
+			return err
+		}
+		var err error
+		createdTeam, err = s.team.GetByTeamName(ctx, req.TeamName)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,75 @@
+package core
+
+import (
+	"avito-tech/internal/app/apikey"
+	"context"
+	"time"
+)
+
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Role      string     `json:"role"`
+	Scope     string     `json:"scope"`
+	Teams     []string   `json:"teams"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	APIKey apikey.APIKeyDTO `json:"api_key"`
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys []*apikey.APIKeyDTO `json:"api_keys"`
+}
+
+type RevokeAPIKeyRequest struct {
+	ID uint64 `json:"id"`
+}
+
+func (s *Service) CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest) (*APIKeyResponse, error) {
+	if s.apiKey == nil {
+		return nil, notSupported("API keys")
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	dto, err := s.apiKey.Create(ctx, &apikey.APIKeyDTO{
+		Name:      req.Name,
+		Role:      req.Role,
+		Scope:     req.Scope,
+		Teams:     req.Teams,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &APIKeyResponse{APIKey: *dto}, nil
+}
+
+func (s *Service) ListAPIKeys(ctx context.Context) (*ListAPIKeysResponse, error) {
+	if s.apiKey == nil {
+		return nil, notSupported("API keys")
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	dto, err := s.apiKey.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ListAPIKeysResponse{APIKeys: dto}, nil
+}
+
+func (s *Service) RevokeAPIKey(ctx context.Context, req RevokeAPIKeyRequest) (*APIKeyResponse, error) {
+	if s.apiKey == nil {
+		return nil, notSupported("API keys")
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	dto, err := s.apiKey.Revoke(ctx, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &APIKeyResponse{APIKey: *dto}, nil
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"avito-tech/internal/app/auth"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+)
+
+func requirePrincipal(ctx context.Context) (*auth.Principal, error) {
+	p := auth.FromContext(ctx)
+	if p == nil {
+		return nil, fmt.Errorf("%w: request is not authenticated", apperrors.ErrUnauthorized)
+	}
+	return p, nil
+}
+
+func requireAdmin(ctx context.Context) error {
+	p, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	// ограниченный командами ключ не управляет глобальными настройками
+	if !p.IsAdmin() || len(p.Teams) > 0 {
+		log.Printf("[core.requireAdmin] '%s' (%s) denied", p.Subject, p.Role)
+		return fmt.Errorf("%w: admin role required", apperrors.ErrForbidden)
+	}
+	return nil
+}
+
+// requireTeamLead пропускает администратора или лида указанной команды
+func requireTeamLead(ctx context.Context, teamName string) error {
+	p, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.CanAccessTeam(teamName) || (!p.IsAdmin() && !p.LeadsTeam(teamName)) {
+		log.Printf("[core.requireTeamLead] '%s' (%s) denied for team '%s'", p.Subject, p.Role, teamName)
+		return fmt.Errorf("%w: admin or lead of team '%s' required", apperrors.ErrForbidden, teamName)
+	}
+	return nil
+}
+
+// requireOwnMembers не даёт лиду забрать в свою команду участников других команд:
+// upsert участников переносит пользователя и меняет его активность и лимит
+func (s *Service) requireOwnMembers(ctx context.Context, teamName string, members []team.TeamMemberDTO) error {
+	p, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	if p.IsAdmin() && len(p.Teams) == 0 {
+		return nil
+	}
+	for _, m := range members {
+		u, err := s.user.GetByID(ctx, m.UserID)
+		if errors.Is(err, apperrors.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if u.TeamName != teamName {
+			log.Printf("[core.requireOwnMembers] '%s' (%s) denied moving '%s' from team '%s' to '%s'", p.Subject, p.Role, m.UserID, u.TeamName, teamName)
+			return fmt.Errorf("%w: user '%s' belongs to team '%s', admin role required to move", apperrors.ErrForbidden, m.UserID, u.TeamName)
+		}
+	}
+	return nil
+}
+
+// requireTeamAccess проверяет, что учётные данные не ограничены другими командами
+func requireTeamAccess(ctx context.Context, teamName string) error {
+	p, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	if !p.CanAccessTeam(teamName) {
+		log.Printf("[core.requireTeamAccess] '%s' is not scoped to team '%s'", p.Subject, teamName)
+		return fmt.Errorf("%w: credential is not scoped to team '%s'", apperrors.ErrForbidden, teamName)
+	}
+	return nil
+}
+
+// requireUserTeamAccess - то же для команды пользователя
+func (s *Service) requireUserTeamAccess(ctx context.Context, userID string) error {
+	p, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	if len(p.Teams) == 0 {
+		return nil
+	}
+	u, err := s.user.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	return requireTeamAccess(ctx, u.TeamName)
+}
+
+// requirePullRequestTeamAccess - то же для команды автора PR
+func (s *Service) requirePullRequestTeamAccess(ctx context.Context, prID string) error {
+	p, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	if len(p.Teams) == 0 {
+		return nil
+	}
+	pr, err := s.pullRequest.GetByID(ctx, prID)
+	if err != nil {
+		return err
+	}
+	return s.requireUserTeamAccess(ctx, pr.AuthorID)
+}
+
+// requireAuthor пропускает администратора или автора PR; op - действие для сообщения об ошибке
+func (s *Service) requireAuthor(ctx context.Context, principal *auth.Principal, prID string, op string) error {
+	if principal.IsAdmin() {
+		return nil
+	}
+	pr, err := s.pullRequest.GetByID(ctx, prID)
+	if err != nil {
+		return err
+	}
+	if pr.AuthorID != principal.Subject {
+		log.Printf("[core.requireAuthor] '%s' is not the author of PR '%s'", principal.Subject, prID)
+		return fmt.Errorf("%w: only the author or an admin can %s PR '%s'", apperrors.ErrForbidden, op, prID)
+	}
+	return nil
+}
+
+// requireSelfOrTeamLead пропускает самого пользователя, лида его команды или администратора
+func (s *Service) requireSelfOrTeamLead(ctx context.Context, userID string) error {
+	p, err := requirePrincipal(ctx)
+	if err != nil {
+		return err
+	}
+	u, err := s.user.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if p.Subject == userID && p.CanAccessTeam(u.TeamName) {
+		return nil
+	}
+	return requireTeamLead(ctx, u.TeamName)
+}
@@ -10,28 +10,38 @@ type CreatePullReqRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	// Draft - создать черновик без ревьюверов (см. /pullRequest/markReady)
+	Draft bool `json:"draft,omitempty"`
 }
 
 type CreatePullReqResponse struct {
-	PR CreatePullReqPR `json:"pr"`
+	PR      CreatePullReqPR `json:"pr"`
+	Version uint64          `json:"-"`
 }
 
 type CreatePullReqPR struct {
-	PullRequestID     string     `json:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         *time.Time `json:"createdAt,omitempty"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	PullRequestID     string                        `json:"pull_request_id"`
+	PullRequestName   string                        `json:"pull_request_name"`
+	AuthorID          string                        `json:"author_id"`
+	Status            string                        `json:"status"`
+	AssignedReviewers []string                      `json:"assigned_reviewers"`
+	ReviewerTeams     []pullrequest.ReviewerTeamDTO `json:"reviewer_teams"`
+	CreatedAt         *time.Time                    `json:"createdAt,omitempty"`
+	MergedAt          *time.Time                    `json:"mergedAt,omitempty"`
 }
 
 func (s *Service) CreatePullRequestFromCreateRequest(ctx context.Context, request *CreatePullReqRequest) (*CreatePullReqResponse, error) {
+	if err := s.requireUserTeamAccess(ctx, request.AuthorID); err != nil {
+		return nil, err
+	}
 	prShort := &pullrequest.PullRequestShortDTOFromHttp{
 		PullRequestID:   request.PullRequestID,
 		PullRequestName: request.PullRequestName,
 		AuthorID:        request.AuthorID,
 	}
+	if request.Draft {
+		prShort.Status = pullrequest.StatusDraft
+	}
 
 	dto, err := s.pullRequest.Create(ctx, prShort)
 	if err != nil {
@@ -39,19 +49,21 @@ func (s *Service) CreatePullRequestFromCreateRequest(ctx context.Context, reques
 	}
 
 	response := &CreatePullReqResponse{
+		Version: dto.Version,
 		PR: CreatePullReqPR{
 			PullRequestID:     dto.PullRequestID,
 			PullRequestName:   dto.PullRequestName,
 			AuthorID:          dto.AuthorID,
 			Status:            dto.Status,
 			AssignedReviewers: dto.AssignedReviewers,
+			ReviewerTeams:     dto.ReviewerTeams,
 		},
 	}
 
 	if !dto.CreatedAt.IsZero() {
 		response.PR.CreatedAt = &dto.CreatedAt
 	}
-	if !dto.MergedAt.IsZero() {
+	if dto.MergedAt != nil {
 		response.PR.MergedAt = dto.MergedAt
 	}
 
@@ -0,0 +1,19 @@
+package core
+
+import (
+	"avito-tech/internal/app/stream"
+	"context"
+)
+
+func (s *Service) SubscribeAssignments(ctx context.Context, userID string, lastEventID uint64) (*stream.Subscription, error) {
+	if s.stream == nil {
+		return nil, notSupported("event stream")
+	}
+	if _, err := s.user.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.requireUserTeamAccess(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.stream.Subscribe(ctx, userID, lastEventID)
+}
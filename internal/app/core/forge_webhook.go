@@ -0,0 +1,99 @@
+package core
+
+import (
+	"avito-tech/internal/app/auth"
+	"avito-tech/internal/app/forge"
+	"avito-tech/internal/app/integration"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+)
+
+type ForgeWebhookResponse struct {
+	Action        string `json:"action"`
+	PullRequestID string `json:"pull_request_id,omitempty"`
+}
+
+func (s *Service) HandleForgeWebhook(ctx context.Context, forgeName string, header http.Header, body []byte) (*ForgeWebhookResponse, error) {
+	ev, err := s.integration.Parse(forgeName, header, body)
+	if err != nil {
+		return nil, err
+	}
+	// запрос forge подписан, дальше действуем от имени интеграции в её арендаторе
+	ctx = tenant.WithTenant(ctx, ev.Tenant)
+	ctx = auth.WithPrincipal(ctx, &auth.Principal{Subject: "integration:" + forgeName, Role: auth.RoleAdmin, Tenant: ev.Tenant})
+
+	response := &ForgeWebhookResponse{
+		Action:        ev.Action,
+		PullRequestID: ev.PullRequestID,
+	}
+
+	switch ev.Action {
+	case integration.ActionOpened:
+		err = s.openForgePullRequest(ctx, ev)
+	case integration.ActionReopened:
+		_, err = s.ReopenPullRequest(ctx, PullReqStatusRequest{PullRequestID: ev.PullRequestID})
+		// PR закрыли до подключения интеграции: заводим его как новый
+		if errors.Is(err, apperrors.ErrNotFound) {
+			err = s.openForgePullRequest(ctx, ev)
+		}
+	case integration.ActionMerged:
+		_, err = s.MergePullRequest(ctx, MergePullReqRequest{PullRequestID: ev.PullRequestID})
+	case integration.ActionClosed:
+		_, err = s.ClosePullRequest(ctx, PullReqStatusRequest{PullRequestID: ev.PullRequestID})
+	}
+	// forge может прислать событие повторно или о PR, которого сервис не знает
+	if errors.Is(err, apperrors.ErrPRExists) || errors.Is(err, apperrors.ErrInvalidTransition) ||
+		(ev.Action == integration.ActionClosed && errors.Is(err, apperrors.ErrNotFound)) {
+		log.Printf("[Service.HandleForgeWebhook] %s of PR '%s' from %s ignored: %v", ev.Action, ev.PullRequestID, forgeName, err)
+		response.Action = integration.ActionIgnore
+		return response, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (s *Service) openForgePullRequest(ctx context.Context, ev *integration.PullRequestEvent) error {
+	// связь пишется до создания PR: событие pr.created может уйти из outbox сразу после коммита
+	if s.forge != nil {
+		err := s.forge.Link(ctx, &forge.LinkDTO{
+			PullRequestID: ev.PullRequestID,
+			Forge:         ev.Forge,
+			Repository:    ev.Repository,
+			Number:        ev.Number,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	_, err := s.CreatePullRequestFromCreateRequest(ctx, &CreatePullReqRequest{
+		PullRequestID:   ev.PullRequestID,
+		PullRequestName: ev.PullRequestName,
+		AuthorID:        ev.AuthorID,
+	})
+	return err
+}
+
+type ForgeSyncResponse struct {
+	Syncs []*forge.SyncDTO `json:"syncs"`
+}
+
+func (s *Service) ForgeSyncs(ctx context.Context, prID string, status string) (*ForgeSyncResponse, error) {
+	if s.forge == nil {
+		return nil, notSupported("forge sync")
+	}
+	if _, err := requirePrincipal(ctx); err != nil {
+		return nil, err
+	}
+	dto, err := s.forge.Syncs(ctx, prID, status)
+	if err != nil {
+		return nil, err
+	}
+	return &ForgeSyncResponse{Syncs: dto}, nil
+}
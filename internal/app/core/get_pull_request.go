@@ -0,0 +1,57 @@
+package core
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"context"
+	"time"
+)
+
+type GetPullReqResponse struct {
+	PR      GetPullReqPR `json:"pr"`
+	Version uint64       `json:"-"`
+}
+
+type GetPullReqPR struct {
+	PullRequestID     string                        `json:"pull_request_id"`
+	PullRequestName   string                        `json:"pull_request_name"`
+	AuthorID          string                        `json:"author_id"`
+	Status            string                        `json:"status"`
+	AssignedReviewers []string                      `json:"assigned_reviewers"`
+	ReviewerTeams     []pullrequest.ReviewerTeamDTO `json:"reviewer_teams"`
+	CreatedAt         *time.Time                    `json:"createdAt,omitempty"`
+	MergedAt          *time.Time                    `json:"mergedAt,omitempty"`
+	Reviews           []pullrequest.ReviewDTO       `json:"reviews"`
+}
+
+func (s *Service) GetPullRequest(ctx context.Context, prID string) (*GetPullReqResponse, error) {
+	if err := s.requirePullRequestTeamAccess(ctx, prID); err != nil {
+		return nil, err
+	}
+	dto, err := s.pullRequest.GetByID(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	return newGetPullReqResponse(dto), nil
+}
+
+func newGetPullReqResponse(dto *pullrequest.PullRequestDTOFromHttp) *GetPullReqResponse {
+	response := &GetPullReqResponse{
+		Version: dto.Version,
+		PR: GetPullReqPR{
+			PullRequestID:     dto.PullRequestID,
+			PullRequestName:   dto.PullRequestName,
+			AuthorID:          dto.AuthorID,
+			Status:            dto.Status,
+			AssignedReviewers: dto.AssignedReviewers,
+			ReviewerTeams:     dto.ReviewerTeams,
+			MergedAt:          dto.MergedAt,
+			Reviews:           dto.Reviews,
+		},
+	}
+
+	if !dto.CreatedAt.IsZero() {
+		response.PR.CreatedAt = &dto.CreatedAt
+	}
+
+	return response
+}
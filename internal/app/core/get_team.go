@@ -6,17 +6,34 @@ import (
 )
 
 type GetTeamResponse struct {
-	TeamName string               `json:"team_name"`
-	Members  []team.TeamMemberDTO `json:"members"`
+	TeamName       string                    `json:"team_name"`
+	ParentTeamName *string                   `json:"parent_team_name,omitempty"`
+	MaxOpenReviews *int                      `json:"max_open_reviews,omitempty"`
+	Escalation     *team.EscalationPolicyDTO `json:"escalation,omitempty"`
+	FallbackTeams  []string                  `json:"fallback_teams,omitempty"`
+	Members        []team.TeamMemberDTO      `json:"members"`
+	Version        uint64                    `json:"-"`
+}
+
+func teamResponse(dto *team.TeamDTO) *GetTeamResponse {
+	return &GetTeamResponse{
+		TeamName:       dto.TeamName,
+		ParentTeamName: dto.ParentTeamName,
+		MaxOpenReviews: dto.MaxOpenReviews,
+		Escalation:     dto.Escalation,
+		FallbackTeams:  dto.FallbackTeams,
+		Members:        dto.Members,
+		Version:        dto.Version,
+	}
 }
 
 func (s *Service) GetTeamByTeamName(ctx context.Context, teamName string) (*GetTeamResponse, error) {
+	if err := requireTeamAccess(ctx, teamName); err != nil {
+		return nil, err
+	}
 	dto, err := s.team.GetByTeamName(ctx, teamName)
 	if err != nil {
 		return nil, err
 	}
-	return &GetTeamResponse{
-		TeamName: dto.TeamName,
-		Members:  dto.Members,
-	}, nil
+	return teamResponse(dto), nil
 }
@@ -0,0 +1,18 @@
+package core
+
+import (
+	"avito-tech/internal/app/scheduler"
+	"context"
+)
+
+type ListJobsResponse struct {
+	Jobs []scheduler.JobStatus `json:"jobs"`
+}
+
+// ListJobs отдаёт состояние фоновых задач на этой реплике
+func (s *Service) ListJobs(ctx context.Context) (*ListJobsResponse, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return &ListJobsResponse{Jobs: s.jobs.Status()}, nil
+}
@@ -1,16 +1,20 @@
 package core
 
 import (
+	pullrequest "avito-tech/internal/app/pull_request"
 	"context"
 	"time"
 )
 
 type MergePullReqRequest struct {
 	PullRequestID string `json:"pull_request_id"`
+	// IfMatch - ожидаемая версия PR из заголовка If-Match
+	IfMatch *uint64 `json:"-"`
 }
 
 type MergePullReqResponse struct {
-	PR MergePullReqPR `json:"pr"`
+	PR      MergePullReqPR `json:"pr"`
+	Version uint64         `json:"-"`
 }
 
 type MergePullReqPR struct {
@@ -24,12 +28,29 @@ type MergePullReqPR struct {
 }
 
 func (s *Service) MergePullRequest(ctx context.Context, req MergePullReqRequest) (*MergePullReqResponse, error) {
-	dto, err := s.pullRequest.Merge(ctx, req.PullRequestID)
+	principal, err := requirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requirePullRequestTeamAccess(ctx, req.PullRequestID); err != nil {
+		return nil, err
+	}
+	// проверка автора и merge в одной транзакции
+	var dto *pullrequest.PullRequestDTOFromHttp
+	err = s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.requireAuthor(ctx, principal, req.PullRequestID, "merge"); err != nil {
+			return err
+		}
+		var err error
+		dto, err = s.pullRequest.Merge(ctx, req.PullRequestID, req.IfMatch, s.mergePolicy)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	response := &MergePullReqResponse{
+		Version: dto.Version,
 		PR: MergePullReqPR{
 			PullRequestID:     dto.PullRequestID,
 			PullRequestName:   dto.PullRequestName,
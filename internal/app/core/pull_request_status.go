@@ -0,0 +1,52 @@
+package core
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"context"
+)
+
+type PullReqStatusRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	// IfMatch - ожидаемая версия PR из заголовка If-Match
+	IfMatch *uint64 `json:"-"`
+}
+
+// MarkPullRequestReady переводит черновик в OPEN и назначает ревьюверов
+func (s *Service) MarkPullRequestReady(ctx context.Context, req PullReqStatusRequest) (*GetPullReqResponse, error) {
+	return s.changePullRequestStatus(ctx, req, "mark ready", s.pullRequest.MarkReady)
+}
+
+// ClosePullRequest закрывает PR без merge и освобождает ревьюверов
+func (s *Service) ClosePullRequest(ctx context.Context, req PullReqStatusRequest) (*GetPullReqResponse, error) {
+	return s.changePullRequestStatus(ctx, req, "close", s.pullRequest.Close)
+}
+
+// ReopenPullRequest возвращает закрытый PR в OPEN
+func (s *Service) ReopenPullRequest(ctx context.Context, req PullReqStatusRequest) (*GetPullReqResponse, error) {
+	return s.changePullRequestStatus(ctx, req, "reopen", s.pullRequest.Reopen)
+}
+
+// changePullRequestStatus - как merge: только автор или администратор, проверка и переход в одной транзакции
+func (s *Service) changePullRequestStatus(ctx context.Context, req PullReqStatusRequest, op string, change func(ctx context.Context, prID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, error)) (*GetPullReqResponse, error) {
+	principal, err := requirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requirePullRequestTeamAccess(ctx, req.PullRequestID); err != nil {
+		return nil, err
+	}
+
+	var dto *pullrequest.PullRequestDTOFromHttp
+	err = s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.requireAuthor(ctx, principal, req.PullRequestID, op); err != nil {
+			return err
+		}
+		var err error
+		dto, err = change(ctx, req.PullRequestID, req.IfMatch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newGetPullReqResponse(dto), nil
+}
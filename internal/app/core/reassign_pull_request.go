@@ -1,6 +1,7 @@
 package core
 
 import (
+	pullrequest "avito-tech/internal/app/pull_request"
 	"context"
 	"time"
 )
@@ -8,37 +9,46 @@ import (
 type ReassignPullReqRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
+	// IfMatch - ожидаемая версия PR из заголовка If-Match
+	IfMatch *uint64 `json:"-"`
 }
 
 type ReassignPullReqResponse struct {
 	PR         ReassignPullReqPR `json:"pr"`
 	ReplacedBy string            `json:"replaced_by"`
+	Version    uint64            `json:"-"`
 }
 
 type ReassignPullReqPR struct {
-	PullRequestID     string     `json:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         *time.Time `json:"createdAt,omitempty"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	PullRequestID     string                        `json:"pull_request_id"`
+	PullRequestName   string                        `json:"pull_request_name"`
+	AuthorID          string                        `json:"author_id"`
+	Status            string                        `json:"status"`
+	AssignedReviewers []string                      `json:"assigned_reviewers"`
+	ReviewerTeams     []pullrequest.ReviewerTeamDTO `json:"reviewer_teams"`
+	CreatedAt         *time.Time                    `json:"createdAt,omitempty"`
+	MergedAt          *time.Time                    `json:"mergedAt,omitempty"`
 }
 
 func (s *Service) ReassignPullRequest(ctx context.Context, request *ReassignPullReqRequest) (*ReassignPullReqResponse, error) {
-	dto, newID, err := s.pullRequest.Reassign(ctx, request.PullRequestID, request.OldUserID)
+	if err := s.requirePullRequestTeamAccess(ctx, request.PullRequestID); err != nil {
+		return nil, err
+	}
+	dto, newID, err := s.pullRequest.Reassign(ctx, request.PullRequestID, request.OldUserID, request.IfMatch)
 	if err != nil {
 		return nil, err
 	}
 
 	response := &ReassignPullReqResponse{
 		ReplacedBy: newID,
+		Version:    dto.Version,
 		PR: ReassignPullReqPR{
 			PullRequestID:     dto.PullRequestID,
 			PullRequestName:   dto.PullRequestName,
 			AuthorID:          dto.AuthorID,
 			Status:            dto.Status,
 			AssignedReviewers: dto.AssignedReviewers,
+			ReviewerTeams:     dto.ReviewerTeams,
 		},
 	}
 
@@ -0,0 +1,73 @@
+package core
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+type ReviewPullReqRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+	// UserID - ревьювер; не админ может оставить вердикт только от своего имени
+	UserID   string `json:"user_id"`
+	Decision string `json:"decision"`
+	Comment  string `json:"comment"`
+}
+
+type ReviewPullReqResponse struct {
+	PR      ReviewPullReqPR `json:"pr"`
+	Version uint64          `json:"-"`
+}
+
+type ReviewPullReqPR struct {
+	PullRequestID     string                  `json:"pull_request_id"`
+	PullRequestName   string                  `json:"pull_request_name"`
+	AuthorID          string                  `json:"author_id"`
+	Status            string                  `json:"status"`
+	AssignedReviewers []string                `json:"assigned_reviewers"`
+	Reviews           []pullrequest.ReviewDTO `json:"reviews"`
+	CreatedAt         *time.Time              `json:"createdAt,omitempty"`
+}
+
+func (s *Service) ReviewPullRequest(ctx context.Context, req *ReviewPullReqRequest) (*ReviewPullReqResponse, error) {
+	principal, err := requirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == "" {
+		req.UserID = principal.Subject
+	}
+	if !principal.IsAdmin() && req.UserID != principal.Subject {
+		log.Printf("[Service.ReviewPullRequest] '%s' tried to review PR '%s' as '%s'", principal.Subject, req.PullRequestID, req.UserID)
+		return nil, fmt.Errorf("%w: reviewers can only submit their own review", apperrors.ErrForbidden)
+	}
+	if err := s.requirePullRequestTeamAccess(ctx, req.PullRequestID); err != nil {
+		return nil, err
+	}
+
+	dto, err := s.pullRequest.Review(ctx, req.PullRequestID, req.UserID, req.Decision, req.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &ReviewPullReqResponse{
+		Version: dto.Version,
+		PR: ReviewPullReqPR{
+			PullRequestID:     dto.PullRequestID,
+			PullRequestName:   dto.PullRequestName,
+			AuthorID:          dto.AuthorID,
+			Status:            dto.Status,
+			AssignedReviewers: dto.AssignedReviewers,
+			Reviews:           dto.Reviews,
+		},
+	}
+
+	if !dto.CreatedAt.IsZero() {
+		response.PR.CreatedAt = &dto.CreatedAt
+	}
+
+	return response, nil
+}
@@ -1,41 +1,143 @@
 package core
 
 import (
+	"avito-tech/internal/app/apikey"
+	"avito-tech/internal/app/availability"
+	"avito-tech/internal/app/escalation"
+	"avito-tech/internal/app/forge"
+	"avito-tech/internal/app/integration"
 	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/scheduler"
+	"avito-tech/internal/app/stream"
 	"avito-tech/internal/app/team"
 	"avito-tech/internal/app/user"
+	"avito-tech/internal/app/webhook"
+	"avito-tech/internal/apperrors"
 	"context"
+	"fmt"
+	"net/http"
 )
 
 type Team interface {
 	GetByTeamName(ctx context.Context, teamName string) (*team.TeamDTO, error)
 	Create(ctx context.Context, dto *team.TeamDTO) error
+	SetMaxOpenReviews(ctx context.Context, teamName string, limit *int) error
+	SetEscalationPolicy(ctx context.Context, teamName string, policy *team.EscalationPolicyDTO) error
+	SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error
+	SetParent(ctx context.Context, teamName string, parentTeamName *string) error
+	Subtree(ctx context.Context, teamName string) (*team.TeamNodeDTO, error)
+	Stats(ctx context.Context, teamName string, subtree bool) (*team.DepartmentStatsDTO, error)
+	Organization(ctx context.Context) ([]*team.TeamNodeDTO, error)
 }
 
 type User interface {
 	GetByID(ctx context.Context, id string) (*user.UserDTO, error)
 	GetByTeamID(ctx context.Context, id uint64) ([]*user.UserDTO, error)
-	SetIsActive(ctx context.Context, id string, isActive bool) (*user.UserDTO, error)
+	SetIsActive(ctx context.Context, id string, isActive bool, ifMatch *uint64) (*user.UserDTO, error)
+	SetTeamIsActive(ctx context.Context, teamName string, subtree bool, isActive bool) ([]*user.UserDTO, error)
 	Create(ctx context.Context, users []*user.UserDTO) error
 	GetReview(ctx context.Context, userID string) ([]*pullrequest.PullRequestShortDTOFromHttp, error)
+	GetLoad(ctx context.Context, userID string) (*user.LoadDTO, error)
+	SetMaxOpenReviews(ctx context.Context, userID string, limit *int) (*user.UserDTO, error)
+	GetWorkingHours(ctx context.Context, userID string) (*user.WorkingHoursDTO, error)
+	SetWorkingHours(ctx context.Context, userID string, dto *user.WorkingHoursDTO) (*user.WorkingHoursDTO, error)
 }
 
 type PullRequest interface {
+	GetByID(ctx context.Context, prID string) (*pullrequest.PullRequestDTOFromHttp, error)
 	Create(ctx context.Context, prShort *pullrequest.PullRequestShortDTOFromHttp) (*pullrequest.PullRequestDTOFromHttp, error)
-	Merge(ctx context.Context, prID string) (*pullrequest.PullRequestDTOFromHttp, error)
-	Reassign(ctx context.Context, prID string, oldUserID string) (*pullrequest.PullRequestDTOFromHttp, string, error)
+	Merge(ctx context.Context, prID string, ifMatch *uint64, policy *pullrequest.MergePolicy) (*pullrequest.PullRequestDTOFromHttp, error)
+	Reassign(ctx context.Context, prID string, oldUserID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, string, error)
+	Review(ctx context.Context, prID string, userID string, decision string, comment string) (*pullrequest.PullRequestDTOFromHttp, error)
+	MarkReady(ctx context.Context, prID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, error)
+	Close(ctx context.Context, prID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, error)
+	Reopen(ctx context.Context, prID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, error)
 }
 
+type Webhook interface {
+	Create(ctx context.Context, dto *webhook.SubscriptionDTO) (*webhook.SubscriptionDTO, error)
+	List(ctx context.Context) ([]*webhook.SubscriptionDTO, error)
+	Delete(ctx context.Context, id uint64) error
+	Deliveries(ctx context.Context, subscriptionID uint64, status string) ([]*webhook.DeliveryDTO, error)
+	DeadLetters(ctx context.Context) ([]*webhook.DeliveryDTO, error)
+}
+
+type Integration interface {
+	Parse(forge string, header http.Header, body []byte) (*integration.PullRequestEvent, error)
+}
+
+type Forge interface {
+	Link(ctx context.Context, dto *forge.LinkDTO) error
+	Syncs(ctx context.Context, prID string, status string) ([]*forge.SyncDTO, error)
+}
+
+type Stream interface {
+	Subscribe(ctx context.Context, userID string, lastEventID uint64) (*stream.Subscription, error)
+}
+
+type APIKey interface {
+	Create(ctx context.Context, dto *apikey.APIKeyDTO) (*apikey.APIKeyDTO, error)
+	List(ctx context.Context) ([]*apikey.APIKeyDTO, error)
+	Revoke(ctx context.Context, id uint64) (*apikey.APIKeyDTO, error)
+}
+
+type Availability interface {
+	Create(ctx context.Context, dto *availability.WindowDTO) (*availability.WindowDTO, error)
+	GetByID(ctx context.Context, id uint64) (*availability.WindowDTO, error)
+	List(ctx context.Context, userID string) ([]*availability.WindowDTO, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+type Escalation interface {
+	List(ctx context.Context, teamName string, subtree bool) ([]*escalation.EscalationDTO, error)
+}
+
+type Jobs interface {
+	Status() []scheduler.JobStatus
+}
+
+// TxManager объединяет вызовы нескольких репозиториев в одну транзакцию
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Service - фасад над компонентами. webhook, forge, stream и apiKey могут быть nil,
+// если хранилище их не поддерживает (см. хранилище в памяти): такие методы отвечают ErrNotSupported.
+// mergePolicy == nil - merge без проверки одобрений.
 type Service struct {
 	team        Team
 	user        User
 	pullRequest PullRequest
+	webhook     Webhook
+	integration Integration
+	forge       Forge
+	stream      Stream
+	apiKey      APIKey
+	available   Availability
+	escalation  Escalation
+	jobs        Jobs
+	tx          TxManager
+	mergePolicy *pullrequest.MergePolicy
 }
 
-func NewService(team Team, user User, pullRequest PullRequest) *Service {
+func NewService(team Team, user User, pullRequest PullRequest, webhook Webhook, integration Integration, forge Forge, stream Stream, apiKey APIKey, available Availability, escalation Escalation, jobs Jobs, tx TxManager, mergePolicy *pullrequest.MergePolicy) *Service {
 	return &Service{
 		team:        team,
 		user:        user,
 		pullRequest: pullRequest,
+		webhook:     webhook,
+		integration: integration,
+		forge:       forge,
+		stream:      stream,
+		apiKey:      apiKey,
+		available:   available,
+		escalation:  escalation,
+		jobs:        jobs,
+		tx:          tx,
+		mergePolicy: mergePolicy,
 	}
 }
+
+func notSupported(feature string) error {
+	return fmt.Errorf("%w: %s requires the postgres storage", apperrors.ErrNotSupported, feature)
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"avito-tech/internal/app/escalation"
+	"avito-tech/internal/app/team"
+	"context"
+)
+
+type SetTeamEscalationPolicyRequest struct {
+	TeamName string `json:"team_name"`
+	// Escalation - SLA ревью и действие при его нарушении; null выключает эскалацию
+	Escalation *team.EscalationPolicyDTO `json:"escalation"`
+}
+
+type ListTeamEscalationsResponse struct {
+	TeamName    string                      `json:"team_name"`
+	Escalations []*escalation.EscalationDTO `json:"escalations"`
+}
+
+func (s *Service) SetTeamEscalationPolicy(ctx context.Context, req SetTeamEscalationPolicyRequest) (*GetTeamResponse, error) {
+	if err := requireTeamLead(ctx, req.TeamName); err != nil {
+		return nil, err
+	}
+	var resp *GetTeamResponse
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.team.SetEscalationPolicy(ctx, req.TeamName, req.Escalation); err != nil {
+			return err
+		}
+		dto, err := s.team.GetByTeamName(ctx, req.TeamName)
+		if err != nil {
+			return err
+		}
+		resp = teamResponse(dto)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ListTeamEscalations с subtree возвращает эскалации всего отдела: команды и её подкоманд
+func (s *Service) ListTeamEscalations(ctx context.Context, teamName string, subtree bool) (*ListTeamEscalationsResponse, error) {
+	if err := requireTeamAccess(ctx, teamName); err != nil {
+		return nil, err
+	}
+	dto, err := s.escalation.List(ctx, teamName, subtree)
+	if err != nil {
+		return nil, err
+	}
+	return &ListTeamEscalationsResponse{TeamName: teamName, Escalations: dto}, nil
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"context"
+)
+
+type SetTeamFallbackTeamsRequest struct {
+	TeamName string `json:"team_name"`
+	// FallbackTeams - запасные команды в порядке приоритета; пустой список их снимает
+	FallbackTeams []string `json:"fallback_teams"`
+}
+
+func (s *Service) SetTeamFallbackTeams(ctx context.Context, req SetTeamFallbackTeamsRequest) (*GetTeamResponse, error) {
+	if err := requireTeamLead(ctx, req.TeamName); err != nil {
+		return nil, err
+	}
+	var resp *GetTeamResponse
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.team.SetFallbackTeams(ctx, req.TeamName, req.FallbackTeams); err != nil {
+			return err
+		}
+		dto, err := s.team.GetByTeamName(ctx, req.TeamName)
+		if err != nil {
+			return err
+		}
+		resp = teamResponse(dto)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"log"
+)
+
+type SetTeamParentRequest struct {
+	TeamName string `json:"team_name"`
+	// ParentTeamName - новый родитель (отдел); null переносит команду в корень организации
+	ParentTeamName *string `json:"parent_team_name"`
+}
+
+type GetTeamSubtreeResponse struct {
+	Team *team.TeamNodeDTO `json:"team"`
+}
+
+type GetTeamStatsResponse struct {
+	TeamName string               `json:"team_name"`
+	Subtree  bool                 `json:"subtree"`
+	Total    team.TeamCountersDTO `json:"total"`
+	Teams    []team.TeamStatsDTO  `json:"teams"`
+}
+
+type SetTeamIsActiveRequest struct {
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+	// Subtree - вместе с участниками всех подкоманд отдела
+	Subtree bool `json:"subtree"`
+}
+
+type SetTeamIsActiveResponse struct {
+	TeamName string `json:"team_name"`
+	// Users - участники, у которых активность изменилась
+	Users []*user.UserDTO `json:"users"`
+}
+
+// GetOrganizationResponse - корень организации: команды без родителя с подкомандами
+type GetOrganizationResponse struct {
+	Teams []*team.TeamNodeDTO `json:"teams"`
+}
+
+// SetTeamParent меняет структуру организации, поэтому доступен только администратору
+func (s *Service) SetTeamParent(ctx context.Context, req SetTeamParentRequest) (*GetTeamResponse, error) {
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	var resp *GetTeamResponse
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.team.SetParent(ctx, req.TeamName, req.ParentTeamName); err != nil {
+			return err
+		}
+		dto, err := s.team.GetByTeamName(ctx, req.TeamName)
+		if err != nil {
+			return err
+		}
+		resp = teamResponse(dto)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *Service) GetTeamSubtree(ctx context.Context, teamName string) (*GetTeamSubtreeResponse, error) {
+	if err := requireTeamAccess(ctx, teamName); err != nil {
+		return nil, err
+	}
+	node, err := s.team.Subtree(ctx, teamName)
+	if err != nil {
+		return nil, err
+	}
+	return &GetTeamSubtreeResponse{Team: node}, nil
+}
+
+// GetOrganization недоступен учётным данным, ограниченным командами: они видят только свои поддеревья
+func (s *Service) GetOrganization(ctx context.Context) (*GetOrganizationResponse, error) {
+	p, err := requirePrincipal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Teams) > 0 {
+		log.Printf("[Service.GetOrganization] '%s' is scoped to teams %v", p.Subject, p.Teams)
+		return nil, fmt.Errorf("%w: credential is scoped to teams %v", apperrors.ErrForbidden, p.Teams)
+	}
+	roots, err := s.team.Organization(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &GetOrganizationResponse{Teams: roots}, nil
+}
+
+// GetTeamStats с subtree считает весь отдел; доступ, как у эскалаций отдела, проверяется по корню поддерева
+func (s *Service) GetTeamStats(ctx context.Context, teamName string, subtree bool) (*GetTeamStatsResponse, error) {
+	if err := requireTeamAccess(ctx, teamName); err != nil {
+		return nil, err
+	}
+	stats, err := s.team.Stats(ctx, teamName, subtree)
+	if err != nil {
+		return nil, err
+	}
+	return &GetTeamStatsResponse{TeamName: teamName, Subtree: subtree, Total: stats.Total, Teams: stats.Teams}, nil
+}
+
+// SetTeamIsActive меняет активность всех участников команды или отдела. Права лида нужны на каждую
+// команду поддерева: лид отдела не управляет участниками подкоманд и поштучно.
+func (s *Service) SetTeamIsActive(ctx context.Context, req SetTeamIsActiveRequest) (*SetTeamIsActiveResponse, error) {
+	if _, err := requirePrincipal(ctx); err != nil {
+		return nil, err
+	}
+	resp := &SetTeamIsActiveResponse{TeamName: req.TeamName}
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		teams := []string{req.TeamName}
+		if req.Subtree {
+			node, err := s.team.Subtree(ctx, req.TeamName)
+			if err != nil {
+				return err
+			}
+			teams = subtreeNames(node, nil)
+		}
+		for _, name := range teams {
+			if err := requireTeamLead(ctx, name); err != nil {
+				return err
+			}
+		}
+		users, err := s.user.SetTeamIsActive(ctx, req.TeamName, req.Subtree, req.IsActive)
+		if err != nil {
+			return err
+		}
+		resp.Users = users
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func subtreeNames(node *team.TeamNodeDTO, names []string) []string {
+	names = append(names, node.TeamName)
+	for _, child := range node.Children {
+		names = subtreeNames(child, names)
+	}
+	return names
+}
@@ -0,0 +1,33 @@
+package core
+
+import (
+	"context"
+)
+
+type SetTeamMaxOpenReviewsRequest struct {
+	TeamName string `json:"team_name"`
+	// MaxOpenReviews - лимит по умолчанию для участников без личного; null снимает ограничение
+	MaxOpenReviews *int `json:"max_open_reviews"`
+}
+
+func (s *Service) SetTeamMaxOpenReviews(ctx context.Context, req SetTeamMaxOpenReviewsRequest) (*GetTeamResponse, error) {
+	if err := requireTeamLead(ctx, req.TeamName); err != nil {
+		return nil, err
+	}
+	var resp *GetTeamResponse
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.team.SetMaxOpenReviews(ctx, req.TeamName, req.MaxOpenReviews); err != nil {
+			return err
+		}
+		dto, err := s.team.GetByTeamName(ctx, req.TeamName)
+		if err != nil {
+			return err
+		}
+		resp = teamResponse(dto)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
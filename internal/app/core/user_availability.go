@@ -0,0 +1,68 @@
+package core
+
+import (
+	"avito-tech/internal/app/availability"
+	"context"
+	"time"
+)
+
+type CreateUnavailabilityRequest struct {
+	UserID   string    `json:"user_id"`
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+	Reason   string    `json:"reason"`
+	// Handover - передать открытые ревью, когда окно начнётся
+	Handover bool `json:"handover"`
+}
+
+type UnavailabilityResponse struct {
+	Window availability.WindowDTO `json:"window"`
+}
+
+type ListUnavailabilityResponse struct {
+	UserID  string                    `json:"user_id"`
+	Windows []*availability.WindowDTO `json:"windows"`
+}
+
+type DeleteUnavailabilityRequest struct {
+	ID uint64 `json:"id"`
+}
+
+func (s *Service) CreateUnavailability(ctx context.Context, req *CreateUnavailabilityRequest) (*UnavailabilityResponse, error) {
+	if err := s.requireSelfOrTeamLead(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+	dto, err := s.available.Create(ctx, &availability.WindowDTO{
+		UserID:   req.UserID,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Reason:   req.Reason,
+		Handover: req.Handover,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &UnavailabilityResponse{Window: *dto}, nil
+}
+
+func (s *Service) ListUnavailability(ctx context.Context, userID string) (*ListUnavailabilityResponse, error) {
+	if err := s.requireUserTeamAccess(ctx, userID); err != nil {
+		return nil, err
+	}
+	dto, err := s.available.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &ListUnavailabilityResponse{UserID: userID, Windows: dto}, nil
+}
+
+func (s *Service) DeleteUnavailability(ctx context.Context, req DeleteUnavailabilityRequest) error {
+	window, err := s.available.GetByID(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.requireSelfOrTeamLead(ctx, window.UserID); err != nil {
+		return err
+	}
+	return s.available.Delete(ctx, req.ID)
+}
@@ -0,0 +1,49 @@
+package core
+
+import (
+	"avito-tech/internal/app/user"
+	"context"
+)
+
+type GetUserResponse struct {
+	User user.UserDTO `json:"user"`
+	Load user.LoadDTO `json:"load"`
+	// WorkingHours - рабочие часы, если заданы
+	WorkingHours *user.WorkingHoursDTO `json:"working_hours,omitempty"`
+	// Version - версия команды пользователя, если операция её изменила
+	Version uint64 `json:"-"`
+}
+
+func (s *Service) GetUser(ctx context.Context, userID string) (*GetUserResponse, error) {
+	if err := s.requireUserTeamAccess(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.getUser(ctx, userID)
+}
+
+// getUser читает пользователя, его нагрузку и рабочие часы одним снимком
+func (s *Service) getUser(ctx context.Context, userID string) (*GetUserResponse, error) {
+	var resp GetUserResponse
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		u, err := s.user.GetByID(ctx, userID)
+		if err != nil {
+			return err
+		}
+		load, err := s.user.GetLoad(ctx, userID)
+		if err != nil {
+			return err
+		}
+		workingHours, err := s.user.GetWorkingHours(ctx, userID)
+		if err != nil {
+			return err
+		}
+		resp.User = *u
+		resp.Load = *load
+		resp.WorkingHours = workingHours
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
@@ -11,6 +11,9 @@ type GetReviewResponse struct {
 }
 
 func (s *Service) GetReview(ctx context.Context, userID string) (*GetReviewResponse, error) {
+	if err := s.requireUserTeamAccess(ctx, userID); err != nil {
+		return nil, err
+	}
 	dto, err := s.user.GetReview(ctx, userID)
 	if err != nil {
 		return nil, err
@@ -8,16 +8,30 @@ import (
 type SetIsActiveRequest struct {
 	UserID   string `json:"user_id"`
 	IsActive bool   `json:"is_active"`
+	// IfMatch - ожидаемая версия команды пользователя из заголовка If-Match
+	IfMatch *uint64 `json:"-"`
 }
 
 type SetIsActiveResponse struct {
-	User user.UserDTO `json:"user"`
+	User    user.UserDTO `json:"user"`
+	Version uint64       `json:"-"`
 }
 
 func (s *Service) UserSetIsActive(ctx context.Context, request SetIsActiveRequest) (*SetIsActiveResponse, error) {
-	userDTO, err := s.user.SetIsActive(ctx, request.UserID, request.IsActive)
+	if _, err := requirePrincipal(ctx); err != nil {
+		return nil, err
+	}
+	target, err := s.user.GetByID(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireTeamLead(ctx, target.TeamName); err != nil {
+		return nil, err
+	}
+
+	userDTO, err := s.user.SetIsActive(ctx, request.UserID, request.IsActive, request.IfMatch)
 	if err != nil {
 		return nil, err
 	}
-	return &SetIsActiveResponse{User: *userDTO}, nil
+	return &SetIsActiveResponse{User: *userDTO, Version: userDTO.TeamVersion}, nil
 }
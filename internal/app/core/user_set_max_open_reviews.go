@@ -0,0 +1,41 @@
+package core
+
+import (
+	"context"
+)
+
+type SetMaxOpenReviewsRequest struct {
+	UserID string `json:"user_id"`
+	// MaxOpenReviews - личный лимит; null возвращает пользователя к лимиту команды
+	MaxOpenReviews *int `json:"max_open_reviews"`
+}
+
+func (s *Service) UserSetMaxOpenReviews(ctx context.Context, request SetMaxOpenReviewsRequest) (*GetUserResponse, error) {
+	if _, err := requirePrincipal(ctx); err != nil {
+		return nil, err
+	}
+	var resp *GetUserResponse
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		target, err := s.user.GetByID(ctx, request.UserID)
+		if err != nil {
+			return err
+		}
+		if err := requireTeamLead(ctx, target.TeamName); err != nil {
+			return err
+		}
+		updated, err := s.user.SetMaxOpenReviews(ctx, request.UserID, request.MaxOpenReviews)
+		if err != nil {
+			return err
+		}
+		resp, err = s.getUser(ctx, request.UserID)
+		if err != nil {
+			return err
+		}
+		resp.Version = updated.TeamVersion
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,31 @@
+package core
+
+import (
+	"avito-tech/internal/app/user"
+	"context"
+)
+
+type SetWorkingHoursRequest struct {
+	UserID string `json:"user_id"`
+	// WorkingHours - новые рабочие часы; null сбрасывает их
+	WorkingHours *user.WorkingHoursDTO `json:"working_hours"`
+}
+
+func (s *Service) UserSetWorkingHours(ctx context.Context, request SetWorkingHoursRequest) (*GetUserResponse, error) {
+	var resp *GetUserResponse
+	err := s.tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.requireSelfOrTeamLead(ctx, request.UserID); err != nil {
+			return err
+		}
+		if _, err := s.user.SetWorkingHours(ctx, request.UserID, request.WorkingHours); err != nil {
+			return err
+		}
+		var err error
+		resp, err = s.getUser(ctx, request.UserID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
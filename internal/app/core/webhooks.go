@@ -0,0 +1,98 @@
+package core
+
+import (
+	"avito-tech/internal/app/webhook"
+	"context"
+)
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+}
+
+type CreateWebhookResponse struct {
+	Webhook webhook.SubscriptionDTO `json:"webhook"`
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []*webhook.SubscriptionDTO `json:"webhooks"`
+}
+
+type DeleteWebhookRequest struct {
+	ID uint64 `json:"id"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []*webhook.DeliveryDTO `json:"deliveries"`
+}
+
+func (s *Service) CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	if s.webhook == nil {
+		return nil, notSupported("webhooks")
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	dto, err := s.webhook.Create(ctx, &webhook.SubscriptionDTO{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CreateWebhookResponse{Webhook: *dto}, nil
+}
+
+func (s *Service) ListWebhooks(ctx context.Context) (*ListWebhooksResponse, error) {
+	if s.webhook == nil {
+		return nil, notSupported("webhooks")
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	dto, err := s.webhook.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &ListWebhooksResponse{Webhooks: dto}, nil
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, req DeleteWebhookRequest) error {
+	if s.webhook == nil {
+		return notSupported("webhooks")
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+	return s.webhook.Delete(ctx, req.ID)
+}
+
+func (s *Service) WebhookDeliveries(ctx context.Context, subscriptionID uint64, status string) (*WebhookDeliveriesResponse, error) {
+	if s.webhook == nil {
+		return nil, notSupported("webhooks")
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	dto, err := s.webhook.Deliveries(ctx, subscriptionID, status)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookDeliveriesResponse{Deliveries: dto}, nil
+}
+
+func (s *Service) WebhookDeadLetters(ctx context.Context) (*WebhookDeliveriesResponse, error) {
+	if s.webhook == nil {
+		return nil, notSupported("webhooks")
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	dto, err := s.webhook.DeadLetters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &WebhookDeliveriesResponse{Deliveries: dto}, nil
+}
@@ -0,0 +1,39 @@
+package escalation
+
+import "time"
+
+// EscalationDTO - ревьювер user_id не оставил вердикт в PR за SLA команды, к нему применено action
+type EscalationDTO struct {
+	ID              uint64 `json:"id"`
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+	UserID          string `json:"user_id"`
+	// TeamName - команда автора PR; в выборке по отделу это одна из его подкоманд
+	TeamName    string    `json:"team_name"`
+	Action      string    `json:"action"`
+	AssignedAt  time.Time `json:"assigned_at"`
+	EscalatedAt time.Time `json:"escalated_at"`
+	ReplacedBy  *string   `json:"replaced_by,omitempty"`
+}
+
+func (e *EscalationDTO) MapFromModel(entity *EscalationEntity) {
+	e.ID = entity.ID
+	e.PullRequestID = entity.PullRequestID
+	e.PullRequestName = entity.PullRequestName
+	e.UserID = entity.UserID
+	e.TeamName = entity.TeamName
+	e.Action = entity.Action
+	e.AssignedAt = entity.AssignedAt
+	e.EscalatedAt = entity.EscalatedAt
+	e.ReplacedBy = entity.ReplacedBy
+}
+
+func MapFromModels(entities []*EscalationEntity) []*EscalationDTO {
+	dto := make([]*EscalationDTO, len(entities))
+	for i, v := range entities {
+		var e EscalationDTO
+		e.MapFromModel(v)
+		dto[i] = &e
+	}
+	return dto
+}
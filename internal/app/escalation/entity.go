@@ -0,0 +1,19 @@
+package escalation
+
+import "time"
+
+// EscalationEntity - эскалация назначения ревьювера: (PullRequestID, UserID, AssignedAt) эскалируется один раз
+type EscalationEntity struct {
+	ID              uint64    `db:"id"`
+	PullRequestID   string    `db:"pull_request_id"`
+	PullRequestName string    `db:"pull_request_name"`
+	AuthorID        string    `db:"author_id"`
+	UserID          string    `db:"user_id"`
+	TeamID          uint64    `db:"team_id"`
+	TeamName        string    `db:"team_name"`
+	AssignedAt      time.Time `db:"assigned_at"`
+	Action          string    `db:"action"`
+	EscalatedAt     time.Time `db:"escalated_at"`
+	// ReplacedBy - новый ревьювер при action = reassign, nil - замена не нашлась
+	ReplacedBy *string `db:"replaced_by"`
+}
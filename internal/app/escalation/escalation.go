@@ -0,0 +1,46 @@
+package escalation
+
+import (
+	"context"
+	"time"
+)
+
+type Repo interface {
+	// claimStale записывает эскалации назначений OPEN PR без вердикта, у которых к now истёк SLA команды
+	// автора (своей политики или ближайшего предка), и возвращает их. Уже эскалированные назначения
+	// не возвращаются.
+	claimStale(ctx context.Context, now time.Time, limit int) ([]*EscalationEntity, error)
+	setReplacedBy(ctx context.Context, id uint64, userID string) error
+	// listByTeam возвращает эскалации команды, а с subtree - и всех её подкоманд
+	listByTeam(ctx context.Context, teamName string, subtree bool) ([]*EscalationEntity, error)
+}
+
+// policyTeamSQL - JOIN команды p, чья политика эскалации действует для команды автора t: своя или ближайшего
+// предка, у которого она задана. Синтаксис общий для Postgres и SQLite.
+const policyTeamSQL = `JOIN team p ON p.id = (
+	SELECT pt.id
+	FROM team_closure c
+	JOIN team pt ON pt.id = c.ancestor_id
+	WHERE c.team_id = t.id AND pt.review_sla_minutes IS NOT NULL
+	ORDER BY c.depth
+	LIMIT 1
+)`
+
+type Escalation struct {
+	repo Repo
+}
+
+func NewEscalation(repo Repo) *Escalation {
+	return &Escalation{
+		repo: repo,
+	}
+}
+
+// List возвращает эскалации ревью PR авторов команды (с subtree - и её подкоманд), от новых к старым
+func (e *Escalation) List(ctx context.Context, teamName string, subtree bool) ([]*EscalationDTO, error) {
+	entities, err := e.repo.listByTeam(ctx, teamName, subtree)
+	if err != nil {
+		return nil, err
+	}
+	return MapFromModels(entities), nil
+}
@@ -0,0 +1,58 @@
+package escalation
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/clock"
+	"context"
+	"log"
+)
+
+type Reassigner interface {
+	Reassign(ctx context.Context, prID string, oldUserID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, string, error)
+}
+
+// Escalator периодически эскалирует ревьюверов, не оставивших вердикт за SLA команды.
+// Эскалация записывается до действия, поэтому назначение эскалируется не больше одного раза;
+// события remind и notify_lead пишутся в outbox вместе с записью. При reassign ревьювер,
+// для которого не нашлось замены (NO_CANDIDATE), остаётся на PR.
+type Escalator struct {
+	repo      Repo
+	prs       Reassigner
+	clock     clock.Clock
+	batchSize int
+}
+
+func NewEscalator(e *Escalation, prs Reassigner, clk clock.Clock, batchSize int) *Escalator {
+	return &Escalator{
+		repo:      e.repo,
+		prs:       prs,
+		clock:     clk,
+		batchSize: batchSize,
+	}
+}
+
+// RunOnce эскалирует просроченные назначения и возвращает число эскалаций
+func (e *Escalator) RunOnce(ctx context.Context) (int, error) {
+	stale, err := e.repo.claimStale(ctx, e.clock.Now(), e.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range stale {
+		if s.Action != team.EscalationReassign {
+			log.Printf("[Escalator.RunOnce] %s: user '%s' has not reviewed PR '%s' since %s", s.Action, s.UserID, s.PullRequestID, s.AssignedAt)
+			continue
+		}
+		_, replacedBy, err := e.prs.Reassign(ctx, s.PullRequestID, s.UserID, nil)
+		if err != nil {
+			log.Printf("[Escalator.RunOnce] PR '%s' stays with user '%s': %v", s.PullRequestID, s.UserID, err)
+			continue
+		}
+		if err := e.repo.setReplacedBy(ctx, s.ID, replacedBy); err != nil {
+			log.Printf("[Escalator.RunOnce] failed to record replacement of escalation %d: %v", s.ID, err)
+			continue
+		}
+		log.Printf("[Escalator.RunOnce] PR '%s' reassigned from '%s' to '%s' (escalation %d)", s.PullRequestID, s.UserID, replacedBy, s.ID)
+	}
+	return len(stale), nil
+}
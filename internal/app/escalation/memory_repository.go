@@ -0,0 +1,152 @@
+package escalation
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/memory"
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"time"
+)
+
+// EscalationMemoryRepo - реализация Repo поверх memory.Store с той же семантикой, что у EscalationRepo
+type EscalationMemoryRepo struct {
+	store *memory.Store
+}
+
+func NewEscalationMemoryRepo(store *memory.Store) *EscalationMemoryRepo {
+	return &EscalationMemoryRepo{store: store}
+}
+
+// assignment - ключ назначения, как уникальный ключ review_escalation
+type assignment struct {
+	prID       string
+	userID     string
+	assignedAt int64
+}
+
+func toEntity(d *memory.Data, e *memory.Escalation) *EscalationEntity {
+	pr := d.PullRequests[e.PullRequestID]
+	entity := &EscalationEntity{
+		ID:              e.ID,
+		PullRequestID:   e.PullRequestID,
+		PullRequestName: pr.Name,
+		AuthorID:        pr.AuthorID,
+		UserID:          e.UserID,
+		TeamID:          e.TeamID,
+		AssignedAt:      e.AssignedAt,
+		Action:          e.Action,
+		EscalatedAt:     e.EscalatedAt,
+		ReplacedBy:      e.ReplacedBy,
+	}
+	if t := d.TeamByID(e.TeamID); t != nil {
+		entity.TeamName = t.Name
+	}
+	return entity
+}
+
+func (r *EscalationMemoryRepo) claimStale(ctx context.Context, now time.Time, limit int) ([]*EscalationEntity, error) {
+	var claimed []*EscalationEntity
+	err := r.store.Write(ctx, func(d *memory.Data) error {
+		escalated := make(map[assignment]bool, len(d.Escalations))
+		for _, e := range d.Escalations {
+			escalated[assignment{e.PullRequestID, e.UserID, e.AssignedAt.UnixNano()}] = true
+		}
+
+		var stale []*memory.Escalation
+		for _, pr := range d.PullRequests {
+			if pr.Status != pullrequest.StatusOpen {
+				continue
+			}
+			teamID := d.Users[pr.AuthorID].TeamID
+			policy := d.EscalationPolicy(teamID)
+			if policy == nil {
+				continue
+			}
+			sla := time.Duration(*policy.ReviewSLAMinutes) * time.Minute
+			for _, userID := range pr.Reviewers {
+				assignedAt := pr.AssignedAt[userID]
+				if now.Before(assignedAt.Add(sla)) || escalated[assignment{pr.ID, userID, assignedAt.UnixNano()}] {
+					continue
+				}
+				reviewed := slices.ContainsFunc(pr.Reviews, func(v memory.Review) bool {
+					return v.UserID == userID
+				})
+				if reviewed {
+					continue
+				}
+				stale = append(stale, &memory.Escalation{
+					PullRequestID: pr.ID,
+					UserID:        userID,
+					TeamID:        teamID,
+					AssignedAt:    assignedAt,
+					Action:        policy.EscalationAction,
+					EscalatedAt:   now,
+				})
+			}
+		}
+		slices.SortFunc(stale, func(x, y *memory.Escalation) int {
+			return cmp.Or(
+				x.AssignedAt.Compare(y.AssignedAt),
+				cmp.Compare(x.PullRequestID, y.PullRequestID),
+				cmp.Compare(x.UserID, y.UserID),
+			)
+		})
+		for _, e := range stale[:min(limit, len(stale))] {
+			e.ID = d.NextEscalationID()
+			d.Escalations[e.ID] = e
+			claimed = append(claimed, toEntity(d, e))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(claimed) > 0 {
+		log.Printf("[EscalationMemoryRepo.claimStale] claimed %d stale reviewers", len(claimed))
+	}
+	return claimed, nil
+}
+
+func (r *EscalationMemoryRepo) setReplacedBy(ctx context.Context, id uint64, userID string) error {
+	return r.store.Write(ctx, func(d *memory.Data) error {
+		e, ok := d.Escalations[id]
+		if !ok {
+			log.Printf("[EscalationMemoryRepo.setReplacedBy] escalation %d not found", id)
+			return apperrors.ErrNotFound
+		}
+		e.ReplacedBy = &userID
+		return nil
+	})
+}
+
+func (r *EscalationMemoryRepo) listByTeam(ctx context.Context, teamName string, subtree bool) ([]*EscalationEntity, error) {
+	var entities []*EscalationEntity
+	err := r.store.Read(ctx, func(d *memory.Data) error {
+		t, ok := d.Teams[teamName]
+		if !ok {
+			log.Printf("[EscalationMemoryRepo.listByTeam] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		for _, e := range d.Escalations {
+			inTeam := e.TeamID == t.ID
+			if !inTeam && subtree {
+				inTeam = slices.ContainsFunc(d.Ancestors(e.TeamID), func(a *memory.Team) bool { return a.ID == t.ID })
+			}
+			if inTeam {
+				entities = append(entities, toEntity(d, e))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entities, func(x, y *EscalationEntity) int {
+		return cmp.Or(y.EscalatedAt.Compare(x.EscalatedAt), cmp.Compare(y.ID, x.ID))
+	})
+	log.Printf("[EscalationMemoryRepo.listByTeam] fetched %d escalations of team '%s'", len(entities), teamName)
+	return entities, nil
+}
@@ -0,0 +1,191 @@
+package escalation
+
+import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/app/outbox"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type DB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Get(ctx context.Context, dest any, query string, args ...any) error
+	Select(ctx context.Context, dest any, query string, args ...any) error
+	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+}
+
+type EscalationRepo struct {
+	db DB
+}
+
+func NewEscalationRepo(db DB) *EscalationRepo {
+	return &EscalationRepo{db: db}
+}
+
+// claimStale забирает назначения через SKIP LOCKED, а уникальный ключ review_escalation не даёт
+// записать эскалацию дважды, поэтому несколько реплик не эскалируют одно назначение повторно
+func (e *EscalationRepo) claimStale(ctx context.Context, now time.Time, limit int) ([]*EscalationEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	tx, err := e.db.Begin(ctx)
+	if err != nil {
+		log.Printf("[EscalationRepo.claimStale] failed to begin transaction: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			_ = tx.Commit(ctx)
+		}
+	}()
+
+	rows, err := tx.Query(ctx, `
+		SELECT r.pull_request_id, pr.pull_request_name, pr.author_id, r.user_id, t.id, t.team_name,
+		       r.assigned_at, p.escalation_action
+		FROM pull_request_reviewer r
+		JOIN pull_request pr ON pr.tenant_id = r.tenant_id AND pr.pull_request_id = r.pull_request_id
+		JOIN users a ON a.tenant_id = pr.tenant_id AND a.user_id = pr.author_id
+		JOIN team t ON t.id = a.team_id
+		`+policyTeamSQL+`
+		WHERE r.tenant_id = $3
+		  AND pr.status = 'OPEN'
+		  AND r.assigned_at + make_interval(mins => p.review_sla_minutes) <= $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM pull_request_review v
+			WHERE v.tenant_id = r.tenant_id AND v.pull_request_id = r.pull_request_id AND v.user_id = r.user_id
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM review_escalation e
+			WHERE e.tenant_id = r.tenant_id AND e.pull_request_id = r.pull_request_id
+			  AND e.user_id = r.user_id AND e.assigned_at = r.assigned_at
+		  )
+		ORDER BY r.assigned_at, r.pull_request_id, r.user_id
+		LIMIT $2
+		FOR UPDATE OF r SKIP LOCKED
+	`, now, limit, tenantID)
+	if err != nil {
+		log.Printf("[EscalationRepo.claimStale] db error fetching stale reviewers: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	var stale []*EscalationEntity
+	for rows.Next() {
+		s := EscalationEntity{EscalatedAt: now}
+		err = rows.Scan(&s.PullRequestID, &s.PullRequestName, &s.AuthorID, &s.UserID, &s.TeamID, &s.TeamName, &s.AssignedAt, &s.Action)
+		if err != nil {
+			rows.Close()
+			log.Printf("[EscalationRepo.claimStale] failed to scan stale reviewer: %v", err)
+			return nil, apperrors.ErrDB
+		}
+		stale = append(stale, &s)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Printf("[EscalationRepo.claimStale] db error reading stale reviewers: %v", err)
+		return nil, apperrors.ErrDB
+	}
+
+	var claimed []*EscalationEntity
+	for _, s := range stale {
+		err = tx.QueryRow(ctx, `
+			INSERT INTO review_escalation (tenant_id, pull_request_id, user_id, team_id, assigned_at, action, escalated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (tenant_id, pull_request_id, user_id, assigned_at) DO NOTHING
+			RETURNING id
+		`, tenantID, s.PullRequestID, s.UserID, s.TeamID, s.AssignedAt, s.Action, s.EscalatedAt).Scan(&s.ID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			log.Printf("[EscalationRepo.claimStale] db error recording escalation of user '%s' in PR '%s': %v", s.UserID, s.PullRequestID, err)
+			return nil, apperrors.ErrDB
+		}
+		if err = writeEvent(ctx, tx, s); err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, s)
+	}
+	if len(claimed) > 0 {
+		log.Printf("[EscalationRepo.claimStale] claimed %d stale reviewers", len(claimed))
+	}
+	return claimed, nil
+}
+
+// writeEvent пишет событие эскалации; о reassign сообщает событие самого переназначения
+func writeEvent(ctx context.Context, tx pgx.Tx, s *EscalationEntity) error {
+	var eventType string
+	switch s.Action {
+	case team.EscalationRemind:
+		eventType = events.ReviewReminder
+	case team.EscalationNotifyLead:
+		eventType = events.ReviewEscalated
+	default:
+		return nil
+	}
+	return outbox.Write(ctx, tx, eventType, s.PullRequestID, events.EscalationData{
+		PullRequestID:   s.PullRequestID,
+		PullRequestName: s.PullRequestName,
+		AuthorID:        s.AuthorID,
+		UserID:          s.UserID,
+		TeamName:        s.TeamName,
+		AssignedAt:      s.AssignedAt,
+		EscalatedAt:     s.EscalatedAt,
+	})
+}
+
+func (e *EscalationRepo) setReplacedBy(ctx context.Context, id uint64, userID string) error {
+	tag, err := e.db.Exec(ctx, "UPDATE review_escalation SET replaced_by = $3 WHERE tenant_id = $1 AND id = $2", tenant.FromContext(ctx), id, userID)
+	if err != nil {
+		log.Printf("[EscalationRepo.setReplacedBy] db error updating escalation %d: %v", id, err)
+		return apperrors.ErrDB
+	}
+	if tag.RowsAffected() == 0 {
+		log.Printf("[EscalationRepo.setReplacedBy] escalation %d not found", id)
+		return apperrors.ErrNotFound
+	}
+	return nil
+}
+
+func (e *EscalationRepo) listByTeam(ctx context.Context, teamName string, subtree bool) ([]*EscalationEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var exists bool
+	err := e.db.Get(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM team WHERE tenant_id=$1 AND team_name=$2)", tenantID, teamName)
+	if err != nil {
+		log.Printf("[EscalationRepo.listByTeam] db error checking existence of team '%s': %v", teamName, err)
+		return nil, apperrors.ErrDB
+	}
+	if !exists {
+		log.Printf("[EscalationRepo.listByTeam] team not found: '%s'", teamName)
+		return nil, apperrors.ErrNotFound
+	}
+
+	var entities []*EscalationEntity
+	err = e.db.Select(ctx, &entities, `
+		SELECT e.id, e.pull_request_id, pr.pull_request_name, pr.author_id, e.user_id, e.team_id, t.team_name,
+		       e.assigned_at, e.action, e.escalated_at, e.replaced_by
+		FROM review_escalation e
+		JOIN pull_request pr ON pr.tenant_id = e.tenant_id AND pr.pull_request_id = e.pull_request_id
+		JOIN team t ON t.id = e.team_id
+		WHERE e.team_id IN (
+			SELECT c.team_id
+			FROM team_closure c
+			JOIN team root ON root.id = c.ancestor_id
+			WHERE root.tenant_id = $1 AND root.team_name = $2 AND (c.depth = 0 OR $3)
+		)
+		ORDER BY e.escalated_at DESC, e.id DESC
+	`, tenantID, teamName, subtree)
+	if err != nil {
+		log.Printf("[EscalationRepo.listByTeam] db error fetching escalations of team '%s': %v", teamName, err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[EscalationRepo.listByTeam] fetched %d escalations of team '%s'", len(entities), teamName)
+	return entities, nil
+}
@@ -0,0 +1,166 @@
+package escalation
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/sqlite"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// EscalationSQLiteRepo - реализация Repo поверх встроенного SQLite с той же семантикой, что у EscalationRepo.
+// Время хранится в UTC.
+type EscalationSQLiteRepo struct {
+	db *sqlite.DB
+}
+
+func NewEscalationSQLiteRepo(db *sqlite.DB) *EscalationSQLiteRepo {
+	return &EscalationSQLiteRepo{db: db}
+}
+
+func (r *EscalationSQLiteRepo) claimStale(ctx context.Context, now time.Time, limit int) ([]*EscalationEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var claimed []*EscalationEntity
+	now = now.UTC()
+	err := r.db.Write(ctx, func(q sqlite.Querier) error {
+		// время хранится строкой в UTC: julianday разбирает её первые 19 символов (до секунд),
+		// SLA прибавляется в долях суток
+		rows, err := q.QueryContext(ctx, `
+			SELECT r.pull_request_id, pr.pull_request_name, pr.author_id, r.user_id, t.id, t.team_name,
+			       r.assigned_at, p.escalation_action
+			FROM pull_request_reviewer r
+			JOIN pull_request pr ON pr.tenant_id = r.tenant_id AND pr.pull_request_id = r.pull_request_id
+			JOIN users a ON a.tenant_id = pr.tenant_id AND a.user_id = pr.author_id
+			JOIN team t ON t.id = a.team_id
+			`+policyTeamSQL+`
+			WHERE r.tenant_id = ?
+			  AND pr.status = 'OPEN'
+			  AND julianday(substr(r.assigned_at, 1, 19)) + p.review_sla_minutes / 1440.0 <= julianday(substr(?, 1, 19))
+			  AND NOT EXISTS (
+				SELECT 1 FROM pull_request_review v
+				WHERE v.tenant_id = r.tenant_id AND v.pull_request_id = r.pull_request_id AND v.user_id = r.user_id
+			  )
+			  AND NOT EXISTS (
+				SELECT 1 FROM review_escalation e
+				WHERE e.tenant_id = r.tenant_id AND e.pull_request_id = r.pull_request_id
+				  AND e.user_id = r.user_id AND e.assigned_at = r.assigned_at
+			  )
+			ORDER BY r.assigned_at, r.pull_request_id, r.user_id
+			LIMIT ?
+		`, tenantID, now, limit)
+		if err != nil {
+			log.Printf("[EscalationSQLiteRepo.claimStale] db error fetching stale reviewers: %v", err)
+			return apperrors.ErrDB
+		}
+		var stale []*EscalationEntity
+		for rows.Next() {
+			s := EscalationEntity{EscalatedAt: now}
+			if err := rows.Scan(&s.PullRequestID, &s.PullRequestName, &s.AuthorID, &s.UserID, &s.TeamID, &s.TeamName, &s.AssignedAt, &s.Action); err != nil {
+				rows.Close()
+				log.Printf("[EscalationSQLiteRepo.claimStale] failed to scan stale reviewer: %v", err)
+				return apperrors.ErrDB
+			}
+			stale = append(stale, &s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			log.Printf("[EscalationSQLiteRepo.claimStale] db error reading stale reviewers: %v", err)
+			return apperrors.ErrDB
+		}
+
+		for _, s := range stale {
+			err := q.QueryRowContext(ctx, `
+				INSERT INTO review_escalation (tenant_id, pull_request_id, user_id, team_id, assigned_at, action, escalated_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (tenant_id, pull_request_id, user_id, assigned_at) DO NOTHING
+				RETURNING id
+			`, tenantID, s.PullRequestID, s.UserID, s.TeamID, s.AssignedAt.UTC(), s.Action, s.EscalatedAt).Scan(&s.ID)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				log.Printf("[EscalationSQLiteRepo.claimStale] db error recording escalation of user '%s' in PR '%s': %v", s.UserID, s.PullRequestID, err)
+				return apperrors.ErrDB
+			}
+			claimed = append(claimed, s)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(claimed) > 0 {
+		log.Printf("[EscalationSQLiteRepo.claimStale] claimed %d stale reviewers", len(claimed))
+	}
+	return claimed, nil
+}
+
+func (r *EscalationSQLiteRepo) setReplacedBy(ctx context.Context, id uint64, userID string) error {
+	return r.db.Write(ctx, func(q sqlite.Querier) error {
+		res, err := q.ExecContext(ctx, "UPDATE review_escalation SET replaced_by = ? WHERE tenant_id = ? AND id = ?", userID, tenant.FromContext(ctx), id)
+		if err != nil {
+			log.Printf("[EscalationSQLiteRepo.setReplacedBy] db error updating escalation %d: %v", id, err)
+			return apperrors.ErrDB
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			log.Printf("[EscalationSQLiteRepo.setReplacedBy] escalation %d not found", id)
+			return apperrors.ErrNotFound
+		}
+		return nil
+	})
+}
+
+func (r *EscalationSQLiteRepo) listByTeam(ctx context.Context, teamName string, subtree bool) ([]*EscalationEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var entities []*EscalationEntity
+	err := r.db.Read(ctx, func(q sqlite.Querier) error {
+		var exists bool
+		if err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM team WHERE tenant_id = ? AND team_name = ?)", tenantID, teamName).Scan(&exists); err != nil {
+			log.Printf("[EscalationSQLiteRepo.listByTeam] db error checking existence of team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		if !exists {
+			log.Printf("[EscalationSQLiteRepo.listByTeam] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+
+		rows, err := q.QueryContext(ctx, `
+			SELECT e.id, e.pull_request_id, pr.pull_request_name, pr.author_id, e.user_id, e.team_id, t.team_name,
+			       e.assigned_at, e.action, e.escalated_at, e.replaced_by
+			FROM review_escalation e
+			JOIN pull_request pr ON pr.tenant_id = e.tenant_id AND pr.pull_request_id = e.pull_request_id
+			JOIN team t ON t.id = e.team_id
+			WHERE e.team_id IN (
+				SELECT c.team_id
+				FROM team_closure c
+				JOIN team root ON root.id = c.ancestor_id
+				WHERE root.tenant_id = ? AND root.team_name = ? AND (c.depth = 0 OR ?)
+			)
+			ORDER BY e.escalated_at DESC, e.id DESC
+		`, tenantID, teamName, subtree)
+		if err != nil {
+			log.Printf("[EscalationSQLiteRepo.listByTeam] db error fetching escalations of team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e EscalationEntity
+			err := rows.Scan(&e.ID, &e.PullRequestID, &e.PullRequestName, &e.AuthorID, &e.UserID, &e.TeamID, &e.TeamName,
+				&e.AssignedAt, &e.Action, &e.EscalatedAt, &e.ReplacedBy)
+			if err != nil {
+				log.Printf("[EscalationSQLiteRepo.listByTeam] failed to scan escalation of team '%s': %v", teamName, err)
+				return apperrors.ErrDB
+			}
+			entities = append(entities, &e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[EscalationSQLiteRepo.listByTeam] fetched %d escalations of team '%s'", len(entities), teamName)
+	return entities, nil
+}
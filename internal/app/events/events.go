@@ -0,0 +1,110 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+const (
+	PRCreated            = "pr.created"
+	PRReviewerReassigned = "pr.reviewer_reassigned"
+	PRMerged             = "pr.merged"
+	PRReviewed           = "pr.reviewed"
+	PRStatusChanged      = "pr.status_changed"
+	UserDeactivated      = "user.deactivated"
+	ReviewReminder       = "review.reminder"
+	ReviewEscalated      = "review.escalated"
+)
+
+// Types - все типы событий, на которые можно подписаться
+var Types = []string{PRCreated, PRReviewerReassigned, PRMerged, PRReviewed, PRStatusChanged, UserDeactivated, ReviewReminder, ReviewEscalated}
+
+type Event struct {
+	// Sequence - порядковый номер записи в outbox, 0 для событий не из outbox
+	Sequence   uint64    `json:"-"`
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Tenant - арендатор, в котором произошло событие
+	Tenant string `json:"tenant"`
+	// Actor - кто выполнил операцию (для аудита)
+	Actor string          `json:"actor,omitempty"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func New(eventType string, data any) (*Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Event{
+		ID:         NewID(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		Data:       payload,
+	}, nil
+}
+
+func NewID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func IsKnownType(eventType string) bool {
+	for _, t := range Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type PullRequestData struct {
+	PullRequestID     string   `json:"pull_request_id"`
+	PullRequestName   string   `json:"pull_request_name"`
+	AuthorID          string   `json:"author_id"`
+	Status            string   `json:"status"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+}
+
+type ReviewerReassignedData struct {
+	PullRequestData
+	OldUserID  string `json:"old_user_id"`
+	ReplacedBy string `json:"replaced_by"`
+}
+
+type ReviewData struct {
+	PullRequestData
+	UserID   string `json:"user_id"`
+	Decision string `json:"decision"`
+	Comment  string `json:"comment"`
+}
+
+// StatusChangedData - markReady, close или reopen; ReleasedReviewers - ревьюверы, снятые при закрытии
+type StatusChangedData struct {
+	PullRequestData
+	PreviousStatus    string   `json:"previous_status"`
+	ReleasedReviewers []string `json:"released_reviewers,omitempty"`
+}
+
+// EscalationData - ревьювер не оставил вердикт за SLA команды: напоминание ему (review.reminder)
+// или сигнал тимлиду (review.escalated)
+type EscalationData struct {
+	PullRequestID   string    `json:"pull_request_id"`
+	PullRequestName string    `json:"pull_request_name"`
+	AuthorID        string    `json:"author_id"`
+	UserID          string    `json:"user_id"`
+	TeamName        string    `json:"team_name"`
+	AssignedAt      time.Time `json:"assigned_at"`
+	EscalatedAt     time.Time `json:"escalated_at"`
+}
+
+type UserData struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+}
@@ -0,0 +1,53 @@
+package forge
+
+import "time"
+
+type LinkDTO struct {
+	PullRequestID string `json:"pull_request_id"`
+	Forge         string `json:"forge"`
+	Repository    string `json:"repository"`
+	Number        int64  `json:"number"`
+}
+
+func (l *LinkDTO) MapToModel() *LinkEntity {
+	return &LinkEntity{
+		PullRequestID: l.PullRequestID,
+		Forge:         l.Forge,
+		Repository:    l.Repository,
+		Number:        l.Number,
+	}
+}
+
+type SyncDTO struct {
+	ID            uint64    `json:"id"`
+	PullRequestID string    `json:"pull_request_id"`
+	EventID       string    `json:"event_id"`
+	Action        string    `json:"action"`
+	Logins        []string  `json:"logins"`
+	Status        string    `json:"status"`
+	Attempts      int       `json:"attempts"`
+	LastError     *string   `json:"last_error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (s *SyncDTO) MapFromModel(entity *SyncEntity) {
+	s.ID = entity.ID
+	s.PullRequestID = entity.PullRequestID
+	s.EventID = entity.EventID
+	s.Action = entity.Action
+	s.Logins = entity.Logins
+	s.Status = entity.Status
+	s.Attempts = entity.Attempts
+	s.LastError = entity.LastError
+	s.CreatedAt = entity.CreatedAt
+}
+
+func MapFromSyncModels(entities []*SyncEntity) []*SyncDTO {
+	dto := make([]*SyncDTO, len(entities))
+	for i, v := range entities {
+		var s SyncDTO
+		s.MapFromModel(v)
+		dto[i] = &s
+	}
+	return dto
+}
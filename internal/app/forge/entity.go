@@ -0,0 +1,31 @@
+package forge
+
+import "time"
+
+const (
+	SyncActionRequest = "REQUEST"
+	SyncActionRemove  = "REMOVE"
+
+	SyncSucceeded = "SUCCEEDED"
+	SyncFailed    = "FAILED"
+	SyncSkipped   = "SKIPPED"
+)
+
+type LinkEntity struct {
+	PullRequestID string `db:"pull_request_id"`
+	Forge         string `db:"forge"`
+	Repository    string `db:"repository"`
+	Number        int64  `db:"number"`
+}
+
+type SyncEntity struct {
+	ID            uint64    `db:"id"`
+	PullRequestID string    `db:"pull_request_id"`
+	EventID       string    `db:"event_id"`
+	Action        string    `db:"action"`
+	Logins        []string  `db:"logins"`
+	Status        string    `db:"status"`
+	Attempts      int       `db:"attempts"`
+	LastError     *string   `db:"last_error"`
+	CreatedAt     time.Time `db:"created_at"`
+}
@@ -0,0 +1,181 @@
+package forge
+
+import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/apperrors"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Adapter - клиент внешнего forge, умеющий управлять запрошенными ревьюверами PR
+type Adapter interface {
+	RequestReviewers(ctx context.Context, repository string, number int64, logins []string) error
+	RemoveReviewers(ctx context.Context, repository string, number int64, logins []string) error
+}
+
+type Repo interface {
+	link(ctx context.Context, entity *LinkEntity) error
+	getLink(ctx context.Context, prID string) (*LinkEntity, error)
+	recordSync(ctx context.Context, entity *SyncEntity) error
+	eventSyncs(ctx context.Context, prID string, eventID string, action string) ([]*SyncEntity, error)
+	listSyncs(ctx context.Context, prID string, status string) ([]*SyncEntity, error)
+}
+
+type Forge struct {
+	repo       Repo
+	adapters   map[string]Adapter
+	logins     map[string]map[string]string
+	maxAttempt int
+}
+
+func NewForge(repo Repo) *Forge {
+	return &Forge{
+		repo:       repo,
+		adapters:   map[string]Adapter{},
+		logins:     map[string]map[string]string{},
+		maxAttempt: 5,
+	}
+}
+
+// Register подключает адаптер forge. users - соответствие логин -> user_id
+// (тот же формат, что и в конфиге интеграций).
+func (f *Forge) Register(forge string, adapter Adapter, users map[string]string) {
+	logins := make(map[string]string, len(users))
+	for login, userID := range users {
+		logins[userID] = login
+	}
+	f.adapters[forge] = adapter
+	f.logins[forge] = logins
+}
+
+func (f *Forge) Link(ctx context.Context, dto *LinkDTO) error {
+	return f.repo.link(ctx, dto.MapToModel())
+}
+
+func (f *Forge) Syncs(ctx context.Context, prID string, status string) ([]*SyncDTO, error) {
+	entities, err := f.repo.listSyncs(ctx, prID, status)
+	if err != nil {
+		return nil, err
+	}
+	return MapFromSyncModels(entities), nil
+}
+
+// Deliver - sink для outbox: после коммита создания PR, переназначения или смены статуса
+// синхронизирует запрошенных ревьюверов во внешнем forge.
+// Каждая попытка записывается в forge_sync. Ошибка forge возвращается outbox'у, и событие
+// повторяется с его backoff'ом, пока не исчерпано maxAttempt попыток: внутри relay не ждём.
+func (f *Forge) Deliver(ctx context.Context, ev *events.Event) error {
+	var (
+		prID   string
+		add    []string
+		remove []string
+	)
+	switch ev.Type {
+	case events.PRCreated:
+		var data events.PullRequestData
+		if err := json.Unmarshal(ev.Data, &data); err != nil {
+			return err
+		}
+		prID, add = data.PullRequestID, data.AssignedReviewers
+	case events.PRReviewerReassigned:
+		var data events.ReviewerReassignedData
+		if err := json.Unmarshal(ev.Data, &data); err != nil {
+			return err
+		}
+		prID, add, remove = data.PullRequestID, []string{data.ReplacedBy}, []string{data.OldUserID}
+	case events.PRStatusChanged:
+		var data events.StatusChangedData
+		if err := json.Unmarshal(ev.Data, &data); err != nil {
+			return err
+		}
+		// markReady и reopen назначают ревьюверов, close их снимает
+		prID, add, remove = data.PullRequestID, data.AssignedReviewers, data.ReleasedReviewers
+	default:
+		return nil
+	}
+
+	link, err := f.repo.getLink(ctx, prID)
+	if errors.Is(err, apperrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	adapter, ok := f.adapters[link.Forge]
+	if !ok {
+		return nil
+	}
+
+	if len(remove) > 0 {
+		if err := f.sync(ctx, ev.ID, link, SyncActionRemove, remove, adapter.RemoveReviewers); err != nil {
+			return err
+		}
+	}
+	if len(add) > 0 {
+		if err := f.sync(ctx, ev.ID, link, SyncActionRequest, add, adapter.RequestReviewers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Forge) sync(ctx context.Context, eventID string, link *LinkEntity, action string, userIDs []string, call func(ctx context.Context, repository string, number int64, logins []string) error) error {
+	// событие могло уже повторяться outbox'ом: успешные действия не повторяем, неудачные считаем
+	prior, err := f.repo.eventSyncs(ctx, link.PullRequestID, eventID, action)
+	if err != nil {
+		return err
+	}
+	for _, p := range prior {
+		if p.Status != SyncFailed {
+			return nil
+		}
+	}
+	if len(prior) >= f.maxAttempt {
+		return nil
+	}
+
+	entity := &SyncEntity{
+		PullRequestID: link.PullRequestID,
+		EventID:       eventID,
+		Action:        action,
+		Logins:        []string{},
+		Attempts:      len(prior) + 1,
+	}
+
+	var unmapped []string
+	for _, userID := range userIDs {
+		if login, ok := f.logins[link.Forge][userID]; ok {
+			entity.Logins = append(entity.Logins, login)
+		} else {
+			unmapped = append(unmapped, userID)
+		}
+	}
+	if len(unmapped) > 0 {
+		msg := fmt.Sprintf("no %s login for users %v", link.Forge, unmapped)
+		entity.LastError = &msg
+	}
+	if len(entity.Logins) == 0 {
+		entity.Status, entity.Attempts = SyncSkipped, 0
+		return f.repo.recordSync(ctx, entity)
+	}
+
+	callErr := call(ctx, link.Repository, link.Number, entity.Logins)
+	if callErr != nil {
+		msg := callErr.Error()
+		entity.LastError = &msg
+		entity.Status = SyncFailed
+	} else {
+		entity.Status = SyncSucceeded
+	}
+	log.Printf("[Forge.sync] %s reviewers %v on %s %s#%d: %s (attempt %d)", action, entity.Logins, link.Forge, link.Repository, link.Number, entity.Status, entity.Attempts)
+	if err := f.repo.recordSync(ctx, entity); err != nil {
+		return err
+	}
+	if callErr != nil && entity.Attempts < f.maxAttempt {
+		return fmt.Errorf("%s reviewers on %s %s#%d: %w", action, link.Forge, link.Repository, link.Number, callErr)
+	}
+	return nil
+}
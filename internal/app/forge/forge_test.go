@@ -0,0 +1,243 @@
+package forge
+
+import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/apperrors"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+type fakeRepo struct {
+	mu    sync.Mutex
+	links map[string]*LinkEntity
+	syncs []*SyncEntity
+}
+
+func (f *fakeRepo) link(_ context.Context, entity *LinkEntity) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.links[entity.PullRequestID] = entity
+	return nil
+}
+
+func (f *fakeRepo) getLink(_ context.Context, prID string) (*LinkEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	link, ok := f.links[prID]
+	if !ok {
+		return nil, apperrors.ErrNotFound
+	}
+	return link, nil
+}
+
+func (f *fakeRepo) recordSync(_ context.Context, entity *SyncEntity) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entity.ID = uint64(len(f.syncs) + 1)
+	f.syncs = append(f.syncs, entity)
+	return nil
+}
+
+func (f *fakeRepo) eventSyncs(_ context.Context, prID string, eventID string, action string) ([]*SyncEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var entities []*SyncEntity
+	for _, s := range f.syncs {
+		if s.PullRequestID == prID && s.EventID == eventID && s.Action == action {
+			entities = append(entities, s)
+		}
+	}
+	return entities, nil
+}
+
+func (f *fakeRepo) listSyncs(_ context.Context, prID string, status string) ([]*SyncEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var entities []*SyncEntity
+	for _, s := range f.syncs {
+		if (prID == "" || s.PullRequestID == prID) && (status == "" || s.Status == status) {
+			entities = append(entities, s)
+		}
+	}
+	return entities, nil
+}
+
+type call struct {
+	method    string
+	path      string
+	auth      string
+	reviewers []string
+}
+
+// fakeGitHub отвечает на requested_reviewers; fail решает, вернуть ли 502 на очередной запрос
+type fakeGitHub struct {
+	mu    sync.Mutex
+	calls []call
+	fail  func(c call) bool
+}
+
+func (g *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Reviewers []string `json:"reviewers"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	c := call{method: r.Method, path: r.URL.Path, auth: r.Header.Get("Authorization"), reviewers: body.Reviewers}
+
+	g.mu.Lock()
+	g.calls = append(g.calls, c)
+	fail := g.fail != nil && g.fail(c)
+	g.mu.Unlock()
+
+	if fail {
+		http.Error(w, `{"message":"Server Error"}`, http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (g *fakeGitHub) count() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.calls)
+}
+
+func setup(t *testing.T, fail func(c call) bool) (*Forge, *fakeRepo, *fakeGitHub) {
+	t.Helper()
+	gh := &fakeGitHub{fail: fail}
+	server := httptest.NewServer(gh)
+	t.Cleanup(server.Close)
+
+	repo := &fakeRepo{links: map[string]*LinkEntity{}}
+	f := NewForge(repo)
+	f.Register("github", NewGitHubAdapter(server.URL, "t0ken", server.Client()), map[string]string{"octocat": "u1", "hubot": "u2"})
+	if err := f.Link(t.Context(), &LinkDTO{PullRequestID: "gh-1-7", Forge: "github", Repository: "acme/api", Number: 7}); err != nil {
+		t.Fatal(err)
+	}
+	return f, repo, gh
+}
+
+func event(t *testing.T, eventType string, data any) *events.Event {
+	t.Helper()
+	ev, err := events.New(eventType, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ev
+}
+
+func TestDeliverRequestsReviewers(t *testing.T) {
+	f, repo, gh := setup(t, nil)
+	ev := event(t, events.PRCreated, events.PullRequestData{PullRequestID: "gh-1-7", AssignedReviewers: []string{"u1", "u3"}})
+	if err := f.Deliver(t.Context(), ev); err != nil {
+		t.Fatal(err)
+	}
+
+	want := call{method: http.MethodPost, path: "/repos/acme/api/pulls/7/requested_reviewers", auth: "Bearer t0ken", reviewers: []string{"octocat"}}
+	if len(gh.calls) != 1 || gh.calls[0].method != want.method || gh.calls[0].path != want.path || gh.calls[0].auth != want.auth || !slices.Equal(gh.calls[0].reviewers, want.reviewers) {
+		t.Fatalf("expected %+v, got %+v", want, gh.calls)
+	}
+	s := repo.syncs[0]
+	if s.Status != SyncSucceeded || s.Attempts != 1 || s.LastError == nil {
+		t.Fatalf("expected succeeded sync noting unmapped u3, got %+v", s)
+	}
+
+	// повторная доставка того же события outbox'ом не ходит в GitHub
+	if err := f.Deliver(t.Context(), ev); err != nil {
+		t.Fatal(err)
+	}
+	if gh.count() != 1 {
+		t.Fatalf("redelivered event was synced again: %d calls", gh.count())
+	}
+}
+
+func TestDeliverFailureIsRetriedByOutbox(t *testing.T) {
+	failures := 1
+	f, repo, gh := setup(t, func(c call) bool {
+		if c.method == http.MethodPost && failures > 0 {
+			failures--
+			return true
+		}
+		return false
+	})
+	ev := event(t, events.PRReviewerReassigned, events.ReviewerReassignedData{
+		PullRequestData: events.PullRequestData{PullRequestID: "gh-1-7"},
+		OldUserID:       "u1",
+		ReplacedBy:      "u2",
+	})
+
+	// ошибка возвращается relay'ю сразу, без ожидания внутри доставки
+	if err := f.Deliver(t.Context(), ev); err == nil {
+		t.Fatal("expected error for outbox retry")
+	}
+	if len(repo.syncs) != 2 || repo.syncs[0].Status != SyncSucceeded || repo.syncs[1].Status != SyncFailed || repo.syncs[1].Attempts != 1 {
+		t.Fatalf("expected succeeded remove and failed request, got %+v %+v", repo.syncs[0], repo.syncs[1])
+	}
+
+	// повтор outbox'а: снятие уже прошло и не повторяется, запрос - вторая попытка
+	if err := f.Deliver(t.Context(), ev); err != nil {
+		t.Fatal(err)
+	}
+	if gh.count() != 3 || gh.calls[2].method != http.MethodPost {
+		t.Fatalf("expected only the request to be retried, got %+v", gh.calls)
+	}
+	if s := repo.syncs[2]; s.Action != SyncActionRequest || s.Status != SyncSucceeded || s.Attempts != 2 {
+		t.Fatalf("expected request succeeded on attempt 2, got %+v", s)
+	}
+}
+
+func TestDeliverGivesUpAfterMaxAttempt(t *testing.T) {
+	f, repo, gh := setup(t, func(call) bool { return true })
+	ev := event(t, events.PRCreated, events.PullRequestData{PullRequestID: "gh-1-7", AssignedReviewers: []string{"u1"}})
+
+	for i := 1; i < f.maxAttempt; i++ {
+		if err := f.Deliver(t.Context(), ev); err == nil {
+			t.Fatalf("attempt %d: expected error for outbox retry", i)
+		}
+	}
+	// последняя попытка записывается, но событие больше не задерживает outbox
+	for range 2 {
+		if err := f.Deliver(t.Context(), ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if gh.count() != f.maxAttempt {
+		t.Fatalf("expected %d calls, got %d", f.maxAttempt, gh.count())
+	}
+	failed, _ := f.Syncs(t.Context(), "gh-1-7", SyncFailed)
+	if len(failed) != f.maxAttempt || repo.syncs[len(repo.syncs)-1].Attempts != f.maxAttempt {
+		t.Fatalf("expected %d failed attempts recorded, got %d", f.maxAttempt, len(failed))
+	}
+}
+
+func TestDeliverSkipsUnlinkedAndUnmapped(t *testing.T) {
+	f, repo, gh := setup(t, nil)
+	if err := f.Deliver(t.Context(), event(t, events.PRCreated, events.PullRequestData{PullRequestID: "pr-local", AssignedReviewers: []string{"u1"}})); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Deliver(t.Context(), event(t, events.PRCreated, events.PullRequestData{PullRequestID: "gh-1-7", AssignedReviewers: []string{"u9"}})); err != nil {
+		t.Fatal(err)
+	}
+	if gh.count() != 0 {
+		t.Fatalf("expected no GitHub calls, got %+v", gh.calls)
+	}
+	if len(repo.syncs) != 1 || repo.syncs[0].Status != SyncSkipped || repo.syncs[0].Attempts != 0 {
+		t.Fatalf("expected one skipped sync, got %+v", repo.syncs)
+	}
+}
@@ -0,0 +1,73 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// GitHubAdapter ходит в GitHub REST API. baseURL можно подменить на локальный фейковый сервер.
+type GitHubAdapter struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewGitHubAdapter(baseURL string, token string, client *http.Client) *GitHubAdapter {
+	if baseURL == "" {
+		baseURL = DefaultGitHubAPIURL
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &GitHubAdapter{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  client,
+	}
+}
+
+func (g *GitHubAdapter) RequestReviewers(ctx context.Context, repository string, number int64, logins []string) error {
+	return g.requestedReviewers(ctx, http.MethodPost, repository, number, logins)
+}
+
+func (g *GitHubAdapter) RemoveReviewers(ctx context.Context, repository string, number int64, logins []string) error {
+	return g.requestedReviewers(ctx, http.MethodDelete, repository, number, logins)
+}
+
+func (g *GitHubAdapter) requestedReviewers(ctx context.Context, method string, repository string, number int64, logins []string) error {
+	body, err := json.Marshal(map[string][]string{"reviewers": logins})
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/requested_reviewers", g.baseURL, repository, number)
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if g.token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.token)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("github responded with status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return nil
+}
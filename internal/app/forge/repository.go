@@ -0,0 +1,107 @@
+package forge
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"log"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type DB interface {
+	Get(ctx context.Context, dest any, query string, args ...any) error
+	Select(ctx context.Context, dest any, query string, args ...any) error
+	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+	ExecQueryRow(ctx context.Context, query string, args ...any) pgx.Row
+}
+
+type ForgeRepo struct {
+	db DB
+}
+
+func NewForgeRepo(db DB) *ForgeRepo {
+	return &ForgeRepo{db: db}
+}
+
+func (f *ForgeRepo) link(ctx context.Context, entity *LinkEntity) error {
+	_, err := f.db.Exec(ctx, `
+		INSERT INTO forge_link (tenant_id, pull_request_id, forge, repository, number)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, pull_request_id) DO UPDATE
+		SET forge = EXCLUDED.forge,
+		    repository = EXCLUDED.repository,
+		    number = EXCLUDED.number
+	`, tenant.FromContext(ctx), entity.PullRequestID, entity.Forge, entity.Repository, entity.Number)
+	if err != nil {
+		log.Printf("[ForgeRepo.link] db error linking PR '%s' to %s %s#%d: %v", entity.PullRequestID, entity.Forge, entity.Repository, entity.Number, err)
+		return apperrors.ErrDB
+	}
+	log.Printf("[ForgeRepo.link] PR '%s' linked to %s %s#%d", entity.PullRequestID, entity.Forge, entity.Repository, entity.Number)
+	return nil
+}
+
+func (f *ForgeRepo) getLink(ctx context.Context, prID string) (*LinkEntity, error) {
+	var entity LinkEntity
+	err := f.db.Get(ctx, &entity, `
+		SELECT pull_request_id, forge, repository, number
+		FROM forge_link
+		WHERE tenant_id = $1 AND pull_request_id = $2
+	`, tenant.FromContext(ctx), prID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[ForgeRepo.getLink] db error fetching link of PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+	return &entity, nil
+}
+
+func (f *ForgeRepo) recordSync(ctx context.Context, entity *SyncEntity) error {
+	_, err := f.db.Exec(ctx, `
+		INSERT INTO forge_sync (tenant_id, pull_request_id, event_id, action, logins, status, attempts, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, tenant.FromContext(ctx), entity.PullRequestID, entity.EventID, entity.Action, entity.Logins, entity.Status, entity.Attempts, entity.LastError)
+	if err != nil {
+		log.Printf("[ForgeRepo.recordSync] db error recording sync of PR '%s': %v", entity.PullRequestID, err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
+
+// eventSyncs возвращает прошлые попытки синхронизации этого события: по ним считается номер попытки
+func (f *ForgeRepo) eventSyncs(ctx context.Context, prID string, eventID string, action string) ([]*SyncEntity, error) {
+	var entities []*SyncEntity
+	err := f.db.Select(ctx, &entities, `
+		SELECT id, pull_request_id, event_id, action, logins, status, attempts, last_error, created_at
+		FROM forge_sync
+		WHERE tenant_id = $1 AND pull_request_id = $2 AND event_id = $3 AND action = $4
+		ORDER BY id
+	`, tenant.FromContext(ctx), prID, eventID, action)
+	if err != nil {
+		log.Printf("[ForgeRepo.eventSyncs] db error fetching syncs of event '%s': %v", eventID, err)
+		return nil, apperrors.ErrDB
+	}
+	return entities, nil
+}
+
+func (f *ForgeRepo) listSyncs(ctx context.Context, prID string, status string) ([]*SyncEntity, error) {
+	var entities []*SyncEntity
+	err := f.db.Select(ctx, &entities, `
+		SELECT id, pull_request_id, event_id, action, logins, status, attempts, last_error, created_at
+		FROM forge_sync
+		WHERE tenant_id = $1
+		  AND ($2 = '' OR pull_request_id = $2)
+		  AND ($3 = '' OR status = $3)
+		ORDER BY id DESC
+		LIMIT 100
+	`, tenant.FromContext(ctx), prID, status)
+	if err != nil {
+		log.Printf("[ForgeRepo.listSyncs] db error fetching syncs: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	return entities, nil
+}
@@ -0,0 +1,20 @@
+package idempotency
+
+import "time"
+
+const (
+	StatusInProgress = "IN_PROGRESS"
+	StatusCompleted  = "COMPLETED"
+)
+
+type RecordEntity struct {
+	Scope           string            `db:"scope"`
+	Key             string            `db:"key"`
+	RequestHash     string            `db:"request_hash"`
+	Status          string            `db:"status"`
+	ResponseCode    *int              `db:"response_code"`
+	ResponseHeaders map[string]string `db:"response_headers"`
+	ResponseBody    []byte            `db:"response_body"`
+	CreatedAt       time.Time         `db:"created_at"`
+	ExpiresAt       time.Time         `db:"expires_at"`
+}
@@ -0,0 +1,91 @@
+package idempotency
+
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+)
+
+// inProgressLease - сколько живёт резерв выполняющегося запроса. Если процесс упал, не успев
+// ни сохранить ответ, ни снять резерв, по истечении аренды ключ занимает повтор.
+const inProgressLease = time.Minute
+
+type Repo interface {
+	reserve(ctx context.Context, scope string, key string, requestHash string, lease time.Duration) (*RecordEntity, bool, error)
+	complete(ctx context.Context, scope string, key string, code int, headers map[string]string, body []byte, ttl time.Duration) error
+	release(ctx context.Context, scope string, key string) error
+	purgeExpired(ctx context.Context) (int64, error)
+}
+
+// Response - сохранённый ответ для повтора
+type Response struct {
+	Code    int
+	Headers map[string]string
+	Body    []byte
+}
+
+type Store struct {
+	repo Repo
+	ttl  time.Duration
+}
+
+func NewStore(repo Repo, ttl time.Duration) *Store {
+	return &Store{repo: repo, ttl: ttl}
+}
+
+func RequestHash(method string, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Begin резервирует ключ. Возвращает сохранённый ответ, если запрос уже выполнялся
+// с тем же телом; ошибку, если тело другое или первый запрос ещё выполняется.
+func (s *Store) Begin(ctx context.Context, scope string, key string, requestHash string) (*Response, error) {
+	existing, reserved, err := s.repo.reserve(ctx, scope, key, requestHash, inProgressLease)
+	if err != nil {
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+	if existing.RequestHash != requestHash {
+		log.Printf("[Store.Begin] key '%s' reused with a different request", key)
+		return nil, fmt.Errorf("%w: key '%s' was used with a different request", apperrors.ErrIdempotencyMismatch, key)
+	}
+	if existing.Status != StatusCompleted || existing.ResponseCode == nil {
+		return nil, fmt.Errorf("%w: request with key '%s' is still being processed", apperrors.ErrIdempotencyInProgress, key)
+	}
+	log.Printf("[Store.Begin] replaying stored response for key '%s'", key)
+	return &Response{
+		Code:    *existing.ResponseCode,
+		Headers: existing.ResponseHeaders,
+		Body:    existing.ResponseBody,
+	}, nil
+}
+
+func (s *Store) Complete(ctx context.Context, scope string, key string, resp *Response) error {
+	return s.repo.complete(ctx, scope, key, resp.Code, resp.Headers, resp.Body, s.ttl)
+}
+
+// Release снимает резерв, чтобы повтор выполнился заново (например, после 5xx или паники)
+func (s *Store) Release(ctx context.Context, scope string, key string) error {
+	return s.repo.release(ctx, scope, key)
+}
+
+// PurgeExpired удаляет просроченные ключи и возвращает их число
+func (s *Store) PurgeExpired(ctx context.Context) (int, error) {
+	n, err := s.repo.purgeExpired(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if n > 0 {
+		log.Printf("[Store.PurgeExpired] purged %d expired idempotency keys", n)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,99 @@
+package idempotency
+
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type DB interface {
+	Get(ctx context.Context, dest any, query string, args ...any) error
+	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+}
+
+type IdempotencyRepo struct {
+	db DB
+}
+
+func NewIdempotencyRepo(db DB) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+// reserve пытается занять ключ на время аренды lease. Если ключ уже есть и не истёк (сохранённый
+// ответ или не истёкшая аренда), возвращает существующую запись и false.
+func (i *IdempotencyRepo) reserve(ctx context.Context, scope string, key string, requestHash string, lease time.Duration) (*RecordEntity, bool, error) {
+	tag, err := i.db.Exec(ctx, `
+		INSERT INTO idempotency_key (scope, key, request_hash, status, expires_at)
+		VALUES ($1, $2, $3, 'IN_PROGRESS', NOW() + $4 * INTERVAL '1 second')
+		ON CONFLICT (scope, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    status = EXCLUDED.status,
+		    response_code = NULL,
+		    response_headers = NULL,
+		    response_body = NULL,
+		    created_at = NOW(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_key.expires_at < NOW()
+	`, scope, key, requestHash, int64(lease.Seconds()))
+	if err != nil {
+		log.Printf("[IdempotencyRepo.reserve] db error reserving key '%s': %v", key, err)
+		return nil, false, apperrors.ErrDB
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, true, nil
+	}
+
+	var entity RecordEntity
+	err = i.db.Get(ctx, &entity, `
+		SELECT scope, key, request_hash, status, response_code, response_headers, response_body, created_at, expires_at
+		FROM idempotency_key
+		WHERE scope = $1 AND key = $2
+	`, scope, key)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// запись успели удалить между запросами: пусть клиент повторит
+			return nil, false, apperrors.ErrIdempotencyInProgress
+		}
+		log.Printf("[IdempotencyRepo.reserve] db error fetching key '%s': %v", key, err)
+		return nil, false, apperrors.ErrDB
+	}
+	return &entity, false, nil
+}
+
+// complete сохраняет ответ и продлевает ключ с аренды до полного ttl
+func (i *IdempotencyRepo) complete(ctx context.Context, scope string, key string, code int, headers map[string]string, body []byte, ttl time.Duration) error {
+	_, err := i.db.Exec(ctx, `
+		UPDATE idempotency_key
+		SET status = 'COMPLETED', response_code = $3, response_headers = $4, response_body = $5,
+		    expires_at = NOW() + $6 * INTERVAL '1 second'
+		WHERE scope = $1 AND key = $2
+	`, scope, key, code, headers, body, int64(ttl.Seconds()))
+	if err != nil {
+		log.Printf("[IdempotencyRepo.complete] db error storing response for key '%s': %v", key, err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
+
+func (i *IdempotencyRepo) release(ctx context.Context, scope string, key string) error {
+	_, err := i.db.Exec(ctx, "DELETE FROM idempotency_key WHERE scope = $1 AND key = $2", scope, key)
+	if err != nil {
+		log.Printf("[IdempotencyRepo.release] db error releasing key '%s': %v", key, err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
+
+func (i *IdempotencyRepo) purgeExpired(ctx context.Context) (int64, error) {
+	tag, err := i.db.Exec(ctx, "DELETE FROM idempotency_key WHERE expires_at < NOW()")
+	if err != nil {
+		log.Printf("[IdempotencyRepo.purgeExpired] db error purging keys: %v", err)
+		return 0, apperrors.ErrDB
+	}
+	return tag.RowsAffected(), nil
+}
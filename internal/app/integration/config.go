@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"avito-tech/internal/app/tenant"
+	"encoding/json"
+	"os"
+)
+
+// Config - секреты вебхуков, арендатор, в котором они создают PR, и соответствие логинов
+// в forge идентификаторам user_id.
+//
+//	{
+//	  "tenant": "acme",
+//	  "github": {"secret": "...", "api_token": "...", "users": {"octocat": "u1"}},
+//	  "gitlab": {"token": "...", "users": {"root": "u2"}}
+//	}
+type Config struct {
+	// Tenant - арендатор событий forge; заголовок X-Tenant-ID на /integrations/* не действует
+	Tenant string      `json:"tenant"`
+	GitHub ForgeConfig `json:"github"`
+	GitLab ForgeConfig `json:"gitlab"`
+}
+
+type ForgeConfig struct {
+	// Secret - секрет HMAC для GitHub (X-Hub-Signature-256)
+	Secret string `json:"secret"`
+	// Token - секретный токен GitLab (X-Gitlab-Token)
+	Token string            `json:"token"`
+	Users map[string]string `json:"users"`
+	// APIURL и APIToken нужны, чтобы проставлять назначенных ревьюверов обратно в forge
+	APIURL   string `json:"api_url"`
+	APIToken string `json:"api_token"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, err
+	}
+	if cfg.Tenant != "" {
+		if err := tenant.Validate(cfg.Tenant); err != nil {
+			return nil, err
+		}
+	}
+	return &cfg, nil
+}
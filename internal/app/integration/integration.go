@@ -0,0 +1,225 @@
+package integration
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+)
+
+const (
+	ActionOpened   = "opened"
+	ActionReopened = "reopened"
+	ActionMerged   = "merged"
+	ActionClosed   = "closed"
+	ActionIgnore   = "ignored"
+)
+
+// PullRequestEvent - событие forge, приведённое к терминам сервиса
+type PullRequestEvent struct {
+	Action          string
+	Forge           string
+	Repository      string
+	Number          int64
+	PullRequestID   string
+	PullRequestName string
+	AuthorID        string
+	// Tenant - арендатор из конфига интеграции: подпись forge подтверждает только его
+	Tenant string
+}
+
+type Integration struct {
+	cfg Config
+}
+
+func NewIntegration(cfg Config) *Integration {
+	if cfg.Tenant == "" {
+		cfg.Tenant = tenant.Default
+	}
+	return &Integration{cfg: cfg}
+}
+
+func (i *Integration) Parse(forge string, header http.Header, body []byte) (*PullRequestEvent, error) {
+	var (
+		ev  *PullRequestEvent
+		err error
+	)
+	switch forge {
+	case ForgeGitHub:
+		ev, err = i.parseGitHub(header, body)
+	case ForgeGitLab:
+		ev, err = i.parseGitLab(header, body)
+	default:
+		return nil, fmt.Errorf("%w: unknown forge '%s'", apperrors.ErrNotFound, forge)
+	}
+	if err != nil {
+		return nil, err
+	}
+	ev.Tenant = i.cfg.Tenant
+	return ev, nil
+}
+
+type githubPullRequestPayload struct {
+	Action      string `json:"action"`
+	Number      int64  `json:"number"`
+	PullRequest struct {
+		Title  string `json:"title"`
+		Merged bool   `json:"merged"`
+		User   struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"pull_request"`
+	Repository struct {
+		ID       int64  `json:"id"`
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+func (i *Integration) parseGitHub(header http.Header, body []byte) (*PullRequestEvent, error) {
+	if i.cfg.GitHub.Secret == "" {
+		return nil, fmt.Errorf("%w: github integration is not configured", apperrors.ErrNotFound)
+	}
+	if !verifyGitHubSignature(i.cfg.GitHub.Secret, header.Get("X-Hub-Signature-256"), body) {
+		log.Printf("[Integration.parseGitHub] invalid signature for delivery '%s'", header.Get("X-GitHub-Delivery"))
+		return nil, fmt.Errorf("%w: invalid github signature", apperrors.ErrUnauthorized)
+	}
+
+	if header.Get("X-GitHub-Event") != "pull_request" {
+		return &PullRequestEvent{Action: ActionIgnore}, nil
+	}
+
+	var payload githubPullRequestPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: invalid github payload: %v", apperrors.ErrBadRequest, err)
+	}
+
+	ev := &PullRequestEvent{
+		Forge:           ForgeGitHub,
+		Repository:      payload.Repository.FullName,
+		Number:          payload.Number,
+		PullRequestID:   fmt.Sprintf("gh-%d-%d", payload.Repository.ID, payload.Number),
+		PullRequestName: payload.PullRequest.Title,
+	}
+	switch {
+	case payload.Action == "opened":
+		ev.Action = ActionOpened
+	case payload.Action == "reopened":
+		ev.Action = ActionReopened
+	case payload.Action == "closed" && payload.PullRequest.Merged:
+		ev.Action = ActionMerged
+	case payload.Action == "closed":
+		ev.Action = ActionClosed
+	default:
+		ev.Action = ActionIgnore
+		return ev, nil
+	}
+
+	authorID, err := mapLogin(i.cfg.GitHub.Users, payload.PullRequest.User.Login)
+	if err != nil {
+		return nil, err
+	}
+	ev.AuthorID = authorID
+	return ev, nil
+}
+
+type gitlabMergeRequestPayload struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		ID                int64  `json:"id"`
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID    int64  `json:"iid"`
+		Title  string `json:"title"`
+		Action string `json:"action"`
+		State  string `json:"state"`
+	} `json:"object_attributes"`
+}
+
+func (i *Integration) parseGitLab(header http.Header, body []byte) (*PullRequestEvent, error) {
+	if i.cfg.GitLab.Token == "" {
+		return nil, fmt.Errorf("%w: gitlab integration is not configured", apperrors.ErrNotFound)
+	}
+	token := header.Get("X-Gitlab-Token")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(i.cfg.GitLab.Token)) != 1 {
+		log.Printf("[Integration.parseGitLab] invalid token for event '%s'", header.Get("X-Gitlab-Event"))
+		return nil, fmt.Errorf("%w: invalid gitlab token", apperrors.ErrUnauthorized)
+	}
+
+	var payload gitlabMergeRequestPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("%w: invalid gitlab payload: %v", apperrors.ErrBadRequest, err)
+	}
+	if payload.ObjectKind != "merge_request" {
+		return &PullRequestEvent{Action: ActionIgnore}, nil
+	}
+
+	ev := &PullRequestEvent{
+		Forge:           ForgeGitLab,
+		Repository:      payload.Project.PathWithNamespace,
+		Number:          payload.ObjectAttributes.IID,
+		PullRequestID:   fmt.Sprintf("gl-%d-%d", payload.Project.ID, payload.ObjectAttributes.IID),
+		PullRequestName: payload.ObjectAttributes.Title,
+	}
+	switch payload.ObjectAttributes.Action {
+	case "open":
+		ev.Action = ActionOpened
+	case "reopen":
+		ev.Action = ActionReopened
+	case "merge":
+		ev.Action = ActionMerged
+	case "close":
+		ev.Action = ActionClosed
+	default:
+		ev.Action = ActionIgnore
+		return ev, nil
+	}
+
+	// в GitLab user - тот, кто совершил действие; автор важен только при открытии
+	// (повторное открытие неизвестного сервису MR тоже заводит PR)
+	authorID, err := mapLogin(i.cfg.GitLab.Users, payload.User.Username)
+	if err != nil && (ev.Action == ActionOpened || ev.Action == ActionReopened) {
+		return nil, err
+	}
+	ev.AuthorID = authorID
+	return ev, nil
+}
+
+func mapLogin(users map[string]string, login string) (string, error) {
+	if userID, ok := users[login]; ok {
+		return userID, nil
+	}
+	if userID, ok := users[strings.ToLower(login)]; ok {
+		return userID, nil
+	}
+	return "", fmt.Errorf("%w: no user mapped to forge login '%s'", apperrors.ErrBadRequest, login)
+}
+
+func verifyGitHubSignature(secret string, signature string, body []byte) bool {
+	const prefix = "sha256="
+	if !strings.HasPrefix(signature, prefix) {
+		return false
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
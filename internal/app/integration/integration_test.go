@@ -0,0 +1,221 @@
+package integration
+
+import (
+	"avito-tech/internal/apperrors"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+var testConfig = Config{
+	GitHub: ForgeConfig{Secret: "s3cr3t", Users: map[string]string{"octocat": "u1"}},
+	GitLab: ForgeConfig{Token: "t0ken", Users: map[string]string{"root": "u2"}},
+}
+
+func payload(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func githubHeader(event string, secret string, body []byte) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	header := http.Header{}
+	header.Set("X-GitHub-Event", event)
+	header.Set("X-GitHub-Delivery", "72d3162e-cc78-11e3-81ab-4c9367dc0958")
+	header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return header
+}
+
+func gitlabHeader(token string) http.Header {
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Merge Request Hook")
+	header.Set("X-Gitlab-Token", token)
+	return header
+}
+
+func TestParseGitHub(t *testing.T) {
+	i := NewIntegration(testConfig)
+	for file, want := range map[string]string{
+		"github_pull_request_opened.json":   ActionOpened,
+		"github_pull_request_reopened.json": ActionReopened,
+		"github_pull_request_merged.json":   ActionMerged,
+		"github_pull_request_closed.json":   ActionClosed,
+		"github_pull_request_labeled.json":  ActionIgnore,
+	} {
+		t.Run(file, func(t *testing.T) {
+			body := payload(t, file)
+			ev, err := i.Parse(ForgeGitHub, githubHeader("pull_request", "s3cr3t", body), body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ev.Action != want {
+				t.Fatalf("action %q, want %q", ev.Action, want)
+			}
+			if ev.PullRequestID != "gh-1296269-42" || ev.Repository != "acme/api" || ev.Number != 42 {
+				t.Fatalf("unexpected PR %+v", ev)
+			}
+			if want != ActionIgnore && ev.AuthorID != "u1" {
+				t.Fatalf("author %q, want u1 mapped case-insensitively from Octocat", ev.AuthorID)
+			}
+		})
+	}
+}
+
+func TestParseGitLab(t *testing.T) {
+	i := NewIntegration(testConfig)
+	for file, want := range map[string]string{
+		"gitlab_merge_request_open.json":   ActionOpened,
+		"gitlab_merge_request_reopen.json": ActionReopened,
+		"gitlab_merge_request_merge.json":  ActionMerged,
+		"gitlab_merge_request_close.json":  ActionClosed,
+		"gitlab_merge_request_update.json": ActionIgnore,
+	} {
+		t.Run(file, func(t *testing.T) {
+			body := payload(t, file)
+			ev, err := i.Parse(ForgeGitLab, gitlabHeader("t0ken"), body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ev.Action != want {
+				t.Fatalf("action %q, want %q", ev.Action, want)
+			}
+			if ev.PullRequestID != "gl-15-7" || ev.Repository != "acme/billing" || ev.Number != 7 {
+				t.Fatalf("unexpected MR %+v", ev)
+			}
+		})
+	}
+}
+
+func TestParseIgnoresOtherEvents(t *testing.T) {
+	i := NewIntegration(testConfig)
+	body := []byte(`{"zen":"Keep it logically awesome.","hook_id":1}`)
+	ev, err := i.Parse(ForgeGitHub, githubHeader("ping", "s3cr3t", body), body)
+	if err != nil || ev.Action != ActionIgnore {
+		t.Fatalf("expected ping to be ignored, got %+v, %v", ev, err)
+	}
+	body = []byte(`{"object_kind":"push"}`)
+	ev, err = i.Parse(ForgeGitLab, gitlabHeader("t0ken"), body)
+	if err != nil || ev.Action != ActionIgnore {
+		t.Fatalf("expected push to be ignored, got %+v, %v", ev, err)
+	}
+}
+
+func TestParseRejectsBadSignature(t *testing.T) {
+	i := NewIntegration(testConfig)
+	body := payload(t, "github_pull_request_opened.json")
+
+	tampered := githubHeader("pull_request", "s3cr3t", body)
+	cases := map[string]http.Header{
+		"wrong secret": githubHeader("pull_request", "other", body),
+		"no signature": {"X-Github-Event": {"pull_request"}},
+		"sha1 prefix":  {"X-Github-Event": {"pull_request"}, "X-Hub-Signature-256": {"sha1=" + tampered.Get("X-Hub-Signature-256")[7:]}},
+		"not hex":      {"X-Github-Event": {"pull_request"}, "X-Hub-Signature-256": {"sha256=zz"}},
+	}
+	for name, header := range cases {
+		if _, err := i.Parse(ForgeGitHub, header, body); !errors.Is(err, apperrors.ErrUnauthorized) {
+			t.Errorf("%s: expected unauthorized, got %v", name, err)
+		}
+	}
+	// подпись считается по телу: изменённое тело не проходит
+	if _, err := i.Parse(ForgeGitHub, tampered, append([]byte(" "), body...)); !errors.Is(err, apperrors.ErrUnauthorized) {
+		t.Errorf("tampered body: expected unauthorized, got %v", err)
+	}
+
+	gitlab := payload(t, "gitlab_merge_request_open.json")
+	for name, header := range map[string]http.Header{"wrong token": gitlabHeader("nope"), "no token": {}} {
+		if _, err := i.Parse(ForgeGitLab, header, gitlab); !errors.Is(err, apperrors.ErrUnauthorized) {
+			t.Errorf("gitlab %s: expected unauthorized, got %v", name, err)
+		}
+	}
+}
+
+func TestParseRequiresConfiguredForge(t *testing.T) {
+	i := NewIntegration(Config{})
+	body := payload(t, "github_pull_request_opened.json")
+	if _, err := i.Parse(ForgeGitHub, githubHeader("pull_request", "", body), body); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected not found for unconfigured github, got %v", err)
+	}
+	if _, err := i.Parse("bitbucket", http.Header{}, body); !errors.Is(err, apperrors.ErrNotFound) {
+		t.Fatalf("expected not found for unknown forge, got %v", err)
+	}
+}
+
+func TestParseUnmappedAuthor(t *testing.T) {
+	i := NewIntegration(Config{
+		GitHub: ForgeConfig{Secret: "s3cr3t", Users: map[string]string{}},
+		GitLab: ForgeConfig{Token: "t0ken", Users: map[string]string{}},
+	})
+	body := payload(t, "github_pull_request_opened.json")
+	if _, err := i.Parse(ForgeGitHub, githubHeader("pull_request", "s3cr3t", body), body); !errors.Is(err, apperrors.ErrBadRequest) {
+		t.Fatalf("expected bad request for unmapped author, got %v", err)
+	}
+	// в GitLab закрытие делает не автор, и сопоставление логина не требуется
+	body = payload(t, "gitlab_merge_request_close.json")
+	ev, err := i.Parse(ForgeGitLab, gitlabHeader("t0ken"), body)
+	if err != nil || ev.Action != ActionClosed {
+		t.Fatalf("expected close without mapped user, got %+v, %v", ev, err)
+	}
+}
+
+func TestParseTenant(t *testing.T) {
+	body := payload(t, "github_pull_request_opened.json")
+	header := githubHeader("pull_request", "s3cr3t", body)
+	header.Set("X-Tenant-ID", "globex")
+
+	ev, err := NewIntegration(testConfig).Parse(ForgeGitHub, header, body)
+	if err != nil || ev.Tenant != "default" {
+		t.Fatalf("expected the default tenant without tenant in config, got %+v, %v", ev, err)
+	}
+	acme := testConfig
+	acme.Tenant = "acme"
+	ev, err = NewIntegration(acme).Parse(ForgeGitHub, header, body)
+	if err != nil || ev.Tenant != "acme" {
+		t.Fatalf("expected the tenant from config, not from the header, got %+v, %v", ev, err)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadConfig(write("ok.json", `{"tenant": "acme", "github": {"secret": "s3cr3t", "users": {"octocat": "u1"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Tenant != "acme" || cfg.GitHub.Secret != "s3cr3t" || cfg.GitHub.Users["octocat"] != "u1" {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+	if _, err := LoadConfig(write("tenant.json", `{"tenant": "Acme Corp"}`)); !errors.Is(err, apperrors.ErrBadRequest) {
+		t.Fatalf("expected an invalid tenant to be rejected, got %v", err)
+	}
+	if _, err := LoadConfig(write("broken.json", `{"tenant":`)); err == nil {
+		t.Fatal("expected an error for malformed json")
+	}
+}
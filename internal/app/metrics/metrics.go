@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Registry - минимальный реестр метрик в текстовом формате Prometheus
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]*family
+}
+
+type family struct {
+	name   string
+	help   string
+	kind   string
+	series map[string]*atomic.Uint64
+}
+
+var Default = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]*family{}}
+}
+
+type Counter struct {
+	v *atomic.Uint64
+}
+
+func (c Counter) Inc() {
+	c.Add(1)
+}
+
+func (c Counter) Add(n uint64) {
+	c.v.Add(n)
+}
+
+// Gauge хранит float64 в битовом представлении
+type Gauge struct {
+	v *atomic.Uint64
+}
+
+func (g Gauge) Set(value float64) {
+	g.v.Store(math.Float64bits(value))
+}
+
+func (g Gauge) Add(delta float64) {
+	for {
+		old := g.v.Load()
+		next := math.Float64bits(math.Float64frombits(old) + delta)
+		if g.v.CompareAndSwap(old, next) {
+			return
+		}
+	}
+}
+
+func (r *Registry) Counter(name string, help string, labels ...string) Counter {
+	return Counter{v: r.series(name, help, "counter", labels)}
+}
+
+func (r *Registry) Gauge(name string, help string, labels ...string) Gauge {
+	return Gauge{v: r.series(name, help, "gauge", labels)}
+}
+
+// series возвращает значение ряда; labels - пары ключ, значение
+func (r *Registry) series(name string, help string, kind string, labels []string) *atomic.Uint64 {
+	key := formatLabels(labels)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.metrics[name]
+	if !ok {
+		f = &family{name: name, help: help, kind: kind, series: map[string]*atomic.Uint64{}}
+		r.metrics[name] = f
+	}
+	v, ok := f.series[key]
+	if !ok {
+		v = &atomic.Uint64{}
+		f.series[key] = v
+	}
+	return v
+}
+
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		f := r.metrics[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+		keys := make([]string, 0, len(f.series))
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			raw := f.series[k].Load()
+			if f.kind == "gauge" {
+				fmt.Fprintf(&b, "%s%s %g\n", f.name, k, math.Float64frombits(raw))
+			} else {
+				fmt.Fprintf(&b, "%s%s %d\n", f.name, k, raw)
+			}
+		}
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		parts = append(parts, fmt.Sprintf("%s=%q", labels[i], labels[i+1]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
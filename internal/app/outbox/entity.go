@@ -0,0 +1,34 @@
+package outbox
+
+import (
+	"avito-tech/internal/app/events"
+	"time"
+)
+
+type OutboxEntity struct {
+	ID          uint64     `db:"id"`
+	TenantID    string     `db:"tenant_id"`
+	EventID     string     `db:"event_id"`
+	EventType   string     `db:"event_type"`
+	AggregateID string     `db:"aggregate_id"`
+	Actor       string     `db:"actor"`
+	Payload     []byte     `db:"payload"`
+	CreatedAt   time.Time  `db:"created_at"`
+	Attempts    int        `db:"attempts"`
+	LastError   *string    `db:"last_error"`
+	PublishedAt *time.Time `db:"published_at"`
+	// DeadAt - когда событие ушло в dead letters после maxAttempts неудачных попыток
+	DeadAt *time.Time `db:"dead_at"`
+}
+
+func (o *OutboxEntity) ToEvent() *events.Event {
+	return &events.Event{
+		Sequence:   o.ID,
+		ID:         o.EventID,
+		Type:       o.EventType,
+		OccurredAt: o.CreatedAt.UTC(),
+		Tenant:     o.TenantID,
+		Actor:      o.Actor,
+		Data:       o.Payload,
+	}
+}
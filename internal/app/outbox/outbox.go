@@ -0,0 +1,101 @@
+package outbox
+
+import (
+	"avito-tech/internal/app/tenant"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+const (
+	// maxAttempts - после стольких неудачных попыток событие уходит в dead letters и больше не доставляется
+	maxAttempts = 10
+	// claimLease - на столько откладывается забранное событие: если реплика упала во время доставки,
+	// событие заберёт следующий запуск после lease
+	claimLease = 5 * time.Minute
+)
+
+type Repo interface {
+	// claim забирает до limit событий, которым пора уйти, и сдвигает их next_attempt_at на lease.
+	// Транзакция claim короткая: sink'и вызываются уже после её фиксации.
+	claim(ctx context.Context, limit int, lease time.Duration) ([]*OutboxEntity, error)
+	markPublished(ctx context.Context, id uint64) error
+	// markFailed записывает неудачную попытку: следующая - через retryIn от времени БД, а с dead событие
+	// уходит в dead letters
+	markFailed(ctx context.Context, id uint64, lastError string, retryIn time.Duration, dead bool) error
+}
+
+// Relay доставляет события из outbox во все sink'и. Доставка at-least-once:
+// событие помечается опубликованным только после успеха во всех sink'ах,
+// поэтому получатели должны дедуплицировать по ID события (ключу идемпотентности).
+type Relay struct {
+	repo      Repo
+	sinks     []Sink
+	batchSize int
+}
+
+func NewRelay(repo Repo, batchSize int, sinks ...Sink) *Relay {
+	return &Relay{
+		repo:      repo,
+		sinks:     sinks,
+		batchSize: batchSize,
+	}
+}
+
+// RunOnce доставляет очередную пачку событий и возвращает число опубликованных; запускается планировщиком.
+// При остановке сервиса исход не пишется: событие останется забранным до истечения lease.
+func (r *Relay) RunOnce(ctx context.Context) (int, error) {
+	batch, err := r.repo.claim(ctx, r.batchSize, claimLease)
+	if err != nil {
+		return 0, err
+	}
+	published := 0
+	for _, e := range batch {
+		derr := r.deliver(ctx, e)
+		if ctx.Err() != nil {
+			return published, nil
+		}
+		if derr == nil {
+			if err := r.repo.markPublished(ctx, e.ID); err != nil {
+				return published, err
+			}
+			published++
+			continue
+		}
+		attempts := e.Attempts + 1
+		dead := attempts >= maxAttempts
+		if err := r.repo.markFailed(ctx, e.ID, derr.Error(), backoff(attempts), dead); err != nil {
+			return published, err
+		}
+		if dead {
+			log.Printf("[Relay.RunOnce] event '%s' moved to dead letters after %d attempts: %v", e.EventID, attempts, derr)
+		} else {
+			log.Printf("[Relay.RunOnce] event '%s' delivery failed (attempt %d): %v", e.EventID, attempts, derr)
+		}
+	}
+	if len(batch) > 0 {
+		log.Printf("[Relay.RunOnce] published %d of %d events", published, len(batch))
+	}
+	return published, nil
+}
+
+// deliver передаёт событие sink'ам в контексте его арендатора: по нему выбираются подписки
+func (r *Relay) deliver(ctx context.Context, entity *OutboxEntity) error {
+	ev := entity.ToEvent()
+	ctx = tenant.WithTenant(ctx, ev.Tenant)
+	for _, sink := range r.sinks {
+		if err := sink.Deliver(ctx, ev); err != nil {
+			return fmt.Errorf("sink %s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+func backoff(attempts int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempts && d < 10*time.Minute; i++ {
+		d *= 2
+	}
+	return d
+}
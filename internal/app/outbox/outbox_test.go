@@ -0,0 +1,169 @@
+package outbox
+
+import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/app/tenant"
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeRepo хранит outbox в памяти; claim отдаёт события, которым подошло время по часам now
+type fakeRepo struct {
+	now    time.Time
+	events []*fakeEvent
+}
+
+type fakeEvent struct {
+	entity        OutboxEntity
+	nextAttemptAt time.Time
+}
+
+func (f *fakeRepo) add(tenantID string, eventID string) {
+	f.events = append(f.events, &fakeEvent{
+		entity:        OutboxEntity{ID: uint64(len(f.events) + 1), TenantID: tenantID, EventID: eventID, EventType: events.PRCreated},
+		nextAttemptAt: f.now,
+	})
+}
+
+func (f *fakeRepo) claim(_ context.Context, limit int, lease time.Duration) ([]*OutboxEntity, error) {
+	var batch []*OutboxEntity
+	for _, e := range f.events {
+		if len(batch) == limit {
+			break
+		}
+		if e.entity.PublishedAt == nil && e.entity.DeadAt == nil && !e.nextAttemptAt.After(f.now) {
+			e.nextAttemptAt = f.now.Add(lease)
+			entity := e.entity
+			batch = append(batch, &entity)
+		}
+	}
+	return batch, nil
+}
+
+func (f *fakeRepo) markPublished(_ context.Context, id uint64) error {
+	e := f.events[id-1]
+	e.entity.Attempts++
+	e.entity.LastError = nil
+	e.entity.PublishedAt = &f.now
+	return nil
+}
+
+func (f *fakeRepo) markFailed(_ context.Context, id uint64, lastError string, retryIn time.Duration, dead bool) error {
+	e := f.events[id-1]
+	e.entity.Attempts++
+	e.entity.LastError = &lastError
+	e.nextAttemptAt = f.now.Add(retryIn)
+	if dead {
+		e.entity.DeadAt = &f.now
+	}
+	return nil
+}
+
+// flakySink падает первые failures доставок и запоминает арендаторов доставленных событий
+type flakySink struct {
+	failures  int
+	calls     int
+	delivered []string
+}
+
+func (s *flakySink) Name() string {
+	return "flaky"
+}
+
+func (s *flakySink) Deliver(ctx context.Context, ev *events.Event) error {
+	s.calls++
+	if s.calls <= s.failures {
+		return errors.New("receiver is down")
+	}
+	s.delivered = append(s.delivered, tenant.FromContext(ctx))
+	return nil
+}
+
+func TestRelayPublishes(t *testing.T) {
+	repo := &fakeRepo{now: time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	repo.add("acme", "e1")
+	repo.add("globex", "e2")
+	sink := &flakySink{}
+	relay := NewRelay(repo, 10, sink)
+
+	n, err := relay.RunOnce(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 || len(sink.delivered) != 2 || sink.delivered[0] != "acme" || sink.delivered[1] != "globex" {
+		t.Fatalf("expected both events delivered in their tenants, got %d: %v", n, sink.delivered)
+	}
+	if n, _ := relay.RunOnce(t.Context()); n != 0 || sink.calls != 2 {
+		t.Fatalf("published events were delivered again: %d calls", sink.calls)
+	}
+}
+
+func TestRelayRetriesWithBackoff(t *testing.T) {
+	repo := &fakeRepo{now: time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	repo.add("acme", "e1")
+	sink := &flakySink{failures: 2}
+	relay := NewRelay(repo, 10, sink)
+
+	if n, _ := relay.RunOnce(t.Context()); n != 0 {
+		t.Fatalf("expected the first attempt to fail")
+	}
+	e := repo.events[0]
+	if e.entity.Attempts != 1 || e.entity.LastError == nil || !e.nextAttemptAt.Equal(repo.now.Add(time.Second)) {
+		t.Fatalf("expected retry in 1s after the first failure, got attempts=%d next=%s", e.entity.Attempts, e.nextAttemptAt)
+	}
+	// до истечения backoff событие не забирается
+	relay.RunOnce(t.Context())
+	if sink.calls != 1 {
+		t.Fatalf("retried before backoff elapsed: %d calls", sink.calls)
+	}
+
+	repo.now = repo.now.Add(time.Second)
+	relay.RunOnce(t.Context())
+	if !e.nextAttemptAt.Equal(repo.now.Add(2 * time.Second)) {
+		t.Fatalf("expected retry in 2s after the second failure, got %s", e.nextAttemptAt)
+	}
+	repo.now = repo.now.Add(2 * time.Second)
+	if n, _ := relay.RunOnce(t.Context()); n != 1 || e.entity.PublishedAt == nil || e.entity.LastError != nil {
+		t.Fatalf("expected the third attempt to publish, got %d", n)
+	}
+}
+
+func TestRelayDeadLetter(t *testing.T) {
+	repo := &fakeRepo{now: time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	repo.add("acme", "e1")
+	sink := &flakySink{failures: 100}
+	relay := NewRelay(repo, 10, sink)
+
+	for range maxAttempts + 5 {
+		if _, err := relay.RunOnce(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+		repo.now = repo.now.Add(time.Hour)
+	}
+	e := repo.events[0]
+	if e.entity.DeadAt == nil || e.entity.Attempts != maxAttempts || sink.calls != maxAttempts {
+		t.Fatalf("expected a dead letter after %d attempts, got attempts=%d calls=%d dead=%v", maxAttempts, e.entity.Attempts, sink.calls, e.entity.DeadAt)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 11: 1024 * time.Second, 20: 1024 * time.Second} {
+		if got := backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package outbox
+
+import (
+	"avito-tech/internal/app/auth"
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"cmp"
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type DB interface {
+	GetPool(_ context.Context) *pgxpool.Pool
+}
+
+// Execer - то, через что репозитории пишут в outbox: обычно их собственная транзакция.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Write кладёт событие в outbox в рамках транзакции вызывающего репозитория,
+// так что событие фиксируется атомарно вместе с изменением данных.
+// Вместе с событием сохраняются арендатор и субъект запроса из контекста.
+func Write(ctx context.Context, tx Execer, eventType string, aggregateID string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("[outbox.Write] failed to marshal '%s' event for '%s': %v", eventType, aggregateID, err)
+		return apperrors.ErrDB
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox (tenant_id, event_id, event_type, aggregate_id, actor, payload)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, tenant.FromContext(ctx), events.NewID(), eventType, aggregateID, auth.Actor(ctx), payload)
+	if err != nil {
+		log.Printf("[outbox.Write] db error writing '%s' event for '%s': %v", eventType, aggregateID, err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
+
+type OutboxRepo struct {
+	db DB
+}
+
+func NewOutboxRepo(db DB) *OutboxRepo {
+	return &OutboxRepo{db: db}
+}
+
+// claim забирает события через SKIP LOCKED и сразу фиксирует сдвиг next_attempt_at на lease, поэтому
+// строки не держатся заблокированными, пока sink'и ходят по сети
+func (o *OutboxRepo) claim(ctx context.Context, limit int, lease time.Duration) ([]*OutboxEntity, error) {
+	rows, err := o.db.GetPool(ctx).Query(ctx, `
+		WITH due AS (
+			SELECT id
+			FROM outbox
+			WHERE published_at IS NULL AND dead_at IS NULL
+			  AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE outbox o
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due
+		WHERE o.id = due.id
+		RETURNING o.id, o.tenant_id, o.event_id, o.event_type, o.aggregate_id, o.actor, o.payload, o.created_at, o.attempts
+	`, limit, lease.Seconds())
+	if err != nil {
+		log.Printf("[OutboxRepo.claim] db error claiming pending events: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	defer rows.Close()
+	var batch []*OutboxEntity
+	for rows.Next() {
+		var e OutboxEntity
+		if err := rows.Scan(&e.ID, &e.TenantID, &e.EventID, &e.EventType, &e.AggregateID, &e.Actor, &e.Payload, &e.CreatedAt, &e.Attempts); err != nil {
+			log.Printf("[OutboxRepo.claim] failed to scan pending event: %v", err)
+			return nil, apperrors.ErrDB
+		}
+		batch = append(batch, &e)
+	}
+	if err := rows.Err(); err != nil {
+		log.Printf("[OutboxRepo.claim] db error reading pending events: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	// UPDATE ... RETURNING не сохраняет порядок подзапроса
+	slices.SortFunc(batch, func(x, y *OutboxEntity) int { return cmp.Compare(x.ID, y.ID) })
+	return batch, nil
+}
+
+func (o *OutboxRepo) markPublished(ctx context.Context, id uint64) error {
+	_, err := o.db.GetPool(ctx).Exec(ctx, `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = NULL, published_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		log.Printf("[OutboxRepo.markPublished] db error marking event %d published: %v", id, err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
+
+func (o *OutboxRepo) markFailed(ctx context.Context, id uint64, lastError string, retryIn time.Duration, dead bool) error {
+	_, err := o.db.GetPool(ctx).Exec(ctx, `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = $1, next_attempt_at = NOW() + make_interval(secs => $2),
+		    dead_at = CASE WHEN $3 THEN NOW() END
+		WHERE id = $4
+	`, lastError, retryIn.Seconds(), dead, id)
+	if err != nil {
+		log.Printf("[OutboxRepo.markFailed] db error recording failure of event %d: %v", id, err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package outbox
+
+import (
+	"avito-tech/internal/app/events"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+)
+
+type Sink interface {
+	Name() string
+	Deliver(ctx context.Context, ev *events.Event) error
+}
+
+type funcSink struct {
+	name string
+	fn   func(ctx context.Context, ev *events.Event) error
+}
+
+// NewFuncSink оборачивает функцию доставки, например webhook.Webhook.Publish.
+func NewFuncSink(name string, fn func(ctx context.Context, ev *events.Event) error) Sink {
+	return &funcSink{name: name, fn: fn}
+}
+
+func (s *funcSink) Name() string {
+	return s.name
+}
+
+func (s *funcSink) Deliver(ctx context.Context, ev *events.Event) error {
+	return s.fn(ctx, ev)
+}
+
+// WriterSink пишет события построчно в JSON (stdout, файл).
+type WriterSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+func NewWriterSink(name string, w io.Writer) *WriterSink {
+	return &WriterSink{name: name, w: w}
+}
+
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink("stdout", os.Stdout)
+}
+
+func NewFileSink(path string) (*WriterSink, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterSink("file:"+path, f), nil
+}
+
+func (s *WriterSink) Name() string {
+	return s.name
+}
+
+func (s *WriterSink) Deliver(_ context.Context, ev *events.Event) error {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,92 @@
+package pullrequest
+
+import (
+	"avito-tech/internal/clock"
+	"log"
+	"time"
+)
+
+const (
+	// StrategyDefault - подходящие участники команды по порядку user_id
+	StrategyDefault = "default"
+	// StrategyWorkingHours - сначала те, у кого сейчас рабочее время; остальные добирают недостающих
+	StrategyWorkingHours = "working-hours"
+)
+
+// Assignment - стратегия выбора ревьюверов и источник времени для неё и окон недоступности.
+// Нулевое значение - StrategyDefault по системным часам.
+type Assignment struct {
+	Strategy string
+	Clock    clock.Clock
+}
+
+func (a Assignment) now() time.Time {
+	if a.Clock == nil {
+		return time.Now()
+	}
+	return a.Clock.Now()
+}
+
+// candidate - участник, прошедший остальные правила назначения, его рабочие часы и команда,
+// из которой он назначается: Priority 0 - команда автора, дальше запасные по порядку
+type candidate struct {
+	UserID    string
+	Timezone  *string
+	WorkStart *int
+	WorkEnd   *int
+	TeamID    uint64
+	Priority  int
+}
+
+// pick выбирает до n кандидатов из упорядоченных по Priority. Запасная команда добирает только тех,
+// кого не хватило в предыдущих. Стратегия меняет порядок лишь внутри команды, поэтому назначение
+// не срывается из-за того, что ни у кого сейчас не рабочее время.
+func (a Assignment) pick(candidates []candidate, now time.Time, n int) []candidate {
+	var picked []candidate
+	for start := 0; start < len(candidates) && len(picked) < n; {
+		end := start
+		for end < len(candidates) && candidates[end].Priority == candidates[start].Priority {
+			end++
+		}
+		var preferred, rest []candidate
+		for _, c := range candidates[start:end] {
+			if a.Strategy != StrategyWorkingHours || c.atWork(now) {
+				preferred = append(preferred, c)
+			} else {
+				rest = append(rest, c)
+			}
+		}
+		picked = append(picked, preferred...)
+		picked = append(picked, rest...)
+		start = end
+	}
+	return picked[:min(n, len(picked))]
+}
+
+func userIDs(candidates []candidate) []string {
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.UserID
+	}
+	return ids
+}
+
+// atWork - сейчас рабочее время кандидата; без заданных часов кандидат считается на месте
+func (c candidate) atWork(now time.Time) bool {
+	if c.Timezone == nil || c.WorkStart == nil || c.WorkEnd == nil {
+		return true
+	}
+	loc, err := time.LoadLocation(*c.Timezone)
+	if err != nil {
+		log.Printf("[Assignment.pick] unknown timezone '%s' of user '%s': %v", *c.Timezone, c.UserID, err)
+		return true
+	}
+	local := now.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	start, end := *c.WorkStart, *c.WorkEnd
+	if start < end {
+		return minute >= start && minute < end
+	}
+	// смена через полночь
+	return minute >= start || minute < end
+}
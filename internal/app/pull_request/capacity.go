@@ -0,0 +1,66 @@
+package pullrequest
+
+import (
+	"avito-tech/internal/apperrors"
+	"fmt"
+)
+
+// capacitySQL - действующий лимит открытых ревью строки users: личный, иначе ближайшей команды в цепочке
+// предков (своя команда, отдел, ...); NULL - без ограничения
+const capacitySQL = `COALESCE(users.max_open_reviews, (
+	SELECT t.max_open_reviews
+	FROM team_closure c
+	JOIN team t ON t.id = c.ancestor_id
+	WHERE c.team_id = users.team_id AND t.max_open_reviews IS NOT NULL
+	ORDER BY c.depth
+	LIMIT 1
+))`
+
+// underCapacitySQL - условие для запросов кандидатов (FROM users): пользователь может взять ещё одно ревью.
+// Синтаксис общий для Postgres и SQLite. Ревью считаются в арендаторе строки users.
+const underCapacitySQL = `(` + capacitySQL + ` IS NULL OR (
+	SELECT COUNT(*)
+	FROM pull_request_reviewer cr
+	JOIN pull_request cp ON cp.tenant_id = cr.tenant_id AND cp.pull_request_id = cr.pull_request_id
+	WHERE cr.tenant_id = users.tenant_id AND cr.user_id = users.user_id AND cp.status = 'OPEN'
+) < ` + capacitySQL + `)`
+
+// availableSQL - у строки users нет окна недоступности, покрывающего момент now (плейсхолдер параметра:
+// $N в Postgres, ? в SQLite - тогда now передаётся дважды)
+func availableSQL(now string) string {
+	return `NOT EXISTS (
+	SELECT 1 FROM user_unavailability uw
+	WHERE uw.tenant_id = users.tenant_id AND uw.user_id = users.user_id AND uw.starts_at <= ` + now + ` AND uw.ends_at > ` + now + `
+)`
+}
+
+// sourceTeamsSQL - JOIN команд, из которых назначаются ревьюверы: команда автора (priority 0) и запасные
+// команды - свои или ближайшего предка, у которого они заданы. Запасные команды и предки всегда из арендатора
+// команды автора, поэтому JOIN сам ограничивает users этим арендатором. Плейсхолдер команды автора подставляется
+// трижды: в Postgres - $N::BIGINT, в SQLite - ?, и тогда команда передаётся трижды. Кандидаты сортируются
+// по src.priority, затем по user_id.
+func sourceTeamsSQL(teamID string) string {
+	return `JOIN (
+	SELECT ` + teamID + ` AS team_id, 0 AS priority
+	UNION ALL
+	SELECT f.fallback_team_id, f.priority
+	FROM team_fallback f
+	WHERE f.fallback_team_id <> ` + teamID + `
+	  AND f.team_id = (
+		SELECT c.ancestor_id
+		FROM team_closure c
+		WHERE c.team_id = ` + teamID + ` AND EXISTS (SELECT 1 FROM team_fallback x WHERE x.team_id = c.ancestor_id)
+		ORDER BY c.depth
+		LIMIT 1
+	  )
+) src ON src.team_id = users.team_id`
+}
+
+// noCandidate объясняет NO_CANDIDATE: если активные и доступные кандидаты были, но все упёрлись в лимит,
+// об этом говорится в сообщении ошибки
+func noCandidate(oldUserID string, atCapacity bool) error {
+	if atCapacity {
+		return fmt.Errorf("%w: every active candidate to replace '%s' has reached max_open_reviews", apperrors.ErrNoCandidate, oldUserID)
+	}
+	return apperrors.ErrNoCandidate
+}
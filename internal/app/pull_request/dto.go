@@ -28,6 +28,7 @@ func (pr *PullRequestShortDTOFromHttp) MapToPREntity() *PullRequestEntity {
 		PullRequestID:   pr.PullRequestID,
 		PullRequestName: pr.PullRequestName,
 		AuthorID:        pr.AuthorID,
+		Status:          pr.Status,
 	}
 }
 
@@ -42,13 +43,29 @@ func MapToModelsShort(entities []PullRequestShortDTO) []*PullRequestShortDTOFrom
 }
 
 type PullRequestDTOFromHttp struct {
-	PullRequestID     string     `json:"pull_request_id"`
-	PullRequestName   string     `json:"pull_request_name"`
-	AuthorID          string     `json:"author_id"`
-	Status            string     `json:"status"`
-	AssignedReviewers []string   `json:"assigned_reviewers"`
-	CreatedAt         time.Time  `json:"created_at"`
-	MergedAt          *time.Time `json:"mergedAt,omitempty"`
+	PullRequestID     string   `json:"pull_request_id"`
+	PullRequestName   string   `json:"pull_request_name"`
+	AuthorID          string   `json:"author_id"`
+	Status            string   `json:"status"`
+	AssignedReviewers []string `json:"assigned_reviewers"`
+	// ReviewerTeams - из какой команды назначен каждый ревьювер (команды автора или запасной)
+	ReviewerTeams []ReviewerTeamDTO `json:"reviewer_teams"`
+	CreatedAt     time.Time         `json:"created_at"`
+	MergedAt      *time.Time        `json:"mergedAt,omitempty"`
+	Reviews       []ReviewDTO       `json:"reviews"`
+	Version       uint64            `json:"-"`
+}
+
+type ReviewerTeamDTO struct {
+	UserID   string `json:"user_id"`
+	TeamName string `json:"team_name"`
+}
+
+type ReviewDTO struct {
+	UserID    string    `json:"user_id"`
+	Decision  string    `json:"decision"`
+	Comment   string    `json:"comment"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func (pr *PullRequestDTOFromHttp) MapToModel() *PullRequestEntity {
@@ -65,6 +82,19 @@ func (pr *PullRequestDTOFromHttp) MapFromModel(entity *PullRequestEntity) {
 	pr.AuthorID = entity.AuthorID
 	pr.Status = entity.Status
 	pr.AssignedReviewers = entity.AssignedReviewers
+	if pr.AssignedReviewers == nil {
+		// у черновика и закрытого PR ревьюверов нет - отдаём пустой список, а не null
+		pr.AssignedReviewers = []string{}
+	}
+	pr.ReviewerTeams = make([]ReviewerTeamDTO, len(entity.ReviewerTeams))
+	for i, t := range entity.ReviewerTeams {
+		pr.ReviewerTeams[i] = ReviewerTeamDTO(t)
+	}
 	pr.CreatedAt = entity.CreatedAt
 	pr.MergedAt = entity.MergedAt
+	pr.Version = entity.Version
+	pr.Reviews = make([]ReviewDTO, len(entity.Reviews))
+	for i, r := range entity.Reviews {
+		pr.Reviews[i] = ReviewDTO(r)
+	}
 }
@@ -3,11 +3,28 @@ package pullrequest
 import "time"
 
 type PullRequestEntity struct {
-	PullRequestID     string     `db:"pull_request_id"`
-	PullRequestName   string     `db:"pull_request_name"`
-	AuthorID          string     `db:"author_id"`
-	Status            string     `db:"status"`
-	AssignedReviewers []string   `db:"-"`
-	CreatedAt         time.Time  `db:"created_at"`
-	MergedAt          *time.Time `db:"merged_at"`
+	PullRequestID     string               `db:"pull_request_id"`
+	PullRequestName   string               `db:"pull_request_name"`
+	AuthorID          string               `db:"author_id"`
+	Status            string               `db:"status"`
+	AssignedReviewers []string             `db:"-"`
+	ReviewerTeams     []ReviewerTeamEntity `db:"-"`
+	Reviews           []ReviewEntity       `db:"-"`
+	CreatedAt         time.Time            `db:"created_at"`
+	MergedAt          *time.Time           `db:"merged_at"`
+	Version           uint64               `db:"version"`
+}
+
+// ReviewerTeamEntity - команда, из которой назначен ревьювер: команда автора или запасная
+type ReviewerTeamEntity struct {
+	UserID   string `db:"user_id"`
+	TeamName string `db:"team_name"`
+}
+
+// ReviewEntity - вердикт назначенного ревьювера
+type ReviewEntity struct {
+	UserID    string    `db:"user_id"`
+	Decision  string    `db:"decision"`
+	Comment   string    `db:"comment"`
+	UpdatedAt time.Time `db:"updated_at"`
 }
@@ -0,0 +1,361 @@
+package pullrequest
+
+import (
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/memory"
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PullRequestMemoryRepo - реализация Repo поверх memory.Store с той же семантикой,
+// что у PullRequestRepo. События в outbox не пишутся.
+type PullRequestMemoryRepo struct {
+	store *memory.Store
+}
+
+func NewMemoryRepo(store *memory.Store) *PullRequestMemoryRepo {
+	return &PullRequestMemoryRepo{
+		store: store,
+	}
+}
+
+func toEntity(d *memory.Data, pr *memory.PullRequest) *PullRequestEntity {
+	entity := &PullRequestEntity{
+		PullRequestID:     pr.ID,
+		PullRequestName:   pr.Name,
+		AuthorID:          pr.AuthorID,
+		Status:            pr.Status,
+		AssignedReviewers: slices.Clone(pr.Reviewers),
+		CreatedAt:         pr.CreatedAt,
+		Version:           pr.Version,
+	}
+	if pr.MergedAt != nil {
+		mergedAt := *pr.MergedAt
+		entity.MergedAt = &mergedAt
+	}
+	for _, userID := range pr.Reviewers {
+		var teamName string
+		if t := d.TeamByID(pr.SourceTeams[userID]); t != nil {
+			teamName = t.Name
+		}
+		entity.ReviewerTeams = append(entity.ReviewerTeams, ReviewerTeamEntity{UserID: userID, TeamName: teamName})
+	}
+	slices.SortFunc(entity.ReviewerTeams, func(a, b ReviewerTeamEntity) int {
+		return strings.Compare(a.UserID, b.UserID)
+	})
+	for _, r := range pr.Reviews {
+		entity.Reviews = append(entity.Reviews, ReviewEntity(r))
+	}
+	slices.SortFunc(entity.Reviews, func(a, b ReviewEntity) int {
+		return strings.Compare(a.UserID, b.UserID)
+	})
+	return entity
+}
+
+// mergeCounts - число назначенных ревьюверов, одобрений и запросов изменений
+func mergeCounts(pr *memory.PullRequest) (reviewers int, approved int, changesRequested int) {
+	for _, r := range pr.Reviews {
+		if !slices.Contains(pr.Reviewers, r.UserID) {
+			continue
+		}
+		switch r.Decision {
+		case ReviewApproved:
+			approved++
+		case ReviewChangesRequested:
+			changesRequested++
+		}
+	}
+	return len(pr.Reviewers), approved, changesRequested
+}
+
+func (request *PullRequestMemoryRepo) create(ctx context.Context, pr *PullRequestEntity, assign Assignment) (*PullRequestEntity, error) {
+	var created *PullRequestEntity
+	err := request.store.Write(ctx, func(d *memory.Data) error {
+		author, ok := d.Users[pr.AuthorID]
+		if !ok {
+			log.Printf("[PullRequestMemoryRepo.create] author's team not found for user '%s'", pr.AuthorID)
+			return apperrors.ErrNotFound
+		}
+		if _, ok := d.PullRequests[pr.PullRequestID]; ok {
+			log.Printf("[PullRequestMemoryRepo.create] PR already exists: '%s'", pr.PullRequestID)
+			return apperrors.ErrPRExists
+		}
+
+		// черновик создаётся без ревьюверов, остальные PR - открытыми
+		status := StatusOpen
+		if pr.Status == StatusDraft {
+			status = StatusDraft
+		}
+		var reviewers []candidate
+		now := assign.now()
+		if status == StatusOpen {
+			reviewers = pickReviewers(d, pr.AuthorID, author.TeamID, assign, now)
+		}
+
+		stored := &memory.PullRequest{
+			ID:          pr.PullRequestID,
+			Name:        pr.PullRequestName,
+			AuthorID:    pr.AuthorID,
+			Status:      status,
+			CreatedAt:   time.Now(),
+			Version:     1,
+			Reviewers:   userIDs(reviewers),
+			AssignedAt:  assignedAt(reviewers, now),
+			SourceTeams: sourceTeams(reviewers),
+		}
+		d.PullRequests[stored.ID] = stored
+		created = toEntity(d, stored)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[PullRequestMemoryRepo.create] PR '%s' created successfully with reviewers: %v", created.PullRequestID, created.AssignedReviewers)
+	return created, nil
+}
+
+// pickReviewers выбирает до MaxReviewers активных и доступных участников команды автора, не достигших лимита ревью;
+// недостающих добирает из запасных команд
+func pickReviewers(d *memory.Data, authorID string, teamID uint64, assign Assignment, now time.Time) []candidate {
+	var candidates []candidate
+	for priority, sourceID := range d.SourceTeams(teamID) {
+		for _, u := range d.TeamMembers(sourceID) {
+			if u.ID != authorID && u.IsActive && !d.Unavailable(u.ID, now) && !d.AtCapacity(u) {
+				candidates = append(candidates, toCandidate(u, priority))
+			}
+		}
+	}
+	return assign.pick(candidates, now, MaxReviewers)
+}
+
+// assignedAt - моменты назначения ревьюверов, как столбец assigned_at в pull_request_reviewer
+func assignedAt(reviewers []candidate, now time.Time) map[string]time.Time {
+	m := make(map[string]time.Time, len(reviewers))
+	for _, c := range reviewers {
+		m[c.UserID] = now
+	}
+	return m
+}
+
+// sourceTeams - команды назначенных ревьюверов, как столбец source_team_id в pull_request_reviewer
+func sourceTeams(reviewers []candidate) map[string]uint64 {
+	m := make(map[string]uint64, len(reviewers))
+	for _, c := range reviewers {
+		m[c.UserID] = c.TeamID
+	}
+	return m
+}
+
+func toCandidate(u *memory.User, priority int) candidate {
+	c := candidate{UserID: u.ID, TeamID: u.TeamID, Priority: priority}
+	if wh := u.WorkingHours; wh != nil {
+		c.Timezone, c.WorkStart, c.WorkEnd = &wh.Timezone, &wh.Start, &wh.End
+	}
+	return c
+}
+
+func (request *PullRequestMemoryRepo) transition(ctx context.Context, prID string, a action, ifMatch *uint64, assign Assignment) (*PullRequestEntity, error) {
+	var (
+		entity *PullRequestEntity
+		from   string
+	)
+	err := request.store.Write(ctx, func(d *memory.Data) error {
+		pr, ok := d.PullRequests[prID]
+		if !ok {
+			log.Printf("[PullRequestMemoryRepo.transition] PR not found: '%s'", prID)
+			return apperrors.ErrNotFound
+		}
+		if ifMatch != nil && *ifMatch != pr.Version {
+			log.Printf("[PullRequestMemoryRepo.transition] PR '%s' is at version %d, client expected %d", prID, pr.Version, *ifMatch)
+			return apperrors.ErrPreconditionFailed
+		}
+		if err := a.check(prID, pr.Status); err != nil {
+			log.Printf("[PullRequestMemoryRepo.transition] %v", err)
+			return err
+		}
+
+		switch a.to {
+		case StatusOpen:
+			now := assign.now()
+			reviewers := pickReviewers(d, pr.AuthorID, d.Users[pr.AuthorID].TeamID, assign, now)
+			pr.Reviewers = userIDs(reviewers)
+			pr.AssignedAt = assignedAt(reviewers, now)
+			pr.SourceTeams = sourceTeams(reviewers)
+		case StatusClosed:
+			pr.Reviewers = nil
+			pr.AssignedAt = nil
+			pr.SourceTeams = nil
+			pr.Reviews = nil
+		}
+		from = pr.Status
+		pr.Status = a.to
+		pr.Version++
+		entity = toEntity(d, pr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[PullRequestMemoryRepo.transition] PR '%s' moved from %s to %s", prID, from, a.to)
+	return entity, nil
+}
+
+func (request *PullRequestMemoryRepo) reassignReviewer(ctx context.Context, prID string, oldUserID string, ifMatch *uint64, assign Assignment) (*PullRequestEntity, string, error) {
+	var (
+		updated   *PullRequestEntity
+		newUserID string
+	)
+	err := request.store.Write(ctx, func(d *memory.Data) error {
+		pr, ok := d.PullRequests[prID]
+		if !ok {
+			log.Printf("[PullRequestMemoryRepo.reassignReviewer] PR not found: '%s'", prID)
+			return apperrors.ErrNotFound
+		}
+		if ifMatch != nil && *ifMatch != pr.Version {
+			log.Printf("[PullRequestMemoryRepo.reassignReviewer] PR '%s' is at version %d, client expected %d", prID, pr.Version, *ifMatch)
+			return apperrors.ErrPreconditionFailed
+		}
+		if pr.Status == StatusMerged {
+			log.Printf("[PullRequestMemoryRepo.reassignReviewer] cannot reassign reviewers for merged PR '%s'", prID)
+			return apperrors.ErrPRMerged
+		}
+		idx := slices.Index(pr.Reviewers, oldUserID)
+		if idx < 0 {
+			log.Printf("[PullRequestMemoryRepo.reassignReviewer] user '%s' not assigned to PR '%s'", oldUserID, prID)
+			return apperrors.ErrNotAssigned
+		}
+
+		author := d.Users[pr.AuthorID]
+		now := assign.now()
+		atCapacity := false
+		var candidates []candidate
+		for priority, sourceID := range d.SourceTeams(author.TeamID) {
+			for _, u := range d.TeamMembers(sourceID) {
+				if u.IsActive && u.ID != pr.AuthorID && u.ID != oldUserID && !slices.Contains(pr.Reviewers, u.ID) && !d.Unavailable(u.ID, now) {
+					if d.AtCapacity(u) {
+						atCapacity = true
+						continue
+					}
+					candidates = append(candidates, toCandidate(u, priority))
+				}
+			}
+		}
+		picked := assign.pick(candidates, now, 1)
+		if len(picked) == 0 {
+			log.Printf("[PullRequestMemoryRepo.reassignReviewer] no candidate available to replace user '%s' in PR '%s' (at capacity: %v)", oldUserID, prID, atCapacity)
+			return noCandidate(oldUserID, atCapacity)
+		}
+		newUserID = picked[0].UserID
+
+		pr.Reviewers[idx] = newUserID
+		delete(pr.AssignedAt, oldUserID)
+		pr.AssignedAt[newUserID] = now
+		delete(pr.SourceTeams, oldUserID)
+		pr.SourceTeams[newUserID] = picked[0].TeamID
+		// вердикт снятого ревьювера больше не действует
+		pr.Reviews = slices.DeleteFunc(pr.Reviews, func(r memory.Review) bool {
+			return r.UserID == oldUserID
+		})
+		pr.Version++
+		updated = toEntity(d, pr)
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	log.Printf("[PullRequestMemoryRepo.reassignReviewer] user '%s' replaced by '%s' in PR '%s'", oldUserID, newUserID, prID)
+	return updated, newUserID, nil
+}
+
+func (request *PullRequestMemoryRepo) getByID(ctx context.Context, prID string) (*PullRequestEntity, error) {
+	var entity *PullRequestEntity
+	err := request.store.Read(ctx, func(d *memory.Data) error {
+		pr, ok := d.PullRequests[prID]
+		if !ok {
+			log.Printf("[PullRequestMemoryRepo.getByID] PR '%s' not found", prID)
+			return apperrors.ErrNotFound
+		}
+		entity = toEntity(d, pr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (request *PullRequestMemoryRepo) merge(ctx context.Context, prID string, ifMatch *uint64, policy *MergePolicy) (*PullRequestEntity, error) {
+	var entity *PullRequestEntity
+	err := request.store.Write(ctx, func(d *memory.Data) error {
+		pr, ok := d.PullRequests[prID]
+		if !ok {
+			log.Printf("[PullRequestMemoryRepo.merge] PR '%s' not found", prID)
+			return apperrors.ErrNotFound
+		}
+		if ifMatch != nil && *ifMatch != pr.Version {
+			log.Printf("[PullRequestMemoryRepo.merge] PR '%s' is at version %d, client expected %d", prID, pr.Version, *ifMatch)
+			return apperrors.ErrPreconditionFailed
+		}
+		// повторный merge возвращает текущее состояние без изменений
+		if pr.Status != StatusMerged {
+			if err := actionMerge.check(prID, pr.Status); err != nil {
+				log.Printf("[PullRequestMemoryRepo.merge] %v", err)
+				return err
+			}
+			if policy != nil {
+				reviewers, approved, changesRequested := mergeCounts(pr)
+				if err := policy.check(prID, reviewers, approved, changesRequested); err != nil {
+					log.Printf("[PullRequestMemoryRepo.merge] %v", err)
+					return err
+				}
+			}
+			now := time.Now()
+			pr.Status = StatusMerged
+			pr.MergedAt = &now
+			pr.Version++
+		}
+		entity = toEntity(d, pr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (request *PullRequestMemoryRepo) review(ctx context.Context, prID string, userID string, decision string, comment string) (*PullRequestEntity, error) {
+	var entity *PullRequestEntity
+	err := request.store.Write(ctx, func(d *memory.Data) error {
+		pr, ok := d.PullRequests[prID]
+		if !ok {
+			log.Printf("[PullRequestMemoryRepo.review] PR not found: '%s'", prID)
+			return apperrors.ErrNotFound
+		}
+		if pr.Status == StatusMerged {
+			log.Printf("[PullRequestMemoryRepo.review] cannot review merged PR '%s'", prID)
+			return apperrors.ErrPRMerged
+		}
+		if !slices.Contains(pr.Reviewers, userID) {
+			log.Printf("[PullRequestMemoryRepo.review] user '%s' not assigned to PR '%s'", userID, prID)
+			return apperrors.ErrNotAssigned
+		}
+
+		review := memory.Review{UserID: userID, Decision: decision, Comment: comment, UpdatedAt: time.Now()}
+		if i := slices.IndexFunc(pr.Reviews, func(r memory.Review) bool { return r.UserID == userID }); i >= 0 {
+			pr.Reviews[i] = review
+		} else {
+			pr.Reviews = append(pr.Reviews, review)
+		}
+		pr.Version++
+		entity = toEntity(d, pr)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[PullRequestMemoryRepo.review] user '%s' left %s on PR '%s'", userID, decision, prID)
+	return entity, nil
+}
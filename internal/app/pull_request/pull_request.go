@@ -1,30 +1,57 @@
 package pullrequest
 
 import (
+	"avito-tech/internal/apperrors"
 	"context"
+	"fmt"
+)
 
-	"github.com/jackc/pgx/v4"
+const (
+	ReviewApproved         = "APPROVED"
+	ReviewChangesRequested = "CHANGES_REQUESTED"
 )
 
 type Repo interface {
-	create(ctx context.Context, pr *PullRequestEntity) (*PullRequestEntity, error)
-	reassignReviewer(ctx context.Context, prID string, oldUserID string) (*PullRequestEntity, string, error)
-	getByIDTx(ctx context.Context, tx pgx.Tx, prID string) (*PullRequestEntity, error)
-	merge(ctx context.Context, prID string) (*PullRequestEntity, error)
+	create(ctx context.Context, pr *PullRequestEntity, assign Assignment) (*PullRequestEntity, error)
+	reassignReviewer(ctx context.Context, prID string, oldUserID string, ifMatch *uint64, assign Assignment) (*PullRequestEntity, string, error)
+	getByID(ctx context.Context, prID string) (*PullRequestEntity, error)
+	merge(ctx context.Context, prID string, ifMatch *uint64, policy *MergePolicy) (*PullRequestEntity, error)
+	review(ctx context.Context, prID string, userID string, decision string, comment string) (*PullRequestEntity, error)
+	transition(ctx context.Context, prID string, a action, ifMatch *uint64, assign Assignment) (*PullRequestEntity, error)
+}
+
+// MergePolicy - условие merge: RequiredApprovals одобрений от назначенных ревьюверов и ни одного
+// CHANGES_REQUESTED. Если ревьюверов назначено меньше (некого было назначить), хватает одобрений всех,
+// но хотя бы одно одобрение нужно всегда: PR без ревьюверов не мёржится.
+type MergePolicy struct {
+	RequiredApprovals int
+}
+
+func (p *MergePolicy) check(prID string, reviewers int, approved int, changesRequested int) error {
+	if changesRequested > 0 {
+		return fmt.Errorf("%w: %d reviewer(s) requested changes on PR '%s'", apperrors.ErrNotApproved, changesRequested, prID)
+	}
+	required := max(min(p.RequiredApprovals, reviewers), 1)
+	if approved < required {
+		return fmt.Errorf("%w: PR '%s' has %d of %d required approvals", apperrors.ErrNotApproved, prID, approved, required)
+	}
+	return nil
 }
 
 type PullRequest struct {
-	repo Repo
+	repo   Repo
+	assign Assignment
 }
 
-func NewPullRequest(repo Repo) *PullRequest {
+func NewPullRequest(repo Repo, assign Assignment) *PullRequest {
 	return &PullRequest{
-		repo: repo,
+		repo:   repo,
+		assign: assign,
 	}
 }
 
 func (pr *PullRequest) Create(ctx context.Context, prShort *PullRequestShortDTOFromHttp) (*PullRequestDTOFromHttp, error) {
-	entity, err := pr.repo.create(ctx, prShort.MapToPREntity())
+	entity, err := pr.repo.create(ctx, prShort.MapToPREntity(), pr.assign)
 	if err != nil {
 		return nil, err
 	}
@@ -33,8 +60,8 @@ func (pr *PullRequest) Create(ctx context.Context, prShort *PullRequestShortDTOF
 	return &answer, nil
 }
 
-func (pr *PullRequest) Merge(ctx context.Context, prID string) (*PullRequestDTOFromHttp, error) {
-	entity, err := pr.repo.merge(ctx, prID)
+func (pr *PullRequest) GetByID(ctx context.Context, prID string) (*PullRequestDTOFromHttp, error) {
+	entity, err := pr.repo.getByID(ctx, prID)
 	if err != nil {
 		return nil, err
 	}
@@ -43,8 +70,20 @@ func (pr *PullRequest) Merge(ctx context.Context, prID string) (*PullRequestDTOF
 	return &answer, nil
 }
 
-func (pr *PullRequest) Reassign(ctx context.Context, prID string, oldUserID string) (*PullRequestDTOFromHttp, string, error) {
-	entity, newUser, err := pr.repo.reassignReviewer(ctx, prID, oldUserID)
+// Merge и Reassign принимают ifMatch - ожидаемую версию PR (nil - без проверки).
+// policy == nil - merge без проверки одобрений.
+func (pr *PullRequest) Merge(ctx context.Context, prID string, ifMatch *uint64, policy *MergePolicy) (*PullRequestDTOFromHttp, error) {
+	entity, err := pr.repo.merge(ctx, prID, ifMatch, policy)
+	if err != nil {
+		return nil, err
+	}
+	var answer PullRequestDTOFromHttp
+	answer.MapFromModel(entity)
+	return &answer, nil
+}
+
+func (pr *PullRequest) Reassign(ctx context.Context, prID string, oldUserID string, ifMatch *uint64) (*PullRequestDTOFromHttp, string, error) {
+	entity, newUser, err := pr.repo.reassignReviewer(ctx, prID, oldUserID, ifMatch, pr.assign)
 	if err != nil {
 		return nil, "", err
 	}
@@ -52,3 +91,42 @@ func (pr *PullRequest) Reassign(ctx context.Context, prID string, oldUserID stri
 	answer.MapFromModel(entity)
 	return &answer, newUser, err
 }
+
+// Review сохраняет вердикт назначенного ревьювера; повторный вердикт заменяет предыдущий
+func (pr *PullRequest) Review(ctx context.Context, prID string, userID string, decision string, comment string) (*PullRequestDTOFromHttp, error) {
+	if decision != ReviewApproved && decision != ReviewChangesRequested {
+		return nil, fmt.Errorf("%w: decision must be %s or %s", apperrors.ErrBadRequest, ReviewApproved, ReviewChangesRequested)
+	}
+	entity, err := pr.repo.review(ctx, prID, userID, decision, comment)
+	if err != nil {
+		return nil, err
+	}
+	var answer PullRequestDTOFromHttp
+	answer.MapFromModel(entity)
+	return &answer, nil
+}
+
+// MarkReady переводит черновик в OPEN и назначает ревьюверов
+func (pr *PullRequest) MarkReady(ctx context.Context, prID string, ifMatch *uint64) (*PullRequestDTOFromHttp, error) {
+	return pr.apply(ctx, prID, actionMarkReady, ifMatch)
+}
+
+// Close закрывает PR без merge и освобождает ревьюверов
+func (pr *PullRequest) Close(ctx context.Context, prID string, ifMatch *uint64) (*PullRequestDTOFromHttp, error) {
+	return pr.apply(ctx, prID, actionClose, ifMatch)
+}
+
+// Reopen возвращает закрытый PR в OPEN с новым набором ревьюверов
+func (pr *PullRequest) Reopen(ctx context.Context, prID string, ifMatch *uint64) (*PullRequestDTOFromHttp, error) {
+	return pr.apply(ctx, prID, actionReopen, ifMatch)
+}
+
+func (pr *PullRequest) apply(ctx context.Context, prID string, a action, ifMatch *uint64) (*PullRequestDTOFromHttp, error) {
+	entity, err := pr.repo.transition(ctx, prID, a, ifMatch, pr.assign)
+	if err != nil {
+		return nil, err
+	}
+	var answer PullRequestDTOFromHttp
+	answer.MapFromModel(entity)
+	return &answer, nil
+}
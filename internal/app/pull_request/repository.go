@@ -1,24 +1,35 @@
 package pullrequest
 
 import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/app/outbox"
+	"avito-tech/internal/app/tenant"
 	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db"
 	"context"
 	"errors"
 	"log"
+	"slices"
+	"time"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type DB interface {
-	GetPool(_ context.Context) *pgxpool.Pool
+	Begin(ctx context.Context) (pgx.Tx, error)
 	Get(ctx context.Context, dest any, query string, args ...any) error
 	Select(ctx context.Context, dest any, query string, args ...any) error
 	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
 	ExecQueryRow(ctx context.Context, query string, args ...any) pgx.Row
 }
 
+// MaxReviewers - сколько ревьюверов назначается на PR при создании
+const MaxReviewers = 2
+
+// reassignAttempts - сколько раз повторяется переназначение, упавшее на гонке
+const reassignAttempts = 5
+
 type PullRequestRepo struct {
 	db DB
 }
@@ -29,8 +40,8 @@ func NewRepo(db DB) *PullRequestRepo {
 	}
 }
 
-func (request *PullRequestRepo) create(ctx context.Context, pr *PullRequestEntity) (*PullRequestEntity, error) {
-	tx, err := request.db.GetPool(ctx).Begin(ctx)
+func (request *PullRequestRepo) create(ctx context.Context, pr *PullRequestEntity, assign Assignment) (*PullRequestEntity, error) {
+	tx, err := request.db.Begin(ctx)
 	if err != nil {
 		log.Printf("[PullRequestRepo.create] failed to begin transaction: %v", err)
 		return nil, apperrors.ErrDB
@@ -47,8 +58,8 @@ func (request *PullRequestRepo) create(ctx context.Context, pr *PullRequestEntit
 	err = tx.QueryRow(ctx, `
 		SELECT team_id
 		FROM users
-		WHERE user_id = $1
-	`, pr.AuthorID).Scan(&teamID)
+		WHERE tenant_id = $1 AND user_id = $2
+	`, tenant.FromContext(ctx), pr.AuthorID).Scan(&teamID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			log.Printf("[PullRequestRepo.create] author's team not found for user '%s'", pr.AuthorID)
@@ -58,11 +69,16 @@ func (request *PullRequestRepo) create(ctx context.Context, pr *PullRequestEntit
 		return nil, err
 	}
 
+	// черновик создаётся без ревьюверов, остальные PR - открытыми
+	if pr.Status != StatusDraft {
+		pr.Status = StatusOpen
+	}
+
 	_, err = tx.Exec(ctx, `
 		INSERT INTO pull_request (
-			pull_request_id, pull_request_name, author_id, status
-		) VALUES ($1, $2, $3, 'OPEN')
-	`, pr.PullRequestID, pr.PullRequestName, pr.AuthorID)
+			tenant_id, pull_request_id, pull_request_name, author_id, status
+		) VALUES ($1, $2, $3, $4, $5)
+	`, tenant.FromContext(ctx), pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -73,50 +89,190 @@ func (request *PullRequestRepo) create(ctx context.Context, pr *PullRequestEntit
 		return nil, apperrors.ErrDB
 	}
 
-	rows, err := tx.Query(ctx, `
-		SELECT user_id
+	var reviewers []string
+	if pr.Status == StatusOpen {
+		reviewers, err = request.assignReviewersTx(ctx, tx, pr.PullRequestID, pr.AuthorID, teamID, assign)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pr.AssignedReviewers = reviewers
+	pr.ReviewerTeams, err = request.getReviewerTeamsTx(ctx, tx, pr.PullRequestID)
+	if err != nil {
+		return nil, err
+	}
+	pr.Version = 1
+
+	err = outbox.Write(ctx, tx, events.PRCreated, pr.PullRequestID, prEventData(pr))
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[PullRequestRepo.create] PR '%s' created successfully with reviewers: %v", pr.PullRequestID, reviewers)
+	return pr, nil
+}
+
+// assignReviewersTx назначает до MaxReviewers активных и доступных участников команды автора, не достигших лимита ревью;
+// недостающих добирает из запасных команд
+func (request *PullRequestRepo) assignReviewersTx(ctx context.Context, tx pgx.Tx, prID string, authorID string, teamID uint64, assign Assignment) ([]string, error) {
+	now := assign.now()
+	candidates, err := selectCandidatesTx(ctx, tx, `
+		SELECT users.user_id, users.timezone, users.work_start, users.work_end, users.team_id, src.priority
 		FROM users
-		WHERE team_id = $1
-		  AND user_id <> $2
-		  AND is_active = true
-		LIMIT 2
-	`, teamID, pr.AuthorID)
+		`+sourceTeamsSQL("$1::BIGINT")+`
+		WHERE users.user_id <> $2
+		  AND users.is_active = true
+		  AND `+availableSQL("$3")+`
+		  AND `+underCapacitySQL+`
+		ORDER BY src.priority, users.user_id
+	`, teamID, authorID, now)
 	if err != nil {
-		log.Printf("[PullRequestRepo.create] db error fetching reviewers for PR '%s': %v", pr.PullRequestID, err)
+		log.Printf("[PullRequestRepo.assignReviewersTx] db error fetching reviewers for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+	reviewers, err := pickLockedTx(ctx, tx, assign, candidates, now, MaxReviewers)
+	if err != nil {
+		log.Printf("[PullRequestRepo.assignReviewersTx] db error locking reviewers for PR '%s': %v", prID, err)
 		return nil, apperrors.ErrDB
 	}
-	defer rows.Close()
 
-	var reviewers []string
-	for rows.Next() {
-		var uid string
-		if err = rows.Scan(&uid); err != nil {
-			log.Printf("[PullRequestRepo.create] failed to scan reviewer for PR '%s': %v", pr.PullRequestID, err)
+	for _, c := range reviewers {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO pull_request_reviewer (
+				tenant_id, pull_request_id, user_id, assigned_at, source_team_id
+			) VALUES ($1, $2, $3, $4, $5)
+		`, tenant.FromContext(ctx), prID, c.UserID, now, c.TeamID)
+		if err != nil {
+			log.Printf("[PullRequestRepo.assignReviewersTx] failed to insert reviewer '%s' for PR '%s': %v", c.UserID, prID, err)
 			return nil, apperrors.ErrDB
 		}
-		reviewers = append(reviewers, uid)
 	}
+	return userIDs(reviewers), nil
+}
 
-	for _, reviewerID := range reviewers {
+// transition выполняет переход a: в OPEN - с назначением ревьюверов,
+// в CLOSED - со снятием ревьюверов и их вердиктов
+func (request *PullRequestRepo) transition(ctx context.Context, prID string, a action, ifMatch *uint64, assign Assignment) (*PullRequestEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	tx, err := request.db.Begin(ctx)
+	if err != nil {
+		log.Printf("[PullRequestRepo.transition] failed to begin transaction: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			_ = tx.Commit(ctx)
+		}
+	}()
+
+	var (
+		status   string
+		authorID string
+		teamID   uint64
+		version  uint64
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT pr.status, pr.author_id, u.team_id, pr.version
+		FROM pull_request pr
+		JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+		WHERE pr.tenant_id = $1 AND pr.pull_request_id = $2
+		FOR UPDATE OF pr
+	`, tenantID, prID).Scan(&status, &authorID, &teamID, &version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[PullRequestRepo.transition] PR not found: '%s'", prID)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[PullRequestRepo.transition] db error fetching PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	if ifMatch != nil && *ifMatch != version {
+		log.Printf("[PullRequestRepo.transition] PR '%s' is at version %d, client expected %d", prID, version, *ifMatch)
+		err = apperrors.ErrPreconditionFailed
+		return nil, err
+	}
+	if err = a.check(prID, status); err != nil {
+		log.Printf("[PullRequestRepo.transition] %v", err)
+		return nil, err
+	}
+
+	var released []string
+	switch a.to {
+	case StatusOpen:
+		_, err = request.assignReviewersTx(ctx, tx, prID, authorID, teamID, assign)
+	case StatusClosed:
+		released, err = request.getReviewersTx(ctx, tx, prID)
+		if err != nil {
+			return nil, err
+		}
 		_, err = tx.Exec(ctx, `
-			INSERT INTO pull_request_reviewer (
-				pull_request_id, user_id
-			) VALUES ($1, $2)
-		`, pr.PullRequestID, reviewerID)
+			DELETE FROM pull_request_reviewer WHERE tenant_id = $1 AND pull_request_id = $2
+		`, tenantID, prID)
 		if err != nil {
-			log.Printf("[PullRequestRepo.create] failed to insert reviewer '%s' for PR '%s': %v", reviewerID, pr.PullRequestID, err)
-			return nil, apperrors.ErrDB
+			log.Printf("[PullRequestRepo.transition] failed to release reviewers of PR '%s': %v", prID, err)
+			err = apperrors.ErrDB
 		}
 	}
+	if err != nil {
+		return nil, err
+	}
 
-	pr.Status = "OPEN"
-	pr.AssignedReviewers = reviewers
-	log.Printf("[PullRequestRepo.create] PR '%s' created successfully with reviewers: %v", pr.PullRequestID, reviewers)
+	_, err = tx.Exec(ctx, `
+		UPDATE pull_request SET status = $3, version = version + 1
+		WHERE tenant_id = $1 AND pull_request_id = $2
+	`, tenantID, prID, a.to)
+	if err != nil {
+		log.Printf("[PullRequestRepo.transition] failed to update status of PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	pr, err := request.getByIDTx(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = outbox.Write(ctx, tx, events.PRStatusChanged, prID, events.StatusChangedData{
+		PullRequestData:   prEventData(pr),
+		PreviousStatus:    status,
+		ReleasedReviewers: released,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[PullRequestRepo.transition] PR '%s' moved from %s to %s", prID, status, a.to)
 	return pr, nil
 }
 
-func (request *PullRequestRepo) reassignReviewer(ctx context.Context, prID string, oldUserID string) (*PullRequestEntity, string, error) {
-	tx, err := request.db.GetPool(ctx).Begin(ctx)
+// reassignReviewer повторяет переназначение, если транзакция проиграла гонку
+func (request *PullRequestRepo) reassignReviewer(ctx context.Context, prID string, oldUserID string, ifMatch *uint64, assign Assignment) (*PullRequestEntity, string, error) {
+	var (
+		pr        *PullRequestEntity
+		newUserID string
+	)
+	err := db.Retry(ctx, reassignAttempts, func() error {
+		var err error
+		pr, newUserID, err = request.reassignReviewerOnce(ctx, prID, oldUserID, ifMatch, assign)
+		return err
+	})
+	if err != nil {
+		if db.IsRetryable(err) {
+			log.Printf("[PullRequestRepo.reassignReviewer] giving up on PR '%s' after %d attempts: %v", prID, reassignAttempts, err)
+			return nil, "", apperrors.ErrDB
+		}
+		return nil, "", err
+	}
+	return pr, newUserID, nil
+}
+
+// reassignReviewerOnce блокирует строку PR, поэтому параллельные reassign и merge
+// по одному PR выполняются по очереди и видят состав ревьюверов друг друга
+func (request *PullRequestRepo) reassignReviewerOnce(ctx context.Context, prID string, oldUserID string, ifMatch *uint64, assign Assignment) (*PullRequestEntity, string, error) {
+	tenantID := tenant.FromContext(ctx)
+	tx, err := request.db.Begin(ctx)
 	if err != nil {
 		log.Printf("[PullRequestRepo.reassignReviewer] failed to begin transaction: %v", err)
 		return nil, "", apperrors.ErrDB
@@ -133,24 +289,35 @@ func (request *PullRequestRepo) reassignReviewer(ctx context.Context, prID strin
 		status   string
 		authorID string
 		teamID   int64
+		version  uint64
 	)
 
 	err = tx.QueryRow(ctx, `
-        SELECT pr.status, pr.author_id, u.team_id
+        SELECT pr.status, pr.author_id, u.team_id, pr.version
         FROM pull_request pr
-        JOIN users u ON u.user_id = pr.author_id
-        WHERE pr.pull_request_id = $1
-    `, prID).Scan(&status, &authorID, &teamID)
+        JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+        WHERE pr.tenant_id = $1 AND pr.pull_request_id = $2
+        FOR UPDATE OF pr
+    `, tenantID, prID).Scan(&status, &authorID, &teamID, &version)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			log.Printf("[PullRequestRepo.reassignReviewer] PR not found: '%s'", prID)
 			return nil, "", apperrors.ErrNotFound
 		}
+		if db.IsRetryable(err) {
+			return nil, "", err
+		}
 		log.Printf("[PullRequestRepo.reassignReviewer] db error fetching PR '%s': %v", prID, err)
 		return nil, "", apperrors.ErrDB
 	}
 
-	if status == "MERGED" {
+	if ifMatch != nil && *ifMatch != version {
+		log.Printf("[PullRequestRepo.reassignReviewer] PR '%s' is at version %d, client expected %d", prID, version, *ifMatch)
+		err = apperrors.ErrPreconditionFailed
+		return nil, "", err
+	}
+
+	if status == StatusMerged {
 		log.Printf("[PullRequestRepo.reassignReviewer] cannot reassign reviewers for merged PR '%s'", prID)
 		return nil, "", apperrors.ErrPRMerged
 	}
@@ -159,9 +326,9 @@ func (request *PullRequestRepo) reassignReviewer(ctx context.Context, prID strin
 	err = tx.QueryRow(ctx, `
         SELECT EXISTS(
             SELECT 1 FROM pull_request_reviewer
-            WHERE pull_request_id = $1 AND user_id = $2
+            WHERE tenant_id = $1 AND pull_request_id = $2 AND user_id = $3
         )
-    `, prID, oldUserID).Scan(&assigned)
+    `, tenantID, prID, oldUserID).Scan(&assigned)
 	if err != nil {
 		log.Printf("[PullRequestRepo.reassignReviewer] db error checking assignment of user '%s' for PR '%s': %v", oldUserID, prID, err)
 		return nil, "", apperrors.ErrDB
@@ -172,84 +339,296 @@ func (request *PullRequestRepo) reassignReviewer(ctx context.Context, prID strin
 		return nil, "", apperrors.ErrNotAssigned
 	}
 
-	var newUserID string
-	err = tx.QueryRow(ctx, `
-        SELECT user_id
+	now := assign.now()
+	candidates, err := selectCandidatesTx(ctx, tx, `
+        SELECT users.user_id, users.timezone, users.work_start, users.work_end, users.team_id, src.priority
         FROM users
-        WHERE team_id = $1
-          AND is_active = true
-          AND user_id <> $2
-          AND user_id <> $3
+        `+sourceTeamsSQL("$1::BIGINT")+`
+        WHERE users.is_active = true
+          AND users.user_id <> $2
+          AND users.user_id <> $3
           AND NOT EXISTS (
               SELECT 1 FROM pull_request_reviewer
-              WHERE pull_request_id = $4 AND user_id = users.user_id
+              WHERE tenant_id = users.tenant_id AND pull_request_id = $4 AND user_id = users.user_id
           )
-        LIMIT 1
-    `, teamID, authorID, oldUserID, prID).Scan(&newUserID)
+          AND `+availableSQL("$5")+`
+          AND `+underCapacitySQL+`
+        ORDER BY src.priority, users.user_id
+    `, teamID, authorID, oldUserID, prID, now)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			log.Printf("[PullRequestRepo.reassignReviewer] no candidate available to replace user '%s' in PR '%s'", oldUserID, prID)
-			return nil, "", apperrors.ErrNoCandidate
+		if db.IsRetryable(err) {
+			return nil, "", err
 		}
 		log.Printf("[PullRequestRepo.reassignReviewer] db error finding replacement for PR '%s': %v", prID, err)
 		return nil, "", apperrors.ErrDB
 	}
+	picked, err := pickLockedTx(ctx, tx, assign, candidates, now, 1)
+	if err != nil {
+		if db.IsRetryable(err) {
+			return nil, "", err
+		}
+		log.Printf("[PullRequestRepo.reassignReviewer] db error locking replacement for PR '%s': %v", prID, err)
+		return nil, "", apperrors.ErrDB
+	}
+	if len(picked) == 0 {
+		// без учёта лимита: отличаем «все заняты» от «нет активных»
+		var atCapacity bool
+		err = tx.QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT 1
+				FROM users
+				`+sourceTeamsSQL("$1::BIGINT")+`
+				WHERE users.is_active = true
+				  AND users.user_id <> $2
+				  AND users.user_id <> $3
+				  AND NOT EXISTS (
+				      SELECT 1 FROM pull_request_reviewer
+				      WHERE tenant_id = users.tenant_id AND pull_request_id = $4 AND user_id = users.user_id
+				  )
+				  AND `+availableSQL("$5")+`
+			)
+		`, teamID, authorID, oldUserID, prID, now).Scan(&atCapacity)
+		if err != nil {
+			log.Printf("[PullRequestRepo.reassignReviewer] db error checking capacity of candidates for PR '%s': %v", prID, err)
+			return nil, "", apperrors.ErrDB
+		}
+		log.Printf("[PullRequestRepo.reassignReviewer] no candidate available to replace user '%s' in PR '%s' (at capacity: %v)", oldUserID, prID, atCapacity)
+		err = noCandidate(oldUserID, atCapacity)
+		return nil, "", err
+	}
+	newUserID := picked[0].UserID
 
+	// вердикт снятого ревьювера удаляется каскадом (pull_request_review ссылается на pull_request_reviewer)
 	_, err = tx.Exec(ctx, `
         DELETE FROM pull_request_reviewer
-        WHERE pull_request_id = $1 AND user_id = $2
-    `, prID, oldUserID)
+        WHERE tenant_id = $1 AND pull_request_id = $2 AND user_id = $3
+    `, tenantID, prID, oldUserID)
 	if err != nil {
+		if db.IsRetryable(err) {
+			return nil, "", err
+		}
 		log.Printf("[PullRequestRepo.reassignReviewer] failed to remove old reviewer '%s' from PR '%s': %v", oldUserID, prID, err)
 		return nil, "", apperrors.ErrDB
 	}
 
 	_, err = tx.Exec(ctx, `
-        INSERT INTO pull_request_reviewer(pull_request_id, user_id)
-        VALUES ($1, $2)
-    `, prID, newUserID)
+        INSERT INTO pull_request_reviewer(tenant_id, pull_request_id, user_id, assigned_at, source_team_id)
+        VALUES ($1, $2, $3, $4, $5)
+    `, tenantID, prID, newUserID, now, picked[0].TeamID)
 	if err != nil {
+		if db.IsRetryable(err) {
+			return nil, "", err
+		}
 		log.Printf("[PullRequestRepo.reassignReviewer] failed to insert new reviewer '%s' for PR '%s': %v", newUserID, prID, err)
 		return nil, "", apperrors.ErrDB
 	}
 
+	// версия поднимается условно: если PR изменили параллельно, запись не пройдёт
+	tag, err := tx.Exec(ctx, `
+        UPDATE pull_request SET version = version + 1
+        WHERE tenant_id = $1 AND pull_request_id = $2 AND version = $3
+    `, tenantID, prID, version)
+	if err != nil {
+		if db.IsRetryable(err) {
+			return nil, "", err
+		}
+		log.Printf("[PullRequestRepo.reassignReviewer] failed to bump version of PR '%s': %v", prID, err)
+		return nil, "", apperrors.ErrDB
+	}
+	if tag.RowsAffected() == 0 {
+		log.Printf("[PullRequestRepo.reassignReviewer] PR '%s' was modified concurrently", prID)
+		err = apperrors.ErrPreconditionFailed
+		return nil, "", err
+	}
+
 	pr, err := request.getByIDTx(ctx, tx, prID)
 	if err != nil {
 		log.Printf("[PullRequestRepo.reassignReviewer] failed to reload PR '%s' after reassignment: %v", prID, err)
 		return nil, "", apperrors.ErrDB
 	}
 
+	err = outbox.Write(ctx, tx, events.PRReviewerReassigned, prID, events.ReviewerReassignedData{
+		PullRequestData: prEventData(pr),
+		OldUserID:       oldUserID,
+		ReplacedBy:      newUserID,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
 	log.Printf("[PullRequestRepo.reassignReviewer] user '%s' replaced by '%s' in PR '%s'", oldUserID, newUserID, prID)
 	return pr, newUserID, nil
 }
 
+func (request *PullRequestRepo) getByID(ctx context.Context, prID string) (*PullRequestEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var pr PullRequestEntity
+	err := request.db.Get(ctx, &pr, `
+        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version
+        FROM pull_request
+        WHERE tenant_id = $1 AND pull_request_id = $2
+    `, tenantID, prID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[PullRequestRepo.getByID] PR '%s' not found", prID)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[PullRequestRepo.getByID] db error fetching PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	err = request.db.Select(ctx, &pr.AssignedReviewers, `
+        SELECT user_id
+        FROM pull_request_reviewer
+        WHERE tenant_id = $1 AND pull_request_id = $2
+    `, tenantID, prID)
+	if err != nil {
+		log.Printf("[PullRequestRepo.getByID] db error fetching reviewers for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	err = request.db.Select(ctx, &pr.ReviewerTeams, `
+        SELECT r.user_id, t.team_name
+        FROM pull_request_reviewer r
+        JOIN team t ON t.id = r.source_team_id
+        WHERE r.tenant_id = $1 AND r.pull_request_id = $2
+        ORDER BY r.user_id
+    `, tenantID, prID)
+	if err != nil {
+		log.Printf("[PullRequestRepo.getByID] db error fetching reviewer teams for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	err = request.db.Select(ctx, &pr.Reviews, `
+        SELECT user_id, decision, comment, updated_at
+        FROM pull_request_review
+        WHERE tenant_id = $1 AND pull_request_id = $2
+        ORDER BY user_id
+    `, tenantID, prID)
+	if err != nil {
+		log.Printf("[PullRequestRepo.getByID] db error fetching reviews for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+	return &pr, nil
+}
+
 func (request *PullRequestRepo) getByIDTx(ctx context.Context, tx pgx.Tx, prID string) (*PullRequestEntity, error) {
 	var pr PullRequestEntity
 
 	err := tx.QueryRow(ctx, `
-        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+        SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version
         FROM pull_request
-        WHERE pull_request_id = $1
-    `, prID).Scan(
+        WHERE tenant_id = $1 AND pull_request_id = $2
+    `, tenant.FromContext(ctx), prID).Scan(
 		&pr.PullRequestID,
 		&pr.PullRequestName,
 		&pr.AuthorID,
 		&pr.Status,
 		&pr.CreatedAt,
 		&pr.MergedAt,
+		&pr.Version,
 	)
 	if err != nil {
 		log.Printf("[PullRequestRepo.getByIDTx] failed to fetch PR '%s': %v", prID, err)
 		return nil, apperrors.ErrDB
 	}
 
+	reviewers, err := request.getReviewersTx(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+	pr.AssignedReviewers = reviewers
+
+	pr.ReviewerTeams, err = request.getReviewerTeamsTx(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	pr.Reviews, err = request.getReviewsTx(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[PullRequestRepo.getByIDTx] fetched PR '%s' with reviewers: %v", prID, reviewers)
+	return &pr, nil
+}
+
+func selectCandidatesTx(ctx context.Context, tx pgx.Tx, query string, args ...any) ([]candidate, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.UserID, &c.Timezone, &c.WorkStart, &c.WorkEnd, &c.TeamID, &c.Priority); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// pickLockedTx выбирает до limit кандидатов и блокирует их строки users FOR UPDATE (по порядку user_id,
+// чтобы параллельные назначения не блокировали друг друга взаимно), затем перепроверяет активность и лимит
+// уже под блокировкой: выборка кандидатов не блокирует строки, и два запроса могли выбрать последнего
+// свободного ревьювера одновременно. Проигравшие перепроверку исключаются, и выбор повторяется.
+func pickLockedTx(ctx context.Context, tx pgx.Tx, assign Assignment, candidates []candidate, now time.Time, limit int) ([]candidate, error) {
+	tenantID := tenant.FromContext(ctx)
+	for {
+		picked := assign.pick(candidates, now, limit)
+		if len(picked) == 0 {
+			return nil, nil
+		}
+		ids := userIDs(picked)
+		slices.Sort(ids)
+		if _, err := tx.Exec(ctx, `
+			SELECT 1 FROM users WHERE tenant_id = $1 AND user_id = ANY($2) ORDER BY user_id FOR UPDATE
+		`, tenantID, ids); err != nil {
+			return nil, err
+		}
+
+		// отдельный запрос после блокировки видит ревью, закоммиченные её прежними владельцами
+		rows, err := tx.Query(ctx, `
+			SELECT users.user_id FROM users
+			WHERE users.tenant_id = $1 AND users.user_id = ANY($2) AND users.is_active = true
+			  AND `+underCapacitySQL+`
+		`, tenantID, ids)
+		if err != nil {
+			return nil, err
+		}
+		var still []string
+		for rows.Next() {
+			var userID string
+			if err := rows.Scan(&userID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			still = append(still, userID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		if len(still) == len(picked) {
+			return picked, nil
+		}
+		candidates = slices.DeleteFunc(candidates, func(c candidate) bool {
+			return slices.Contains(ids, c.UserID) && !slices.Contains(still, c.UserID)
+		})
+	}
+}
+
+func (request *PullRequestRepo) getReviewersTx(ctx context.Context, tx pgx.Tx, prID string) ([]string, error) {
 	rows, err := tx.Query(ctx, `
         SELECT user_id
         FROM pull_request_reviewer
-        WHERE pull_request_id = $1
-    `, prID)
+        WHERE tenant_id = $1 AND pull_request_id = $2
+    `, tenant.FromContext(ctx), prID)
 	if err != nil {
-		log.Printf("[PullRequestRepo.getByIDTx] failed to fetch reviewers for PR '%s': %v", prID, err)
+		log.Printf("[PullRequestRepo.getReviewersTx] failed to fetch reviewers for PR '%s': %v", prID, err)
 		return nil, apperrors.ErrDB
 	}
 	defer rows.Close()
@@ -258,41 +637,170 @@ func (request *PullRequestRepo) getByIDTx(ctx context.Context, tx pgx.Tx, prID s
 	for rows.Next() {
 		var uid string
 		if err := rows.Scan(&uid); err != nil {
-			log.Printf("[PullRequestRepo.getByIDTx] failed to scan reviewer for PR '%s': %v", prID, err)
+			log.Printf("[PullRequestRepo.getReviewersTx] failed to scan reviewer for PR '%s': %v", prID, err)
 			return nil, apperrors.ErrDB
 		}
 		reviewers = append(reviewers, uid)
 	}
-	pr.AssignedReviewers = reviewers
+	return reviewers, nil
+}
 
-	log.Printf("[PullRequestRepo.getByIDTx] fetched PR '%s' with reviewers: %v", prID, reviewers)
-	return &pr, nil
+func (request *PullRequestRepo) getReviewerTeamsTx(ctx context.Context, tx pgx.Tx, prID string) ([]ReviewerTeamEntity, error) {
+	rows, err := tx.Query(ctx, `
+        SELECT r.user_id, t.team_name
+        FROM pull_request_reviewer r
+        JOIN team t ON t.id = r.source_team_id
+        WHERE r.tenant_id = $1 AND r.pull_request_id = $2
+        ORDER BY r.user_id
+    `, tenant.FromContext(ctx), prID)
+	if err != nil {
+		log.Printf("[PullRequestRepo.getReviewerTeamsTx] failed to fetch reviewer teams for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+	defer rows.Close()
+
+	var teams []ReviewerTeamEntity
+	for rows.Next() {
+		var t ReviewerTeamEntity
+		if err := rows.Scan(&t.UserID, &t.TeamName); err != nil {
+			log.Printf("[PullRequestRepo.getReviewerTeamsTx] failed to scan reviewer team for PR '%s': %v", prID, err)
+			return nil, apperrors.ErrDB
+		}
+		teams = append(teams, t)
+	}
+	return teams, nil
+}
+
+func (request *PullRequestRepo) getReviewsTx(ctx context.Context, tx pgx.Tx, prID string) ([]ReviewEntity, error) {
+	rows, err := tx.Query(ctx, `
+        SELECT user_id, decision, comment, updated_at
+        FROM pull_request_review
+        WHERE tenant_id = $1 AND pull_request_id = $2
+        ORDER BY user_id
+    `, tenant.FromContext(ctx), prID)
+	if err != nil {
+		log.Printf("[PullRequestRepo.getReviewsTx] failed to fetch reviews for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+	defer rows.Close()
+
+	var reviews []ReviewEntity
+	for rows.Next() {
+		var r ReviewEntity
+		if err := rows.Scan(&r.UserID, &r.Decision, &r.Comment, &r.UpdatedAt); err != nil {
+			log.Printf("[PullRequestRepo.getReviewsTx] failed to scan review for PR '%s': %v", prID, err)
+			return nil, apperrors.ErrDB
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, nil
 }
 
-func (request *PullRequestRepo) merge(ctx context.Context, prID string) (*PullRequestEntity, error) {
+// checkMergePolicy блокирует строку PR и считает вердикты назначенных ревьюверов.
+// Отсутствующий или не открытый PR пропускается - его дальше обработает merge.
+func (request *PullRequestRepo) checkMergePolicy(ctx context.Context, tx pgx.Tx, prID string, policy *MergePolicy) error {
+	tenantID := tenant.FromContext(ctx)
+	var status string
+	err := tx.QueryRow(ctx, `
+		SELECT status FROM pull_request WHERE tenant_id = $1 AND pull_request_id = $2 FOR UPDATE
+	`, tenantID, prID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		log.Printf("[PullRequestRepo.checkMergePolicy] db error locking PR '%s': %v", prID, err)
+		return apperrors.ErrDB
+	}
+	// черновик и закрытый PR отклонит сам merge
+	if status != StatusOpen {
+		return nil
+	}
+
+	var reviewers, approved, changesRequested int
+	err = tx.QueryRow(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE v.decision = 'APPROVED'),
+			COUNT(*) FILTER (WHERE v.decision = 'CHANGES_REQUESTED')
+		FROM pull_request_reviewer r
+		LEFT JOIN pull_request_review v
+			ON v.tenant_id = r.tenant_id AND v.pull_request_id = r.pull_request_id AND v.user_id = r.user_id
+		WHERE r.tenant_id = $1 AND r.pull_request_id = $2
+	`, tenantID, prID).Scan(&reviewers, &approved, &changesRequested)
+	if err != nil {
+		log.Printf("[PullRequestRepo.checkMergePolicy] db error counting reviews for PR '%s': %v", prID, err)
+		return apperrors.ErrDB
+	}
+	if err := policy.check(prID, reviewers, approved, changesRequested); err != nil {
+		log.Printf("[PullRequestRepo.checkMergePolicy] %v", err)
+		return err
+	}
+	return nil
+}
+
+func (request *PullRequestRepo) merge(ctx context.Context, prID string, ifMatch *uint64, policy *MergePolicy) (*PullRequestEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	tx, err := request.db.Begin(ctx)
+	if err != nil {
+		log.Printf("[PullRequestRepo.merge] failed to begin transaction: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			_ = tx.Commit(ctx)
+		}
+	}()
+
+	if policy != nil {
+		if err = request.checkMergePolicy(ctx, tx, prID, policy); err != nil {
+			return nil, err
+		}
+	}
+
 	var entity PullRequestEntity
 
-	err := request.db.ExecQueryRow(ctx, `
+	err = tx.QueryRow(ctx, `
 		UPDATE pull_request
-		SET status = 'MERGED', merged_at = NOW()
-		WHERE pull_request_id = $1 AND status <> 'MERGED'
+		SET status = 'MERGED', merged_at = NOW(), version = version + 1
+		WHERE tenant_id = $3 AND pull_request_id = $1 AND status = 'OPEN'
+		  AND ($2::BIGINT IS NULL OR version = $2)
 		RETURNING 
 			pull_request_id, 
 			pull_request_name, 
 			author_id, 
 			status, 
 			created_at, 
-			merged_at
-	`, prID).Scan(
+			merged_at,
+			version
+	`, prID, ifMatch, tenantID).Scan(
 		&entity.PullRequestID,
 		&entity.PullRequestName,
 		&entity.AuthorID,
 		&entity.Status,
 		&entity.CreatedAt,
 		&entity.MergedAt,
+		&entity.Version,
 	)
 
 	if err == nil {
+		entity.AssignedReviewers, err = request.getReviewersTx(ctx, tx, prID)
+		if err != nil {
+			return nil, err
+		}
+		entity.ReviewerTeams, err = request.getReviewerTeamsTx(ctx, tx, prID)
+		if err != nil {
+			return nil, err
+		}
+		entity.Reviews, err = request.getReviewsTx(ctx, tx, prID)
+		if err != nil {
+			return nil, err
+		}
+		err = outbox.Write(ctx, tx, events.PRMerged, prID, prEventData(&entity))
+		if err != nil {
+			return nil, err
+		}
 		log.Printf("[PullRequestRepo.merge] PR '%s' merged successfully", prID)
 		return &entity, nil
 	}
@@ -302,23 +810,25 @@ func (request *PullRequestRepo) merge(ctx context.Context, prID string) (*PullRe
 		return nil, apperrors.ErrDB
 	}
 
-	err = request.db.ExecQueryRow(ctx, `
+	err = tx.QueryRow(ctx, `
 		SELECT 
 			pull_request_id, 
 			pull_request_name, 
 			author_id, 
 			status, 
 			created_at, 
-			merged_at
+			merged_at,
+			version
 		FROM pull_request
-		WHERE pull_request_id = $1
-	`, prID).Scan(
+		WHERE tenant_id = $1 AND pull_request_id = $2
+	`, tenantID, prID).Scan(
 		&entity.PullRequestID,
 		&entity.PullRequestName,
 		&entity.AuthorID,
 		&entity.Status,
 		&entity.CreatedAt,
 		&entity.MergedAt,
+		&entity.Version,
 	)
 
 	if err != nil {
@@ -331,6 +841,130 @@ func (request *PullRequestRepo) merge(ctx context.Context, prID string) (*PullRe
 		return nil, apperrors.ErrDB
 	}
 
+	// UPDATE не прошёл из-за версии или статуса; для смёрженного повтор идемпотентен только с актуальной версией
+	if ifMatch != nil && *ifMatch != entity.Version {
+		log.Printf("[PullRequestRepo.merge] PR '%s' is at version %d, client expected %d", prID, entity.Version, *ifMatch)
+		err = apperrors.ErrPreconditionFailed
+		return nil, err
+	}
+	if entity.Status != StatusMerged {
+		err = actionMerge.check(prID, entity.Status)
+		log.Printf("[PullRequestRepo.merge] %v", err)
+		return nil, err
+	}
+
+	entity.AssignedReviewers, err = request.getReviewersTx(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+	entity.ReviewerTeams, err = request.getReviewerTeamsTx(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+	entity.Reviews, err = request.getReviewsTx(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Printf("[PullRequestRepo.merge] PR '%s' already merged, returning existing state", prID)
 	return &entity, nil
 }
+
+func (request *PullRequestRepo) review(ctx context.Context, prID string, userID string, decision string, comment string) (*PullRequestEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	tx, err := request.db.Begin(ctx)
+	if err != nil {
+		log.Printf("[PullRequestRepo.review] failed to begin transaction: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			_ = tx.Commit(ctx)
+		}
+	}()
+
+	// блокировка строки упорядочивает вердикт относительно merge и reassign
+	var status string
+	err = tx.QueryRow(ctx, `
+		SELECT status FROM pull_request WHERE tenant_id = $1 AND pull_request_id = $2 FOR UPDATE
+	`, tenantID, prID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[PullRequestRepo.review] PR not found: '%s'", prID)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[PullRequestRepo.review] db error fetching PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+	if status == StatusMerged {
+		log.Printf("[PullRequestRepo.review] cannot review merged PR '%s'", prID)
+		err = apperrors.ErrPRMerged
+		return nil, err
+	}
+
+	var assigned bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM pull_request_reviewer
+			WHERE tenant_id = $1 AND pull_request_id = $2 AND user_id = $3
+		)
+	`, tenantID, prID, userID).Scan(&assigned)
+	if err != nil {
+		log.Printf("[PullRequestRepo.review] db error checking assignment of user '%s' for PR '%s': %v", userID, prID, err)
+		return nil, apperrors.ErrDB
+	}
+	if !assigned {
+		log.Printf("[PullRequestRepo.review] user '%s' not assigned to PR '%s'", userID, prID)
+		err = apperrors.ErrNotAssigned
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO pull_request_review (tenant_id, pull_request_id, user_id, decision, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, pull_request_id, user_id) DO UPDATE
+		SET decision = EXCLUDED.decision, comment = EXCLUDED.comment, updated_at = NOW()
+	`, tenantID, prID, userID, decision, comment)
+	if err != nil {
+		log.Printf("[PullRequestRepo.review] failed to save review of user '%s' for PR '%s': %v", userID, prID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE pull_request SET version = version + 1 WHERE tenant_id = $1 AND pull_request_id = $2
+	`, tenantID, prID)
+	if err != nil {
+		log.Printf("[PullRequestRepo.review] failed to bump version of PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	pr, err := request.getByIDTx(ctx, tx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = outbox.Write(ctx, tx, events.PRReviewed, prID, events.ReviewData{
+		PullRequestData: prEventData(pr),
+		UserID:          userID,
+		Decision:        decision,
+		Comment:         comment,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("[PullRequestRepo.review] user '%s' left %s on PR '%s'", userID, decision, prID)
+	return pr, nil
+}
+
+func prEventData(pr *PullRequestEntity) events.PullRequestData {
+	return events.PullRequestData{
+		PullRequestID:     pr.PullRequestID,
+		PullRequestName:   pr.PullRequestName,
+		AuthorID:          pr.AuthorID,
+		Status:            pr.Status,
+		AssignedReviewers: pr.AssignedReviewers,
+	}
+}
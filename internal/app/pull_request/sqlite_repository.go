@@ -0,0 +1,618 @@
+package pullrequest
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/sqlite"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+// PullRequestSQLiteRepo - реализация Repo поверх встроенного SQLite с той же семантикой,
+// что у PullRequestRepo. События в outbox не пишутся.
+type PullRequestSQLiteRepo struct {
+	db *sqlite.DB
+}
+
+func NewSQLiteRepo(db *sqlite.DB) *PullRequestSQLiteRepo {
+	return &PullRequestSQLiteRepo{
+		db: db,
+	}
+}
+
+func (request *PullRequestSQLiteRepo) create(ctx context.Context, pr *PullRequestEntity, assign Assignment) (*PullRequestEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	err := request.db.Write(ctx, func(q sqlite.Querier) error {
+		var teamID uint64
+		err := q.QueryRowContext(ctx, "SELECT team_id FROM users WHERE tenant_id = ? AND user_id = ?", tenantID, pr.AuthorID).Scan(&teamID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[PullRequestSQLiteRepo.create] author's team not found for user '%s'", pr.AuthorID)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[PullRequestSQLiteRepo.create] db error fetching author's team for user '%s': %v", pr.AuthorID, err)
+			return apperrors.ErrDB
+		}
+
+		// черновик создаётся без ревьюверов, остальные PR - открытыми
+		if pr.Status != StatusDraft {
+			pr.Status = StatusOpen
+		}
+
+		pr.CreatedAt = time.Now().UTC()
+		_, err = q.ExecContext(ctx, `
+			INSERT INTO pull_request (tenant_id, pull_request_id, pull_request_name, author_id, status, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, tenantID, pr.PullRequestID, pr.PullRequestName, pr.AuthorID, pr.Status, pr.CreatedAt)
+		if err != nil {
+			if sqlite.IsUniqueViolation(err) {
+				log.Printf("[PullRequestSQLiteRepo.create] PR already exists: '%s'", pr.PullRequestID)
+				return apperrors.ErrPRExists
+			}
+			log.Printf("[PullRequestSQLiteRepo.create] db error inserting PR '%s': %v", pr.PullRequestID, err)
+			return apperrors.ErrDB
+		}
+
+		if pr.Status == StatusOpen {
+			if _, err = assignReviewers(ctx, q, pr.PullRequestID, pr.AuthorID, teamID, assign); err != nil {
+				return err
+			}
+		}
+
+		stored, err := request.get(ctx, q, pr.PullRequestID)
+		if err != nil {
+			return err
+		}
+		pr = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[PullRequestSQLiteRepo.create] PR '%s' created successfully with reviewers: %v", pr.PullRequestID, pr.AssignedReviewers)
+	return pr, nil
+}
+
+// assignReviewers назначает до MaxReviewers активных и доступных участников команды автора, не достигших лимита ревью;
+// недостающих добирает из запасных команд
+func assignReviewers(ctx context.Context, q sqlite.Querier, prID string, authorID string, teamID uint64, assign Assignment) ([]string, error) {
+	now := assign.now().UTC()
+	candidates, err := selectCandidates(ctx, q, `
+		SELECT users.user_id, users.timezone, users.work_start, users.work_end, users.team_id, src.priority
+		FROM users
+		`+sourceTeamsSQL("?")+`
+		WHERE users.user_id <> ?
+		  AND users.is_active
+		  AND `+availableSQL("?")+`
+		  AND `+underCapacitySQL+`
+		ORDER BY src.priority, users.user_id
+	`, teamID, teamID, teamID, authorID, now, now)
+	if err != nil {
+		log.Printf("[PullRequestSQLiteRepo.assignReviewers] db error fetching reviewers for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+	reviewers := assign.pick(candidates, now, MaxReviewers)
+
+	for _, c := range reviewers {
+		_, err = q.ExecContext(ctx, `
+			INSERT INTO pull_request_reviewer (tenant_id, pull_request_id, user_id, assigned_at, source_team_id) VALUES (?, ?, ?, ?, ?)
+		`, tenant.FromContext(ctx), prID, c.UserID, now, c.TeamID)
+		if err != nil {
+			log.Printf("[PullRequestSQLiteRepo.assignReviewers] failed to insert reviewer '%s' for PR '%s': %v", c.UserID, prID, err)
+			return nil, apperrors.ErrDB
+		}
+	}
+	return userIDs(reviewers), nil
+}
+
+func (request *PullRequestSQLiteRepo) transition(ctx context.Context, prID string, a action, ifMatch *uint64, assign Assignment) (*PullRequestEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var (
+		pr     *PullRequestEntity
+		status string
+	)
+	err := request.db.Write(ctx, func(q sqlite.Querier) error {
+		var (
+			authorID string
+			teamID   uint64
+			version  uint64
+		)
+		err := q.QueryRowContext(ctx, `
+			SELECT pr.status, pr.author_id, u.team_id, pr.version
+			FROM pull_request pr
+			JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+			WHERE pr.tenant_id = ? AND pr.pull_request_id = ?
+		`, tenantID, prID).Scan(&status, &authorID, &teamID, &version)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[PullRequestSQLiteRepo.transition] PR not found: '%s'", prID)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[PullRequestSQLiteRepo.transition] db error fetching PR '%s': %v", prID, err)
+			return apperrors.ErrDB
+		}
+
+		if ifMatch != nil && *ifMatch != version {
+			log.Printf("[PullRequestSQLiteRepo.transition] PR '%s' is at version %d, client expected %d", prID, version, *ifMatch)
+			return apperrors.ErrPreconditionFailed
+		}
+		if err := a.check(prID, status); err != nil {
+			log.Printf("[PullRequestSQLiteRepo.transition] %v", err)
+			return err
+		}
+
+		switch a.to {
+		case StatusOpen:
+			if _, err := assignReviewers(ctx, q, prID, authorID, teamID, assign); err != nil {
+				return err
+			}
+		case StatusClosed:
+			// вердикты снятых ревьюверов удаляются каскадом
+			if _, err := q.ExecContext(ctx, "DELETE FROM pull_request_reviewer WHERE tenant_id = ? AND pull_request_id = ?", tenantID, prID); err != nil {
+				log.Printf("[PullRequestSQLiteRepo.transition] failed to release reviewers of PR '%s': %v", prID, err)
+				return apperrors.ErrDB
+			}
+		}
+
+		_, err = q.ExecContext(ctx, "UPDATE pull_request SET status = ?, version = version + 1 WHERE tenant_id = ? AND pull_request_id = ?", a.to, tenantID, prID)
+		if err != nil {
+			log.Printf("[PullRequestSQLiteRepo.transition] failed to update status of PR '%s': %v", prID, err)
+			return apperrors.ErrDB
+		}
+
+		pr, err = request.get(ctx, q, prID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[PullRequestSQLiteRepo.transition] PR '%s' moved from %s to %s", prID, status, a.to)
+	return pr, nil
+}
+
+func (request *PullRequestSQLiteRepo) reassignReviewer(ctx context.Context, prID string, oldUserID string, ifMatch *uint64, assign Assignment) (*PullRequestEntity, string, error) {
+	var (
+		pr        *PullRequestEntity
+		newUserID string
+	)
+	tenantID := tenant.FromContext(ctx)
+	now := assign.now().UTC()
+	// у SQLite один писатель, поэтому транзакция Write уже исключает гонку двух reassign
+	err := request.db.Write(ctx, func(q sqlite.Querier) error {
+		var (
+			status   string
+			authorID string
+			teamID   int64
+			version  uint64
+		)
+		err := q.QueryRowContext(ctx, `
+			SELECT pr.status, pr.author_id, u.team_id, pr.version
+			FROM pull_request pr
+			JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+			WHERE pr.tenant_id = ? AND pr.pull_request_id = ?
+		`, tenantID, prID).Scan(&status, &authorID, &teamID, &version)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[PullRequestSQLiteRepo.reassignReviewer] PR not found: '%s'", prID)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[PullRequestSQLiteRepo.reassignReviewer] db error fetching PR '%s': %v", prID, err)
+			return apperrors.ErrDB
+		}
+
+		if ifMatch != nil && *ifMatch != version {
+			log.Printf("[PullRequestSQLiteRepo.reassignReviewer] PR '%s' is at version %d, client expected %d", prID, version, *ifMatch)
+			return apperrors.ErrPreconditionFailed
+		}
+		if status == StatusMerged {
+			log.Printf("[PullRequestSQLiteRepo.reassignReviewer] cannot reassign reviewers for merged PR '%s'", prID)
+			return apperrors.ErrPRMerged
+		}
+
+		// вердикт снятого ревьювера удаляется каскадом
+		res, err := q.ExecContext(ctx, "DELETE FROM pull_request_reviewer WHERE tenant_id = ? AND pull_request_id = ? AND user_id = ?", tenantID, prID, oldUserID)
+		if err != nil {
+			log.Printf("[PullRequestSQLiteRepo.reassignReviewer] failed to remove old reviewer '%s' from PR '%s': %v", oldUserID, prID, err)
+			return apperrors.ErrDB
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			log.Printf("[PullRequestSQLiteRepo.reassignReviewer] user '%s' not assigned to PR '%s'", oldUserID, prID)
+			return apperrors.ErrNotAssigned
+		}
+
+		candidates, err := selectCandidates(ctx, q, `
+			SELECT users.user_id, users.timezone, users.work_start, users.work_end, users.team_id, src.priority
+			FROM users
+			`+sourceTeamsSQL("?")+`
+			WHERE users.is_active
+			  AND users.user_id <> ?
+			  AND users.user_id <> ?
+			  AND NOT EXISTS (
+			      SELECT 1 FROM pull_request_reviewer
+			      WHERE tenant_id = users.tenant_id AND pull_request_id = ? AND user_id = users.user_id
+			  )
+			  AND `+availableSQL("?")+`
+			  AND `+underCapacitySQL+`
+			ORDER BY src.priority, users.user_id
+		`, teamID, teamID, teamID, authorID, oldUserID, prID, now, now)
+		if err != nil {
+			log.Printf("[PullRequestSQLiteRepo.reassignReviewer] db error finding replacement for PR '%s': %v", prID, err)
+			return apperrors.ErrDB
+		}
+		picked := assign.pick(candidates, now, 1)
+		if len(picked) == 0 {
+			// без учёта лимита: отличаем «все заняты» от «нет активных»
+			var atCapacity bool
+			err = q.QueryRowContext(ctx, `
+				SELECT EXISTS(
+					SELECT 1
+					FROM users
+					`+sourceTeamsSQL("?")+`
+					WHERE users.is_active
+					  AND users.user_id <> ?
+					  AND users.user_id <> ?
+					  AND NOT EXISTS (
+					      SELECT 1 FROM pull_request_reviewer
+					      WHERE tenant_id = users.tenant_id AND pull_request_id = ? AND user_id = users.user_id
+					  )
+					  AND `+availableSQL("?")+`
+				)
+			`, teamID, teamID, teamID, authorID, oldUserID, prID, now, now).Scan(&atCapacity)
+			if err != nil {
+				log.Printf("[PullRequestSQLiteRepo.reassignReviewer] db error checking capacity of candidates for PR '%s': %v", prID, err)
+				return apperrors.ErrDB
+			}
+			log.Printf("[PullRequestSQLiteRepo.reassignReviewer] no candidate available to replace user '%s' in PR '%s' (at capacity: %v)", oldUserID, prID, atCapacity)
+			return noCandidate(oldUserID, atCapacity)
+		}
+		newUserID = picked[0].UserID
+
+		_, err = q.ExecContext(ctx, `
+			INSERT INTO pull_request_reviewer (tenant_id, pull_request_id, user_id, assigned_at, source_team_id) VALUES (?, ?, ?, ?, ?)
+		`, tenantID, prID, newUserID, now, picked[0].TeamID)
+		if err != nil {
+			log.Printf("[PullRequestSQLiteRepo.reassignReviewer] failed to insert new reviewer '%s' for PR '%s': %v", newUserID, prID, err)
+			return apperrors.ErrDB
+		}
+		_, err = q.ExecContext(ctx, "UPDATE pull_request SET version = version + 1 WHERE tenant_id = ? AND pull_request_id = ?", tenantID, prID)
+		if err != nil {
+			log.Printf("[PullRequestSQLiteRepo.reassignReviewer] failed to bump version of PR '%s': %v", prID, err)
+			return apperrors.ErrDB
+		}
+
+		pr, err = request.get(ctx, q, prID)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	log.Printf("[PullRequestSQLiteRepo.reassignReviewer] user '%s' replaced by '%s' in PR '%s'", oldUserID, newUserID, prID)
+	return pr, newUserID, nil
+}
+
+func (request *PullRequestSQLiteRepo) getByID(ctx context.Context, prID string) (*PullRequestEntity, error) {
+	var pr *PullRequestEntity
+	err := request.db.Read(ctx, func(q sqlite.Querier) error {
+		var err error
+		pr, err = request.get(ctx, q, prID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+func (request *PullRequestSQLiteRepo) merge(ctx context.Context, prID string, ifMatch *uint64, policy *MergePolicy) (*PullRequestEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var pr *PullRequestEntity
+	err := request.db.Write(ctx, func(q sqlite.Querier) error {
+		if policy != nil {
+			if err := request.checkMergePolicy(ctx, q, prID, policy); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now().UTC()
+		var (
+			entity   PullRequestEntity
+			mergedAt sql.NullTime
+		)
+		err := q.QueryRowContext(ctx, `
+			UPDATE pull_request
+			SET status = 'MERGED', merged_at = ?, version = version + 1
+			WHERE tenant_id = ? AND pull_request_id = ? AND status = 'OPEN'
+			  AND (? IS NULL OR version = ?)
+			RETURNING pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version
+		`, now, tenantID, prID, ifMatch, ifMatch).Scan(
+			&entity.PullRequestID,
+			&entity.PullRequestName,
+			&entity.AuthorID,
+			&entity.Status,
+			&entity.CreatedAt,
+			&mergedAt,
+			&entity.Version,
+		)
+		if err == nil {
+			if mergedAt.Valid {
+				entity.MergedAt = &mergedAt.Time
+			}
+			entity.AssignedReviewers, err = selectReviewers(ctx, q, prID)
+			if err != nil {
+				log.Printf("[PullRequestSQLiteRepo.merge] db error fetching reviewers for PR '%s': %v", prID, err)
+				return apperrors.ErrDB
+			}
+			entity.ReviewerTeams, err = selectReviewerTeams(ctx, q, prID)
+			if err != nil {
+				log.Printf("[PullRequestSQLiteRepo.merge] db error fetching reviewer teams for PR '%s': %v", prID, err)
+				return apperrors.ErrDB
+			}
+			entity.Reviews, err = selectReviews(ctx, q, prID)
+			if err != nil {
+				log.Printf("[PullRequestSQLiteRepo.merge] db error fetching reviews for PR '%s': %v", prID, err)
+				return apperrors.ErrDB
+			}
+			pr = &entity
+			log.Printf("[PullRequestSQLiteRepo.merge] PR '%s' merged successfully", prID)
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[PullRequestSQLiteRepo.merge] db error updating PR '%s': %v", prID, err)
+			return apperrors.ErrDB
+		}
+
+		// UPDATE ничего не вернул: PR нет, он не открыт или версия не совпала
+		pr, err = request.get(ctx, q, prID)
+		if err != nil {
+			return err
+		}
+		if ifMatch != nil && *ifMatch != pr.Version {
+			log.Printf("[PullRequestSQLiteRepo.merge] PR '%s' is at version %d, client expected %d", prID, pr.Version, *ifMatch)
+			return apperrors.ErrPreconditionFailed
+		}
+		if pr.Status != StatusMerged {
+			err = actionMerge.check(prID, pr.Status)
+			log.Printf("[PullRequestSQLiteRepo.merge] %v", err)
+			return err
+		}
+		log.Printf("[PullRequestSQLiteRepo.merge] PR '%s' already merged, returning existing state", prID)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// get читает PR вместе с ревьюверами в порядке назначения
+func (request *PullRequestSQLiteRepo) get(ctx context.Context, q sqlite.Querier, prID string) (*PullRequestEntity, error) {
+	var (
+		pr       PullRequestEntity
+		mergedAt sql.NullTime
+	)
+	err := q.QueryRowContext(ctx, `
+		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version
+		FROM pull_request
+		WHERE tenant_id = ? AND pull_request_id = ?
+	`, tenant.FromContext(ctx), prID).Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &mergedAt, &pr.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			log.Printf("[PullRequestSQLiteRepo.get] PR '%s' not found", prID)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[PullRequestSQLiteRepo.get] db error fetching PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+	if mergedAt.Valid {
+		pr.MergedAt = &mergedAt.Time
+	}
+
+	pr.AssignedReviewers, err = selectReviewers(ctx, q, prID)
+	if err != nil {
+		log.Printf("[PullRequestSQLiteRepo.get] db error fetching reviewers for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	pr.ReviewerTeams, err = selectReviewerTeams(ctx, q, prID)
+	if err != nil {
+		log.Printf("[PullRequestSQLiteRepo.get] db error fetching reviewer teams for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	pr.Reviews, err = selectReviews(ctx, q, prID)
+	if err != nil {
+		log.Printf("[PullRequestSQLiteRepo.get] db error fetching reviews for PR '%s': %v", prID, err)
+		return nil, apperrors.ErrDB
+	}
+	return &pr, nil
+}
+
+// checkMergePolicy считает вердикты назначенных ревьюверов; отсутствующий или
+// не открытый PR пропускается - его дальше обработает merge
+func (request *PullRequestSQLiteRepo) checkMergePolicy(ctx context.Context, q sqlite.Querier, prID string, policy *MergePolicy) error {
+	tenantID := tenant.FromContext(ctx)
+	var status string
+	err := q.QueryRowContext(ctx, "SELECT status FROM pull_request WHERE tenant_id = ? AND pull_request_id = ?", tenantID, prID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		log.Printf("[PullRequestSQLiteRepo.checkMergePolicy] db error fetching PR '%s': %v", prID, err)
+		return apperrors.ErrDB
+	}
+	// черновик и закрытый PR отклонит сам merge
+	if status != StatusOpen {
+		return nil
+	}
+
+	var reviewers, approved, changesRequested int
+	err = q.QueryRowContext(ctx, `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE v.decision = 'APPROVED'),
+			COUNT(*) FILTER (WHERE v.decision = 'CHANGES_REQUESTED')
+		FROM pull_request_reviewer r
+		LEFT JOIN pull_request_review v
+			ON v.tenant_id = r.tenant_id AND v.pull_request_id = r.pull_request_id AND v.user_id = r.user_id
+		WHERE r.tenant_id = ? AND r.pull_request_id = ?
+	`, tenantID, prID).Scan(&reviewers, &approved, &changesRequested)
+	if err != nil {
+		log.Printf("[PullRequestSQLiteRepo.checkMergePolicy] db error counting reviews for PR '%s': %v", prID, err)
+		return apperrors.ErrDB
+	}
+	if err := policy.check(prID, reviewers, approved, changesRequested); err != nil {
+		log.Printf("[PullRequestSQLiteRepo.checkMergePolicy] %v", err)
+		return err
+	}
+	return nil
+}
+
+func (request *PullRequestSQLiteRepo) review(ctx context.Context, prID string, userID string, decision string, comment string) (*PullRequestEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var pr *PullRequestEntity
+	err := request.db.Write(ctx, func(q sqlite.Querier) error {
+		var status string
+		err := q.QueryRowContext(ctx, "SELECT status FROM pull_request WHERE tenant_id = ? AND pull_request_id = ?", tenantID, prID).Scan(&status)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[PullRequestSQLiteRepo.review] PR not found: '%s'", prID)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[PullRequestSQLiteRepo.review] db error fetching PR '%s': %v", prID, err)
+			return apperrors.ErrDB
+		}
+		if status == StatusMerged {
+			log.Printf("[PullRequestSQLiteRepo.review] cannot review merged PR '%s'", prID)
+			return apperrors.ErrPRMerged
+		}
+
+		var assigned bool
+		err = q.QueryRowContext(ctx, `
+			SELECT EXISTS(SELECT 1 FROM pull_request_reviewer WHERE tenant_id = ? AND pull_request_id = ? AND user_id = ?)
+		`, tenantID, prID, userID).Scan(&assigned)
+		if err != nil {
+			log.Printf("[PullRequestSQLiteRepo.review] db error checking assignment of user '%s' for PR '%s': %v", userID, prID, err)
+			return apperrors.ErrDB
+		}
+		if !assigned {
+			log.Printf("[PullRequestSQLiteRepo.review] user '%s' not assigned to PR '%s'", userID, prID)
+			return apperrors.ErrNotAssigned
+		}
+
+		_, err = q.ExecContext(ctx, `
+			INSERT INTO pull_request_review (tenant_id, pull_request_id, user_id, decision, comment, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (tenant_id, pull_request_id, user_id) DO UPDATE
+			SET decision = excluded.decision, comment = excluded.comment, updated_at = excluded.updated_at
+		`, tenantID, prID, userID, decision, comment, time.Now().UTC())
+		if err != nil {
+			log.Printf("[PullRequestSQLiteRepo.review] failed to save review of user '%s' for PR '%s': %v", userID, prID, err)
+			return apperrors.ErrDB
+		}
+		_, err = q.ExecContext(ctx, "UPDATE pull_request SET version = version + 1 WHERE tenant_id = ? AND pull_request_id = ?", tenantID, prID)
+		if err != nil {
+			log.Printf("[PullRequestSQLiteRepo.review] failed to bump version of PR '%s': %v", prID, err)
+			return apperrors.ErrDB
+		}
+
+		pr, err = request.get(ctx, q, prID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[PullRequestSQLiteRepo.review] user '%s' left %s on PR '%s'", userID, decision, prID)
+	return pr, nil
+}
+
+// selectReviewers - ревьюверы PR в порядке назначения
+func selectReviewers(ctx context.Context, q sqlite.Querier, prID string) ([]string, error) {
+	return selectStrings(ctx, q, "SELECT user_id FROM pull_request_reviewer WHERE tenant_id = ? AND pull_request_id = ? ORDER BY id",
+		tenant.FromContext(ctx), prID)
+}
+
+func selectReviews(ctx context.Context, q sqlite.Querier, prID string) ([]ReviewEntity, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT user_id, decision, comment, updated_at
+		FROM pull_request_review
+		WHERE tenant_id = ? AND pull_request_id = ?
+		ORDER BY user_id
+	`, tenant.FromContext(ctx), prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []ReviewEntity
+	for rows.Next() {
+		var r ReviewEntity
+		if err := rows.Scan(&r.UserID, &r.Decision, &r.Comment, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+func selectReviewerTeams(ctx context.Context, q sqlite.Querier, prID string) ([]ReviewerTeamEntity, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT r.user_id, t.team_name
+		FROM pull_request_reviewer r
+		JOIN team t ON t.id = r.source_team_id
+		WHERE r.tenant_id = ? AND r.pull_request_id = ?
+		ORDER BY r.user_id
+	`, tenant.FromContext(ctx), prID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teams []ReviewerTeamEntity
+	for rows.Next() {
+		var t ReviewerTeamEntity
+		if err := rows.Scan(&t.UserID, &t.TeamName); err != nil {
+			return nil, err
+		}
+		teams = append(teams, t)
+	}
+	return teams, rows.Err()
+}
+
+func selectStrings(ctx context.Context, q sqlite.Querier, query string, args ...any) ([]string, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+func selectCandidates(ctx context.Context, q sqlite.Querier, query string, args ...any) ([]candidate, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []candidate
+	for rows.Next() {
+		var c candidate
+		if err := rows.Scan(&c.UserID, &c.Timezone, &c.WorkStart, &c.WorkEnd, &c.TeamID, &c.Priority); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
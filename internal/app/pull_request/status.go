@@ -0,0 +1,38 @@
+package pullrequest
+
+import (
+	"avito-tech/internal/apperrors"
+	"fmt"
+	"slices"
+)
+
+const (
+	// StatusDraft - ревьюверы не назначаются до markReady
+	StatusDraft = "DRAFT"
+	StatusOpen  = "OPEN"
+	// StatusClosed - PR брошен, ревьюверы освобождены; можно переоткрыть
+	StatusClosed = "CLOSED"
+	StatusMerged = "MERGED"
+)
+
+// action - переход жизненного цикла PR: из каких статусов допустим и куда ведёт
+type action struct {
+	name string
+	from []string
+	to   string
+}
+
+// Допустимые переходы. Повторный merge смёрженного PR переходом не считается и остаётся идемпотентным.
+var (
+	actionMarkReady = action{name: "markReady", from: []string{StatusDraft}, to: StatusOpen}
+	actionClose     = action{name: "close", from: []string{StatusDraft, StatusOpen}, to: StatusClosed}
+	actionReopen    = action{name: "reopen", from: []string{StatusClosed}, to: StatusOpen}
+	actionMerge     = action{name: "merge", from: []string{StatusOpen}, to: StatusMerged}
+)
+
+func (a action) check(prID string, status string) error {
+	if slices.Contains(a.from, status) {
+		return nil
+	}
+	return fmt.Errorf("%w: cannot %s PR '%s' in status %s", apperrors.ErrInvalidTransition, a.name, prID, status)
+}
@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// bucket - классический token bucket: rate токенов в секунду, не больше burst
+type bucket struct {
+	tokens   float64
+	last     time.Time
+	lastSeen time.Time
+}
+
+func (b *bucket) take(now time.Time, rate float64, burst int) (bool, time.Duration) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(burst), b.tokens+elapsed*rate)
+		b.last = now
+	}
+	b.lastSeen = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return false, wait
+}
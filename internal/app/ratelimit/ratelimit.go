@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"avito-tech/internal/app/metrics"
+	"sync"
+	"time"
+)
+
+const (
+	ClassRead  = "read"
+	ClassWrite = "write"
+	// ClassIP - общий бюджет IP до аутентификации: ограничивает и запросы с неверными учётными данными
+	ClassIP = "ip"
+
+	idleBucketTTL = 10 * time.Minute
+)
+
+type Budget struct {
+	// Rate - запросов в секунду, 0 отключает ограничение
+	Rate  float64
+	Burst int
+}
+
+type Config struct {
+	Read  Budget
+	Write Budget
+	IP    Budget
+}
+
+// Limiter ограничивает частоту запросов по ключу (API-ключ, субъект или IP)
+// отдельно для чтения и записи, а также по IP до аутентификации.
+type Limiter struct {
+	cfg Config
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewLimiter(cfg Config) *Limiter {
+	return &Limiter{
+		cfg:     cfg,
+		now:     time.Now,
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Allow списывает токен. Если бюджет исчерпан, возвращает время до появления следующего токена.
+func (l *Limiter) Allow(key string, class string) (bool, time.Duration) {
+	budget := l.cfg.Read
+	switch class {
+	case ClassWrite:
+		budget = l.cfg.Write
+	case ClassIP:
+		budget = l.cfg.IP
+	}
+	if budget.Rate <= 0 {
+		return true, 0
+	}
+	burst := budget.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	now := l.now()
+	l.mu.Lock()
+	l.sweep(now)
+	b, ok := l.buckets[class+"|"+key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), last: now}
+		l.buckets[class+"|"+key] = b
+	}
+	allowed, wait := b.take(now, budget.Rate, burst)
+	l.mu.Unlock()
+
+	result := "allowed"
+	if !allowed {
+		result = "rejected"
+	}
+	metrics.Default.Counter("ratelimit_requests_total", "Requests checked by the rate limiter.", "class", class, "result", result).Inc()
+	return allowed, wait
+}
+
+// sweep удаляет бакеты неактивных клиентов, чтобы карта не росла бесконечно
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+	metrics.Default.Gauge("ratelimit_buckets", "Active rate limiter buckets.").Set(float64(len(l.buckets)))
+}
@@ -0,0 +1,98 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestLimiter(cfg Config) (*Limiter, *time.Time) {
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter(cfg)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+	l, now := newTestLimiter(Config{Write: Budget{Rate: 2, Burst: 3}})
+
+	for i := range 3 {
+		if ok, _ := l.Allow("sub:a", ClassWrite); !ok {
+			t.Fatalf("request %d within burst was rejected", i+1)
+		}
+	}
+	ok, wait := l.Allow("sub:a", ClassWrite)
+	if ok || wait != 500*time.Millisecond {
+		t.Fatalf("expected rejection with 500ms until the next token, got %v, %s", ok, wait)
+	}
+
+	*now = now.Add(250 * time.Millisecond)
+	if ok, wait := l.Allow("sub:a", ClassWrite); ok || wait != 250*time.Millisecond {
+		t.Fatalf("half a token refilled: expected 250ms wait, got %v, %s", ok, wait)
+	}
+	*now = now.Add(250 * time.Millisecond)
+	if ok, _ := l.Allow("sub:a", ClassWrite); !ok {
+		t.Fatal("expected a refilled token to be accepted")
+	}
+
+	// простой не накапливает токенов больше burst
+	*now = now.Add(time.Hour)
+	for range 3 {
+		if ok, _ := l.Allow("sub:a", ClassWrite); !ok {
+			t.Fatal("expected a full burst after idling")
+		}
+	}
+	if ok, _ := l.Allow("sub:a", ClassWrite); ok {
+		t.Fatal("tokens must not exceed burst")
+	}
+}
+
+func TestLimiterSeparatesKeysAndClasses(t *testing.T) {
+	l, _ := newTestLimiter(Config{Read: Budget{Rate: 1, Burst: 1}, Write: Budget{Rate: 1, Burst: 1}, IP: Budget{Rate: 1}})
+
+	for _, c := range []struct{ key, class string }{
+		{"sub:a", ClassRead}, {"sub:a", ClassWrite}, {"sub:b", ClassRead}, {"192.0.2.1", ClassIP},
+	} {
+		if ok, _ := l.Allow(c.key, c.class); !ok {
+			t.Fatalf("%s/%s should have its own bucket", c.key, c.class)
+		}
+	}
+	// burst меньше 1 считается как 1
+	if ok, _ := l.Allow("192.0.2.1", ClassIP); ok {
+		t.Fatal("expected the IP budget with burst 0 to allow one request")
+	}
+	if ok, _ := l.Allow("sub:a", ClassRead); ok {
+		t.Fatal("expected the read budget of sub:a to be exhausted")
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	l, _ := newTestLimiter(Config{Write: Budget{Rate: 1, Burst: 1}})
+	for range 100 {
+		if ok, _ := l.Allow("sub:a", ClassRead); !ok {
+			t.Fatal("rate 0 must disable the limit")
+		}
+	}
+	if len(l.buckets) != 0 {
+		t.Fatalf("disabled classes should not create buckets, got %d", len(l.buckets))
+	}
+}
+
+func TestLimiterSweepsIdleBuckets(t *testing.T) {
+	l, now := newTestLimiter(Config{Read: Budget{Rate: 1, Burst: 1}})
+	l.Allow("sub:idle", ClassRead)
+	*now = now.Add(idleBucketTTL / 2)
+	l.Allow("sub:active", ClassRead)
+	if len(l.buckets) != 2 {
+		t.Fatalf("expected 2 buckets, got %d", len(l.buckets))
+	}
+
+	*now = now.Add(idleBucketTTL/2 + time.Second)
+	l.Allow("sub:active", ClassRead)
+	if _, ok := l.buckets[ClassRead+"|sub:idle"]; ok || len(l.buckets) != 1 {
+		t.Fatalf("expected only the idle bucket to be swept, got %d buckets", len(l.buckets))
+	}
+	// удалённый бакет создаётся заново с полным burst
+	if ok, _ := l.Allow("sub:idle", ClassRead); !ok {
+		t.Fatal("expected a swept client to start with a full bucket")
+	}
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"avito-tech/internal/app/metrics"
+	"context"
+	"time"
+)
+
+// Shedder ограничивает число одновременно выполняемых запросов. Запрос ждёт
+// свободного слота не дольше maxWait, иначе отбрасывается, чтобы очередь
+// не раздувала задержку выше SLI.
+type Shedder struct {
+	slots   chan struct{}
+	maxWait time.Duration
+
+	inflight metrics.Gauge
+	shed     metrics.Counter
+}
+
+func NewShedder(maxInflight int, maxWait time.Duration) *Shedder {
+	return &Shedder{
+		slots:    make(chan struct{}, maxInflight),
+		maxWait:  maxWait,
+		inflight: metrics.Default.Gauge("http_inflight_requests", "Requests currently being served."),
+		shed:     metrics.Default.Counter("http_requests_shed_total", "Requests rejected by the concurrency limiter."),
+	}
+}
+
+// Acquire занимает слот; release нужно вызвать по завершении запроса
+func (s *Shedder) Acquire(ctx context.Context) (release func(), ok bool) {
+	release = func() {
+		<-s.slots
+		s.inflight.Add(-1)
+	}
+	select {
+	case s.slots <- struct{}{}:
+		s.inflight.Add(1)
+		return release, true
+	default:
+	}
+
+	timer := time.NewTimer(s.maxWait)
+	defer timer.Stop()
+	select {
+	case s.slots <- struct{}{}:
+		s.inflight.Add(1)
+		return release, true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	s.shed.Inc()
+	return nil, false
+}
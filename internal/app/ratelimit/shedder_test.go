@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShedderLimitsInflight(t *testing.T) {
+	s := NewShedder(2, 10*time.Millisecond)
+
+	first, ok := s.Acquire(t.Context())
+	if !ok {
+		t.Fatal("expected a free slot")
+	}
+	if _, ok := s.Acquire(t.Context()); !ok {
+		t.Fatal("expected a second free slot")
+	}
+	start := time.Now()
+	if _, ok := s.Acquire(t.Context()); ok {
+		t.Fatal("expected the request to be shed when all slots are busy")
+	}
+	if waited := time.Since(start); waited < 10*time.Millisecond {
+		t.Fatalf("expected to wait maxWait before shedding, waited %s", waited)
+	}
+
+	first()
+	if _, ok := s.Acquire(t.Context()); !ok {
+		t.Fatal("expected a released slot to be reused")
+	}
+}
+
+func TestShedderWaitsForSlot(t *testing.T) {
+	s := NewShedder(1, time.Minute)
+	release, _ := s.Acquire(t.Context())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		release()
+	}()
+	if _, ok := s.Acquire(t.Context()); !ok {
+		t.Fatal("expected the request to get a slot released within maxWait")
+	}
+}
+
+func TestShedderStopsWaitingOnCancel(t *testing.T) {
+	s := NewShedder(1, time.Minute)
+	if _, ok := s.Acquire(t.Context()); !ok {
+		t.Fatal("expected a free slot")
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, ok := s.Acquire(ctx); ok {
+		t.Fatal("expected a cancelled request to give up waiting")
+	}
+}
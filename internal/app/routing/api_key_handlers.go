@@ -0,0 +1,50 @@
+package routing
+
+import (
+	"avito-tech/internal/app/core"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func (s *Server) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.CreateAPIKey(r.Context(), &req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.impl.ListAPIKeys(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.RevokeAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.RevokeAPIKey(r.Context(), req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
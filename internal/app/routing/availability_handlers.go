@@ -0,0 +1,60 @@
+package routing
+
+import (
+	"avito-tech/internal/app/core"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func (s *Server) CreateUnavailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.CreateUnavailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.CreateUnavailability(r.Context(), &req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) ListUnavailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		s.writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "BAD_REQUEST",
+				Message: "user_id parameter is required",
+			},
+		})
+		return
+	}
+
+	resp, err := s.impl.ListUnavailability(r.Context(), userID)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) DeleteUnavailabilityHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.DeleteUnavailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := s.impl.DeleteUnavailability(r.Context(), req); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
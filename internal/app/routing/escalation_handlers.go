@@ -0,0 +1,51 @@
+package routing
+
+import (
+	"avito-tech/internal/app/core"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+func (s *Server) SetTeamEscalationPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.SetTeamEscalationPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.SetTeamEscalationPolicy(r.Context(), req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	setETag(w, resp.Version)
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) ListTeamEscalationsHandler(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		s.writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "BAD_REQUEST",
+				Message: "team_name parameter is required",
+			},
+		})
+		return
+	}
+
+	subtree, ok := s.subtreeParam(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := s.impl.ListTeamEscalations(r.Context(), teamName, subtree)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,52 @@
+package routing
+
+import (
+	"avito-tech/internal/apperrors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// setETag отдаёт версию ресурса как сильный ETag
+func setETag(w http.ResponseWriter, version uint64) {
+	w.Header().Set("ETag", formatETag(version))
+}
+
+func formatETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// parseIfMatch возвращает ожидаемую клиентом версию; nil - заголовка нет или передан "*"
+func parseIfMatch(r *http.Request) (*uint64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+	// у ресурса одна текущая версия, поэтому список тегов не поддерживается
+	value := strings.TrimPrefix(header, "W/")
+	value = strings.Trim(value, `"`)
+	version, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unsupported If-Match value %q", apperrors.ErrPreconditionFailed, header)
+	}
+	return &version, nil
+}
+
+// notModified проверяет If-None-Match и при совпадении отвечает 304
+func notModified(w http.ResponseWriter, r *http.Request, version uint64) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	current := formatETag(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == current {
+			setETag(w, version)
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
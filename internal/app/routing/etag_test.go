@@ -0,0 +1,153 @@
+package routing
+
+import (
+	"avito-tech/internal/app/core"
+	"avito-tech/internal/apperrors"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// versionedImpl отдаёт команду и PR версии version и проверяет If-Match так же, как сервис
+type versionedImpl struct {
+	ImplInterface
+	version uint64
+	calls   int
+}
+
+func (f *versionedImpl) GetTeamByTeamName(_ context.Context, teamName string) (*core.GetTeamResponse, error) {
+	return &core.GetTeamResponse{TeamName: teamName, Version: f.version}, nil
+}
+
+func (f *versionedImpl) GetPullRequest(_ context.Context, prID string) (*core.GetPullReqResponse, error) {
+	return &core.GetPullReqResponse{PR: core.GetPullReqPR{PullRequestID: prID}, Version: f.version}, nil
+}
+
+func (f *versionedImpl) UserSetIsActive(_ context.Context, req core.SetIsActiveRequest) (*core.SetIsActiveResponse, error) {
+	f.calls++
+	if req.IfMatch != nil && *req.IfMatch != f.version {
+		return nil, fmt.Errorf("%w: version %d, got %d", apperrors.ErrPreconditionFailed, f.version, *req.IfMatch)
+	}
+	f.version++
+	return &core.SetIsActiveResponse{Version: f.version}, nil
+}
+
+func TestFormatETag(t *testing.T) {
+	for version, want := range map[uint64]string{0: `"0"`, 7: `"7"`, 18446744073709551615: `"18446744073709551615"`} {
+		if got := formatETag(version); got != want {
+			t.Errorf("formatETag(%d) = %s, want %s", version, got, want)
+		}
+	}
+}
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    *uint64
+		wantErr bool
+	}{
+		{header: ""},
+		{header: "*"},
+		{header: `"3"`, want: ptr(3)},
+		{header: ` W/"3" `, want: ptr(3)},
+		{header: `"3", "4"`, wantErr: true},
+		{header: `"abc"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("If-Match", tt.header)
+		got, err := parseIfMatch(req)
+		if tt.wantErr {
+			if err == nil || !strings.Contains(err.Error(), "If-Match") {
+				t.Errorf("%q: expected a precondition error, got %v", tt.header, err)
+			}
+			continue
+		}
+		if err != nil || (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+			t.Errorf("%q: got %v, %v, want %v", tt.header, got, err, tt.want)
+		}
+	}
+}
+
+func ptr(v uint64) *uint64 {
+	return &v
+}
+
+func TestGetReturnsETagAndNotModified(t *testing.T) {
+	s := NewServer(&versionedImpl{version: 3})
+	for _, target := range []struct {
+		path    string
+		handler http.HandlerFunc
+	}{
+		{"/team/get?team_name=backend", s.GetTeamHandler},
+		{"/pullRequest/get?pull_request_id=pr-1", s.GetPullRequestHandler},
+	} {
+		rec := httptest.NewRecorder()
+		target.handler(rec, httptest.NewRequest(http.MethodGet, target.path, nil))
+		if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"3"` {
+			t.Fatalf("%s: expected 200 with ETag \"3\", got %d %q", target.path, rec.Code, rec.Header().Get("ETag"))
+		}
+
+		for header, want := range map[string]int{
+			`"3"`:        http.StatusNotModified,
+			`W/"3"`:      http.StatusNotModified,
+			`"1", "3"`:   http.StatusNotModified,
+			`*`:          http.StatusNotModified,
+			`"2"`:        http.StatusOK,
+			`"2", W/"4"`: http.StatusOK,
+		} {
+			req := httptest.NewRequest(http.MethodGet, target.path, nil)
+			req.Header.Set("If-None-Match", header)
+			rec := httptest.NewRecorder()
+			target.handler(rec, req)
+			if rec.Code != want {
+				t.Fatalf("%s with If-None-Match %s: got %d, want %d", target.path, header, rec.Code, want)
+			}
+			if rec.Header().Get("ETag") != `"3"` {
+				t.Fatalf("%s with If-None-Match %s: expected ETag \"3\", got %q", target.path, header, rec.Header().Get("ETag"))
+			}
+			if want == http.StatusNotModified && rec.Body.Len() != 0 {
+				t.Fatalf("%s: 304 must have no body, got %q", target.path, rec.Body.String())
+			}
+		}
+	}
+}
+
+func TestIfMatchPreconditionFailed(t *testing.T) {
+	impl := &versionedImpl{version: 3}
+	s := NewServer(impl)
+	setIsActive := func(ifMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users/setIsActive", strings.NewReader(`{"user_id": "u1", "is_active": false}`))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		rec := httptest.NewRecorder()
+		s.SetIsActiveHandler(rec, req)
+		return rec
+	}
+
+	rec := setIsActive(`"2"`)
+	var body ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusPreconditionFailed || body.Error.Code != "PRECONDITION_FAILED" {
+		t.Fatalf("stale If-Match: expected 412 PRECONDITION_FAILED, got %d %s", rec.Code, body.Error.Code)
+	}
+
+	// неразбираемый If-Match отклоняется до вызова сервиса
+	if rec := setIsActive(`"latest"`); rec.Code != http.StatusPreconditionFailed || impl.calls != 1 {
+		t.Fatalf("malformed If-Match: expected 412 without calling the service, got %d after %d calls", rec.Code, impl.calls)
+	}
+
+	if rec := setIsActive(`"3"`); rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"4"` {
+		t.Fatalf("current If-Match: expected 200 with the new ETag \"4\", got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+	if rec := setIsActive(""); rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"5"` {
+		t.Fatalf("no If-Match: expected an unconditional update, got %d %q", rec.Code, rec.Header().Get("ETag"))
+	}
+}
@@ -3,6 +3,7 @@ package routing
 import (
 	"avito-tech/internal/app/core"
 	"avito-tech/internal/apperrors"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -41,6 +42,39 @@ func (s *Server) writeError(w http.ResponseWriter, err error) {
 	case errors.Is(err, apperrors.ErrNoCandidate):
 		statusCode = http.StatusConflict
 		errorCode = "NO_CANDIDATE"
+	case errors.Is(err, apperrors.ErrNotApproved):
+		statusCode = http.StatusConflict
+		errorCode = "NOT_APPROVED"
+	case errors.Is(err, apperrors.ErrInvalidTransition):
+		statusCode = http.StatusConflict
+		errorCode = "INVALID_TRANSITION"
+	case errors.Is(err, apperrors.ErrBadRequest):
+		statusCode = http.StatusBadRequest
+		errorCode = "BAD_REQUEST"
+	case errors.Is(err, apperrors.ErrUnauthorized):
+		statusCode = http.StatusUnauthorized
+		errorCode = "UNAUTHORIZED"
+	case errors.Is(err, apperrors.ErrForbidden):
+		statusCode = http.StatusForbidden
+		errorCode = "FORBIDDEN"
+	case errors.Is(err, apperrors.ErrRateLimited):
+		statusCode = http.StatusTooManyRequests
+		errorCode = "RATE_LIMITED"
+	case errors.Is(err, apperrors.ErrOverloaded):
+		statusCode = http.StatusServiceUnavailable
+		errorCode = "OVERLOADED"
+	case errors.Is(err, apperrors.ErrIdempotencyMismatch):
+		statusCode = http.StatusUnprocessableEntity
+		errorCode = "IDEMPOTENCY_KEY_MISMATCH"
+	case errors.Is(err, apperrors.ErrIdempotencyInProgress):
+		statusCode = http.StatusConflict
+		errorCode = "IDEMPOTENCY_IN_PROGRESS"
+	case errors.Is(err, apperrors.ErrPreconditionFailed):
+		statusCode = http.StatusPreconditionFailed
+		errorCode = "PRECONDITION_FAILED"
+	case errors.Is(err, apperrors.ErrNotSupported):
+		statusCode = http.StatusNotImplemented
+		errorCode = "NOT_SUPPORTED"
 	default:
 		statusCode = http.StatusInternalServerError
 		errorCode = "INTERNAL_ERROR"
@@ -78,6 +112,7 @@ func (s *Server) AddTeamHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setETag(w, resp.Team.Version)
 	s.writeJSON(w, http.StatusCreated, resp)
 }
 
@@ -99,6 +134,10 @@ func (s *Server) GetTeamHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if notModified(w, r, resp.Version) {
+		return
+	}
+	setETag(w, resp.Version)
 	s.writeJSON(w, http.StatusOK, resp)
 }
 
@@ -109,12 +148,87 @@ func (s *Server) SetIsActiveHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	req.IfMatch = ifMatch
+
 	resp, err := s.impl.UserSetIsActive(r.Context(), req)
 	if err != nil {
 		s.writeError(w, err)
 		return
 	}
 
+	setETag(w, resp.Version)
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetTeamMaxOpenReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.SetTeamMaxOpenReviewsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.SetTeamMaxOpenReviews(r.Context(), req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	setETag(w, resp.Version)
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetTeamFallbackTeamsHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.SetTeamFallbackTeamsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.SetTeamFallbackTeams(r.Context(), req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	setETag(w, resp.Version)
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetMaxOpenReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.SetMaxOpenReviewsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.UserSetMaxOpenReviews(r.Context(), req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	setETag(w, resp.Version)
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetWorkingHoursHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.SetWorkingHoursRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.UserSetWorkingHours(r.Context(), req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
 	s.writeJSON(w, http.StatusOK, resp)
 }
 
@@ -131,6 +245,7 @@ func (s *Server) CreatePullRequestHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	setETag(w, resp.Version)
 	s.writeJSON(w, http.StatusCreated, resp)
 }
 
@@ -141,12 +256,20 @@ func (s *Server) MergePullRequestHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	req.IfMatch = ifMatch
+
 	resp, err := s.impl.MergePullRequest(r.Context(), req)
 	if err != nil {
 		s.writeError(w, err)
 		return
 	}
 
+	setETag(w, resp.Version)
 	s.writeJSON(w, http.StatusOK, resp)
 }
 
@@ -157,12 +280,89 @@ func (s *Server) ReassignPullRequestHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	ifMatch, err := parseIfMatch(r)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	req.IfMatch = ifMatch
+
 	resp, err := s.impl.ReassignPullRequest(r.Context(), &req)
 	if err != nil {
 		s.writeError(w, err)
 		return
 	}
 
+	setETag(w, resp.Version)
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) ReviewPullRequestHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.ReviewPullReqRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.ReviewPullRequest(r.Context(), &req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	setETag(w, resp.Version)
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// PullRequestStatusHandler - общий обработчик markReady, close и reopen
+func (s *Server) PullRequestStatusHandler(change func(ctx context.Context, req core.PullReqStatusRequest) (*core.GetPullReqResponse, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req core.PullReqStatusRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+
+		ifMatch, err := parseIfMatch(r)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		req.IfMatch = ifMatch
+
+		resp, err := change(r.Context(), req)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		setETag(w, resp.Version)
+		s.writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) GetPullRequestHandler(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		s.writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "BAD_REQUEST",
+				Message: "pull_request_id parameter is required",
+			},
+		})
+		return
+	}
+
+	resp, err := s.impl.GetPullRequest(r.Context(), prID)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	if notModified(w, r, resp.Version) {
+		return
+	}
+	setETag(w, resp.Version)
 	s.writeJSON(w, http.StatusOK, resp)
 }
 
@@ -186,3 +386,24 @@ func (s *Server) GetReviewHandler(w http.ResponseWriter, r *http.Request) {
 
 	s.writeJSON(w, http.StatusOK, resp)
 }
+
+func (s *Server) GetUserHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		s.writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "BAD_REQUEST",
+				Message: "user_id parameter is required",
+			},
+		})
+		return
+	}
+
+	resp, err := s.impl.GetUser(r.Context(), userID)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
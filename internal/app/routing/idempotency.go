@@ -0,0 +1,123 @@
+package routing
+
+import (
+	"avito-tech/internal/app/auth"
+	"avito-tech/internal/app/idempotency"
+	"avito-tech/internal/app/tenant"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	idempotencyHeader  = "Idempotency-Key"
+	maxIdempotencyBody = 1 << 20
+)
+
+type IdempotencyStore interface {
+	Begin(ctx context.Context, scope string, key string, requestHash string) (*idempotency.Response, error)
+	Complete(ctx context.Context, scope string, key string, resp *idempotency.Response) error
+	Release(ctx context.Context, scope string, key string) error
+}
+
+// replayedHeaders - заголовки ответа, которые сохраняются и отдаются при повторе
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	rw.code = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	if rw.code == 0 {
+		rw.code = http.StatusOK
+	}
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// IdempotencyMiddleware для POST-запросов с заголовком Idempotency-Key сохраняет ответ
+// и отдаёт его повторно на ретрай с тем же телом. Ключи изолированы по субъекту запроса.
+func (s *Server) IdempotencyMiddleware(store IdempotencyStore) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(idempotencyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotencyBody))
+			if err != nil {
+				s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// одинаковые субъекты разных арендаторов не делят ключи
+			scope := tenant.FromContext(r.Context()) + ":" + auth.Actor(r.Context())
+			hash := idempotency.RequestHash(r.Method, r.URL.Path, body)
+
+			stored, err := store.Begin(r.Context(), scope, key, hash)
+			if err != nil {
+				s.writeError(w, err)
+				return
+			}
+			if stored != nil {
+				for name, value := range stored.Headers {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(stored.Code)
+				w.Write(stored.Body)
+				return
+			}
+
+			// ответ пишем в фоне от запроса: клиент мог уже отключиться
+			ctx := context.WithoutCancel(r.Context())
+			rw := &recordingWriter{ResponseWriter: w}
+			completed := false
+			defer func() {
+				// срабатывает и при панике обработчика: иначе ключ висел бы IN_PROGRESS до конца аренды
+				if completed {
+					return
+				}
+				if err := store.Release(ctx, scope, key); err != nil {
+					log.Printf("[Server.IdempotencyMiddleware] failed to release key '%s': %v", key, err)
+				}
+			}()
+			next.ServeHTTP(rw, r)
+
+			if rw.code == 0 || rw.code >= http.StatusInternalServerError {
+				// ошибки сервера не фиксируем, повтор выполнится заново
+				return
+			}
+			completed = true
+			headers := map[string]string{}
+			for _, name := range replayedHeaders {
+				if value := w.Header().Get(name); value != "" {
+					headers[name] = value
+				}
+			}
+			err = store.Complete(ctx, scope, key, &idempotency.Response{
+				Code:    rw.code,
+				Headers: headers,
+				Body:    rw.body.Bytes(),
+			})
+			if err != nil {
+				log.Printf("[Server.IdempotencyMiddleware] failed to store response for key '%s': %v", key, err)
+			}
+		})
+	}
+}
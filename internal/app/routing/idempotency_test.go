@@ -0,0 +1,91 @@
+package routing
+
+import (
+	"avito-tech/internal/app/idempotency"
+	"context"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeStore запоминает, чем закончилась обработка ключа
+type fakeStore struct {
+	released  []string
+	completed map[string]*idempotency.Response
+}
+
+func (f *fakeStore) Begin(context.Context, string, string, string) (*idempotency.Response, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) Complete(_ context.Context, _ string, key string, resp *idempotency.Response) error {
+	f.completed[key] = resp
+	return nil
+}
+
+func (f *fakeStore) Release(_ context.Context, _ string, key string) error {
+	f.released = append(f.released, key)
+	return nil
+}
+
+func serve(store *fakeStore, handler http.HandlerFunc) (code int, panicked any) {
+	h := NewServer(nil).IdempotencyMiddleware(store)(handler)
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", strings.NewReader(`{}`))
+	req.Header.Set(idempotencyHeader, "k1")
+	rec := httptest.NewRecorder()
+	defer func() {
+		panicked = recover()
+		code = rec.Code
+	}()
+	h.ServeHTTP(rec, req)
+	return
+}
+
+func TestIdempotencyReleasesKeyOnPanic(t *testing.T) {
+	store := &fakeStore{completed: map[string]*idempotency.Response{}}
+	_, panicked := serve(store, func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	})
+	if panicked != "boom" {
+		t.Fatalf("panic must propagate, got %v", panicked)
+	}
+	if len(store.released) != 1 || len(store.completed) != 0 {
+		t.Fatalf("expected the key released after panic, released %v, completed %v", store.released, store.completed)
+	}
+}
+
+func TestIdempotencyReleasesKeyOnServerError(t *testing.T) {
+	store := &fakeStore{completed: map[string]*idempotency.Response{}}
+	serve(store, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	if len(store.released) != 1 || len(store.completed) != 0 {
+		t.Fatalf("expected the key released after 5xx, released %v", store.released)
+	}
+}
+
+func TestIdempotencyCompletesKey(t *testing.T) {
+	store := &fakeStore{completed: map[string]*idempotency.Response{}}
+	serve(store, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"ok":true}`))
+	})
+	resp := store.completed["k1"]
+	if len(store.released) != 0 || resp == nil || resp.Code != http.StatusCreated || string(resp.Body) != `{"ok":true}` {
+		t.Fatalf("expected stored 201 response, released %v, stored %+v", store.released, resp)
+	}
+}
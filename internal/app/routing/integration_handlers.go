@@ -0,0 +1,37 @@
+package routing
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const maxForgePayloadSize = 5 << 20
+
+func (s *Server) ForgeWebhookHandler(forge string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxForgePayloadSize))
+		if err != nil {
+			s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+			return
+		}
+
+		resp, err := s.impl.HandleForgeWebhook(r.Context(), forge, r.Header, body)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		s.writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+func (s *Server) ForgeSyncHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.impl.ForgeSyncs(r.Context(), r.URL.Query().Get("pull_request_id"), r.URL.Query().Get("status"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,15 @@
+package routing
+
+import (
+	"net/http"
+)
+
+func (s *Server) ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.impl.ListJobs(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
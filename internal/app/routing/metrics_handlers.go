@@ -0,0 +1,12 @@
+package routing
+
+import (
+	"avito-tech/internal/app/metrics"
+	"net/http"
+)
+
+func (s *Server) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+	metrics.Default.WriteTo(w)
+}
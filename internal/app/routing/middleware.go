@@ -0,0 +1,230 @@
+package routing
+
+import (
+	"avito-tech/internal/app/auth"
+	"avito-tech/internal/app/ratelimit"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*auth.Principal, error)
+}
+
+// publicPrefixes - маршруты без аутентификации со своей проверкой подлинности (подписи forge)
+var publicPrefixes = []string{"/integrations/"}
+
+// metricsPath - метрики без аутентификации и лимитов; сравнивается точно, чтобы префикс не открывал другие пути
+const metricsPath = "/metrics"
+
+// streamingPrefixes - долгоживущие соединения, которые не должны занимать слоты лимитера конкурентности
+var streamingPrefixes = []string{"/events/stream"}
+
+func isPublicPath(path string) bool {
+	return path == metricsPath || hasAnyPrefix(path, publicPrefixes)
+}
+
+func hasAnyPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthMiddleware требует Authorization: Bearer <token> (или X-API-Key) и кладёт Principal
+// в контекст запроса. Ключи со scope read допускаются только к GET-маршрутам.
+func (s *Server) AuthMiddleware(authn Authenticator) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isPublicPath(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+				token, ok = apiKey, true
+			}
+			if !ok || token == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				s.writeError(w, fmt.Errorf("%w: bearer token is required", apperrors.ErrUnauthorized))
+				return
+			}
+
+			principal, err := authn.Authenticate(r.Context(), token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				s.writeError(w, err)
+				return
+			}
+			if principal.ReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+				s.writeError(w, fmt.Errorf("%w: credential is read-only", apperrors.ErrForbidden))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// NoAuthMiddleware - для локального запуска без аутентификации: все запросы выполняются от имени администратора
+func (s *Server) NoAuthMiddleware() mux.MiddlewareFunc {
+	log.Printf("[Server.NoAuthMiddleware] authentication is disabled, every request runs as admin")
+	anonymous := &auth.Principal{Subject: "anonymous", Role: auth.RoleAdmin}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), anonymous)))
+		})
+	}
+}
+
+// TenantMiddleware определяет арендатора запроса (tenant.Header) и кладёт его в контекст. Учётные данные,
+// привязанные к арендатору, задают его сами, и заголовок может только совпадать с ним. Непривязанный
+// администратор выбирает арендатора заголовком, остальные непривязанные учётные данные работают в арендаторе
+// по умолчанию. Публичные маршруты заголовок не читают: без учётных данных его нечем подтвердить, а интеграции
+// берут арендатора из своего конфига. Должен стоять после AuthMiddleware.
+func (s *Server) TenantMiddleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p := auth.FromContext(r.Context())
+			if p == nil {
+				next.ServeHTTP(w, r.WithContext(tenant.WithTenant(r.Context(), tenant.Default)))
+				return
+			}
+			requested := r.Header.Get(tenant.Header)
+			id := requested
+			switch {
+			case p.Tenant != "":
+				id = p.Tenant
+			case !p.IsAdmin() || len(p.Teams) > 0:
+				id = tenant.Default
+			}
+			if requested != "" && requested != id {
+				log.Printf("[Server.TenantMiddleware] '%s' asked for tenant '%s' but is bound to '%s'", p.Subject, requested, id)
+				s.writeError(w, fmt.Errorf("%w: credential is not valid for tenant '%s'", apperrors.ErrForbidden, requested))
+				return
+			}
+			if id == "" {
+				id = tenant.Default
+			}
+			if err := tenant.Validate(id); err != nil {
+				s.writeError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(tenant.WithTenant(r.Context(), id)))
+		})
+	}
+}
+
+type RateLimiter interface {
+	Allow(key string, class string) (bool, time.Duration)
+}
+
+type LoadShedder interface {
+	Acquire(ctx context.Context) (func(), bool)
+}
+
+// RateLimitMiddleware ограничивает частоту запросов по субъекту (API-ключ, токен)
+// или, для неаутентифицированных маршрутов, по IP клиента. Должен стоять после AuthMiddleware.
+func (s *Server) RateLimitMiddleware(limiter RateLimiter, trustForwardedFor bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == metricsPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			key := "ip:" + clientIP(r, trustForwardedFor)
+			if p := auth.FromContext(r.Context()); p != nil && p.Subject != "anonymous" {
+				key = "sub:" + p.Subject
+			}
+			class := ratelimit.ClassWrite
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				class = ratelimit.ClassRead
+			}
+
+			if ok, wait := limiter.Allow(key, class); !ok {
+				w.Header().Set("Retry-After", retryAfterSeconds(wait))
+				s.writeError(w, fmt.Errorf("%w: %s budget exhausted", apperrors.ErrRateLimited, class))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IPRateLimitMiddleware ограничивает частоту запросов с одного IP до аутентификации,
+// чтобы перебор и поток запросов с неверными учётными данными тоже упирались в лимит.
+// Должен стоять перед AuthMiddleware.
+func (s *Server) IPRateLimitMiddleware(limiter RateLimiter, trustForwardedFor bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == metricsPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if ok, wait := limiter.Allow(clientIP(r, trustForwardedFor), ratelimit.ClassIP); !ok {
+				w.Header().Set("Retry-After", retryAfterSeconds(wait))
+				s.writeError(w, fmt.Errorf("%w: %s budget exhausted", apperrors.ErrRateLimited, ratelimit.ClassIP))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// LoadSheddingMiddleware отбрасывает запросы с 503, когда все слоты заняты дольше допустимого ожидания
+func (s *Server) LoadSheddingMiddleware(shedder LoadShedder) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hasAnyPrefix(r.URL.Path, streamingPrefixes) || r.URL.Path == metricsPath {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			release, ok := shedder.Acquire(r.Context())
+			if !ok {
+				w.Header().Set("Retry-After", "1")
+				s.writeError(w, apperrors.ErrOverloaded)
+				return
+			}
+			defer release()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func retryAfterSeconds(wait time.Duration) string {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
@@ -0,0 +1,211 @@
+package routing
+
+import (
+	"avito-tech/internal/app/auth"
+	"avito-tech/internal/app/ratelimit"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type rejectingAuthenticator struct{}
+
+func (rejectingAuthenticator) Authenticate(context.Context, string) (*auth.Principal, error) {
+	return nil, apperrors.ErrUnauthorized
+}
+
+func chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+func TestIPRateLimitAppliesBeforeAuth(t *testing.T) {
+	s := NewServer(nil)
+	limiter := ratelimit.NewLimiter(ratelimit.Config{IP: ratelimit.Budget{Rate: 1, Burst: 2}})
+	h := chain(http.NotFoundHandler(), s.IPRateLimitMiddleware(limiter, false), s.AuthMiddleware(rejectingAuthenticator{}))
+
+	var codes []int
+	for range 3 {
+		req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+		req.Header.Set("Authorization", "Bearer wrong")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		codes = append(codes, rec.Code)
+	}
+	if codes[0] != http.StatusUnauthorized || codes[1] != http.StatusUnauthorized || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("expected bad credentials to be limited per IP, got %v", codes)
+	}
+
+	// другой IP не делит бюджет, метрики не ограничиваются
+	req := httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req.RemoteAddr = "192.0.2.2:1234"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected another IP to reach auth, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, metricsPath, nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected /metrics to skip the IP limit and auth, got %d", rec.Code)
+	}
+}
+
+func TestMetricsPathMatchesExactly(t *testing.T) {
+	h := NewServer(nil).AuthMiddleware(rejectingAuthenticator{})(http.NotFoundHandler())
+	for path, want := range map[string]int{
+		"/metrics":             http.StatusNotFound,
+		"/metrics/../team":     http.StatusUnauthorized,
+		"/metricsx":            http.StatusUnauthorized,
+		"/integrations/github": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: got %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+// fakeLimiter отклоняет все запросы, кроме ключей из allowed, и запоминает класс
+type fakeLimiter struct {
+	allowed map[string]bool
+	wait    time.Duration
+	classes []string
+}
+
+func (l *fakeLimiter) Allow(key string, class string) (bool, time.Duration) {
+	l.classes = append(l.classes, class)
+	if l.allowed[key] {
+		return true, 0
+	}
+	return false, l.wait
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	s := NewServer(nil)
+	limiter := &fakeLimiter{allowed: map[string]bool{"sub:alice": true}, wait: 1200 * time.Millisecond}
+	h := s.RateLimitMiddleware(limiter, true)(http.NotFoundHandler())
+
+	req := httptest.NewRequest(http.MethodPost, "/pullRequest/create", nil)
+	req.Header.Set("X-Forwarded-For", "198.51.100.7, 10.0.0.1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "2" {
+		t.Fatalf("expected 429 with Retry-After 2 (rounded up), got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// аутентифицированный субъект лимитируется по своему ключу, GET - бюджетом чтения
+	req = httptest.NewRequest(http.MethodGet, "/team/get", nil)
+	req = req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{Subject: "alice", Role: auth.RoleMember}))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected alice to pass the limit, got %d", rec.Code)
+	}
+	if len(limiter.classes) != 2 || limiter.classes[0] != ratelimit.ClassWrite || limiter.classes[1] != ratelimit.ClassRead {
+		t.Fatalf("expected write then read budget, got %v", limiter.classes)
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	for wait, want := range map[time.Duration]string{0: "1", 100 * time.Millisecond: "1", time.Second: "1", 1001 * time.Millisecond: "2", 30 * time.Second: "30"} {
+		if got := retryAfterSeconds(wait); got != want {
+			t.Errorf("retryAfterSeconds(%s) = %s, want %s", wait, got, want)
+		}
+	}
+}
+
+// fakeShedder выдаёт slots слотов и считает освобождённые
+type fakeShedder struct {
+	slots    int
+	released int
+}
+
+func (f *fakeShedder) Acquire(context.Context) (func(), bool) {
+	if f.slots == 0 {
+		return nil, false
+	}
+	f.slots--
+	return func() { f.released++ }, true
+}
+
+func TestLoadSheddingMiddleware(t *testing.T) {
+	s := NewServer(nil)
+	shedder := &fakeShedder{slots: 1}
+	h := s.LoadSheddingMiddleware(shedder)(http.NotFoundHandler())
+
+	var codes []int
+	for range 2 {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/team/get", nil))
+		codes = append(codes, rec.Code)
+		if rec.Code == http.StatusServiceUnavailable && rec.Header().Get("Retry-After") != "1" {
+			t.Fatalf("expected Retry-After 1 on 503, got %q", rec.Header().Get("Retry-After"))
+		}
+	}
+	if codes[0] != http.StatusNotFound || codes[1] != http.StatusServiceUnavailable || shedder.released != 1 {
+		t.Fatalf("expected one served and one shed request with the slot released, got %v, released %d", codes, shedder.released)
+	}
+
+	// поток событий и метрики не занимают слоты
+	for _, path := range []string{"/events/stream", metricsPath} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected to bypass load shedding, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestTenantMiddleware(t *testing.T) {
+	var got string
+	h := NewServer(nil).TenantMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = tenant.FromContext(r.Context())
+	}))
+	admin := &auth.Principal{Subject: "admin", Role: auth.RoleAdmin}
+	bound := &auth.Principal{Subject: "ci", Role: auth.RoleAdmin, Tenant: "acme"}
+	member := &auth.Principal{Subject: "bob", Role: auth.RoleMember}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		header    string
+		wantCode  int
+		want      string
+	}{
+		// публичные маршруты: заголовок не подтверждён учётными данными
+		{"public ignores header", nil, "globex", http.StatusOK, tenant.Default},
+		{"public without header", nil, "", http.StatusOK, tenant.Default},
+		{"admin chooses", admin, "globex", http.StatusOK, "globex"},
+		{"admin without header", admin, "", http.StatusOK, tenant.Default},
+		{"admin invalid tenant", admin, "Globex Inc", http.StatusBadRequest, ""},
+		{"bound credential", bound, "", http.StatusOK, "acme"},
+		{"bound credential matching header", bound, "acme", http.StatusOK, "acme"},
+		{"bound credential other tenant", bound, "globex", http.StatusForbidden, ""},
+		{"member stays in default", member, "", http.StatusOK, tenant.Default},
+		{"member other tenant", member, "globex", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		got = ""
+		req := httptest.NewRequest(http.MethodPost, "/integrations/github", nil)
+		if tt.principal != nil {
+			req = httptest.NewRequest(http.MethodPost, "/team/add", nil)
+			req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+		}
+		if tt.header != "" {
+			req.Header.Set(tenant.Header, tt.header)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.wantCode || got != tt.want {
+			t.Errorf("%s: got %d in tenant %q, want %d in %q", tt.name, rec.Code, got, tt.wantCode, tt.want)
+		}
+	}
+}
@@ -1,24 +1,71 @@
 package routing
 
 import (
+	"avito-tech/internal/app/integration"
+
 	"github.com/gorilla/mux"
 )
 
-func NewRouter(server *Server) *mux.Router {
+func NewRouter(server *Server, middlewares ...mux.MiddlewareFunc) *mux.Router {
 	router := mux.NewRouter()
+	router.Use(middlewares...)
 
 	// Teams
 	router.HandleFunc("/team/add", server.AddTeamHandler).Methods("POST")
 	router.HandleFunc("/team/get", server.GetTeamHandler).Methods("GET")
+	router.HandleFunc("/team/setMaxOpenReviews", server.SetTeamMaxOpenReviewsHandler).Methods("POST")
+	router.HandleFunc("/team/setEscalationPolicy", server.SetTeamEscalationPolicyHandler).Methods("POST")
+	router.HandleFunc("/team/escalations", server.ListTeamEscalationsHandler).Methods("GET")
+	router.HandleFunc("/team/setFallbackTeams", server.SetTeamFallbackTeamsHandler).Methods("POST")
+	router.HandleFunc("/team/setParent", server.SetTeamParentHandler).Methods("POST")
+	router.HandleFunc("/team/subtree", server.GetTeamSubtreeHandler).Methods("GET")
+	router.HandleFunc("/team/stats", server.GetTeamStatsHandler).Methods("GET")
+	router.HandleFunc("/team/setIsActive", server.SetTeamIsActiveHandler).Methods("POST")
+	router.HandleFunc("/organization/tree", server.GetOrganizationHandler).Methods("GET")
 
 	// Users
 	router.HandleFunc("/users/setIsActive", server.SetIsActiveHandler).Methods("POST")
 	router.HandleFunc("/users/getReview", server.GetReviewHandler).Methods("GET")
+	router.HandleFunc("/users/get", server.GetUserHandler).Methods("GET")
+	router.HandleFunc("/users/setMaxOpenReviews", server.SetMaxOpenReviewsHandler).Methods("POST")
+	router.HandleFunc("/users/setWorkingHours", server.SetWorkingHoursHandler).Methods("POST")
+	router.HandleFunc("/users/availability", server.CreateUnavailabilityHandler).Methods("POST")
+	router.HandleFunc("/users/availability", server.ListUnavailabilityHandler).Methods("GET")
+	router.HandleFunc("/users/availability/delete", server.DeleteUnavailabilityHandler).Methods("POST")
 
 	// PullRequests
 	router.HandleFunc("/pullRequest/create", server.CreatePullRequestHandler).Methods("POST")
 	router.HandleFunc("/pullRequest/merge", server.MergePullRequestHandler).Methods("POST")
 	router.HandleFunc("/pullRequest/reassign", server.ReassignPullRequestHandler).Methods("POST")
+	router.HandleFunc("/pullRequest/get", server.GetPullRequestHandler).Methods("GET")
+	router.HandleFunc("/pullRequest/review", server.ReviewPullRequestHandler).Methods("POST")
+	router.HandleFunc("/pullRequest/markReady", server.PullRequestStatusHandler(server.impl.MarkPullRequestReady)).Methods("POST")
+	router.HandleFunc("/pullRequest/close", server.PullRequestStatusHandler(server.impl.ClosePullRequest)).Methods("POST")
+	router.HandleFunc("/pullRequest/reopen", server.PullRequestStatusHandler(server.impl.ReopenPullRequest)).Methods("POST")
+	router.HandleFunc("/pullRequest/forgeSync", server.ForgeSyncHandler).Methods("GET")
+
+	// Webhooks
+	router.HandleFunc("/webhooks/create", server.CreateWebhookHandler).Methods("POST")
+	router.HandleFunc("/webhooks/list", server.ListWebhooksHandler).Methods("GET")
+	router.HandleFunc("/webhooks/delete", server.DeleteWebhookHandler).Methods("POST")
+	router.HandleFunc("/webhooks/deliveries", server.WebhookDeliveriesHandler).Methods("GET")
+	router.HandleFunc("/webhooks/deadLetters", server.WebhookDeadLettersHandler).Methods("GET")
+
+	// Events
+	router.HandleFunc("/events/stream", server.EventsStreamHandler).Methods("GET")
+
+	// Admin
+	router.HandleFunc("/admin/apiKeys", server.CreateAPIKeyHandler).Methods("POST")
+	router.HandleFunc("/admin/apiKeys", server.ListAPIKeysHandler).Methods("GET")
+	router.HandleFunc("/admin/apiKeys/revoke", server.RevokeAPIKeyHandler).Methods("POST")
+	router.HandleFunc("/admin/jobs", server.ListJobsHandler).Methods("GET")
+
+	// Metrics
+	router.HandleFunc("/metrics", server.MetricsHandler).Methods("GET")
+
+	// Integrations
+	router.HandleFunc("/integrations/github", server.ForgeWebhookHandler(integration.ForgeGitHub)).Methods("POST")
+	router.HandleFunc("/integrations/gitlab", server.ForgeWebhookHandler(integration.ForgeGitLab)).Methods("POST")
 
 	return router
 }
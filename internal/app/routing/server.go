@@ -2,7 +2,9 @@ package routing
 
 import (
 	"avito-tech/internal/app/core"
+	"avito-tech/internal/app/stream"
 	"context"
+	"net/http"
 )
 
 type ImplInterface interface {
@@ -11,8 +13,40 @@ type ImplInterface interface {
 	GetTeamByTeamName(ctx context.Context, teamName string) (*core.GetTeamResponse, error)
 	MergePullRequest(ctx context.Context, req core.MergePullReqRequest) (*core.MergePullReqResponse, error)
 	ReassignPullRequest(ctx context.Context, request *core.ReassignPullReqRequest) (*core.ReassignPullReqResponse, error)
+	GetPullRequest(ctx context.Context, prID string) (*core.GetPullReqResponse, error)
+	ReviewPullRequest(ctx context.Context, req *core.ReviewPullReqRequest) (*core.ReviewPullReqResponse, error)
+	MarkPullRequestReady(ctx context.Context, req core.PullReqStatusRequest) (*core.GetPullReqResponse, error)
+	ClosePullRequest(ctx context.Context, req core.PullReqStatusRequest) (*core.GetPullReqResponse, error)
+	ReopenPullRequest(ctx context.Context, req core.PullReqStatusRequest) (*core.GetPullReqResponse, error)
 	GetReview(ctx context.Context, userID string) (*core.GetReviewResponse, error)
 	UserSetIsActive(ctx context.Context, request core.SetIsActiveRequest) (*core.SetIsActiveResponse, error)
+	GetUser(ctx context.Context, userID string) (*core.GetUserResponse, error)
+	UserSetMaxOpenReviews(ctx context.Context, request core.SetMaxOpenReviewsRequest) (*core.GetUserResponse, error)
+	UserSetWorkingHours(ctx context.Context, request core.SetWorkingHoursRequest) (*core.GetUserResponse, error)
+	SetTeamMaxOpenReviews(ctx context.Context, req core.SetTeamMaxOpenReviewsRequest) (*core.GetTeamResponse, error)
+	SetTeamEscalationPolicy(ctx context.Context, req core.SetTeamEscalationPolicyRequest) (*core.GetTeamResponse, error)
+	ListTeamEscalations(ctx context.Context, teamName string, subtree bool) (*core.ListTeamEscalationsResponse, error)
+	SetTeamFallbackTeams(ctx context.Context, req core.SetTeamFallbackTeamsRequest) (*core.GetTeamResponse, error)
+	SetTeamParent(ctx context.Context, req core.SetTeamParentRequest) (*core.GetTeamResponse, error)
+	GetTeamSubtree(ctx context.Context, teamName string) (*core.GetTeamSubtreeResponse, error)
+	GetOrganization(ctx context.Context) (*core.GetOrganizationResponse, error)
+	GetTeamStats(ctx context.Context, teamName string, subtree bool) (*core.GetTeamStatsResponse, error)
+	SetTeamIsActive(ctx context.Context, req core.SetTeamIsActiveRequest) (*core.SetTeamIsActiveResponse, error)
+	CreateUnavailability(ctx context.Context, req *core.CreateUnavailabilityRequest) (*core.UnavailabilityResponse, error)
+	ListUnavailability(ctx context.Context, userID string) (*core.ListUnavailabilityResponse, error)
+	DeleteUnavailability(ctx context.Context, req core.DeleteUnavailabilityRequest) error
+	CreateWebhook(ctx context.Context, req *core.CreateWebhookRequest) (*core.CreateWebhookResponse, error)
+	ListWebhooks(ctx context.Context) (*core.ListWebhooksResponse, error)
+	DeleteWebhook(ctx context.Context, req core.DeleteWebhookRequest) error
+	WebhookDeliveries(ctx context.Context, subscriptionID uint64, status string) (*core.WebhookDeliveriesResponse, error)
+	WebhookDeadLetters(ctx context.Context) (*core.WebhookDeliveriesResponse, error)
+	HandleForgeWebhook(ctx context.Context, forge string, header http.Header, body []byte) (*core.ForgeWebhookResponse, error)
+	ForgeSyncs(ctx context.Context, prID string, status string) (*core.ForgeSyncResponse, error)
+	CreateAPIKey(ctx context.Context, req *core.CreateAPIKeyRequest) (*core.APIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) (*core.ListAPIKeysResponse, error)
+	RevokeAPIKey(ctx context.Context, req core.RevokeAPIKeyRequest) (*core.APIKeyResponse, error)
+	ListJobs(ctx context.Context) (*core.ListJobsResponse, error)
+	SubscribeAssignments(ctx context.Context, userID string, lastEventID uint64) (*stream.Subscription, error)
 }
 
 type Server struct {
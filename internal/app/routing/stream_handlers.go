@@ -0,0 +1,89 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const streamHeartbeat = 15 * time.Second
+
+func (s *Server) EventsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		s.writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "BAD_REQUEST",
+				Message: "user_id parameter is required",
+			},
+		})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.writeError(w, fmt.Errorf("streaming is not supported"))
+		return
+	}
+
+	var lastEventID uint64
+	if raw := r.Header.Get("Last-Event-ID"); raw != "" {
+		lastEventID, _ = strconv.ParseUint(raw, 10, 64)
+	}
+
+	sub, err := s.impl.SubscribeAssignments(r.Context(), userID, lastEventID)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	notifications := make(chan []byte)
+	go func() {
+		defer close(notifications)
+		for {
+			n, ok := sub.Next(r.Context())
+			if !ok {
+				return
+			}
+			data, _ := json.Marshal(n)
+			frame := fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", n.ID, n.Kind, data)
+			select {
+			case notifications <- []byte(frame):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case frame, ok := <-notifications:
+			if !ok {
+				return
+			}
+			if _, err := w.Write(frame); err != nil {
+				return
+			}
+			flusher.Flush()
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package routing
+
+import (
+	"avito-tech/internal/app/core"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+func (s *Server) SetTeamParentHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.SetTeamParentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.SetTeamParent(r.Context(), req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	setETag(w, resp.Version)
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) GetTeamSubtreeHandler(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		s.writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "BAD_REQUEST",
+				Message: "team_name parameter is required",
+			},
+		})
+		return
+	}
+
+	resp, err := s.impl.GetTeamSubtree(r.Context(), teamName)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) GetOrganizationHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.impl.GetOrganization(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) GetTeamStatsHandler(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		s.writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "BAD_REQUEST",
+				Message: "team_name parameter is required",
+			},
+		})
+		return
+	}
+	subtree, ok := s.subtreeParam(w, r)
+	if !ok {
+		return
+	}
+
+	resp, err := s.impl.GetTeamStats(r.Context(), teamName, subtree)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) SetTeamIsActiveHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.SetTeamIsActiveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.SetTeamIsActive(r.Context(), req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// subtreeParam разбирает необязательный параметр subtree - выборку по отделу; при ошибке отвечает 400
+func (s *Server) subtreeParam(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	raw := r.URL.Query().Get("subtree")
+	if raw == "" {
+		return false, true
+	}
+	subtree, err := strconv.ParseBool(raw)
+	if err != nil {
+		s.writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error: ErrorDetail{
+				Code:    "BAD_REQUEST",
+				Message: "subtree must be a boolean",
+			},
+		})
+		return false, false
+	}
+	return subtree, true
+}
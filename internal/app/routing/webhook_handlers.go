@@ -0,0 +1,85 @@
+package routing
+
+import (
+	"avito-tech/internal/app/core"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+func (s *Server) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	resp, err := s.impl.CreateWebhook(r.Context(), &req)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, resp)
+}
+
+func (s *Server) ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.impl.ListWebhooks(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req core.DeleteWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	if err := s.impl.DeleteWebhook(r.Context(), req); err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) WebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	var subscriptionID uint64
+	if raw := r.URL.Query().Get("subscription_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			s.writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error: ErrorDetail{
+					Code:    "BAD_REQUEST",
+					Message: "subscription_id must be a positive integer",
+				},
+			})
+			return
+		}
+		subscriptionID = id
+	}
+
+	resp, err := s.impl.WebhookDeliveries(r.Context(), subscriptionID, r.URL.Query().Get("status"))
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) WebhookDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	resp, err := s.impl.WebhookDeadLetters(r.Context())
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// at - момент в локальном поясе: в нём считает cron
+func at(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.Local)
+}
+
+func TestCronNext(t *testing.T) {
+	tests := []struct {
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		// 2026-01-01 - четверг
+		{"*/15 * * * *", at(2026, time.January, 1, 12, 7), at(2026, time.January, 1, 12, 15)},
+		{"*/10 * * * *", at(2026, time.January, 1, 12, 10).Add(30 * time.Second), at(2026, time.January, 1, 12, 20)},
+		{"*/10 * * * *", at(2026, time.January, 1, 12, 10), at(2026, time.January, 1, 12, 20)},
+		{"5/20 * * * *", at(2026, time.January, 1, 12, 26), at(2026, time.January, 1, 12, 45)},
+		{"0 10-12/2 * * *", at(2026, time.January, 1, 10, 0), at(2026, time.January, 1, 12, 0)},
+		{"15,45 * * * *", at(2026, time.January, 1, 23, 50), at(2026, time.January, 2, 0, 15)},
+		{"0 9 * * 1-5", at(2026, time.January, 2, 10, 0), at(2026, time.January, 5, 9, 0)},
+		{"0 12 * * 7", at(2026, time.January, 1, 0, 0), at(2026, time.January, 4, 12, 0)},
+		{"0 12 * * 0", at(2026, time.January, 1, 0, 0), at(2026, time.January, 4, 12, 0)},
+		// ограничены оба дня: достаточно совпадения одного (среда, 7 января, раньше 15-го)
+		{"0 0 1,15 * 3", at(2026, time.January, 2, 0, 0), at(2026, time.January, 7, 0, 0)},
+		{"0 0 1,15 * 3", at(2026, time.January, 14, 0, 0), at(2026, time.January, 15, 0, 0)},
+		// день недели "*": нужен именно день месяца
+		{"0 0 13 * *", at(2026, time.January, 1, 0, 0), at(2026, time.January, 13, 0, 0)},
+		// шаг от "*" по дню месяца тоже считается "*": нужны оба условия (понедельник 1, 11, 21 или 31 числа)
+		{"0 0 */10 * 1", at(2026, time.January, 1, 0, 0), at(2026, time.May, 11, 0, 0)},
+		{"0 0 31 * *", at(2026, time.January, 31, 0, 0), at(2026, time.March, 31, 0, 0)},
+		{"30 23 31 12 *", at(2026, time.December, 31, 23, 30), at(2027, time.December, 31, 23, 30)},
+		{"0 0 29 2 *", at(2026, time.March, 1, 0, 0), at(2028, time.February, 29, 0, 0)},
+		{"0 0 1 */3 *", at(2026, time.February, 10, 0, 0), at(2026, time.April, 1, 0, 0)},
+		{"0 0 30 2 *", at(2026, time.January, 1, 0, 0), time.Time{}},
+	}
+	for _, tt := range tests {
+		c, err := parseCron(tt.expr, tt.expr)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.expr, err)
+		}
+		if got := c.Next(tt.after); !got.Equal(tt.want) {
+			t.Errorf("%s after %s: got %s, want %s", tt.expr, tt.after.Format(time.DateTime), got.Format(time.DateTime), tt.want.Format(time.DateTime))
+		}
+	}
+}
+
+func TestParseCronRejects(t *testing.T) {
+	for expr, wantErr := range map[string]string{
+		"* * * *":      "must have 5 fields",
+		"* * * * * *":  "must have 5 fields",
+		"60 * * * *":   "minute",
+		"* 24 * * *":   "hour",
+		"0 0 0 * *":    "day of month",
+		"0 0 * 13 *":   "month",
+		"0 0 * * 8":    "day of week",
+		"*/0 * * * *":  "invalid step",
+		"*/x * * * *":  "invalid step",
+		"5-1 * * * *":  "out of range",
+		"a * * * *":    "invalid value",
+		"1-b * * * *":  "invalid value",
+		"1,,2 * * * *": "invalid value",
+		"0 0 1-32 * *": "out of range",
+		"0 0 * * -1":   "invalid value",
+	} {
+		_, err := parseCron(expr, expr)
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%q: expected error containing %q, got %v", expr, wantErr, err)
+		}
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	for spec, want := range map[string]string{
+		"30s":           "@every 30s",
+		" @every 5m ":   "@every 5m0s",
+		"@daily":        "@daily",
+		"@hourly":       "@hourly",
+		"*/5 * * * *":   "*/5 * * * *",
+		"0 3 * * 1,3,5": "0 3 * * 1,3,5",
+	} {
+		s, err := ParseSchedule(spec)
+		if err != nil {
+			t.Fatalf("%q: %v", spec, err)
+		}
+		if s.String() != want {
+			t.Errorf("%q: got %s, want %s", spec, s, want)
+		}
+	}
+
+	for _, spec := range []string{"", "abc", "-5s", "0s", "@every", "@reboot", "0 0 30 2 *", "0 0 31 4 *"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+
+	daily, _ := ParseSchedule("@daily")
+	if got, want := daily.Next(at(2026, time.January, 1, 12, 0)), at(2026, time.January, 2, 0, 0); !got.Equal(want) {
+		t.Errorf("@daily: got %s, want %s", got, want)
+	}
+	every, _ := ParseSchedule("90s")
+	if got, want := every.Next(at(2026, time.January, 1, 12, 0)), at(2026, time.January, 1, 12, 1).Add(30*time.Second); !got.Equal(want) {
+		t.Errorf("90s: got %s, want %s", got, want)
+	}
+}
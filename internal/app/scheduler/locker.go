@@ -0,0 +1,113 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Locker выбирает реплику, которая выполняет задачу. Acquire идемпотентен: держатель
+// блокировки получает true при каждом вызове, остальные реплики - false, пока она не освобождена.
+type Locker interface {
+	Acquire(ctx context.Context, name string) (bool, error)
+	Release(ctx context.Context, name string) error
+}
+
+// LocalLocker - единственная реплика всегда лидер (режимы memory и sqlite)
+type LocalLocker struct{}
+
+func (LocalLocker) Acquire(context.Context, string) (bool, error) {
+	return true, nil
+}
+
+func (LocalLocker) Release(context.Context, string) error {
+	return nil
+}
+
+// AdvisoryLocker держит сессионные advisory-блокировки Postgres на отдельном соединении,
+// изъятом из пула. Блокировка живёт, пока живо соединение: если реплика падает или теряет
+// соединение, Postgres снимает её блокировки и задачи подхватывает другая реплика.
+type AdvisoryLocker struct {
+	pool *pgxpool.Pool
+	mu   sync.Mutex
+	conn *pgxpool.Conn
+	held map[string]bool
+}
+
+func NewAdvisoryLocker(pool *pgxpool.Pool) *AdvisoryLocker {
+	return &AdvisoryLocker{
+		pool: pool,
+		held: map[string]bool{},
+	}
+}
+
+func (l *AdvisoryLocker) Acquire(ctx context.Context, name string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.Conn().Ping(ctx); err != nil {
+			log.Printf("[AdvisoryLocker.Acquire] lock connection lost, %d locks dropped: %v", len(l.held), err)
+			l.reset()
+		}
+	}
+	if l.conn == nil {
+		conn, err := l.pool.Acquire(ctx)
+		if err != nil {
+			log.Printf("[AdvisoryLocker.Acquire] failed to acquire lock connection: %v", err)
+			return false, err
+		}
+		l.conn = conn
+	}
+	if l.held[name] {
+		return true, nil
+	}
+
+	var acquired bool
+	err := l.conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext('scheduler'), hashtext($1))", name).Scan(&acquired)
+	if err != nil {
+		log.Printf("[AdvisoryLocker.Acquire] db error locking job '%s': %v", name, err)
+		l.reset()
+		return false, err
+	}
+	if acquired {
+		log.Printf("[AdvisoryLocker.Acquire] this replica now leads job '%s'", name)
+		l.held[name] = true
+	}
+	return acquired, nil
+}
+
+func (l *AdvisoryLocker) Release(ctx context.Context, name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil || !l.held[name] {
+		return nil
+	}
+	delete(l.held, name)
+	if _, err := l.conn.Exec(ctx, "SELECT pg_advisory_unlock(hashtext('scheduler'), hashtext($1))", name); err != nil {
+		log.Printf("[AdvisoryLocker.Release] db error unlocking job '%s': %v", name, err)
+		l.reset()
+		return err
+	}
+	return nil
+}
+
+// Close закрывает соединение и тем самым снимает все блокировки реплики
+func (l *AdvisoryLocker) Close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reset()
+}
+
+// reset закрывает соединение: блокировки на сервере снимаются вместе с сессией,
+// а соединение не возвращается в пул, чтобы чужие запросы не унаследовали их
+func (l *AdvisoryLocker) reset() {
+	if l.conn != nil {
+		_ = l.conn.Hijack().Close(context.Background())
+	}
+	l.conn = nil
+	l.held = map[string]bool{}
+}
@@ -0,0 +1,76 @@
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule задаёт моменты запуска задачи
+type Schedule interface {
+	// Next возвращает ближайший запуск строго после after; нулевое время - запусков больше не будет
+	Next(after time.Time) time.Time
+	String() string
+}
+
+// interval - запуск через фиксированный промежуток после окончания предыдущего
+type interval time.Duration
+
+func Every(d time.Duration) Schedule {
+	return interval(d)
+}
+
+func (i interval) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(i))
+}
+
+func (i interval) String() string {
+	return "@every " + time.Duration(i).String()
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseSchedule разбирает расписание: длительность ("30s", "@every 5m"),
+// дескриптор (@hourly, @daily, ...) или cron-выражение из пяти полей
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if d, ok := strings.CutPrefix(spec, "@every "); ok {
+		return parseInterval(strings.TrimSpace(d))
+	}
+	if expr, ok := descriptors[spec]; ok {
+		return parseCron(expr, spec)
+	}
+	if strings.HasPrefix(spec, "@") {
+		return nil, fmt.Errorf("unknown schedule descriptor %q", spec)
+	}
+	if len(strings.Fields(spec)) == 1 {
+		return parseInterval(spec)
+	}
+	c, err := parseCron(spec, spec)
+	if err != nil {
+		return nil, err
+	}
+	if c.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("cron expression %q never fires", spec)
+	}
+	return c, nil
+}
+
+func parseInterval(spec string) (Schedule, error) {
+	d, err := time.ParseDuration(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interval %q: %w", spec, err)
+	}
+	if d <= 0 {
+		return nil, fmt.Errorf("interval must be positive, got %s", d)
+	}
+	return Every(d), nil
+}
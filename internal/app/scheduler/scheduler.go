@@ -0,0 +1,202 @@
+// Package scheduler запускает периодические фоновые задачи. Каждую задачу в момент запуска
+// выполняет только реплика, которая держит её блокировку (см. Locker).
+package scheduler
+
+import (
+	"avito-tech/internal/app/metrics"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	recentRuns     = 20
+	releaseTimeout = 5 * time.Second
+)
+
+type Job struct {
+	Name     string
+	Schedule Schedule
+	// Run возвращает число обработанных записей
+	Run func(ctx context.Context) (int, error)
+}
+
+type RunInfo struct {
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	Processed  int       `json:"processed"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// JobStatus - состояние задачи на этой реплике: запуски, пропущенные без лидерства, остаются
+// только в счётчике skipped
+type JobStatus struct {
+	Name          string     `json:"name"`
+	Schedule      string     `json:"schedule"`
+	Leader        bool       `json:"leader"`
+	Running       bool       `json:"running"`
+	NextRunAt     *time.Time `json:"next_run_at,omitempty"`
+	Runs          uint64     `json:"runs"`
+	Failures      uint64     `json:"failures"`
+	Skipped       uint64     `json:"skipped"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	RecentRuns    []RunInfo  `json:"recent_runs"`
+}
+
+type job struct {
+	Job
+	status JobStatus
+}
+
+type Scheduler struct {
+	locker Locker
+	mu     sync.Mutex
+	jobs   []*job
+}
+
+func New(locker Locker) *Scheduler {
+	return &Scheduler{locker: locker}
+}
+
+// Register добавляет задачу; задачи регистрируются до Run
+func (s *Scheduler) Register(j Job) error {
+	if j.Name == "" || j.Schedule == nil || j.Run == nil {
+		return fmt.Errorf("job %q needs a name, a schedule and a run function", j.Name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if slices.ContainsFunc(s.jobs, func(x *job) bool { return x.Name == j.Name }) {
+		return fmt.Errorf("job %q is already registered", j.Name)
+	}
+	s.jobs = append(s.jobs, &job{
+		Job: j,
+		status: JobStatus{
+			Name:       j.Name,
+			Schedule:   j.Schedule.String(),
+			RecentRuns: []RunInfo{},
+		},
+	})
+	return nil
+}
+
+// Run выполняет задачи по расписанию до отмены ctx; выполняющиеся задачи получают тот же ctx.
+// Возвращается, когда все задачи завершились и отпустили блокировки.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	jobs := slices.Clone(s.jobs)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, j)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.release(ctx, j)
+	for {
+		next := j.Schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("[Scheduler.loop] job '%s' has no further runs in schedule '%s'", j.Name, j.Schedule)
+			s.update(j, func(st *JobStatus) { st.NextRunAt = nil })
+			return
+		}
+		s.update(j, func(st *JobStatus) { st.NextRunAt = &next })
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		leader, err := s.locker.Acquire(ctx, j.Name)
+		if err != nil {
+			log.Printf("[Scheduler.loop] failed to check leadership of job '%s': %v", j.Name, err)
+		}
+		s.update(j, func(st *JobStatus) { st.Leader = leader })
+		if !leader {
+			s.update(j, func(st *JobStatus) { st.Skipped++ })
+			metrics.Default.Counter("scheduler_job_runs_total", "Scheduled job ticks by result.", "job", j.Name, "result", "skipped").Inc()
+			continue
+		}
+		s.execute(ctx, j)
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, j *job) {
+	s.update(j, func(st *JobStatus) { st.Running = true })
+	started := time.Now()
+	processed, err := safeRun(ctx, j)
+	run := RunInfo{
+		StartedAt:  started,
+		DurationMs: time.Since(started).Milliseconds(),
+		Processed:  processed,
+	}
+
+	result := "success"
+	if err != nil {
+		result = "failure"
+		run.Error = err.Error()
+		log.Printf("[Scheduler.execute] job '%s' failed: %v", j.Name, err)
+	}
+	metrics.Default.Counter("scheduler_job_runs_total", "Scheduled job ticks by result.", "job", j.Name, "result", result).Inc()
+
+	s.update(j, func(st *JobStatus) {
+		st.Running = false
+		st.Runs++
+		if err != nil {
+			st.Failures++
+		} else {
+			st.LastSuccessAt = &started
+		}
+		st.RecentRuns = append([]RunInfo{run}, st.RecentRuns[:min(len(st.RecentRuns), recentRuns-1)]...)
+	})
+}
+
+// safeRun превращает панику задачи в ошибку запуска, чтобы она не остановила остальные задачи
+func safeRun(ctx context.Context, j *job) (n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.Run(ctx)
+}
+
+func (s *Scheduler) release(ctx context.Context, j *job) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+	if err := s.locker.Release(ctx, j.Name); err != nil {
+		log.Printf("[Scheduler.release] failed to release job '%s': %v", j.Name, err)
+	}
+	s.update(j, func(st *JobStatus) { st.Leader = false })
+}
+
+func (s *Scheduler) update(j *job, f func(st *JobStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f(&j.status)
+}
+
+// Status возвращает состояние задач на этой реплике в порядке регистрации
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		st := j.status
+		st.RecentRuns = slices.Clone(st.RecentRuns)
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
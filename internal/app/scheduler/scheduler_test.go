@@ -0,0 +1,175 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeLocker отдаёт лидерство по заранее заданной последовательности, дальше - false
+type fakeLocker struct {
+	mu       sync.Mutex
+	grants   []bool
+	err      error
+	acquires int
+	released []string
+}
+
+func (l *fakeLocker) Acquire(context.Context, string) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.acquires++
+	if len(l.grants) == 0 {
+		return false, l.err
+	}
+	grant := l.grants[0]
+	l.grants = l.grants[1:]
+	return grant, nil
+}
+
+func (l *fakeLocker) Release(_ context.Context, name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.released = append(l.released, name)
+	return nil
+}
+
+func (l *fakeLocker) acquired() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.acquires
+}
+
+// runUntil крутит планировщик, пока locker не ответит ticks раз, и возвращает состояние задач
+func runUntil(t *testing.T, s *Scheduler, locker *fakeLocker, ticks int) []JobStatus {
+	t.Helper()
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	deadline := time.After(5 * time.Second)
+	for locker.acquired() < ticks {
+		select {
+		case <-deadline:
+			t.Fatalf("scheduler ticked %d times, want %d", locker.acquired(), ticks)
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel()
+	<-done
+	return s.Status()
+}
+
+func TestSchedulerSkipsWithoutLock(t *testing.T) {
+	locker := &fakeLocker{grants: []bool{false, true, false, true}}
+	s := New(locker)
+	var (
+		mu   sync.Mutex
+		runs int
+	)
+	err := s.Register(Job{Name: "relay", Schedule: Every(time.Millisecond), Run: func(context.Context) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		runs++
+		return 3, nil
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := runUntil(t, s, locker, 6)[0]
+	mu.Lock()
+	defer mu.Unlock()
+	if runs != 2 || status.Runs != 2 {
+		t.Fatalf("job must run only while holding the lock: ran %d times, status %d", runs, status.Runs)
+	}
+	if status.Skipped < 4 || status.Failures != 0 || status.LastSuccessAt == nil {
+		t.Fatalf("expected skipped ticks without the lock, got %+v", status)
+	}
+	if len(status.RecentRuns) != 2 || status.RecentRuns[0].Processed != 3 {
+		t.Fatalf("expected 2 recent runs with 3 processed, got %+v", status.RecentRuns)
+	}
+	// после остановки блокировка отпущена
+	if status.Leader || len(locker.released) != 1 || locker.released[0] != "relay" {
+		t.Fatalf("expected the lock of relay released on stop, got leader=%v released=%v", status.Leader, locker.released)
+	}
+}
+
+func TestSchedulerLockErrorSkips(t *testing.T) {
+	locker := &fakeLocker{err: errors.New("connection refused")}
+	s := New(locker)
+	ran := make(chan struct{}, 1)
+	if err := s.Register(Job{Name: "purge", Schedule: Every(time.Millisecond), Run: func(context.Context) (int, error) {
+		ran <- struct{}{}
+		return 0, nil
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	status := runUntil(t, s, locker, 3)[0]
+	select {
+	case <-ran:
+		t.Fatal("job must not run when leadership cannot be checked")
+	default:
+	}
+	if status.Runs != 0 || status.Skipped < 3 {
+		t.Fatalf("expected only skipped ticks, got %+v", status)
+	}
+}
+
+func TestSchedulerRecordsFailures(t *testing.T) {
+	locker := &fakeLocker{grants: []bool{true, true}}
+	s := New(locker)
+	var calls int
+	if err := s.Register(Job{Name: "escalation", Schedule: Every(time.Millisecond), Run: func(context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		return 0, errors.New("db is down")
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	status := runUntil(t, s, locker, 3)[0]
+	if status.Runs != 2 || status.Failures != 2 || status.LastSuccessAt != nil {
+		t.Fatalf("expected 2 failed runs, got %+v", status)
+	}
+	if status.RecentRuns[0].Error != "db is down" || status.RecentRuns[1].Error != "panic: boom" {
+		t.Fatalf("expected the latest run first with its error, got %+v", status.RecentRuns)
+	}
+}
+
+func TestRegisterValidates(t *testing.T) {
+	s := New(LocalLocker{})
+	run := func(context.Context) (int, error) { return 0, nil }
+	if err := s.Register(Job{Name: "a", Schedule: Every(time.Second), Run: run}); err != nil {
+		t.Fatal(err)
+	}
+	for _, j := range []Job{
+		{Name: "a", Schedule: Every(time.Second), Run: run},
+		{Schedule: Every(time.Second), Run: run},
+		{Name: "b", Run: run},
+		{Name: "c", Schedule: Every(time.Second)},
+	} {
+		if err := s.Register(j); err == nil {
+			t.Errorf("expected job %q to be rejected", j.Name)
+		}
+	}
+}
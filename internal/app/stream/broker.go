@@ -0,0 +1,190 @@
+package stream
+
+import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/app/tenant"
+	"context"
+	"log"
+	"sync"
+)
+
+const (
+	subscriberBuffer = 64
+	replayLimit      = 500
+	// seenLimit - сколько последних отданных ID помнит подписка для отсева дублей
+	seenLimit = 1024
+)
+
+type Repo interface {
+	since(ctx context.Context, userID string, afterID uint64, limit int) ([]*events.Event, error)
+}
+
+// Broker раздаёт уведомления подключённым клиентам. Один на процесс,
+// события получает как sink outbox relay.
+type Broker struct {
+	repo Repo
+
+	mu   sync.RWMutex
+	subs map[subscriber]map[*Subscription]struct{}
+}
+
+// subscriber - ревьювер внутри арендатора: один user_id в разных арендаторах - разные люди
+type subscriber struct {
+	tenant string
+	userID string
+}
+
+func NewBroker(repo Repo) *Broker {
+	return &Broker{
+		repo: repo,
+		subs: map[subscriber]map[*Subscription]struct{}{},
+	}
+}
+
+type Subscription struct {
+	broker *Broker
+	key    subscriber
+	userID string
+	live   chan *Notification
+	replay []*Notification
+	seen   *recentIDs
+	once   sync.Once
+	done   chan struct{}
+}
+
+// Subscribe подписывает клиента на уведомления ревьювера. Если lastEventID > 0,
+// сначала отдаются пропущенные события из outbox, затем живой поток без дублей.
+// Relay может доставлять события не по порядку ID и повторно, поэтому дубли
+// отсеиваются по множеству недавно отданных ID, а не по последнему ID.
+func (b *Broker) Subscribe(ctx context.Context, userID string, lastEventID uint64) (*Subscription, error) {
+	sub := &Subscription{
+		broker: b,
+		key:    subscriber{tenant: tenant.FromContext(ctx), userID: userID},
+		userID: userID,
+		live:   make(chan *Notification, subscriberBuffer),
+		seen:   newRecentIDs(seenLimit),
+		done:   make(chan struct{}),
+	}
+
+	// подписываемся до чтения истории, чтобы не потерять события между ними
+	b.mu.Lock()
+	if b.subs[sub.key] == nil {
+		b.subs[sub.key] = map[*Subscription]struct{}{}
+	}
+	b.subs[sub.key][sub] = struct{}{}
+	b.mu.Unlock()
+
+	if lastEventID > 0 {
+		missed, err := b.repo.since(ctx, userID, lastEventID, replayLimit)
+		if err != nil {
+			sub.Close()
+			return nil, err
+		}
+		for _, ev := range missed {
+			for _, n := range notificationsFor(ev) {
+				if n.UserID == userID && sub.seen.add(n.ID) {
+					sub.replay = append(sub.replay, n)
+				}
+			}
+		}
+	}
+
+	log.Printf("[Broker.Subscribe] '%s' subscribed from event %d, %d to replay", userID, lastEventID, len(sub.replay))
+	return sub, nil
+}
+
+// Next блокируется до следующего уведомления. Возвращает nil, false,
+// если подписка закрыта (в том числе брокером из-за медленного клиента).
+func (s *Subscription) Next(ctx context.Context) (*Notification, bool) {
+	if len(s.replay) > 0 {
+		n := s.replay[0]
+		s.replay = s.replay[1:]
+		return n, true
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-s.done:
+			return nil, false
+		case n := <-s.live:
+			if !s.seen.add(n.ID) {
+				continue
+			}
+			return n, true
+		}
+	}
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		delete(s.broker.subs[s.key], s)
+		if len(s.broker.subs[s.key]) == 0 {
+			delete(s.broker.subs, s.key)
+		}
+		s.broker.mu.Unlock()
+		close(s.done)
+	})
+}
+
+func (b *Broker) Name() string {
+	return "stream"
+}
+
+// Deliver - sink для outbox. Никогда не блокируется: клиент, не успевающий
+// читать, отключается и может переподключиться с Last-Event-ID.
+func (b *Broker) Deliver(_ context.Context, ev *events.Event) error {
+	var slow []*Subscription
+	b.mu.RLock()
+	for _, n := range notificationsFor(ev) {
+		for sub := range b.subs[subscriber{tenant: ev.Tenant, userID: n.UserID}] {
+			select {
+			case sub.live <- n:
+			default:
+				slow = append(slow, sub)
+			}
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range slow {
+		log.Printf("[Broker.Deliver] dropping slow subscriber of '%s'", sub.userID)
+		sub.Close()
+	}
+	return nil
+}
+
+// recentIDs - ограниченное множество последних ID: при переполнении вытесняется самый старый
+type recentIDs struct {
+	ring []uint64
+	next int
+	set  map[uint64]struct{}
+}
+
+func newRecentIDs(size int) *recentIDs {
+	return &recentIDs{
+		ring: make([]uint64, 0, size),
+		set:  make(map[uint64]struct{}, size),
+	}
+}
+
+// add запоминает ID и возвращает false, если он уже встречался. ID 0 (событие без
+// номера в outbox) не запоминается и всегда считается новым.
+func (r *recentIDs) add(id uint64) bool {
+	if id == 0 {
+		return true
+	}
+	if _, ok := r.set[id]; ok {
+		return false
+	}
+	if len(r.ring) < cap(r.ring) {
+		r.ring = append(r.ring, id)
+	} else {
+		delete(r.set, r.ring[r.next])
+		r.ring[r.next] = id
+		r.next = (r.next + 1) % len(r.ring)
+	}
+	r.set[id] = struct{}{}
+	return true
+}
@@ -0,0 +1,117 @@
+package stream
+
+import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/app/tenant"
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeRepo отдаёт историю outbox из памяти
+type fakeRepo struct {
+	history []*events.Event
+}
+
+func (f *fakeRepo) since(_ context.Context, _ string, afterID uint64, limit int) ([]*events.Event, error) {
+	var missed []*events.Event
+	for _, ev := range f.history {
+		if ev.Sequence > afterID && len(missed) < limit {
+			missed = append(missed, ev)
+		}
+	}
+	return missed, nil
+}
+
+func assigned(t *testing.T, seq uint64, userID string) *events.Event {
+	t.Helper()
+	ev, err := events.New(events.PRCreated, events.PullRequestData{PullRequestID: "pr", AssignedReviewers: []string{userID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev.Sequence, ev.Tenant = seq, tenant.Default
+	return ev
+}
+
+// received читает уведомления, пока они приходят, и возвращает их ID
+func received(t *testing.T, sub *Subscription) []uint64 {
+	t.Helper()
+	var ids []uint64
+	for {
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		n, ok := sub.Next(ctx)
+		cancel()
+		if !ok {
+			return ids
+		}
+		ids = append(ids, n.ID)
+	}
+}
+
+func TestNextDeliversOutOfOrderOnce(t *testing.T) {
+	b := NewBroker(&fakeRepo{})
+	sub, err := b.Subscribe(t.Context(), "u1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	// relay может доставить событие с меньшим ID позже и повторить уже доставленное
+	for _, seq := range []uint64{2, 1, 2, 3, 1} {
+		if err := b.Deliver(t.Context(), assigned(t, seq, "u1")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := received(t, sub), []uint64{2, 1, 3}; !slices.Equal(got, want) {
+		t.Fatalf("received %v, want %v", got, want)
+	}
+}
+
+func TestNextSkipsReplayedLiveEvents(t *testing.T) {
+	repo := &fakeRepo{history: []*events.Event{assigned(t, 5, "u1"), assigned(t, 6, "u2"), assigned(t, 7, "u1")}}
+	b := NewBroker(repo)
+	sub, err := b.Subscribe(t.Context(), "u1", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	// событие 7 уже досылается из истории, 4 пришло с опозданием
+	for _, ev := range []*events.Event{assigned(t, 7, "u1"), assigned(t, 4, "u1"), assigned(t, 8, "u1")} {
+		if err := b.Deliver(t.Context(), ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := received(t, sub), []uint64{5, 7, 4, 8}; !slices.Equal(got, want) {
+		t.Fatalf("received %v, want %v", got, want)
+	}
+}
+
+func TestRecentIDsIsBounded(t *testing.T) {
+	r := newRecentIDs(3)
+	for _, id := range []uint64{1, 2, 3, 4} {
+		if !r.add(id) {
+			t.Fatalf("%d reported as seen", id)
+		}
+	}
+	if len(r.set) != 3 || r.add(4) || r.add(2) {
+		t.Fatalf("expected only the last 3 IDs to be remembered, got %v", r.ring)
+	}
+	// 1 вытеснен и снова считается новым
+	if !r.add(1) || !r.add(0) || !r.add(0) {
+		t.Fatal("evicted ID and ID 0 must be new")
+	}
+}
@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"avito-tech/internal/app/events"
+	"encoding/json"
+	"time"
+)
+
+const (
+	KindAssigned   = "assigned"
+	KindUnassigned = "unassigned"
+	KindMerged     = "merged"
+)
+
+// Notification - событие для конкретного ревьювера
+type Notification struct {
+	ID              uint64    `json:"id"`
+	Kind            string    `json:"kind"`
+	UserID          string    `json:"user_id"`
+	PullRequestID   string    `json:"pull_request_id"`
+	PullRequestName string    `json:"pull_request_name"`
+	EventType       string    `json:"event_type"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
+
+// notificationsFor раскладывает событие outbox на уведомления затронутых ревьюверов
+func notificationsFor(ev *events.Event) []*Notification {
+	var (
+		data   events.ReviewerReassignedData
+		byUser = map[string]string{}
+	)
+	if err := json.Unmarshal(ev.Data, &data); err != nil {
+		return nil
+	}
+
+	switch ev.Type {
+	case events.PRCreated:
+		for _, userID := range data.AssignedReviewers {
+			byUser[userID] = KindAssigned
+		}
+	case events.PRReviewerReassigned:
+		byUser[data.OldUserID] = KindUnassigned
+		byUser[data.ReplacedBy] = KindAssigned
+	case events.PRMerged:
+		for _, userID := range data.AssignedReviewers {
+			byUser[userID] = KindMerged
+		}
+	case events.PRStatusChanged:
+		var changed events.StatusChangedData
+		if err := json.Unmarshal(ev.Data, &changed); err != nil {
+			return nil
+		}
+		for _, userID := range changed.ReleasedReviewers {
+			byUser[userID] = KindUnassigned
+		}
+		for _, userID := range changed.AssignedReviewers {
+			byUser[userID] = KindAssigned
+		}
+	default:
+		return nil
+	}
+
+	notifications := make([]*Notification, 0, len(byUser))
+	for userID, kind := range byUser {
+		notifications = append(notifications, &Notification{
+			ID:              ev.Sequence,
+			Kind:            kind,
+			UserID:          userID,
+			PullRequestID:   data.PullRequestID,
+			PullRequestName: data.PullRequestName,
+			EventType:       ev.Type,
+			OccurredAt:      ev.OccurredAt,
+		})
+	}
+	return notifications
+}
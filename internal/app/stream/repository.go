@@ -0,0 +1,67 @@
+package stream
+
+import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"log"
+	"time"
+)
+
+type DB interface {
+	Select(ctx context.Context, dest any, query string, args ...any) error
+}
+
+type StreamRepo struct {
+	db DB
+}
+
+func NewStreamRepo(db DB) *StreamRepo {
+	return &StreamRepo{db: db}
+}
+
+type outboxRow struct {
+	ID        uint64    `db:"id"`
+	EventID   string    `db:"event_id"`
+	EventType string    `db:"event_type"`
+	Payload   []byte    `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// since читает из outbox уже опубликованные события арендатора, касающиеся ревьювера, после afterID
+func (s *StreamRepo) since(ctx context.Context, userID string, afterID uint64, limit int) ([]*events.Event, error) {
+	tenantID := tenant.FromContext(ctx)
+	var rows []outboxRow
+	err := s.db.Select(ctx, &rows, `
+		SELECT id, event_id, event_type, payload, created_at
+		FROM outbox
+		WHERE tenant_id = $4
+		  AND id > $1
+		  AND published_at IS NOT NULL
+		  AND event_type IN ('pr.created', 'pr.reviewer_reassigned', 'pr.merged', 'pr.status_changed')
+		  AND (payload->'assigned_reviewers' ? $2
+		       OR payload->'released_reviewers' ? $2
+		       OR payload->>'old_user_id' = $2
+		       OR payload->>'replaced_by' = $2)
+		ORDER BY id
+		LIMIT $3
+	`, afterID, userID, limit, tenantID)
+	if err != nil {
+		log.Printf("[StreamRepo.since] db error fetching events for '%s' after %d: %v", userID, afterID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	result := make([]*events.Event, len(rows))
+	for i, r := range rows {
+		result[i] = &events.Event{
+			Sequence:   r.ID,
+			ID:         r.EventID,
+			Type:       r.EventType,
+			OccurredAt: r.CreatedAt.UTC(),
+			Tenant:     tenantID,
+			Data:       r.Payload,
+		}
+	}
+	return result, nil
+}
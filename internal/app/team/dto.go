@@ -2,21 +2,88 @@ package team
 
 // TeamDTO - DTO для работы с командой (используется в API)
 type TeamDTO struct {
-	TeamName string          `json:"team_name"`
-	Members  []TeamMemberDTO `json:"members"`
+	TeamName       string               `json:"team_name"`
+	ParentTeamName *string              `json:"parent_team_name,omitempty"`
+	MaxOpenReviews *int                 `json:"max_open_reviews,omitempty"`
+	Escalation     *EscalationPolicyDTO `json:"escalation,omitempty"`
+	FallbackTeams  []string             `json:"fallback_teams,omitempty"`
+	Members        []TeamMemberDTO      `json:"members"`
+	Version        uint64               `json:"-"`
+}
+
+// EscalationPolicyDTO - ревьювер, ничего не сделавший за review_sla_minutes с момента назначения,
+// эскалируется действием action. У команды без политики (nil) эскалация выключена.
+type EscalationPolicyDTO struct {
+	ReviewSLAMinutes int    `json:"review_sla_minutes"`
+	Action           string `json:"action"`
+}
+
+func (e *EscalationPolicyDTO) MapToModel() EscalationPolicyEntity {
+	if e == nil {
+		return EscalationPolicyEntity{Action: EscalationRemind}
+	}
+	sla := e.ReviewSLAMinutes
+	return EscalationPolicyEntity{ReviewSLAMinutes: &sla, Action: e.Action}
+}
+
+// EscalationPolicyFromModel возвращает nil для выключенной эскалации
+func EscalationPolicyFromModel(entity *EscalationPolicyEntity) *EscalationPolicyDTO {
+	if entity.ReviewSLAMinutes == nil {
+		return nil
+	}
+	return &EscalationPolicyDTO{ReviewSLAMinutes: *entity.ReviewSLAMinutes, Action: entity.Action}
+}
+
+// TeamNodeDTO - команда и её подкоманды
+type TeamNodeDTO struct {
+	TeamName string         `json:"team_name"`
+	Children []*TeamNodeDTO `json:"children"`
+}
+
+// TeamCountersDTO - счётчики команды: участники, OPEN PR их авторства, их назначения ревьюверами
+// в OPEN PR и эскалации, записанные на команду
+type TeamCountersDTO struct {
+	Members          int `json:"members"`
+	ActiveMembers    int `json:"active_members"`
+	OpenPullRequests int `json:"open_pull_requests"`
+	OpenReviews      int `json:"open_reviews"`
+	Escalations      int `json:"escalations"`
+}
+
+func (c *TeamCountersDTO) add(other TeamCountersDTO) {
+	c.Members += other.Members
+	c.ActiveMembers += other.ActiveMembers
+	c.OpenPullRequests += other.OpenPullRequests
+	c.OpenReviews += other.OpenReviews
+	c.Escalations += other.Escalations
+}
+
+type TeamStatsDTO struct {
+	TeamName string `json:"team_name"`
+	// Depth - глубина команды относительно корня выборки, 0 - сам корень
+	Depth int `json:"depth"`
+	TeamCountersDTO
+}
+
+// DepartmentStatsDTO - счётчики каждой команды поддерева и их сумма
+type DepartmentStatsDTO struct {
+	Total TeamCountersDTO `json:"total"`
+	Teams []TeamStatsDTO  `json:"teams"`
 }
 
 type TeamMemberDTO struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
-	IsActive bool   `json:"is_active"`
+	UserID         string `json:"user_id"`
+	Username       string `json:"username"`
+	IsActive       bool   `json:"is_active"`
+	MaxOpenReviews *int   `json:"max_open_reviews,omitempty"`
 }
 
 func (tm *TeamMemberDTO) ToEntity() TeamMemberEntity {
 	return TeamMemberEntity{
-		UserID:   tm.UserID,
-		Username: tm.Username,
-		IsActive: tm.IsActive,
+		UserID:         tm.UserID,
+		Username:       tm.Username,
+		IsActive:       tm.IsActive,
+		MaxOpenReviews: tm.MaxOpenReviews,
 	}
 }
 
@@ -24,6 +91,7 @@ func (tm *TeamMemberDTO) FromEntity(entity *TeamMemberEntity) {
 	tm.UserID = entity.UserID
 	tm.Username = entity.Username
 	tm.IsActive = entity.IsActive
+	tm.MaxOpenReviews = entity.MaxOpenReviews
 }
 
 func ToTeamMeberEntities(tms []TeamMemberDTO) []TeamMemberEntity {
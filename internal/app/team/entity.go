@@ -3,10 +3,43 @@ package team
 type TeamEntity struct {
 	ID       uint64 `db:"id"`
 	TeamName string `db:"team_name"`
+	Version  uint64 `db:"version"`
+	// MaxOpenReviews - лимит открытых ревью участника по умолчанию, nil - без ограничения
+	MaxOpenReviews *int `db:"max_open_reviews"`
+	EscalationPolicyEntity
+	// FallbackTeams - запасные команды в порядке приоритета
+	FallbackTeams []string `db:"-"`
+	// ParentTeamName - родительская команда (отдел), nil - команда в корне организации
+	ParentTeamName *string `db:"parent_team_name"`
+}
+
+// TeamNodeEntity - команда в иерархии организации
+type TeamNodeEntity struct {
+	TeamName       string  `db:"team_name"`
+	ParentTeamName *string `db:"parent_team_name"`
+}
+
+// TeamStatsEntity - счётчики команды поддерева; Depth - глубина относительно корня выборки
+type TeamStatsEntity struct {
+	TeamName         string `db:"team_name"`
+	Depth            int    `db:"depth"`
+	Members          int    `db:"members"`
+	ActiveMembers    int    `db:"active_members"`
+	OpenPullRequests int    `db:"open_pull_requests"`
+	OpenReviews      int    `db:"open_reviews"`
+	Escalations      int    `db:"escalations"`
+}
+
+// EscalationPolicyEntity - SLA ревью и действие при его нарушении; ReviewSLAMinutes nil - эскалация выключена
+type EscalationPolicyEntity struct {
+	ReviewSLAMinutes *int   `db:"review_sla_minutes"`
+	Action           string `db:"escalation_action"`
 }
 
 type TeamMemberEntity struct {
 	UserID   string `db:"user_id"`
 	Username string `db:"username"`
 	IsActive bool   `db:"is_active"`
+	// MaxOpenReviews - личный лимит, перекрывает командный
+	MaxOpenReviews *int `db:"max_open_reviews"`
 }
@@ -0,0 +1,270 @@
+package team
+
+import (
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/memory"
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// TeamMemoryRepo - реализация Repo поверх memory.Store с той же семантикой, что у TeamRepo
+type TeamMemoryRepo struct {
+	store *memory.Store
+}
+
+func NewTeamMemoryRepo(store *memory.Store) *TeamMemoryRepo {
+	return &TeamMemoryRepo{
+		store: store,
+	}
+}
+
+func (t *TeamMemoryRepo) create(ctx context.Context, teamName string, maxOpenReviews *int, members []*TeamMemberEntity) error {
+	err := t.store.Write(ctx, func(d *memory.Data) error {
+		if _, ok := d.Teams[teamName]; ok {
+			log.Printf("[TeamMemoryRepo.create] team with this name already exists: '%s'", teamName)
+			return apperrors.ErrTeamExists
+		}
+		created := &memory.Team{ID: d.NextTeamID(), Name: teamName, Version: 1, MaxOpenReviews: maxOpenReviews, EscalationAction: EscalationRemind}
+		d.Teams[teamName] = created
+
+		// участники, переезжающие из других команд, меняют и их состав
+		bumped := map[uint64]bool{}
+		for _, member := range members {
+			if u, ok := d.Users[member.UserID]; ok && !bumped[u.TeamID] {
+				if old := d.TeamByID(u.TeamID); old != nil {
+					old.Version++
+				}
+				bumped[u.TeamID] = true
+			}
+			stored := &memory.User{
+				ID:             member.UserID,
+				Username:       member.Username,
+				TeamID:         created.ID,
+				IsActive:       member.IsActive,
+				MaxOpenReviews: member.MaxOpenReviews,
+			}
+			// рабочие часы переезжают вместе с пользователем, как в upsert TeamRepo.create
+			if u, ok := d.Users[member.UserID]; ok {
+				stored.WorkingHours = u.WorkingHours
+			}
+			d.Users[member.UserID] = stored
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[TeamMemoryRepo.create] team '%s' created/updated successfully with %d members", teamName, len(members))
+	return nil
+}
+
+func (t *TeamMemoryRepo) getByName(ctx context.Context, teamName string) (*TeamEntity, []TeamMemberEntity, error) {
+	var (
+		entity  TeamEntity
+		members []TeamMemberEntity
+	)
+	err := t.store.Read(ctx, func(d *memory.Data) error {
+		team, ok := d.Teams[teamName]
+		if !ok {
+			log.Printf("[TeamMemoryRepo.getByName] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		entity = TeamEntity{
+			ID:             team.ID,
+			TeamName:       team.Name,
+			Version:        team.Version,
+			MaxOpenReviews: team.MaxOpenReviews,
+			EscalationPolicyEntity: EscalationPolicyEntity{
+				ReviewSLAMinutes: team.ReviewSLAMinutes,
+				Action:           team.EscalationAction,
+			},
+		}
+		for _, id := range team.FallbackTeamIDs {
+			entity.FallbackTeams = append(entity.FallbackTeams, d.TeamByID(id).Name)
+		}
+		if team.ParentID != nil {
+			parent := d.TeamByID(*team.ParentID).Name
+			entity.ParentTeamName = &parent
+		}
+		for _, u := range d.TeamMembers(team.ID) {
+			members = append(members, TeamMemberEntity{UserID: u.ID, Username: u.Username, IsActive: u.IsActive, MaxOpenReviews: u.MaxOpenReviews})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("[TeamMemoryRepo.getByName] fetched team '%s' with %d members", teamName, len(members))
+	return &entity, members, nil
+}
+
+func (t *TeamMemoryRepo) setMaxOpenReviews(ctx context.Context, teamName string, limit *int) error {
+	err := t.store.Write(ctx, func(d *memory.Data) error {
+		team, ok := d.Teams[teamName]
+		if !ok {
+			log.Printf("[TeamMemoryRepo.setMaxOpenReviews] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		team.MaxOpenReviews = limit
+		team.Version++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[TeamMemoryRepo.setMaxOpenReviews] updated max_open_reviews of team '%s'", teamName)
+	return nil
+}
+
+func (t *TeamMemoryRepo) setEscalationPolicy(ctx context.Context, teamName string, policy EscalationPolicyEntity) error {
+	err := t.store.Write(ctx, func(d *memory.Data) error {
+		team, ok := d.Teams[teamName]
+		if !ok {
+			log.Printf("[TeamMemoryRepo.setEscalationPolicy] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		team.ReviewSLAMinutes = policy.ReviewSLAMinutes
+		team.EscalationAction = policy.Action
+		team.Version++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[TeamMemoryRepo.setEscalationPolicy] updated escalation policy of team '%s'", teamName)
+	return nil
+}
+
+func (t *TeamMemoryRepo) setFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error {
+	err := t.store.Write(ctx, func(d *memory.Data) error {
+		team, ok := d.Teams[teamName]
+		if !ok {
+			log.Printf("[TeamMemoryRepo.setFallbackTeams] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		ids := make([]uint64, 0, len(fallbackTeams))
+		for _, name := range fallbackTeams {
+			fallback, ok := d.Teams[name]
+			if !ok {
+				log.Printf("[TeamMemoryRepo.setFallbackTeams] fallback team not found: '%s'", name)
+				return fmt.Errorf("%w: fallback team '%s'", apperrors.ErrNotFound, name)
+			}
+			ids = append(ids, fallback.ID)
+		}
+		team.FallbackTeamIDs = ids
+		team.Version++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[TeamMemoryRepo.setFallbackTeams] team '%s' now falls back to %v", teamName, fallbackTeams)
+	return nil
+}
+
+func (t *TeamMemoryRepo) setParent(ctx context.Context, teamName string, parentTeamName *string) error {
+	err := t.store.Write(ctx, func(d *memory.Data) error {
+		team, ok := d.Teams[teamName]
+		if !ok {
+			log.Printf("[TeamMemoryRepo.setParent] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		var parentID *uint64
+		if parentTeamName != nil {
+			parent, ok := d.Teams[*parentTeamName]
+			if !ok {
+				log.Printf("[TeamMemoryRepo.setParent] parent team not found: '%s'", *parentTeamName)
+				return fmt.Errorf("%w: parent team '%s'", apperrors.ErrNotFound, *parentTeamName)
+			}
+			if slices.ContainsFunc(d.Ancestors(parent.ID), func(a *memory.Team) bool { return a.ID == team.ID }) {
+				log.Printf("[TeamMemoryRepo.setParent] team '%s' is inside the subtree of '%s'", *parentTeamName, teamName)
+				return fmt.Errorf("%w: team '%s' cannot be moved under its own subteam '%s'", apperrors.ErrBadRequest, teamName, *parentTeamName)
+			}
+			parentID = &parent.ID
+		}
+		team.ParentID = parentID
+		team.Version++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if parentTeamName == nil {
+		log.Printf("[TeamMemoryRepo.setParent] moved team '%s' to the organization root", teamName)
+	} else {
+		log.Printf("[TeamMemoryRepo.setParent] moved team '%s' under '%s'", teamName, *parentTeamName)
+	}
+	return nil
+}
+
+func (t *TeamMemoryRepo) hierarchy(ctx context.Context) ([]TeamNodeEntity, error) {
+	var entities []TeamNodeEntity
+	err := t.store.Read(ctx, func(d *memory.Data) error {
+		for _, team := range d.Teams {
+			e := TeamNodeEntity{TeamName: team.Name}
+			if team.ParentID != nil {
+				parent := d.TeamByID(*team.ParentID).Name
+				e.ParentTeamName = &parent
+			}
+			entities = append(entities, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[TeamMemoryRepo.hierarchy] fetched %d teams", len(entities))
+	return entities, nil
+}
+
+func (t *TeamMemoryRepo) stats(ctx context.Context, teamName string, subtree bool) ([]TeamStatsEntity, error) {
+	var entities []TeamStatsEntity
+	err := t.store.Read(ctx, func(d *memory.Data) error {
+		root, ok := d.Teams[teamName]
+		if !ok {
+			log.Printf("[TeamMemoryRepo.stats] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		for id, depth := range d.Subtree(root.ID) {
+			if depth > 0 && !subtree {
+				continue
+			}
+			e := TeamStatsEntity{TeamName: d.TeamByID(id).Name, Depth: depth}
+			for _, u := range d.TeamMembers(id) {
+				e.Members++
+				if u.IsActive {
+					e.ActiveMembers++
+				}
+				e.OpenReviews += d.OpenReviews(u.ID)
+			}
+			for _, pr := range d.PullRequests {
+				if author, ok := d.Users[pr.AuthorID]; ok && author.TeamID == id && pr.Status == "OPEN" {
+					e.OpenPullRequests++
+				}
+			}
+			for _, esc := range d.Escalations {
+				if esc.TeamID == id {
+					e.Escalations++
+				}
+			}
+			entities = append(entities, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entities, func(x, y TeamStatsEntity) int {
+		return cmp.Or(cmp.Compare(x.Depth, y.Depth), strings.Compare(x.TeamName, y.TeamName))
+	})
+	log.Printf("[TeamMemoryRepo.stats] fetched stats of %d teams under '%s'", len(entities), teamName)
+	return entities, nil
+}
+
+func (t *TeamMemoryRepo) tenants(ctx context.Context) ([]string, error) {
+	return t.store.Tenants(), nil
+}
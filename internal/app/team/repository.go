@@ -1,21 +1,23 @@
 package team
 
 import (
+	"avito-tech/internal/app/tenant"
 	"avito-tech/internal/apperrors"
 	"context"
 	"errors"
+	"fmt"
 	"log"
 
 	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4"
-	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type DB interface {
-	GetPool(_ context.Context) *pgxpool.Pool
+	Begin(ctx context.Context) (pgx.Tx, error)
 	Get(ctx context.Context, dest any, query string, args ...any) error
 	Select(ctx context.Context, dest any, query string, args ...any) error
 	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, query string, args ...any) (pgx.Rows, error)
 	ExecQueryRow(ctx context.Context, query string, args ...any) pgx.Row
 }
 
@@ -29,8 +31,9 @@ func NewTeamRepo(db DB) *TeamRepo {
 	}
 }
 
-func (t *TeamRepo) create(ctx context.Context, teamName string, members []*TeamMemberEntity) error {
-	tx, err := t.db.GetPool(ctx).BeginTx(ctx, pgx.TxOptions{})
+func (t *TeamRepo) create(ctx context.Context, teamName string, maxOpenReviews *int, members []*TeamMemberEntity) error {
+	tenantID := tenant.FromContext(ctx)
+	tx, err := t.db.Begin(ctx)
 	if err != nil {
 		log.Printf("[TeamRepo.create] failed to begin transaction: %v", err)
 		return apperrors.ErrDB
@@ -45,8 +48,8 @@ func (t *TeamRepo) create(ctx context.Context, teamName string, members []*TeamM
 
 
 	_, err = tx.Exec(ctx, `
-		INSERT INTO team (team_name) VALUES ($1)
-	`, teamName)
+		INSERT INTO team (tenant_id, team_name, max_open_reviews) VALUES ($1, $2, $3)
+	`, tenantID, teamName, maxOpenReviews)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -57,14 +60,39 @@ func (t *TeamRepo) create(ctx context.Context, teamName string, members []*TeamM
 		return apperrors.ErrDB
 	}
 
+	_, err = tx.Exec(ctx, `
+		INSERT INTO team_closure (ancestor_id, team_id, depth)
+		SELECT id, id, 0 FROM team WHERE tenant_id = $1 AND team_name = $2
+	`, tenantID, teamName)
+	if err != nil {
+		log.Printf("[TeamRepo.create] error inserting team '%s' into hierarchy: %v", teamName, err)
+		return apperrors.ErrDB
+	}
+
+
+	// участники, переезжающие из других команд, меняют и их состав
+	userIDs := make([]string, len(members))
+	for i, member := range members {
+		userIDs[i] = member.UserID
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE team SET version = version + 1
+		WHERE tenant_id = $1 AND team_name <> $2
+		  AND id IN (SELECT team_id FROM users WHERE tenant_id = $1 AND user_id = ANY($3))
+	`, tenantID, teamName, userIDs)
+	if err != nil {
+		log.Printf("[TeamRepo.create] failed to bump versions of teams losing members: %v", err)
+		return apperrors.ErrDB
+	}
 
 	for _, member := range members {
 		_, err := tx.Exec(ctx, `
-			INSERT INTO users (user_id, username, team_id, is_active)
-			VALUES ($1, $2, (SELECT id FROM team WHERE team_name=$3), $4)
-			ON CONFLICT (user_id) DO UPDATE
-			SET username=EXCLUDED.username, team_id=EXCLUDED.team_id, is_active=EXCLUDED.is_active
-		`, member.UserID, member.Username, teamName, member.IsActive)
+			INSERT INTO users (tenant_id, user_id, username, team_id, is_active, max_open_reviews)
+			VALUES ($1, $2, $3, (SELECT id FROM team WHERE tenant_id=$1 AND team_name=$4), $5, $6)
+			ON CONFLICT (tenant_id, user_id) DO UPDATE
+			SET username=EXCLUDED.username, team_id=EXCLUDED.team_id, is_active=EXCLUDED.is_active,
+			    max_open_reviews=EXCLUDED.max_open_reviews
+		`, tenantID, member.UserID, member.Username, teamName, member.IsActive, member.MaxOpenReviews)
 		if err != nil {
 			log.Printf("[TeamRepo.create] failed to insert/update user %s: %v", member.UserID, err)
 			return apperrors.ErrDB
@@ -77,7 +105,13 @@ func (t *TeamRepo) create(ctx context.Context, teamName string, members []*TeamM
 
 func (t *TeamRepo) getByName(ctx context.Context, teamName string) (*TeamEntity, []TeamMemberEntity, error) {
 	var entity TeamEntity
-	err := t.db.Get(ctx, &entity, "SELECT id, team_name FROM team WHERE team_name=$1", teamName)
+	err := t.db.Get(ctx, &entity, `
+		SELECT t.id, t.team_name, t.version, t.max_open_reviews, t.review_sla_minutes, t.escalation_action,
+		       p.team_name AS parent_team_name
+		FROM team t
+		LEFT JOIN team p ON p.id = t.parent_id
+		WHERE t.tenant_id=$1 AND t.team_name=$2
+	`, tenant.FromContext(ctx), teamName)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			log.Printf("[TeamRepo.getByName] team not found: '%s', %v", teamName, err)
@@ -87,8 +121,8 @@ func (t *TeamRepo) getByName(ctx context.Context, teamName string) (*TeamEntity,
 		return nil, nil, apperrors.ErrDB
 	}
 
-	rows, err := t.db.GetPool(ctx).Query(ctx, `
-        SELECT user_id, username, is_active
+	rows, err := t.db.Query(ctx, `
+        SELECT user_id, username, is_active, max_open_reviews
         FROM users
         WHERE team_id = $1
     `, entity.ID)
@@ -101,13 +135,279 @@ func (t *TeamRepo) getByName(ctx context.Context, teamName string) (*TeamEntity,
 	var members []TeamMemberEntity
 	for rows.Next() {
 		var m TeamMemberEntity
-		if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive); err != nil {
+		if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive, &m.MaxOpenReviews); err != nil {
 			log.Printf("[TeamRepo.getByName] failed to scan member for team '%s': %v", teamName, err)
 			return nil, nil, apperrors.ErrDB
 		}
 		members = append(members, m)
 	}
 
+	err = t.db.Select(ctx, &entity.FallbackTeams, `
+		SELECT ft.team_name
+		FROM team_fallback f
+		JOIN team ft ON ft.id = f.fallback_team_id
+		WHERE f.team_id = $1
+		ORDER BY f.priority
+	`, entity.ID)
+	if err != nil {
+		log.Printf("[TeamRepo.getByName] DB error while fetching fallback teams of team '%s': %v", teamName, err)
+		return nil, nil, apperrors.ErrDB
+	}
+
 	log.Printf("[TeamRepo.getByName] fetched team '%s' with %d members", teamName, len(members))
 	return &entity, members, nil
 }
+
+func (t *TeamRepo) setMaxOpenReviews(ctx context.Context, teamName string, limit *int) error {
+	tag, err := t.db.Exec(ctx, `
+		UPDATE team SET max_open_reviews = $3, version = version + 1
+		WHERE tenant_id = $1 AND team_name = $2
+	`, tenant.FromContext(ctx), teamName, limit)
+	if err != nil {
+		log.Printf("[TeamRepo.setMaxOpenReviews] db error updating team '%s': %v", teamName, err)
+		return apperrors.ErrDB
+	}
+	if tag.RowsAffected() == 0 {
+		log.Printf("[TeamRepo.setMaxOpenReviews] team not found: '%s'", teamName)
+		return apperrors.ErrNotFound
+	}
+	log.Printf("[TeamRepo.setMaxOpenReviews] updated max_open_reviews of team '%s'", teamName)
+	return nil
+}
+
+func (t *TeamRepo) setEscalationPolicy(ctx context.Context, teamName string, policy EscalationPolicyEntity) error {
+	tag, err := t.db.Exec(ctx, `
+		UPDATE team SET review_sla_minutes = $3, escalation_action = $4, version = version + 1
+		WHERE tenant_id = $1 AND team_name = $2
+	`, tenant.FromContext(ctx), teamName, policy.ReviewSLAMinutes, policy.Action)
+	if err != nil {
+		log.Printf("[TeamRepo.setEscalationPolicy] db error updating team '%s': %v", teamName, err)
+		return apperrors.ErrDB
+	}
+	if tag.RowsAffected() == 0 {
+		log.Printf("[TeamRepo.setEscalationPolicy] team not found: '%s'", teamName)
+		return apperrors.ErrNotFound
+	}
+	log.Printf("[TeamRepo.setEscalationPolicy] updated escalation policy of team '%s'", teamName)
+	return nil
+}
+
+func (t *TeamRepo) setFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error {
+	tenantID := tenant.FromContext(ctx)
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		log.Printf("[TeamRepo.setFallbackTeams] failed to begin transaction: %v", err)
+		return apperrors.ErrDB
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			_ = tx.Commit(ctx)
+		}
+	}()
+
+	var teamID uint64
+	err = tx.QueryRow(ctx, `
+		UPDATE team SET version = version + 1
+		WHERE tenant_id = $1 AND team_name = $2
+		RETURNING id
+	`, tenantID, teamName).Scan(&teamID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[TeamRepo.setFallbackTeams] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		log.Printf("[TeamRepo.setFallbackTeams] db error updating team '%s': %v", teamName, err)
+		return apperrors.ErrDB
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM team_fallback WHERE team_id = $1", teamID)
+	if err != nil {
+		log.Printf("[TeamRepo.setFallbackTeams] db error clearing fallback teams of team '%s': %v", teamName, err)
+		return apperrors.ErrDB
+	}
+
+	for i, name := range fallbackTeams {
+		tag, execErr := tx.Exec(ctx, `
+			INSERT INTO team_fallback (team_id, fallback_team_id, priority)
+			SELECT $1, id, $3 FROM team WHERE tenant_id = $4 AND team_name = $2
+		`, teamID, name, i+1, tenantID)
+		if execErr != nil {
+			err = execErr
+			log.Printf("[TeamRepo.setFallbackTeams] db error adding fallback team '%s' to team '%s': %v", name, teamName, err)
+			return apperrors.ErrDB
+		}
+		if tag.RowsAffected() == 0 {
+			log.Printf("[TeamRepo.setFallbackTeams] fallback team not found: '%s'", name)
+			err = fmt.Errorf("%w: fallback team '%s'", apperrors.ErrNotFound, name)
+			return err
+		}
+	}
+
+	log.Printf("[TeamRepo.setFallbackTeams] team '%s' now falls back to %v", teamName, fallbackTeams)
+	return nil
+}
+
+func (t *TeamRepo) setParent(ctx context.Context, teamName string, parentTeamName *string) error {
+	tenantID := tenant.FromContext(ctx)
+	tx, err := t.db.Begin(ctx)
+	if err != nil {
+		log.Printf("[TeamRepo.setParent] failed to begin transaction: %v", err)
+		return apperrors.ErrDB
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			_ = tx.Commit(ctx)
+		}
+	}()
+
+	// переносы сериализуются: два встречных переноса иначе могли бы образовать цикл
+	_, err = tx.Exec(ctx, "LOCK TABLE team_closure IN SHARE ROW EXCLUSIVE MODE")
+	if err != nil {
+		log.Printf("[TeamRepo.setParent] failed to lock team hierarchy: %v", err)
+		return apperrors.ErrDB
+	}
+
+	var teamID uint64
+	err = tx.QueryRow(ctx, "SELECT id FROM team WHERE tenant_id = $1 AND team_name = $2", tenantID, teamName).Scan(&teamID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[TeamRepo.setParent] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		log.Printf("[TeamRepo.setParent] db error fetching team '%s': %v", teamName, err)
+		return apperrors.ErrDB
+	}
+
+	var parentID *uint64
+	if parentTeamName != nil {
+		var id uint64
+		var inSubtree bool
+		err = tx.QueryRow(ctx, `
+			SELECT p.id, EXISTS(SELECT 1 FROM team_closure c WHERE c.ancestor_id = $2 AND c.team_id = p.id)
+			FROM team p
+			WHERE p.tenant_id = $3 AND p.team_name = $1
+		`, *parentTeamName, teamID, tenantID).Scan(&id, &inSubtree)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("[TeamRepo.setParent] parent team not found: '%s'", *parentTeamName)
+				err = fmt.Errorf("%w: parent team '%s'", apperrors.ErrNotFound, *parentTeamName)
+				return err
+			}
+			log.Printf("[TeamRepo.setParent] db error fetching parent team '%s': %v", *parentTeamName, err)
+			return apperrors.ErrDB
+		}
+		if inSubtree {
+			log.Printf("[TeamRepo.setParent] team '%s' is inside the subtree of '%s'", *parentTeamName, teamName)
+			err = fmt.Errorf("%w: team '%s' cannot be moved under its own subteam '%s'", apperrors.ErrBadRequest, teamName, *parentTeamName)
+			return err
+		}
+		parentID = &id
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE team SET parent_id = $2, version = version + 1 WHERE id = $1", teamID, parentID)
+	if err != nil {
+		log.Printf("[TeamRepo.setParent] db error updating team '%s': %v", teamName, err)
+		return apperrors.ErrDB
+	}
+
+	// поддерево отрывается от прежних предков и подвешивается к предкам нового родителя
+	_, err = tx.Exec(ctx, `
+		DELETE FROM team_closure
+		WHERE team_id IN (SELECT team_id FROM team_closure WHERE ancestor_id = $1)
+		  AND ancestor_id NOT IN (SELECT team_id FROM team_closure WHERE ancestor_id = $1)
+	`, teamID)
+	if err != nil {
+		log.Printf("[TeamRepo.setParent] db error detaching team '%s': %v", teamName, err)
+		return apperrors.ErrDB
+	}
+	if parentID != nil {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO team_closure (ancestor_id, team_id, depth)
+			SELECT up.ancestor_id, down.team_id, up.depth + down.depth + 1
+			FROM team_closure up, team_closure down
+			WHERE up.team_id = $1 AND down.ancestor_id = $2
+		`, *parentID, teamID)
+		if err != nil {
+			log.Printf("[TeamRepo.setParent] db error attaching team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+	}
+
+	if parentTeamName == nil {
+		log.Printf("[TeamRepo.setParent] moved team '%s' to the organization root", teamName)
+	} else {
+		log.Printf("[TeamRepo.setParent] moved team '%s' under '%s'", teamName, *parentTeamName)
+	}
+	return nil
+}
+
+func (t *TeamRepo) hierarchy(ctx context.Context) ([]TeamNodeEntity, error) {
+	var entities []TeamNodeEntity
+	err := t.db.Select(ctx, &entities, `
+		SELECT t.team_name, p.team_name AS parent_team_name
+		FROM team t
+		LEFT JOIN team p ON p.id = t.parent_id
+		WHERE t.tenant_id = $1
+	`, tenant.FromContext(ctx))
+	if err != nil {
+		log.Printf("[TeamRepo.hierarchy] db error fetching team hierarchy: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[TeamRepo.hierarchy] fetched %d teams", len(entities))
+	return entities, nil
+}
+
+func (t *TeamRepo) stats(ctx context.Context, teamName string, subtree bool) ([]TeamStatsEntity, error) {
+	var entities []TeamStatsEntity
+	err := t.db.Select(ctx, &entities, `
+		SELECT
+			t.team_name,
+			c.depth,
+			(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.tenant_id AND u.team_id = t.id) AS members,
+			(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.tenant_id AND u.team_id = t.id AND u.is_active) AS active_members,
+			(
+				SELECT COUNT(*)
+				FROM pull_request pr
+				JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+				WHERE pr.tenant_id = t.tenant_id AND u.team_id = t.id AND pr.status = 'OPEN'
+			) AS open_pull_requests,
+			(
+				SELECT COUNT(*)
+				FROM pull_request_reviewer prr
+				JOIN pull_request pr ON pr.tenant_id = prr.tenant_id AND pr.pull_request_id = prr.pull_request_id
+				JOIN users u ON u.tenant_id = prr.tenant_id AND u.user_id = prr.user_id
+				WHERE prr.tenant_id = t.tenant_id AND u.team_id = t.id AND pr.status = 'OPEN'
+			) AS open_reviews,
+			(SELECT COUNT(*) FROM review_escalation e WHERE e.team_id = t.id) AS escalations
+		FROM team root
+		JOIN team_closure c ON c.ancestor_id = root.id AND (c.depth = 0 OR $3)
+		JOIN team t ON t.id = c.team_id
+		WHERE root.tenant_id = $1 AND root.team_name = $2
+		ORDER BY c.depth, t.team_name
+	`, tenant.FromContext(ctx), teamName, subtree)
+	if err != nil {
+		log.Printf("[TeamRepo.stats] db error fetching stats of team '%s': %v", teamName, err)
+		return nil, apperrors.ErrDB
+	}
+	// строка глубины 0 есть у любой команды, пустой результат - команды нет
+	if len(entities) == 0 {
+		log.Printf("[TeamRepo.stats] team not found: '%s'", teamName)
+		return nil, apperrors.ErrNotFound
+	}
+	log.Printf("[TeamRepo.stats] fetched stats of %d teams under '%s'", len(entities), teamName)
+	return entities, nil
+}
+
+func (t *TeamRepo) tenants(ctx context.Context) ([]string, error) {
+	var tenants []string
+	err := t.db.Select(ctx, &tenants, "SELECT DISTINCT tenant_id FROM team ORDER BY tenant_id")
+	if err != nil {
+		log.Printf("[TeamRepo.tenants] db error fetching tenants: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	return tenants, nil
+}
@@ -0,0 +1,432 @@
+package team
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/sqlite"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// TeamSQLiteRepo - реализация Repo поверх встроенного SQLite с той же семантикой, что у TeamRepo
+type TeamSQLiteRepo struct {
+	db *sqlite.DB
+}
+
+func NewTeamSQLiteRepo(db *sqlite.DB) *TeamSQLiteRepo {
+	return &TeamSQLiteRepo{
+		db: db,
+	}
+}
+
+func (t *TeamSQLiteRepo) create(ctx context.Context, teamName string, maxOpenReviews *int, members []*TeamMemberEntity) error {
+	tenantID := tenant.FromContext(ctx)
+	err := t.db.Write(ctx, func(q sqlite.Querier) error {
+		_, err := q.ExecContext(ctx, "INSERT INTO team (tenant_id, team_name, max_open_reviews) VALUES (?, ?, ?)", tenantID, teamName, maxOpenReviews)
+		if err != nil {
+			if sqlite.IsUniqueViolation(err) {
+				log.Printf("[TeamSQLiteRepo.create] team with this name already exists: %v", err)
+				return apperrors.ErrTeamExists
+			}
+			log.Printf("[TeamSQLiteRepo.create] error inserting team into DB: %v", err)
+			return apperrors.ErrDB
+		}
+
+		_, err = q.ExecContext(ctx, `
+			INSERT INTO team_closure (ancestor_id, team_id, depth)
+			SELECT id, id, 0 FROM team WHERE tenant_id = ? AND team_name = ?
+		`, tenantID, teamName)
+		if err != nil {
+			log.Printf("[TeamSQLiteRepo.create] error inserting team '%s' into hierarchy: %v", teamName, err)
+			return apperrors.ErrDB
+		}
+
+		for _, member := range members {
+			// участник, переезжающий из другой команды, меняет и её состав
+			_, err = q.ExecContext(ctx, `
+				UPDATE team SET version = version + 1
+				WHERE tenant_id = ? AND team_name <> ?
+				  AND id = (SELECT team_id FROM users WHERE tenant_id = ? AND user_id = ?)
+			`, tenantID, teamName, tenantID, member.UserID)
+			if err != nil {
+				log.Printf("[TeamSQLiteRepo.create] failed to bump version of previous team of %s: %v", member.UserID, err)
+				return apperrors.ErrDB
+			}
+
+			_, err = q.ExecContext(ctx, `
+				INSERT INTO users (tenant_id, user_id, username, team_id, is_active, max_open_reviews)
+				VALUES (?, ?, ?, (SELECT id FROM team WHERE tenant_id = ? AND team_name = ?), ?, ?)
+				ON CONFLICT (tenant_id, user_id) DO UPDATE
+				SET username = excluded.username, team_id = excluded.team_id, is_active = excluded.is_active,
+				    max_open_reviews = excluded.max_open_reviews
+			`, tenantID, member.UserID, member.Username, tenantID, teamName, member.IsActive, member.MaxOpenReviews)
+			if err != nil {
+				log.Printf("[TeamSQLiteRepo.create] failed to insert/update user %s: %v", member.UserID, err)
+				return apperrors.ErrDB
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[TeamSQLiteRepo.create] team '%s' created/updated successfully with %d members", teamName, len(members))
+	return nil
+}
+
+func (t *TeamSQLiteRepo) getByName(ctx context.Context, teamName string) (*TeamEntity, []TeamMemberEntity, error) {
+	var (
+		entity  TeamEntity
+		members []TeamMemberEntity
+	)
+	err := t.db.Read(ctx, func(q sqlite.Querier) error {
+		err := q.QueryRowContext(ctx, `
+			SELECT t.id, t.team_name, t.version, t.max_open_reviews, t.review_sla_minutes, t.escalation_action, p.team_name
+			FROM team t
+			LEFT JOIN team p ON p.id = t.parent_id
+			WHERE t.tenant_id = ? AND t.team_name = ?
+		`, tenant.FromContext(ctx), teamName).Scan(&entity.ID, &entity.TeamName, &entity.Version, &entity.MaxOpenReviews, &entity.ReviewSLAMinutes, &entity.Action, &entity.ParentTeamName)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[TeamSQLiteRepo.getByName] team not found: '%s'", teamName)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[TeamSQLiteRepo.getByName] DB error while fetching team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+
+		rows, err := q.QueryContext(ctx, "SELECT user_id, username, is_active, max_open_reviews FROM users WHERE team_id = ? ORDER BY user_id", entity.ID)
+		if err != nil {
+			log.Printf("[TeamSQLiteRepo.getByName] DB error while fetching members for team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var m TeamMemberEntity
+			if err := rows.Scan(&m.UserID, &m.Username, &m.IsActive, &m.MaxOpenReviews); err != nil {
+				log.Printf("[TeamSQLiteRepo.getByName] failed to scan member for team '%s': %v", teamName, err)
+				return apperrors.ErrDB
+			}
+			members = append(members, m)
+		}
+
+		fallbackRows, err := q.QueryContext(ctx, `
+			SELECT ft.team_name
+			FROM team_fallback f
+			JOIN team ft ON ft.id = f.fallback_team_id
+			WHERE f.team_id = ?
+			ORDER BY f.priority
+		`, entity.ID)
+		if err != nil {
+			log.Printf("[TeamSQLiteRepo.getByName] DB error while fetching fallback teams of team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		defer fallbackRows.Close()
+
+		for fallbackRows.Next() {
+			var name string
+			if err := fallbackRows.Scan(&name); err != nil {
+				log.Printf("[TeamSQLiteRepo.getByName] failed to scan fallback team of team '%s': %v", teamName, err)
+				return apperrors.ErrDB
+			}
+			entity.FallbackTeams = append(entity.FallbackTeams, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	log.Printf("[TeamSQLiteRepo.getByName] fetched team '%s' with %d members", teamName, len(members))
+	return &entity, members, nil
+}
+
+func (t *TeamSQLiteRepo) setMaxOpenReviews(ctx context.Context, teamName string, limit *int) error {
+	err := t.db.Write(ctx, func(q sqlite.Querier) error {
+		res, err := q.ExecContext(ctx, "UPDATE team SET max_open_reviews = ?, version = version + 1 WHERE tenant_id = ? AND team_name = ?", limit, tenant.FromContext(ctx), teamName)
+		if err != nil {
+			log.Printf("[TeamSQLiteRepo.setMaxOpenReviews] db error updating team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			log.Printf("[TeamSQLiteRepo.setMaxOpenReviews] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[TeamSQLiteRepo.setMaxOpenReviews] updated max_open_reviews of team '%s'", teamName)
+	return nil
+}
+
+func (t *TeamSQLiteRepo) setEscalationPolicy(ctx context.Context, teamName string, policy EscalationPolicyEntity) error {
+	err := t.db.Write(ctx, func(q sqlite.Querier) error {
+		res, err := q.ExecContext(ctx, `
+			UPDATE team SET review_sla_minutes = ?, escalation_action = ?, version = version + 1
+			WHERE tenant_id = ? AND team_name = ?
+		`, policy.ReviewSLAMinutes, policy.Action, tenant.FromContext(ctx), teamName)
+		if err != nil {
+			log.Printf("[TeamSQLiteRepo.setEscalationPolicy] db error updating team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			log.Printf("[TeamSQLiteRepo.setEscalationPolicy] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[TeamSQLiteRepo.setEscalationPolicy] updated escalation policy of team '%s'", teamName)
+	return nil
+}
+
+func (t *TeamSQLiteRepo) setFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error {
+	tenantID := tenant.FromContext(ctx)
+	err := t.db.Write(ctx, func(q sqlite.Querier) error {
+		var teamID uint64
+		err := q.QueryRowContext(ctx, "UPDATE team SET version = version + 1 WHERE tenant_id = ? AND team_name = ? RETURNING id", tenantID, teamName).Scan(&teamID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[TeamSQLiteRepo.setFallbackTeams] team not found: '%s'", teamName)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[TeamSQLiteRepo.setFallbackTeams] db error updating team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+
+		if _, err := q.ExecContext(ctx, "DELETE FROM team_fallback WHERE team_id = ?", teamID); err != nil {
+			log.Printf("[TeamSQLiteRepo.setFallbackTeams] db error clearing fallback teams of team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+
+		for i, name := range fallbackTeams {
+			res, err := q.ExecContext(ctx, `
+				INSERT INTO team_fallback (team_id, fallback_team_id, priority)
+				SELECT ?, id, ? FROM team WHERE tenant_id = ? AND team_name = ?
+			`, teamID, i+1, tenantID, name)
+			if err != nil {
+				log.Printf("[TeamSQLiteRepo.setFallbackTeams] db error adding fallback team '%s' to team '%s': %v", name, teamName, err)
+				return apperrors.ErrDB
+			}
+			if n, _ := res.RowsAffected(); n == 0 {
+				log.Printf("[TeamSQLiteRepo.setFallbackTeams] fallback team not found: '%s'", name)
+				return fmt.Errorf("%w: fallback team '%s'", apperrors.ErrNotFound, name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[TeamSQLiteRepo.setFallbackTeams] team '%s' now falls back to %v", teamName, fallbackTeams)
+	return nil
+}
+
+func (t *TeamSQLiteRepo) setParent(ctx context.Context, teamName string, parentTeamName *string) error {
+	tenantID := tenant.FromContext(ctx)
+	err := t.db.Write(ctx, func(q sqlite.Querier) error {
+		var teamID uint64
+		err := q.QueryRowContext(ctx, "SELECT id FROM team WHERE tenant_id = ? AND team_name = ?", tenantID, teamName).Scan(&teamID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[TeamSQLiteRepo.setParent] team not found: '%s'", teamName)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[TeamSQLiteRepo.setParent] db error fetching team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+
+		var parentID *uint64
+		if parentTeamName != nil {
+			var id uint64
+			var inSubtree bool
+			err := q.QueryRowContext(ctx, `
+				SELECT p.id, EXISTS(SELECT 1 FROM team_closure c WHERE c.ancestor_id = ? AND c.team_id = p.id)
+				FROM team p
+				WHERE p.tenant_id = ? AND p.team_name = ?
+			`, teamID, tenantID, *parentTeamName).Scan(&id, &inSubtree)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					log.Printf("[TeamSQLiteRepo.setParent] parent team not found: '%s'", *parentTeamName)
+					return fmt.Errorf("%w: parent team '%s'", apperrors.ErrNotFound, *parentTeamName)
+				}
+				log.Printf("[TeamSQLiteRepo.setParent] db error fetching parent team '%s': %v", *parentTeamName, err)
+				return apperrors.ErrDB
+			}
+			if inSubtree {
+				log.Printf("[TeamSQLiteRepo.setParent] team '%s' is inside the subtree of '%s'", *parentTeamName, teamName)
+				return fmt.Errorf("%w: team '%s' cannot be moved under its own subteam '%s'", apperrors.ErrBadRequest, teamName, *parentTeamName)
+			}
+			parentID = &id
+		}
+
+		if _, err := q.ExecContext(ctx, "UPDATE team SET parent_id = ?, version = version + 1 WHERE id = ?", parentID, teamID); err != nil {
+			log.Printf("[TeamSQLiteRepo.setParent] db error updating team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+
+		// поддерево отрывается от прежних предков и подвешивается к предкам нового родителя
+		_, err = q.ExecContext(ctx, `
+			DELETE FROM team_closure
+			WHERE team_id IN (SELECT team_id FROM team_closure WHERE ancestor_id = ?)
+			  AND ancestor_id NOT IN (SELECT team_id FROM team_closure WHERE ancestor_id = ?)
+		`, teamID, teamID)
+		if err != nil {
+			log.Printf("[TeamSQLiteRepo.setParent] db error detaching team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		if parentID != nil {
+			_, err = q.ExecContext(ctx, `
+				INSERT INTO team_closure (ancestor_id, team_id, depth)
+				SELECT up.ancestor_id, down.team_id, up.depth + down.depth + 1
+				FROM team_closure up, team_closure down
+				WHERE up.team_id = ? AND down.ancestor_id = ?
+			`, *parentID, teamID)
+			if err != nil {
+				log.Printf("[TeamSQLiteRepo.setParent] db error attaching team '%s': %v", teamName, err)
+				return apperrors.ErrDB
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if parentTeamName == nil {
+		log.Printf("[TeamSQLiteRepo.setParent] moved team '%s' to the organization root", teamName)
+	} else {
+		log.Printf("[TeamSQLiteRepo.setParent] moved team '%s' under '%s'", teamName, *parentTeamName)
+	}
+	return nil
+}
+
+func (t *TeamSQLiteRepo) hierarchy(ctx context.Context) ([]TeamNodeEntity, error) {
+	var entities []TeamNodeEntity
+	err := t.db.Read(ctx, func(q sqlite.Querier) error {
+		rows, err := q.QueryContext(ctx, `
+			SELECT t.team_name, p.team_name
+			FROM team t
+			LEFT JOIN team p ON p.id = t.parent_id
+			WHERE t.tenant_id = ?
+		`, tenant.FromContext(ctx))
+		if err != nil {
+			log.Printf("[TeamSQLiteRepo.hierarchy] db error fetching team hierarchy: %v", err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e TeamNodeEntity
+			if err := rows.Scan(&e.TeamName, &e.ParentTeamName); err != nil {
+				log.Printf("[TeamSQLiteRepo.hierarchy] failed to scan team: %v", err)
+				return apperrors.ErrDB
+			}
+			entities = append(entities, e)
+		}
+		if err := rows.Err(); err != nil {
+			log.Printf("[TeamSQLiteRepo.hierarchy] db error reading team hierarchy: %v", err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[TeamSQLiteRepo.hierarchy] fetched %d teams", len(entities))
+	return entities, nil
+}
+
+func (t *TeamSQLiteRepo) stats(ctx context.Context, teamName string, subtree bool) ([]TeamStatsEntity, error) {
+	var entities []TeamStatsEntity
+	err := t.db.Read(ctx, func(q sqlite.Querier) error {
+		rows, err := q.QueryContext(ctx, `
+			SELECT
+				t.team_name,
+				c.depth,
+				(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.tenant_id AND u.team_id = t.id),
+				(SELECT COUNT(*) FROM users u WHERE u.tenant_id = t.tenant_id AND u.team_id = t.id AND u.is_active),
+				(
+					SELECT COUNT(*)
+					FROM pull_request pr
+					JOIN users u ON u.tenant_id = pr.tenant_id AND u.user_id = pr.author_id
+					WHERE pr.tenant_id = t.tenant_id AND u.team_id = t.id AND pr.status = 'OPEN'
+				),
+				(
+					SELECT COUNT(*)
+					FROM pull_request_reviewer prr
+					JOIN pull_request pr ON pr.tenant_id = prr.tenant_id AND pr.pull_request_id = prr.pull_request_id
+					JOIN users u ON u.tenant_id = prr.tenant_id AND u.user_id = prr.user_id
+					WHERE prr.tenant_id = t.tenant_id AND u.team_id = t.id AND pr.status = 'OPEN'
+				),
+				(SELECT COUNT(*) FROM review_escalation e WHERE e.team_id = t.id)
+			FROM team root
+			JOIN team_closure c ON c.ancestor_id = root.id AND (c.depth = 0 OR ?)
+			JOIN team t ON t.id = c.team_id
+			WHERE root.tenant_id = ? AND root.team_name = ?
+			ORDER BY c.depth, t.team_name
+		`, subtree, tenant.FromContext(ctx), teamName)
+		if err != nil {
+			log.Printf("[TeamSQLiteRepo.stats] db error fetching stats of team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e TeamStatsEntity
+			if err := rows.Scan(&e.TeamName, &e.Depth, &e.Members, &e.ActiveMembers, &e.OpenPullRequests, &e.OpenReviews, &e.Escalations); err != nil {
+				log.Printf("[TeamSQLiteRepo.stats] failed to scan stats of team '%s': %v", teamName, err)
+				return apperrors.ErrDB
+			}
+			entities = append(entities, e)
+		}
+		if err := rows.Err(); err != nil {
+			log.Printf("[TeamSQLiteRepo.stats] db error reading stats of team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// строка глубины 0 есть у любой команды, пустой результат - команды нет
+	if len(entities) == 0 {
+		log.Printf("[TeamSQLiteRepo.stats] team not found: '%s'", teamName)
+		return nil, apperrors.ErrNotFound
+	}
+	log.Printf("[TeamSQLiteRepo.stats] fetched stats of %d teams under '%s'", len(entities), teamName)
+	return entities, nil
+}
+
+func (t *TeamSQLiteRepo) tenants(ctx context.Context) ([]string, error) {
+	var tenants []string
+	err := t.db.Read(ctx, func(q sqlite.Querier) error {
+		rows, err := q.QueryContext(ctx, "SELECT DISTINCT tenant_id FROM team ORDER BY tenant_id")
+		if err != nil {
+			log.Printf("[TeamSQLiteRepo.tenants] db error fetching tenants: %v", err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				log.Printf("[TeamSQLiteRepo.tenants] failed to scan tenant: %v", err)
+				return apperrors.ErrDB
+			}
+			tenants = append(tenants, id)
+		}
+		if err := rows.Err(); err != nil {
+			log.Printf("[TeamSQLiteRepo.tenants] db error reading tenants: %v", err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
@@ -1,10 +1,35 @@
 package team
 
-import "context"
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// EscalationRemind - событие review.reminder для зависшего ревьювера
+	EscalationRemind = "remind"
+	// EscalationReassign - переназначение по обычным правилам reassign
+	EscalationReassign = "reassign"
+	// EscalationNotifyLead - событие review.escalated для тимлида команды
+	EscalationNotifyLead = "notify_lead"
+)
+
+var escalationActions = []string{EscalationRemind, EscalationReassign, EscalationNotifyLead}
 
 type Repo interface {
-	create(ctx context.Context, teamName string, members []*TeamMemberEntity) error
+	create(ctx context.Context, teamName string, maxOpenReviews *int, members []*TeamMemberEntity) error
 	getByName(ctx context.Context, teamName string) (*TeamEntity, []TeamMemberEntity, error)
+	setMaxOpenReviews(ctx context.Context, teamName string, limit *int) error
+	setEscalationPolicy(ctx context.Context, teamName string, policy EscalationPolicyEntity) error
+	setFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error
+	setParent(ctx context.Context, teamName string, parentTeamName *string) error
+	hierarchy(ctx context.Context) ([]TeamNodeEntity, error)
+	// stats возвращает счётчики команды, а с subtree - и всех её подкоманд, по глубине и имени
+	stats(ctx context.Context, teamName string, subtree bool) ([]TeamStatsEntity, error)
+	tenants(ctx context.Context) ([]string, error)
 }
 
 type Team struct {
@@ -25,16 +50,158 @@ func (t *Team) GetByTeamName(ctx context.Context, teamName string) (*TeamDTO, er
 	members := FromTeamMeberEntities(entities)
 	var dto TeamDTO
 	dto.TeamName = entity.TeamName
+	dto.MaxOpenReviews = entity.MaxOpenReviews
+	dto.Escalation = EscalationPolicyFromModel(&entity.EscalationPolicyEntity)
+	dto.FallbackTeams = entity.FallbackTeams
+	dto.ParentTeamName = entity.ParentTeamName
 	dto.Members = members
+	dto.Version = entity.Version
 	return &dto, nil
 }
 
 func (t *Team) Create(ctx context.Context, dto *TeamDTO) error {
+	if err := checkLimit(dto.MaxOpenReviews); err != nil {
+		return err
+	}
 	entities := ToTeamMeberEntities(dto.Members)
 	members := make([]*TeamMemberEntity, len(entities))
 	for i := range entities {
+		if err := checkLimit(entities[i].MaxOpenReviews); err != nil {
+			return err
+		}
 		members[i] = &entities[i]
 	}
-	err := t.repo.create(ctx, dto.TeamName, members)
+	err := t.repo.create(ctx, dto.TeamName, dto.MaxOpenReviews, members)
 	return err
 }
+
+// SetMaxOpenReviews меняет лимит по умолчанию для участников без личного лимита; nil снимает ограничение
+func (t *Team) SetMaxOpenReviews(ctx context.Context, teamName string, limit *int) error {
+	if err := checkLimit(limit); err != nil {
+		return err
+	}
+	return t.repo.setMaxOpenReviews(ctx, teamName, limit)
+}
+
+// SetEscalationPolicy меняет SLA ревью команды и действие при его нарушении; nil выключает эскалацию.
+// Пустое действие - remind.
+func (t *Team) SetEscalationPolicy(ctx context.Context, teamName string, policy *EscalationPolicyDTO) error {
+	if policy != nil {
+		if policy.ReviewSLAMinutes <= 0 {
+			return fmt.Errorf("%w: review_sla_minutes must be positive", apperrors.ErrBadRequest)
+		}
+		if policy.Action == "" {
+			policy.Action = EscalationRemind
+		}
+		if !slices.Contains(escalationActions, policy.Action) {
+			return fmt.Errorf("%w: action must be one of %v", apperrors.ErrBadRequest, escalationActions)
+		}
+	}
+	return t.repo.setEscalationPolicy(ctx, teamName, policy.MapToModel())
+}
+
+// SetFallbackTeams заменяет список запасных команд; порядок задаёт приоритет, пустой список их снимает
+func (t *Team) SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error {
+	for i, name := range fallbackTeams {
+		switch {
+		case name == "":
+			return fmt.Errorf("%w: fallback team name must not be empty", apperrors.ErrBadRequest)
+		case name == teamName:
+			return fmt.Errorf("%w: team '%s' cannot be its own fallback", apperrors.ErrBadRequest, teamName)
+		case slices.Contains(fallbackTeams[:i], name):
+			return fmt.Errorf("%w: fallback team '%s' is listed twice", apperrors.ErrBadRequest, name)
+		}
+	}
+	return t.repo.setFallbackTeams(ctx, teamName, fallbackTeams)
+}
+
+// SetParent переносит команду со всеми подкомандами под parentTeamName; nil переносит её в корень организации
+func (t *Team) SetParent(ctx context.Context, teamName string, parentTeamName *string) error {
+	if parentTeamName != nil {
+		switch *parentTeamName {
+		case "":
+			return fmt.Errorf("%w: parent team name must not be empty", apperrors.ErrBadRequest)
+		case teamName:
+			return fmt.Errorf("%w: team '%s' cannot be its own parent", apperrors.ErrBadRequest, teamName)
+		}
+	}
+	return t.repo.setParent(ctx, teamName, parentTeamName)
+}
+
+// Stats возвращает счётчики команды; с subtree - каждой команды отдела и их сумму
+func (t *Team) Stats(ctx context.Context, teamName string, subtree bool) (*DepartmentStatsDTO, error) {
+	entities, err := t.repo.stats(ctx, teamName, subtree)
+	if err != nil {
+		return nil, err
+	}
+	dto := &DepartmentStatsDTO{Teams: make([]TeamStatsDTO, len(entities))}
+	for i, e := range entities {
+		dto.Teams[i] = TeamStatsDTO{
+			TeamName: e.TeamName,
+			Depth:    e.Depth,
+			TeamCountersDTO: TeamCountersDTO{
+				Members:          e.Members,
+				ActiveMembers:    e.ActiveMembers,
+				OpenPullRequests: e.OpenPullRequests,
+				OpenReviews:      e.OpenReviews,
+				Escalations:      e.Escalations,
+			},
+		}
+		dto.Total.add(dto.Teams[i].TeamCountersDTO)
+	}
+	return dto, nil
+}
+
+// Subtree возвращает команду со всеми подкомандами
+func (t *Team) Subtree(ctx context.Context, teamName string) (*TeamNodeDTO, error) {
+	nodes, _, err := t.tree(ctx)
+	if err != nil {
+		return nil, err
+	}
+	node, ok := nodes[teamName]
+	if !ok {
+		return nil, fmt.Errorf("%w: team '%s'", apperrors.ErrNotFound, teamName)
+	}
+	return node, nil
+}
+
+// Organization возвращает корень организации - команды без родителя с их подкомандами
+func (t *Team) Organization(ctx context.Context) ([]*TeamNodeDTO, error) {
+	_, roots, err := t.tree(ctx)
+	return roots, err
+}
+
+// Tenants возвращает арендаторов, у которых есть команды (для фоновых задач по всем арендаторам)
+func (t *Team) Tenants(ctx context.Context) ([]string, error) {
+	return t.repo.tenants(ctx)
+}
+
+// tree собирает иерархию: узлы по имени команды и корневые узлы; дети упорядочены по имени
+func (t *Team) tree(ctx context.Context) (map[string]*TeamNodeDTO, []*TeamNodeDTO, error) {
+	entities, err := t.repo.hierarchy(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	slices.SortFunc(entities, func(a, b TeamNodeEntity) int { return strings.Compare(a.TeamName, b.TeamName) })
+	nodes := make(map[string]*TeamNodeDTO, len(entities))
+	for _, e := range entities {
+		nodes[e.TeamName] = &TeamNodeDTO{TeamName: e.TeamName, Children: []*TeamNodeDTO{}}
+	}
+	roots := []*TeamNodeDTO{}
+	for _, e := range entities {
+		if e.ParentTeamName == nil {
+			roots = append(roots, nodes[e.TeamName])
+			continue
+		}
+		parent := nodes[*e.ParentTeamName]
+		parent.Children = append(parent.Children, nodes[e.TeamName])
+	}
+	return nodes, roots, nil
+}
+
+func checkLimit(limit *int) error {
+	if limit != nil && *limit < 0 {
+		return fmt.Errorf("%w: max_open_reviews must not be negative", apperrors.ErrBadRequest)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+// Package tenant - арендатор (организация), в рамках которого живут команды, пользователи и PR.
+// Арендатор запроса лежит в контексте; репозитории ограничивают им каждый запрос к хранилищу,
+// поэтому идентификаторы команд, пользователей и PR уникальны только внутри арендатора.
+package tenant
+
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+)
+
+const (
+	// Default - арендатор однотенантной установки и данных, созданных до появления арендаторов
+	Default = "default"
+	// Header - заголовок, из которого берётся арендатор запроса
+	Header = "X-Tenant-ID"
+)
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// Validate проверяет идентификатор: строчные латинские буквы, цифры, '-' и '_', до 64 символов
+func Validate(id string) error {
+	if !idPattern.MatchString(id) {
+		return fmt.Errorf("%w: tenant id '%s' must match %s", apperrors.ErrBadRequest, id, idPattern)
+	}
+	return nil
+}
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, id)
+}
+
+// FromContext возвращает арендатора запроса; без него - Default
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(tenantKey{}).(string); ok {
+		return id
+	}
+	return Default
+}
+
+// Lister перечисляет арендаторов, у которых есть данные
+type Lister interface {
+	Tenants(ctx context.Context) ([]string, error)
+}
+
+// Each превращает задачу одного арендатора в задачу для всех: run выполняется для каждого арендатора
+// в его контексте. Ошибка одного арендатора не останавливает остальных.
+func Each(l Lister, run func(ctx context.Context) (int, error)) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		tenants, err := l.Tenants(ctx)
+		if err != nil {
+			return 0, err
+		}
+		var (
+			total int
+			errs  []error
+		)
+		for _, id := range tenants {
+			if ctx.Err() != nil {
+				break
+			}
+			n, err := run(WithTenant(ctx, id))
+			total += n
+			if err != nil {
+				log.Printf("[tenant.Each] tenant '%s' failed: %v", id, err)
+				errs = append(errs, fmt.Errorf("tenant '%s': %w", id, err))
+			}
+		}
+		return total, errors.Join(errs...)
+	}
+}
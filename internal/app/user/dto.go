@@ -1,10 +1,18 @@
 package user
 
+import (
+	"avito-tech/internal/apperrors"
+	"fmt"
+	"time"
+)
+
 type UserDTO struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	TeamName string `json:"team_name"`
 	IsActive bool   `json:"is_active"`
+
+	TeamVersion uint64 `json:"-"`
 }
 
 func (u *UserDTO) MapToModel() *UserEntity {
@@ -21,6 +29,7 @@ func (u *UserDTO) MapFromModel(entity *UserEntity) {
 	u.Username = entity.Username
 	u.TeamName = entity.TeamName
 	u.IsActive = entity.IsActive
+	u.TeamVersion = entity.TeamVersion
 }
 
 func MapFromModels(entities []*UserEntity) []*UserDTO {
@@ -32,3 +41,62 @@ func MapFromModels(entities []*UserEntity) []*UserDTO {
 	}
 	return dto
 }
+
+// LoadDTO - текущая нагрузка и лимит; max_open_reviews = null - без ограничения
+type LoadDTO struct {
+	OpenReviews    int  `json:"open_reviews"`
+	MaxOpenReviews *int `json:"max_open_reviews"`
+	AtCapacity     bool `json:"at_capacity"`
+}
+
+func (l *LoadDTO) MapFromModel(entity *LoadEntity) {
+	l.OpenReviews = entity.OpenReviews
+	l.MaxOpenReviews = entity.MaxOpenReviews
+	l.AtCapacity = entity.MaxOpenReviews != nil && entity.OpenReviews >= *entity.MaxOpenReviews
+}
+
+// WorkingHoursDTO - рабочие часы "HH:MM" по местному времени IANA-зоны; start > end - смена через полночь
+type WorkingHoursDTO struct {
+	Timezone string `json:"timezone"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+}
+
+func (w *WorkingHoursDTO) MapToModel() (*WorkingHoursEntity, error) {
+	if w.Timezone == "" || w.Timezone == "Local" {
+		return nil, fmt.Errorf("%w: timezone must be an IANA name like Europe/Moscow", apperrors.ErrBadRequest)
+	}
+	if _, err := time.LoadLocation(w.Timezone); err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone '%s'", apperrors.ErrBadRequest, w.Timezone)
+	}
+	start, err := parseMinute(w.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := parseMinute(w.End)
+	if err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, fmt.Errorf("%w: start and end of working hours must differ", apperrors.ErrBadRequest)
+	}
+	return &WorkingHoursEntity{Timezone: &w.Timezone, WorkStart: &start, WorkEnd: &end}, nil
+}
+
+func (w *WorkingHoursDTO) MapFromModel(entity *WorkingHoursEntity) {
+	w.Timezone = *entity.Timezone
+	w.Start = formatMinute(*entity.WorkStart)
+	w.End = formatMinute(*entity.WorkEnd)
+}
+
+func parseMinute(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: working hours must be HH:MM, got '%s'", apperrors.ErrBadRequest, value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatMinute(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
@@ -6,4 +6,19 @@ type UserEntity struct {
 	TeamID   uint64 `db:"team_id"`
 	TeamName string `db:"-"`
 	IsActive bool   `db:"is_active"`
+
+	TeamVersion uint64 `db:"-"`
+}
+
+// LoadEntity - нагрузка ревьювера: OPEN PR, где он назначен, и действующий лимит (личный или командный)
+type LoadEntity struct {
+	OpenReviews    int  `db:"open_reviews"`
+	MaxOpenReviews *int `db:"max_open_reviews"`
+}
+
+// WorkingHoursEntity - рабочие часы: зона и [WorkStart, WorkEnd) в минутах от полуночи; все поля nil - не заданы
+type WorkingHoursEntity struct {
+	Timezone  *string `db:"timezone"`
+	WorkStart *int    `db:"work_start"`
+	WorkEnd   *int    `db:"work_end"`
 }
@@ -0,0 +1,278 @@
+package user
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/memory"
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"strings"
+)
+
+// UserMemoryRepo - реализация Repo поверх memory.Store с той же семантикой,
+// что у UserRepo. События в outbox не пишутся.
+type UserMemoryRepo struct {
+	store *memory.Store
+}
+
+func NewUserMemoryRepo(store *memory.Store) *UserMemoryRepo {
+	return &UserMemoryRepo{store: store}
+}
+
+func (user *UserMemoryRepo) getByID(ctx context.Context, id string) (*UserEntity, error) {
+	var entity *UserEntity
+	err := user.store.Read(ctx, func(d *memory.Data) error {
+		u, ok := d.Users[id]
+		if !ok {
+			log.Printf("[UserMemoryRepo.getByID] user '%s' not found", id)
+			return apperrors.ErrNotFound
+		}
+		entity = &UserEntity{UserID: u.ID, Username: u.Username, TeamID: u.TeamID, IsActive: u.IsActive}
+		if t := d.TeamByID(u.TeamID); t != nil {
+			entity.TeamName = t.Name
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (user *UserMemoryRepo) getByTeamID(ctx context.Context, id uint64) ([]*UserEntity, error) {
+	var entities []*UserEntity
+	_ = user.store.Read(ctx, func(d *memory.Data) error {
+		for _, u := range d.TeamMembers(id) {
+			entities = append(entities, &UserEntity{UserID: u.ID, Username: u.Username, TeamID: u.TeamID, IsActive: u.IsActive})
+		}
+		return nil
+	})
+	if len(entities) == 0 {
+		log.Printf("[UserMemoryRepo.getByTeamID] no users found for team '%d'", id)
+		return nil, apperrors.ErrNotFound
+	}
+	return entities, nil
+}
+
+func (user *UserMemoryRepo) setIsActive(ctx context.Context, userID string, isActive bool, ifMatch *uint64) (*UserEntity, error) {
+	var entity *UserEntity
+	err := user.store.Write(ctx, func(d *memory.Data) error {
+		u, ok := d.Users[userID]
+		if !ok {
+			log.Printf("[UserMemoryRepo.setIsActive] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		team := d.TeamByID(u.TeamID)
+		if team == nil {
+			log.Printf("[UserMemoryRepo.setIsActive] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		if ifMatch != nil && *ifMatch != team.Version {
+			log.Printf("[UserMemoryRepo.setIsActive] team of user '%s' is at version %d, client expected %d", userID, team.Version, *ifMatch)
+			return apperrors.ErrPreconditionFailed
+		}
+		if u.IsActive != isActive {
+			u.IsActive = isActive
+			team.Version++
+		}
+		entity = &UserEntity{
+			UserID:      u.ID,
+			Username:    u.Username,
+			TeamID:      u.TeamID,
+			TeamName:    team.Name,
+			IsActive:    u.IsActive,
+			TeamVersion: team.Version,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[UserMemoryRepo.setIsActive] updated user '%s' isActive=%v", userID, isActive)
+	return entity, nil
+}
+
+func (user *UserMemoryRepo) setTeamIsActive(ctx context.Context, teamName string, subtree bool, isActive bool) ([]*UserEntity, error) {
+	var entities []*UserEntity
+	err := user.store.Write(ctx, func(d *memory.Data) error {
+		root, ok := d.Teams[teamName]
+		if !ok {
+			log.Printf("[UserMemoryRepo.setTeamIsActive] team not found: '%s'", teamName)
+			return apperrors.ErrNotFound
+		}
+		for id, depth := range d.Subtree(root.ID) {
+			if depth > 0 && !subtree {
+				continue
+			}
+			team := d.TeamByID(id)
+			changed := false
+			for _, u := range d.TeamMembers(id) {
+				if u.IsActive == isActive {
+					continue
+				}
+				u.IsActive = isActive
+				changed = true
+				entities = append(entities, &UserEntity{UserID: u.ID, Username: u.Username, TeamID: id, TeamName: team.Name, IsActive: isActive})
+			}
+			if changed {
+				team.Version++
+			}
+		}
+		for _, e := range entities {
+			e.TeamVersion = d.TeamByID(e.TeamID).Version
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entities, func(a, b *UserEntity) int {
+		return cmp.Or(cmp.Compare(a.TeamID, b.TeamID), strings.Compare(a.UserID, b.UserID))
+	})
+	log.Printf("[UserMemoryRepo.setTeamIsActive] set isActive=%v for %d users under team '%s'", isActive, len(entities), teamName)
+	return entities, nil
+}
+
+func (user *UserMemoryRepo) create(ctx context.Context, entities []*UserEntity) error {
+	err := user.store.Write(ctx, func(d *memory.Data) error {
+		for _, e := range entities {
+			// team_id ссылается на team, как внешний ключ в Postgres
+			if d.TeamByID(e.TeamID) == nil {
+				log.Printf("[UserMemoryRepo.create] team '%d' does not exist for user '%s'", e.TeamID, e.UserID)
+				return apperrors.ErrDB
+			}
+			stored := &memory.User{ID: e.UserID, Username: e.Username, TeamID: e.TeamID, IsActive: e.IsActive}
+			// upsert не трогает личный лимит и рабочие часы, как ON CONFLICT в UserRepo.create
+			if old, ok := d.Users[e.UserID]; ok {
+				stored.MaxOpenReviews = old.MaxOpenReviews
+				stored.WorkingHours = old.WorkingHours
+			}
+			d.Users[e.UserID] = stored
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[UserMemoryRepo.create] inserted/updated %d users", len(entities))
+	return nil
+}
+
+func (user *UserMemoryRepo) getReview(ctx context.Context, userID string) ([]pullrequest.PullRequestShortDTO, error) {
+	var prs []pullrequest.PullRequestShortDTO
+	err := user.store.Read(ctx, func(d *memory.Data) error {
+		if _, ok := d.Users[userID]; !ok {
+			log.Printf("[UserMemoryRepo.getReview] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		for _, pr := range d.PullRequests {
+			if slices.Contains(pr.Reviewers, userID) {
+				prs = append(prs, pullrequest.PullRequestShortDTO{
+					PullRequestID:   pr.ID,
+					PullRequestName: pr.Name,
+					AuthorID:        pr.AuthorID,
+					Status:          pr.Status,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(prs, func(a, b pullrequest.PullRequestShortDTO) int {
+		return strings.Compare(a.PullRequestID, b.PullRequestID)
+	})
+	log.Printf("[UserMemoryRepo.getReview] fetched %d PRs for reviewer '%s'", len(prs), userID)
+	return prs, nil
+}
+
+func (user *UserMemoryRepo) getLoad(ctx context.Context, userID string) (*LoadEntity, error) {
+	var entity *LoadEntity
+	err := user.store.Read(ctx, func(d *memory.Data) error {
+		u, ok := d.Users[userID]
+		if !ok {
+			log.Printf("[UserMemoryRepo.getLoad] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		entity = &LoadEntity{OpenReviews: d.OpenReviews(userID), MaxOpenReviews: d.Capacity(u)}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entity, nil
+}
+
+func (user *UserMemoryRepo) setMaxOpenReviews(ctx context.Context, userID string, limit *int) (*UserEntity, error) {
+	var entity *UserEntity
+	err := user.store.Write(ctx, func(d *memory.Data) error {
+		u, ok := d.Users[userID]
+		if !ok {
+			log.Printf("[UserMemoryRepo.setMaxOpenReviews] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		team := d.TeamByID(u.TeamID)
+		if team == nil {
+			log.Printf("[UserMemoryRepo.setMaxOpenReviews] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		u.MaxOpenReviews = limit
+		team.Version++
+		entity = &UserEntity{
+			UserID:      u.ID,
+			Username:    u.Username,
+			TeamID:      u.TeamID,
+			TeamName:    team.Name,
+			IsActive:    u.IsActive,
+			TeamVersion: team.Version,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[UserMemoryRepo.setMaxOpenReviews] updated max_open_reviews of user '%s'", userID)
+	return entity, nil
+}
+
+func (user *UserMemoryRepo) getWorkingHours(ctx context.Context, userID string) (*WorkingHoursEntity, error) {
+	var entity WorkingHoursEntity
+	err := user.store.Read(ctx, func(d *memory.Data) error {
+		u, ok := d.Users[userID]
+		if !ok {
+			log.Printf("[UserMemoryRepo.getWorkingHours] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		if wh := u.WorkingHours; wh != nil {
+			entity = WorkingHoursEntity{Timezone: &wh.Timezone, WorkStart: &wh.Start, WorkEnd: &wh.End}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (user *UserMemoryRepo) setWorkingHours(ctx context.Context, userID string, entity *WorkingHoursEntity) error {
+	err := user.store.Write(ctx, func(d *memory.Data) error {
+		u, ok := d.Users[userID]
+		if !ok {
+			log.Printf("[UserMemoryRepo.setWorkingHours] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		u.WorkingHours = nil
+		if entity.Timezone != nil {
+			u.WorkingHours = &memory.WorkingHours{Timezone: *entity.Timezone, Start: *entity.WorkStart, End: *entity.WorkEnd}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[UserMemoryRepo.setWorkingHours] updated working hours of user '%s'", userID)
+	return nil
+}
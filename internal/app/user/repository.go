@@ -1,12 +1,17 @@
 package user
 
 import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/app/outbox"
 	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/tenant"
 	"avito-tech/internal/apperrors"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 
 	"github.com/jackc/pgconn"
@@ -14,6 +19,7 @@ import (
 )
 
 type DB interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
 	Get(ctx context.Context, dest any, query string, args ...any) error
 	Select(ctx context.Context, dest any, query string, args ...any) error
 	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
@@ -30,7 +36,12 @@ func NewUserRepo(db DB) *UserRepo {
 
 func (user *UserRepo) getByID(ctx context.Context, id string) (*UserEntity, error) {
 	var entity UserEntity
-	err := user.db.Get(ctx, &entity, "SELECT user_id, username, team_id, is_active FROM users WHERE user_id=$1", id)
+	err := user.db.ExecQueryRow(ctx, `
+		SELECT u.user_id, u.username, u.team_id, t.team_name, u.is_active
+		FROM users u
+		JOIN team t ON t.id = u.team_id
+		WHERE u.tenant_id = $1 AND u.user_id = $2
+	`, tenant.FromContext(ctx), id).Scan(&entity.UserID, &entity.Username, &entity.TeamID, &entity.TeamName, &entity.IsActive)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			log.Printf("[UserRepo.getByID] user '%s' not found", id)
@@ -45,7 +56,7 @@ func (user *UserRepo) getByID(ctx context.Context, id string) (*UserEntity, erro
 
 func (user *UserRepo) getByTeamID(ctx context.Context, id uint64) ([]*UserEntity, error) {
 	var entities []*UserEntity
-	err := user.db.Select(ctx, &entities, "SELECT user_id, username, team_id, is_active FROM users WHERE team_id=$1", id)
+	err := user.db.Select(ctx, &entities, "SELECT user_id, username, team_id, is_active FROM users WHERE tenant_id=$1 AND team_id=$2", tenant.FromContext(ctx), id)
 	if err != nil {
 		log.Printf("[UserRepo.getByTeamID] db error fetching users for team '%d': %v", id, err)
 		return nil, apperrors.ErrDB
@@ -58,14 +69,53 @@ func (user *UserRepo) getByTeamID(ctx context.Context, id uint64) ([]*UserEntity
 	return entities, nil
 }
 
-func (user *UserRepo) setIsActive(ctx context.Context, userID string, isActive bool) (*UserEntity, error) {
+func (user *UserRepo) setIsActive(ctx context.Context, userID string, isActive bool, ifMatch *uint64) (*UserEntity, error) {
+	tx, err := user.db.Begin(ctx)
+	if err != nil {
+		log.Printf("[UserRepo.setIsActive] failed to begin transaction: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			_ = tx.Commit(ctx)
+		}
+	}()
+
+	var (
+		wasActive   bool
+		teamVersion uint64
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT u.is_active, t.version
+		FROM users u
+		JOIN team t ON t.id = u.team_id
+		WHERE u.tenant_id = $1 AND u.user_id = $2
+		FOR UPDATE OF u
+	`, tenant.FromContext(ctx), userID).Scan(&wasActive, &teamVersion)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[UserRepo.setIsActive] user '%s' not found", userID)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[UserRepo.setIsActive] db error locking user '%s': %v", userID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	if ifMatch != nil && *ifMatch != teamVersion {
+		log.Printf("[UserRepo.setIsActive] team of user '%s' is at version %d, client expected %d", userID, teamVersion, *ifMatch)
+		err = apperrors.ErrPreconditionFailed
+		return nil, err
+	}
+
 	var entity UserEntity
 
-	err := user.db.ExecQueryRow(ctx, `
+	err = tx.QueryRow(ctx, `
 		UPDATE users u
 		SET is_active = $1
 		FROM team t
-		WHERE u.user_id = $2
+		WHERE u.tenant_id = $3 AND u.user_id = $2
 		  AND t.id = u.team_id
 		RETURNING 
 			u.user_id, 
@@ -73,7 +123,7 @@ func (user *UserRepo) setIsActive(ctx context.Context, userID string, isActive b
 			u.team_id, 
 			t.team_name,
 			u.is_active
-	`, isActive, userID).Scan(
+	`, isActive, userID, tenant.FromContext(ctx)).Scan(
 		&entity.UserID,
 		&entity.Username,
 		&entity.TeamID,
@@ -90,24 +140,148 @@ func (user *UserRepo) setIsActive(ctx context.Context, userID string, isActive b
 		return nil, apperrors.ErrDB
 	}
 
+	entity.TeamVersion = teamVersion
+	if wasActive != entity.IsActive {
+		// условное обновление: параллельная запись в команду приводит к 412, а не к перезаписи
+		err = tx.QueryRow(ctx, `
+			UPDATE team SET version = version + 1
+			WHERE id = $1 AND version = $2
+			RETURNING version
+		`, entity.TeamID, teamVersion).Scan(&entity.TeamVersion)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				log.Printf("[UserRepo.setIsActive] team of user '%s' was modified concurrently", userID)
+				err = apperrors.ErrPreconditionFailed
+				return nil, err
+			}
+			log.Printf("[UserRepo.setIsActive] db error bumping team version for user '%s': %v", userID, err)
+			return nil, apperrors.ErrDB
+		}
+	}
+
+	if wasActive && !entity.IsActive {
+		err = outbox.Write(ctx, tx, events.UserDeactivated, userID, events.UserData{
+			UserID:   entity.UserID,
+			Username: entity.Username,
+			TeamName: entity.TeamName,
+			IsActive: entity.IsActive,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	log.Printf("[UserRepo.setIsActive] updated user '%s' isActive=%v", userID, isActive)
 	return &entity, nil
 }
 
+func (user *UserRepo) setTeamIsActive(ctx context.Context, teamName string, subtree bool, isActive bool) ([]*UserEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	tx, err := user.db.Begin(ctx)
+	if err != nil {
+		log.Printf("[UserRepo.setTeamIsActive] failed to begin transaction: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			_ = tx.Commit(ctx)
+		}
+	}()
+
+	var rootID uint64
+	err = tx.QueryRow(ctx, "SELECT id FROM team WHERE tenant_id = $1 AND team_name = $2", tenantID, teamName).Scan(&rootID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[UserRepo.setTeamIsActive] team not found: '%s'", teamName)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[UserRepo.setTeamIsActive] db error fetching team '%s': %v", teamName, err)
+		return nil, apperrors.ErrDB
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE users u
+		SET is_active = $1
+		FROM team t
+		WHERE u.tenant_id = $2 AND u.is_active <> $1 AND t.id = u.team_id
+		  AND u.team_id IN (SELECT c.team_id FROM team_closure c WHERE c.ancestor_id = $3 AND (c.depth = 0 OR $4))
+		RETURNING u.user_id, u.username, u.team_id, t.team_name, u.is_active
+	`, isActive, tenantID, rootID, subtree)
+	if err != nil {
+		log.Printf("[UserRepo.setTeamIsActive] db error updating users of team '%s': %v", teamName, err)
+		return nil, apperrors.ErrDB
+	}
+	var entities []*UserEntity
+	for rows.Next() {
+		var e UserEntity
+		err = rows.Scan(&e.UserID, &e.Username, &e.TeamID, &e.TeamName, &e.IsActive)
+		if err != nil {
+			rows.Close()
+			log.Printf("[UserRepo.setTeamIsActive] failed to scan user of team '%s': %v", teamName, err)
+			return nil, apperrors.ErrDB
+		}
+		entities = append(entities, &e)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		log.Printf("[UserRepo.setTeamIsActive] db error reading users of team '%s': %v", teamName, err)
+		return nil, apperrors.ErrDB
+	}
+	slices.SortFunc(entities, func(a, b *UserEntity) int {
+		return cmp.Or(cmp.Compare(a.TeamID, b.TeamID), strings.Compare(a.UserID, b.UserID))
+	})
+
+	// версия каждой затронутой команды растёт один раз: If-Match, выданный до операции, получит 412
+	versions := map[uint64]uint64{}
+	for _, e := range entities {
+		if _, ok := versions[e.TeamID]; ok {
+			continue
+		}
+		var version uint64
+		err = tx.QueryRow(ctx, "UPDATE team SET version = version + 1 WHERE id = $1 RETURNING version", e.TeamID).Scan(&version)
+		if err != nil {
+			log.Printf("[UserRepo.setTeamIsActive] db error bumping version of team '%s': %v", e.TeamName, err)
+			return nil, apperrors.ErrDB
+		}
+		versions[e.TeamID] = version
+	}
+
+	for _, e := range entities {
+		e.TeamVersion = versions[e.TeamID]
+		if isActive {
+			continue
+		}
+		err = outbox.Write(ctx, tx, events.UserDeactivated, e.UserID, events.UserData{
+			UserID:   e.UserID,
+			Username: e.Username,
+			TeamName: e.TeamName,
+			IsActive: e.IsActive,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Printf("[UserRepo.setTeamIsActive] set isActive=%v for %d users under team '%s'", isActive, len(entities), teamName)
+	return entities, nil
+}
+
 func (user *UserRepo) create(ctx context.Context, entities []*UserEntity) error {
-	values := []interface{}{}
+	values := []interface{}{tenant.FromContext(ctx)}
 	placeholders := []string{}
 
 	for i, u := range entities {
-		n := i * 4
-		placeholders = append(placeholders, fmt.Sprintf("($%d,$%d,$%d,$%d)", n+1, n+2, n+3, n+4))
+		n := i*4 + 1
+		placeholders = append(placeholders, fmt.Sprintf("($1,$%d,$%d,$%d,$%d)", n+1, n+2, n+3, n+4))
 		values = append(values, u.UserID, u.Username, u.TeamID, u.IsActive)
 	}
 
 	query := fmt.Sprintf(`
-        INSERT INTO users (user_id, username, team_id, is_active)
+        INSERT INTO users (tenant_id, user_id, username, team_id, is_active)
         VALUES %s
-        ON CONFLICT (user_id) DO UPDATE
+        ON CONFLICT (tenant_id, user_id) DO UPDATE
         SET username = EXCLUDED.username,
             team_id = EXCLUDED.team_id,
             is_active = EXCLUDED.is_active
@@ -124,8 +298,9 @@ func (user *UserRepo) create(ctx context.Context, entities []*UserEntity) error
 }
 
 func (user *UserRepo) getReview(ctx context.Context, userID string) ([]pullrequest.PullRequestShortDTO, error) {
+	tenantID := tenant.FromContext(ctx)
 	var exists bool
-	err := user.db.Get(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE user_id=$1)", userID)
+	err := user.db.Get(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM users WHERE tenant_id=$1 AND user_id=$2)", tenantID, userID)
 	if err != nil {
 		log.Printf("[UserRepo.getReview] db error checking existence of user '%s': %v", userID, err)
 		return nil, apperrors.ErrDB
@@ -144,10 +319,10 @@ func (user *UserRepo) getReview(ctx context.Context, userID string) ([]pullreque
 			pr.status
 		FROM pull_request pr
 		JOIN pull_request_reviewer prr
-			ON pr.pull_request_id = prr.pull_request_id
-		WHERE prr.user_id = $1
+			ON prr.tenant_id = pr.tenant_id AND pr.pull_request_id = prr.pull_request_id
+		WHERE prr.tenant_id = $1 AND prr.user_id = $2
 	`
-	err = user.db.Select(ctx, &prs, query, userID)
+	err = user.db.Select(ctx, &prs, query, tenantID, userID)
 	if err != nil {
 		log.Printf("[UserRepo.getReview] db error fetching PRs for reviewer '%s': %v", userID, err)
 		return nil, apperrors.ErrDB
@@ -156,3 +331,116 @@ func (user *UserRepo) getReview(ctx context.Context, userID string) ([]pullreque
 	log.Printf("[UserRepo.getReview] fetched %d PRs for reviewer '%s'", len(prs), userID)
 	return prs, nil
 }
+
+func (user *UserRepo) getLoad(ctx context.Context, userID string) (*LoadEntity, error) {
+	var entity LoadEntity
+	err := user.db.ExecQueryRow(ctx, `
+		SELECT
+			(
+				SELECT COUNT(*)
+				FROM pull_request_reviewer prr
+				JOIN pull_request pr ON pr.tenant_id = prr.tenant_id AND pr.pull_request_id = prr.pull_request_id
+				WHERE prr.tenant_id = u.tenant_id AND prr.user_id = u.user_id AND pr.status = 'OPEN'
+			),
+			-- лимит команды наследуется от ближайшего предка, у которого он задан
+			COALESCE(u.max_open_reviews, (
+				SELECT t.max_open_reviews
+				FROM team_closure c
+				JOIN team t ON t.id = c.ancestor_id
+				WHERE c.team_id = u.team_id AND t.max_open_reviews IS NOT NULL
+				ORDER BY c.depth
+				LIMIT 1
+			))
+		FROM users u
+		WHERE u.tenant_id = $1 AND u.user_id = $2
+	`, tenant.FromContext(ctx), userID).Scan(&entity.OpenReviews, &entity.MaxOpenReviews)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[UserRepo.getLoad] user '%s' not found", userID)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[UserRepo.getLoad] db error fetching load of user '%s': %v", userID, err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[UserRepo.getLoad] user '%s' has %d open reviews", userID, entity.OpenReviews)
+	return &entity, nil
+}
+
+func (user *UserRepo) setMaxOpenReviews(ctx context.Context, userID string, limit *int) (*UserEntity, error) {
+	tx, err := user.db.Begin(ctx)
+	if err != nil {
+		log.Printf("[UserRepo.setMaxOpenReviews] failed to begin transaction: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		} else {
+			_ = tx.Commit(ctx)
+		}
+	}()
+
+	var entity UserEntity
+	err = tx.QueryRow(ctx, `
+		UPDATE users u
+		SET max_open_reviews = $1
+		FROM team t
+		WHERE u.tenant_id = $3 AND u.user_id = $2
+		  AND t.id = u.team_id
+		RETURNING u.user_id, u.username, u.team_id, t.team_name, u.is_active
+	`, limit, userID, tenant.FromContext(ctx)).Scan(&entity.UserID, &entity.Username, &entity.TeamID, &entity.TeamName, &entity.IsActive)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[UserRepo.setMaxOpenReviews] user '%s' not found", userID)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[UserRepo.setMaxOpenReviews] db error updating user '%s': %v", userID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	// лимит участника виден в составе команды, поэтому версия команды меняется
+	err = tx.QueryRow(ctx, `
+		UPDATE team SET version = version + 1
+		WHERE id = $1
+		RETURNING version
+	`, entity.TeamID).Scan(&entity.TeamVersion)
+	if err != nil {
+		log.Printf("[UserRepo.setMaxOpenReviews] db error bumping team version for user '%s': %v", userID, err)
+		return nil, apperrors.ErrDB
+	}
+
+	log.Printf("[UserRepo.setMaxOpenReviews] updated max_open_reviews of user '%s'", userID)
+	return &entity, nil
+}
+
+func (user *UserRepo) getWorkingHours(ctx context.Context, userID string) (*WorkingHoursEntity, error) {
+	var entity WorkingHoursEntity
+	err := user.db.ExecQueryRow(ctx, `
+		SELECT timezone, work_start, work_end FROM users WHERE tenant_id = $1 AND user_id = $2
+	`, tenant.FromContext(ctx), userID).Scan(&entity.Timezone, &entity.WorkStart, &entity.WorkEnd)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[UserRepo.getWorkingHours] user '%s' not found", userID)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[UserRepo.getWorkingHours] db error fetching working hours of user '%s': %v", userID, err)
+		return nil, apperrors.ErrDB
+	}
+	return &entity, nil
+}
+
+func (user *UserRepo) setWorkingHours(ctx context.Context, userID string, entity *WorkingHoursEntity) error {
+	tag, err := user.db.Exec(ctx, `
+		UPDATE users SET timezone = $2, work_start = $3, work_end = $4 WHERE tenant_id = $5 AND user_id = $1
+	`, userID, entity.Timezone, entity.WorkStart, entity.WorkEnd, tenant.FromContext(ctx))
+	if err != nil {
+		log.Printf("[UserRepo.setWorkingHours] db error updating user '%s': %v", userID, err)
+		return apperrors.ErrDB
+	}
+	if tag.RowsAffected() == 0 {
+		log.Printf("[UserRepo.setWorkingHours] user '%s' not found", userID)
+		return apperrors.ErrNotFound
+	}
+	log.Printf("[UserRepo.setWorkingHours] updated working hours of user '%s'", userID)
+	return nil
+}
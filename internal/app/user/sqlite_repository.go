@@ -0,0 +1,378 @@
+package user
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"avito-tech/internal/db/sqlite"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+)
+
+// UserSQLiteRepo - реализация Repo поверх встроенного SQLite с той же семантикой,
+// что у UserRepo. События в outbox не пишутся.
+type UserSQLiteRepo struct {
+	db *sqlite.DB
+}
+
+func NewUserSQLiteRepo(db *sqlite.DB) *UserSQLiteRepo {
+	return &UserSQLiteRepo{db: db}
+}
+
+func (user *UserSQLiteRepo) getByID(ctx context.Context, id string) (*UserEntity, error) {
+	var entity UserEntity
+	err := user.db.Read(ctx, func(q sqlite.Querier) error {
+		err := q.QueryRowContext(ctx, `
+			SELECT u.user_id, u.username, u.team_id, t.team_name, u.is_active
+			FROM users u
+			JOIN team t ON t.id = u.team_id
+			WHERE u.tenant_id = ? AND u.user_id = ?
+		`, tenant.FromContext(ctx), id).Scan(&entity.UserID, &entity.Username, &entity.TeamID, &entity.TeamName, &entity.IsActive)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[UserSQLiteRepo.getByID] user '%s' not found", id)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[UserSQLiteRepo.getByID] db error fetching user '%s': %v", id, err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (user *UserSQLiteRepo) getByTeamID(ctx context.Context, id uint64) ([]*UserEntity, error) {
+	var entities []*UserEntity
+	err := user.db.Read(ctx, func(q sqlite.Querier) error {
+		rows, err := q.QueryContext(ctx, "SELECT user_id, username, team_id, is_active FROM users WHERE tenant_id = ? AND team_id = ? ORDER BY user_id", tenant.FromContext(ctx), id)
+		if err != nil {
+			log.Printf("[UserSQLiteRepo.getByTeamID] db error fetching users for team '%d': %v", id, err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e UserEntity
+			if err := rows.Scan(&e.UserID, &e.Username, &e.TeamID, &e.IsActive); err != nil {
+				log.Printf("[UserSQLiteRepo.getByTeamID] failed to scan user for team '%d': %v", id, err)
+				return apperrors.ErrDB
+			}
+			entities = append(entities, &e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(entities) == 0 {
+		log.Printf("[UserSQLiteRepo.getByTeamID] no users found for team '%d'", id)
+		return nil, apperrors.ErrNotFound
+	}
+	return entities, nil
+}
+
+func (user *UserSQLiteRepo) setIsActive(ctx context.Context, userID string, isActive bool, ifMatch *uint64) (*UserEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var entity UserEntity
+	err := user.db.Write(ctx, func(q sqlite.Querier) error {
+		err := q.QueryRowContext(ctx, `
+			SELECT u.user_id, u.username, u.team_id, t.team_name, u.is_active, t.version
+			FROM users u
+			JOIN team t ON t.id = u.team_id
+			WHERE u.tenant_id = ? AND u.user_id = ?
+		`, tenantID, userID).Scan(&entity.UserID, &entity.Username, &entity.TeamID, &entity.TeamName, &entity.IsActive, &entity.TeamVersion)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[UserSQLiteRepo.setIsActive] user '%s' not found", userID)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[UserSQLiteRepo.setIsActive] db error fetching user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+
+		if ifMatch != nil && *ifMatch != entity.TeamVersion {
+			log.Printf("[UserSQLiteRepo.setIsActive] team of user '%s' is at version %d, client expected %d", userID, entity.TeamVersion, *ifMatch)
+			return apperrors.ErrPreconditionFailed
+		}
+		if entity.IsActive == isActive {
+			return nil
+		}
+
+		if _, err := q.ExecContext(ctx, "UPDATE users SET is_active = ? WHERE tenant_id = ? AND user_id = ?", isActive, tenantID, userID); err != nil {
+			log.Printf("[UserSQLiteRepo.setIsActive] db error updating user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		err = q.QueryRowContext(ctx, "UPDATE team SET version = version + 1 WHERE id = ? RETURNING version", entity.TeamID).Scan(&entity.TeamVersion)
+		if err != nil {
+			log.Printf("[UserSQLiteRepo.setIsActive] db error bumping team version for user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		entity.IsActive = isActive
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[UserSQLiteRepo.setIsActive] updated user '%s' isActive=%v", userID, isActive)
+	return &entity, nil
+}
+
+func (user *UserSQLiteRepo) setTeamIsActive(ctx context.Context, teamName string, subtree bool, isActive bool) ([]*UserEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var entities []*UserEntity
+	err := user.db.Write(ctx, func(q sqlite.Querier) error {
+		var rootID uint64
+		err := q.QueryRowContext(ctx, "SELECT id FROM team WHERE tenant_id = ? AND team_name = ?", tenantID, teamName).Scan(&rootID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[UserSQLiteRepo.setTeamIsActive] team not found: '%s'", teamName)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[UserSQLiteRepo.setTeamIsActive] db error fetching team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+
+		rows, err := q.QueryContext(ctx, `
+			UPDATE users
+			SET is_active = ?
+			WHERE tenant_id = ? AND is_active <> ?
+			  AND team_id IN (SELECT c.team_id FROM team_closure c WHERE c.ancestor_id = ? AND (c.depth = 0 OR ?))
+			RETURNING user_id, username, team_id, is_active
+		`, isActive, tenantID, isActive, rootID, subtree)
+		if err != nil {
+			log.Printf("[UserSQLiteRepo.setTeamIsActive] db error updating users of team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var e UserEntity
+			if err := rows.Scan(&e.UserID, &e.Username, &e.TeamID, &e.IsActive); err != nil {
+				log.Printf("[UserSQLiteRepo.setTeamIsActive] failed to scan user of team '%s': %v", teamName, err)
+				return apperrors.ErrDB
+			}
+			entities = append(entities, &e)
+		}
+		if err := rows.Err(); err != nil {
+			log.Printf("[UserSQLiteRepo.setTeamIsActive] db error reading users of team '%s': %v", teamName, err)
+			return apperrors.ErrDB
+		}
+		rows.Close()
+
+		teams := map[uint64]*UserEntity{}
+		for _, e := range entities {
+			if team, ok := teams[e.TeamID]; ok {
+				e.TeamName, e.TeamVersion = team.TeamName, team.TeamVersion
+				continue
+			}
+			err := q.QueryRowContext(ctx, "UPDATE team SET version = version + 1 WHERE id = ? RETURNING team_name, version", e.TeamID).Scan(&e.TeamName, &e.TeamVersion)
+			if err != nil {
+				log.Printf("[UserSQLiteRepo.setTeamIsActive] db error bumping version of team '%d': %v", e.TeamID, err)
+				return apperrors.ErrDB
+			}
+			teams[e.TeamID] = e
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(entities, func(a, b *UserEntity) int {
+		return cmp.Or(cmp.Compare(a.TeamID, b.TeamID), strings.Compare(a.UserID, b.UserID))
+	})
+	log.Printf("[UserSQLiteRepo.setTeamIsActive] set isActive=%v for %d users under team '%s'", isActive, len(entities), teamName)
+	return entities, nil
+}
+
+func (user *UserSQLiteRepo) create(ctx context.Context, entities []*UserEntity) error {
+	tenantID := tenant.FromContext(ctx)
+	err := user.db.Write(ctx, func(q sqlite.Querier) error {
+		for _, u := range entities {
+			_, err := q.ExecContext(ctx, `
+				INSERT INTO users (tenant_id, user_id, username, team_id, is_active)
+				VALUES (?, ?, ?, ?, ?)
+				ON CONFLICT (tenant_id, user_id) DO UPDATE
+				SET username = excluded.username,
+				    team_id = excluded.team_id,
+				    is_active = excluded.is_active
+			`, tenantID, u.UserID, u.Username, u.TeamID, u.IsActive)
+			if err != nil {
+				log.Printf("[UserSQLiteRepo.create] db error inserting/updating user '%s': %v", u.UserID, err)
+				return apperrors.ErrDB
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[UserSQLiteRepo.create] inserted/updated %d users", len(entities))
+	return nil
+}
+
+func (user *UserSQLiteRepo) getReview(ctx context.Context, userID string) ([]pullrequest.PullRequestShortDTO, error) {
+	tenantID := tenant.FromContext(ctx)
+	var prs []pullrequest.PullRequestShortDTO
+	err := user.db.Read(ctx, func(q sqlite.Querier) error {
+		var exists bool
+		if err := q.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE tenant_id = ? AND user_id = ?)", tenantID, userID).Scan(&exists); err != nil {
+			log.Printf("[UserSQLiteRepo.getReview] db error checking existence of user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		if !exists {
+			log.Printf("[UserSQLiteRepo.getReview] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+
+		rows, err := q.QueryContext(ctx, `
+			SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+			FROM pull_request pr
+			JOIN pull_request_reviewer prr ON prr.tenant_id = pr.tenant_id AND pr.pull_request_id = prr.pull_request_id
+			WHERE prr.tenant_id = ? AND prr.user_id = ?
+			ORDER BY pr.pull_request_id
+		`, tenantID, userID)
+		if err != nil {
+			log.Printf("[UserSQLiteRepo.getReview] db error fetching PRs for reviewer '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var pr pullrequest.PullRequestShortDTO
+			if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
+				log.Printf("[UserSQLiteRepo.getReview] failed to scan PR for reviewer '%s': %v", userID, err)
+				return apperrors.ErrDB
+			}
+			prs = append(prs, pr)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[UserSQLiteRepo.getReview] fetched %d PRs for reviewer '%s'", len(prs), userID)
+	return prs, nil
+}
+
+func (user *UserSQLiteRepo) getLoad(ctx context.Context, userID string) (*LoadEntity, error) {
+	var entity LoadEntity
+	err := user.db.Read(ctx, func(q sqlite.Querier) error {
+		err := q.QueryRowContext(ctx, `
+			SELECT
+				(
+					SELECT COUNT(*)
+					FROM pull_request_reviewer prr
+					JOIN pull_request pr ON pr.tenant_id = prr.tenant_id AND pr.pull_request_id = prr.pull_request_id
+					WHERE prr.tenant_id = u.tenant_id AND prr.user_id = u.user_id AND pr.status = 'OPEN'
+				),
+				-- лимит команды наследуется от ближайшего предка, у которого он задан
+				COALESCE(u.max_open_reviews, (
+					SELECT t.max_open_reviews
+					FROM team_closure c
+					JOIN team t ON t.id = c.ancestor_id
+					WHERE c.team_id = u.team_id AND t.max_open_reviews IS NOT NULL
+					ORDER BY c.depth
+					LIMIT 1
+				))
+			FROM users u
+			WHERE u.tenant_id = ? AND u.user_id = ?
+		`, tenant.FromContext(ctx), userID).Scan(&entity.OpenReviews, &entity.MaxOpenReviews)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[UserSQLiteRepo.getLoad] user '%s' not found", userID)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[UserSQLiteRepo.getLoad] db error fetching load of user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (user *UserSQLiteRepo) setMaxOpenReviews(ctx context.Context, userID string, limit *int) (*UserEntity, error) {
+	tenantID := tenant.FromContext(ctx)
+	var entity UserEntity
+	err := user.db.Write(ctx, func(q sqlite.Querier) error {
+		err := q.QueryRowContext(ctx, `
+			SELECT u.user_id, u.username, u.team_id, t.team_name, u.is_active
+			FROM users u
+			JOIN team t ON t.id = u.team_id
+			WHERE u.tenant_id = ? AND u.user_id = ?
+		`, tenantID, userID).Scan(&entity.UserID, &entity.Username, &entity.TeamID, &entity.TeamName, &entity.IsActive)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[UserSQLiteRepo.setMaxOpenReviews] user '%s' not found", userID)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[UserSQLiteRepo.setMaxOpenReviews] db error fetching user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+
+		if _, err := q.ExecContext(ctx, "UPDATE users SET max_open_reviews = ? WHERE tenant_id = ? AND user_id = ?", limit, tenantID, userID); err != nil {
+			log.Printf("[UserSQLiteRepo.setMaxOpenReviews] db error updating user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		err = q.QueryRowContext(ctx, "UPDATE team SET version = version + 1 WHERE id = ? RETURNING version", entity.TeamID).Scan(&entity.TeamVersion)
+		if err != nil {
+			log.Printf("[UserSQLiteRepo.setMaxOpenReviews] db error bumping team version for user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("[UserSQLiteRepo.setMaxOpenReviews] updated max_open_reviews of user '%s'", userID)
+	return &entity, nil
+}
+
+func (user *UserSQLiteRepo) getWorkingHours(ctx context.Context, userID string) (*WorkingHoursEntity, error) {
+	var entity WorkingHoursEntity
+	err := user.db.Read(ctx, func(q sqlite.Querier) error {
+		err := q.QueryRowContext(ctx, "SELECT timezone, work_start, work_end FROM users WHERE tenant_id = ? AND user_id = ?", tenant.FromContext(ctx), userID).
+			Scan(&entity.Timezone, &entity.WorkStart, &entity.WorkEnd)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				log.Printf("[UserSQLiteRepo.getWorkingHours] user '%s' not found", userID)
+				return apperrors.ErrNotFound
+			}
+			log.Printf("[UserSQLiteRepo.getWorkingHours] db error fetching working hours of user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+func (user *UserSQLiteRepo) setWorkingHours(ctx context.Context, userID string, entity *WorkingHoursEntity) error {
+	err := user.db.Write(ctx, func(q sqlite.Querier) error {
+		res, err := q.ExecContext(ctx, "UPDATE users SET timezone = ?, work_start = ?, work_end = ? WHERE tenant_id = ? AND user_id = ?",
+			entity.Timezone, entity.WorkStart, entity.WorkEnd, tenant.FromContext(ctx), userID)
+		if err != nil {
+			log.Printf("[UserSQLiteRepo.setWorkingHours] db error updating user '%s': %v", userID, err)
+			return apperrors.ErrDB
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			log.Printf("[UserSQLiteRepo.setWorkingHours] user '%s' not found", userID)
+			return apperrors.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[UserSQLiteRepo.setWorkingHours] updated working hours of user '%s'", userID)
+	return nil
+}
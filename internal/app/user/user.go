@@ -2,15 +2,25 @@ package user
 
 import (
 	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/apperrors"
 	"context"
+	"fmt"
 )
 
 type Repo interface {
 	getByID(ctx context.Context, id string) (*UserEntity, error)
-	setIsActive(ctx context.Context, userID string, isActive bool) (*UserEntity, error)
+	setIsActive(ctx context.Context, userID string, isActive bool, ifMatch *uint64) (*UserEntity, error)
+	// setTeamIsActive меняет активность участников команды (с subtree - и её подкоманд) и возвращает тех,
+	// у кого она изменилась
+	setTeamIsActive(ctx context.Context, teamName string, subtree bool, isActive bool) ([]*UserEntity, error)
 	create(ctx context.Context, entities []*UserEntity) error
 	getByTeamID(ctx context.Context, id uint64) ([]*UserEntity, error)
 	getReview(ctx context.Context, userID string) ([]pullrequest.PullRequestShortDTO, error)
+	getLoad(ctx context.Context, userID string) (*LoadEntity, error)
+	setMaxOpenReviews(ctx context.Context, userID string, limit *int) (*UserEntity, error)
+	getWorkingHours(ctx context.Context, userID string) (*WorkingHoursEntity, error)
+	// setWorkingHours с пустой сущностью сбрасывает рабочие часы
+	setWorkingHours(ctx context.Context, userID string, entity *WorkingHoursEntity) error
 }
 
 type User struct {
@@ -42,9 +52,10 @@ func (u *User) GetByTeamID(ctx context.Context, id uint64) ([]*UserDTO, error) {
 	return dto, nil
 }
 
-func (u *User) SetIsActive(ctx context.Context, id string, isActive bool) (*UserDTO, error) {
+// SetIsActive меняет состав команды, поэтому ifMatch сверяется с версией команды пользователя
+func (u *User) SetIsActive(ctx context.Context, id string, isActive bool, ifMatch *uint64) (*UserDTO, error) {
 	var dto UserDTO
-	entity, err := u.repo.setIsActive(ctx, id, isActive)
+	entity, err := u.repo.setIsActive(ctx, id, isActive, ifMatch)
 	if err != nil {
 		return nil, err
 	}
@@ -52,6 +63,15 @@ func (u *User) SetIsActive(ctx context.Context, id string, isActive bool) (*User
 	return &dto, err
 }
 
+// SetTeamIsActive - SetIsActive для всех участников команды или отдела; версии затронутых команд растут
+func (u *User) SetTeamIsActive(ctx context.Context, teamName string, subtree bool, isActive bool) ([]*UserDTO, error) {
+	entities, err := u.repo.setTeamIsActive(ctx, teamName, subtree, isActive)
+	if err != nil {
+		return nil, err
+	}
+	return MapFromModels(entities), nil
+}
+
 func (u *User) Create(ctx context.Context, users []*UserDTO) error {
 	entities := make([]*UserEntity, len(users))
 	for i, v := range users {
@@ -70,3 +90,58 @@ func (u *User) GetReview(ctx context.Context, userID string) ([]*pullrequest.Pul
 	answer := pullrequest.MapToModelsShort(entities)
 	return answer, nil
 }
+
+// GetLoad возвращает число открытых ревью пользователя и его действующий лимит
+func (u *User) GetLoad(ctx context.Context, userID string) (*LoadDTO, error) {
+	entity, err := u.repo.getLoad(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var dto LoadDTO
+	dto.MapFromModel(entity)
+	return &dto, nil
+}
+
+// SetMaxOpenReviews задаёт личный лимит открытых ревью; nil возвращает к лимиту команды
+func (u *User) SetMaxOpenReviews(ctx context.Context, userID string, limit *int) (*UserDTO, error) {
+	if limit != nil && *limit < 0 {
+		return nil, fmt.Errorf("%w: max_open_reviews must not be negative", apperrors.ErrBadRequest)
+	}
+	entity, err := u.repo.setMaxOpenReviews(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	var dto UserDTO
+	dto.MapFromModel(entity)
+	return &dto, nil
+}
+
+// GetWorkingHours возвращает рабочие часы пользователя; nil - не заданы
+func (u *User) GetWorkingHours(ctx context.Context, userID string) (*WorkingHoursDTO, error) {
+	entity, err := u.repo.getWorkingHours(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if entity.Timezone == nil {
+		return nil, nil
+	}
+	var dto WorkingHoursDTO
+	dto.MapFromModel(entity)
+	return &dto, nil
+}
+
+// SetWorkingHours задаёт рабочие часы; nil сбрасывает их
+func (u *User) SetWorkingHours(ctx context.Context, userID string, dto *WorkingHoursDTO) (*WorkingHoursDTO, error) {
+	entity := &WorkingHoursEntity{}
+	if dto != nil {
+		var err error
+		entity, err = dto.MapToModel()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := u.repo.setWorkingHours(ctx, userID, entity); err != nil {
+		return nil, err
+	}
+	return u.GetWorkingHours(ctx, userID)
+}
@@ -0,0 +1,189 @@
+package webhook
+
+import (
+	"avito-tech/internal/app/events"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	HeaderEvent       = "X-Webhook-Event"
+	HeaderDelivery    = "X-Webhook-Delivery"
+	HeaderSignature   = "X-Webhook-Signature"
+	HeaderIdempotency = "Idempotency-Key"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    time.Minute,
+	}
+}
+
+// Backoff возвращает задержку перед попыткой attempt (начиная с 1):
+// BaseDelay, 2*BaseDelay, 4*BaseDelay, ... но не больше MaxDelay.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= p.MaxDelay {
+			return p.MaxDelay
+		}
+	}
+	return d
+}
+
+// Sign считает HMAC-SHA256 тела запроса, получатель сверяет его с заголовком X-Webhook-Signature.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+const (
+	dueBatch = 50
+	// deliveryLease - на столько откладывается забранная доставка: если реплика упала во время отправки,
+	// доставку повторит следующий запуск после lease
+	deliveryLease = time.Minute
+)
+
+// Dispatcher ставит события в доставку и отправляет их. Состояние доставки (попытки, следующая попытка)
+// хранится в webhook_delivery, поэтому повторы переживают перезапуск сервиса.
+type Dispatcher struct {
+	repo   Repo
+	client *http.Client
+	policy RetryPolicy
+}
+
+func newDispatcher(repo Repo, client *http.Client, policy RetryPolicy) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+	if policy.MaxAttempts <= 0 {
+		policy = DefaultRetryPolicy()
+	}
+	return &Dispatcher{
+		repo:   repo,
+		client: client,
+		policy: policy,
+	}
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, sub *SubscriptionEntity, ev *events.Event) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, _, err = d.repo.createDelivery(ctx, &DeliveryEntity{
+		SubscriptionID: sub.ID,
+		EventID:        ev.ID,
+		EventType:      ev.Type,
+		Payload:        body,
+		Status:         DeliveryPending,
+	})
+	return err
+}
+
+// deliverDue отправляет пачку доставок, которым подошло время, параллельно и возвращает число успешных
+func (d *Dispatcher) deliverDue(ctx context.Context) (int, error) {
+	due, err := d.repo.claimDue(ctx, dueBatch, deliveryLease)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+	)
+	for _, delivery := range due {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if d.attempt(ctx, delivery) {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return delivered, nil
+}
+
+// attempt выполняет одну попытку и записывает её исход. При остановке сервиса исход не пишется:
+// доставка останется забранной до истечения lease и уйдёт после перезапуска.
+func (d *Dispatcher) attempt(ctx context.Context, due *DueDeliveryEntity) bool {
+	delivery := &due.DeliveryEntity
+	delivery.Attempts++
+	var retryIn time.Duration
+	code, err := d.send(ctx, due.URL, due.Secret, delivery)
+	if ctx.Err() != nil {
+		return false
+	}
+	if code != 0 {
+		delivery.ResponseCode = &code
+	}
+
+	switch {
+	case err == nil:
+		delivery.Status = DeliveryDelivered
+		delivery.LastError = nil
+		log.Printf("[Dispatcher.attempt] delivery %d of event '%s' to '%s' succeeded after %d attempts", delivery.ID, delivery.EventID, due.URL, delivery.Attempts)
+	case delivery.Attempts >= d.policy.MaxAttempts:
+		msg := err.Error()
+		delivery.LastError = &msg
+		delivery.Status = DeliveryDead
+		log.Printf("[Dispatcher.attempt] delivery %d of event '%s' to '%s' moved to dead letters: %v", delivery.ID, delivery.EventID, due.URL, err)
+	default:
+		msg := err.Error()
+		delivery.LastError = &msg
+		delivery.Status = DeliveryFailed
+		retryIn = d.policy.Backoff(delivery.Attempts)
+		log.Printf("[Dispatcher.attempt] delivery %d of event '%s' to '%s' failed (attempt %d), retry in %s: %v", delivery.ID, delivery.EventID, due.URL, delivery.Attempts, retryIn, err)
+	}
+	if err := d.repo.updateDelivery(ctx, delivery, retryIn); err != nil {
+		return false
+	}
+	return delivery.Status == DeliveryDelivered
+}
+
+func (d *Dispatcher) send(ctx context.Context, url string, secret string, delivery *DeliveryEntity) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.EventID)
+	req.Header.Set(HeaderIdempotency, delivery.EventID)
+	req.Header.Set(HeaderSignature, Sign(secret, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
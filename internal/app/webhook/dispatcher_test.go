@@ -0,0 +1,198 @@
+package webhook
+
+import (
+	"avito-tech/internal/app/events"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRepo хранит доставки в памяти; claimDue отдаёт те, у которых подошёл next_attempt_at по часам now
+type fakeRepo struct {
+	Repo
+	mu         sync.Mutex
+	now        func() time.Time
+	sub        *SubscriptionEntity
+	deliveries []*DeliveryEntity
+}
+
+func (f *fakeRepo) createDelivery(_ context.Context, entity *DeliveryEntity) (*DeliveryEntity, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range f.deliveries {
+		if d.SubscriptionID == entity.SubscriptionID && d.EventID == entity.EventID {
+			return nil, false, nil
+		}
+	}
+	entity.ID = uint64(len(f.deliveries) + 1)
+	entity.NextAttemptAt = f.now()
+	f.deliveries = append(f.deliveries, entity)
+	return entity, true, nil
+}
+
+func (f *fakeRepo) claimDue(_ context.Context, limit int, lease time.Duration) ([]*DueDeliveryEntity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var due []*DueDeliveryEntity
+	for _, d := range f.deliveries {
+		if len(due) == limit {
+			break
+		}
+		if (d.Status == DeliveryPending || d.Status == DeliveryFailed) && !d.NextAttemptAt.After(f.now()) {
+			d.NextAttemptAt = f.now().Add(lease)
+			due = append(due, &DueDeliveryEntity{DeliveryEntity: *d, URL: f.sub.URL, Secret: f.sub.Secret})
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeRepo) updateDelivery(_ context.Context, entity *DeliveryEntity, retryIn time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entity.NextAttemptAt = f.now().Add(retryIn)
+	*f.deliveries[entity.ID-1] = *entity
+	return nil
+}
+
+func (f *fakeRepo) delivery(t *testing.T) DeliveryEntity {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(f.deliveries))
+	}
+	return *f.deliveries[0]
+}
+
+// receiver - локальный получатель, который первые failures запросов отвечает 500
+type receiver struct {
+	mu         sync.Mutex
+	failures   int
+	requests   int
+	signatures []string
+	bodies     [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests++
+	r.signatures = append(r.signatures, req.Header.Get(HeaderSignature))
+	r.bodies = append(r.bodies, body)
+	if r.requests <= r.failures {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func setup(t *testing.T, failures int) (*Dispatcher, *fakeRepo, *receiver, *time.Time) {
+	t.Helper()
+	rcv := &receiver{failures: failures}
+	server := httptest.NewServer(rcv)
+	t.Cleanup(server.Close)
+
+	now := time.Date(2026, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	repo := &fakeRepo{now: clock, sub: &SubscriptionEntity{ID: 1, URL: server.URL, Secret: "s3cr3t"}}
+	d := newDispatcher(repo, server.Client(), RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute})
+
+	ev, err := events.New(events.PRCreated, events.PullRequestData{PullRequestID: "pr-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.enqueue(t.Context(), repo.sub, ev); err != nil {
+		t.Fatal(err)
+	}
+	// повторная публикация того же события не создаёт вторую доставку
+	if err := d.enqueue(t.Context(), repo.sub, ev); err != nil {
+		t.Fatal(err)
+	}
+	return d, repo, rcv, &now
+}
+
+func deliver(t *testing.T, d *Dispatcher) int {
+	t.Helper()
+	n, err := d.deliverDue(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestDeliverySigned(t *testing.T) {
+	d, repo, rcv, _ := setup(t, 0)
+	if n := deliver(t, d); n != 1 {
+		t.Fatalf("expected 1 delivered, got %d", n)
+	}
+	if got := repo.delivery(t); got.Status != DeliveryDelivered || got.Attempts != 1 {
+		t.Fatalf("expected delivered after 1 attempt, got %s after %d", got.Status, got.Attempts)
+	}
+	if want := Sign("s3cr3t", rcv.bodies[0]); rcv.signatures[0] != want {
+		t.Fatalf("signature %q does not match body, want %q", rcv.signatures[0], want)
+	}
+	if n := deliver(t, d); n != 0 || rcv.requests != 1 {
+		t.Fatalf("delivered event was sent again: %d requests", rcv.requests)
+	}
+}
+
+func TestDeliveryRetriesWithBackoff(t *testing.T) {
+	d, repo, rcv, now := setup(t, 2)
+
+	deliver(t, d)
+	got := repo.delivery(t)
+	if got.Status != DeliveryFailed || got.ResponseCode == nil || *got.ResponseCode != http.StatusInternalServerError {
+		t.Fatalf("expected failed delivery with code 500, got %s", got.Status)
+	}
+	if want := now.Add(time.Second); !got.NextAttemptAt.Equal(want) {
+		t.Fatalf("first retry at %s, want %s", got.NextAttemptAt, want)
+	}
+	// до следующей попытки доставка не отправляется
+	deliver(t, d)
+	if rcv.requests != 1 {
+		t.Fatalf("retried before backoff elapsed: %d requests", rcv.requests)
+	}
+
+	*now = now.Add(time.Second)
+	deliver(t, d)
+	if got := repo.delivery(t); !got.NextAttemptAt.Equal(now.Add(2 * time.Second)) {
+		t.Fatalf("second retry at %s, want %s", got.NextAttemptAt, now.Add(2*time.Second))
+	}
+
+	*now = now.Add(2 * time.Second)
+	if n := deliver(t, d); n != 1 {
+		t.Fatalf("expected the third attempt to succeed")
+	}
+	if got := repo.delivery(t); got.Status != DeliveryDelivered || got.Attempts != 3 || got.LastError != nil {
+		t.Fatalf("expected delivered after 3 attempts, got %s after %d", got.Status, got.Attempts)
+	}
+}
+
+func TestDeliveryDeadLetter(t *testing.T) {
+	d, repo, rcv, now := setup(t, 10)
+	for range 5 {
+		deliver(t, d)
+		*now = now.Add(time.Minute)
+	}
+	got := repo.delivery(t)
+	if got.Status != DeliveryDead || got.Attempts != 3 || got.LastError == nil {
+		t.Fatalf("expected dead letter after 3 attempts, got %s after %d", got.Status, got.Attempts)
+	}
+	if rcv.requests != 3 {
+		t.Fatalf("dead letter should not be sent again, got %d requests", rcv.requests)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 8: 5 * time.Second} {
+		if got := p.Backoff(attempt); got != want {
+			t.Errorf("Backoff(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
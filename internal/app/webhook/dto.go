@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type SubscriptionDTO struct {
+	ID         uint64    `json:"id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	EventTypes []string  `json:"event_types"`
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (s *SubscriptionDTO) MapToModel() *SubscriptionEntity {
+	return &SubscriptionEntity{
+		URL:        s.URL,
+		Secret:     s.Secret,
+		EventTypes: s.EventTypes,
+		IsActive:   true,
+	}
+}
+
+func (s *SubscriptionDTO) MapFromModel(entity *SubscriptionEntity) {
+	s.ID = entity.ID
+	s.URL = entity.URL
+	s.EventTypes = entity.EventTypes
+	s.IsActive = entity.IsActive
+	s.CreatedAt = entity.CreatedAt
+}
+
+type DeliveryDTO struct {
+	ID             uint64          `json:"id"`
+	SubscriptionID uint64          `json:"subscription_id"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseCode   *int            `json:"response_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+func (d *DeliveryDTO) MapFromModel(entity *DeliveryEntity) {
+	d.ID = entity.ID
+	d.SubscriptionID = entity.SubscriptionID
+	d.EventID = entity.EventID
+	d.EventType = entity.EventType
+	d.Payload = entity.Payload
+	d.Status = entity.Status
+	d.Attempts = entity.Attempts
+	d.ResponseCode = entity.ResponseCode
+	d.LastError = entity.LastError
+	if entity.Status == DeliveryPending || entity.Status == DeliveryFailed {
+		d.NextAttemptAt = &entity.NextAttemptAt
+	}
+	d.CreatedAt = entity.CreatedAt
+	d.UpdatedAt = entity.UpdatedAt
+}
+
+func MapFromSubscriptionModels(entities []*SubscriptionEntity) []*SubscriptionDTO {
+	dto := make([]*SubscriptionDTO, len(entities))
+	for i, v := range entities {
+		var s SubscriptionDTO
+		s.MapFromModel(v)
+		dto[i] = &s
+	}
+	return dto
+}
+
+func MapFromDeliveryModels(entities []*DeliveryEntity) []*DeliveryDTO {
+	dto := make([]*DeliveryDTO, len(entities))
+	for i, v := range entities {
+		var d DeliveryDTO
+		d.MapFromModel(v)
+		dto[i] = &d
+	}
+	return dto
+}
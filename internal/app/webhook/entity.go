@@ -0,0 +1,41 @@
+package webhook
+
+import "time"
+
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryFailed    = "FAILED"
+	DeliveryDead      = "DEAD"
+)
+
+type SubscriptionEntity struct {
+	ID         uint64    `db:"id"`
+	URL        string    `db:"url"`
+	Secret     string    `db:"secret"`
+	EventTypes []string  `db:"event_types"`
+	IsActive   bool      `db:"is_active"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type DeliveryEntity struct {
+	ID             uint64    `db:"id"`
+	SubscriptionID uint64    `db:"subscription_id"`
+	EventID        string    `db:"event_id"`
+	EventType      string    `db:"event_type"`
+	Payload        []byte    `db:"payload"`
+	Status         string    `db:"status"`
+	Attempts       int       `db:"attempts"`
+	ResponseCode   *int      `db:"response_code"`
+	LastError      *string   `db:"last_error"`
+	NextAttemptAt  time.Time `db:"next_attempt_at"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+// DueDeliveryEntity - доставка, которую пора отправить, с адресом и секретом её подписки
+type DueDeliveryEntity struct {
+	DeliveryEntity
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+type DB interface {
+	Get(ctx context.Context, dest any, query string, args ...any) error
+	Select(ctx context.Context, dest any, query string, args ...any) error
+	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+	ExecQueryRow(ctx context.Context, query string, args ...any) pgx.Row
+}
+
+type WebhookRepo struct {
+	db DB
+}
+
+func NewWebhookRepo(db DB) *WebhookRepo {
+	return &WebhookRepo{db: db}
+}
+
+func (w *WebhookRepo) create(ctx context.Context, entity *SubscriptionEntity) (*SubscriptionEntity, error) {
+	err := w.db.ExecQueryRow(ctx, `
+		INSERT INTO webhook_subscription (tenant_id, url, secret, event_types, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, tenant.FromContext(ctx), entity.URL, entity.Secret, entity.EventTypes, entity.IsActive).Scan(&entity.ID, &entity.CreatedAt)
+	if err != nil {
+		log.Printf("[WebhookRepo.create] db error inserting subscription for '%s': %v", entity.URL, err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[WebhookRepo.create] subscription %d created for '%s'", entity.ID, entity.URL)
+	return entity, nil
+}
+
+func (w *WebhookRepo) list(ctx context.Context) ([]*SubscriptionEntity, error) {
+	var entities []*SubscriptionEntity
+	err := w.db.Select(ctx, &entities, `
+		SELECT id, url, secret, event_types, is_active, created_at
+		FROM webhook_subscription
+		WHERE tenant_id = $1
+		ORDER BY id
+	`, tenant.FromContext(ctx))
+	if err != nil {
+		log.Printf("[WebhookRepo.list] db error fetching subscriptions: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[WebhookRepo.list] fetched %d subscriptions", len(entities))
+	return entities, nil
+}
+
+func (w *WebhookRepo) delete(ctx context.Context, id uint64) error {
+	tag, err := w.db.Exec(ctx, "DELETE FROM webhook_subscription WHERE tenant_id=$1 AND id=$2", tenant.FromContext(ctx), id)
+	if err != nil {
+		log.Printf("[WebhookRepo.delete] db error deleting subscription %d: %v", id, err)
+		return apperrors.ErrDB
+	}
+	if tag.RowsAffected() == 0 {
+		log.Printf("[WebhookRepo.delete] subscription %d not found", id)
+		return apperrors.ErrNotFound
+	}
+	log.Printf("[WebhookRepo.delete] subscription %d deleted", id)
+	return nil
+}
+
+func (w *WebhookRepo) getSubscribed(ctx context.Context, eventType string) ([]*SubscriptionEntity, error) {
+	var entities []*SubscriptionEntity
+	err := w.db.Select(ctx, &entities, `
+		SELECT id, url, secret, event_types, is_active, created_at
+		FROM webhook_subscription
+		WHERE tenant_id = $1
+		  AND is_active = true
+		  AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+	`, tenant.FromContext(ctx), eventType)
+	if err != nil {
+		log.Printf("[WebhookRepo.getSubscribed] db error fetching subscriptions for '%s': %v", eventType, err)
+		return nil, apperrors.ErrDB
+	}
+	return entities, nil
+}
+
+func (w *WebhookRepo) getByID(ctx context.Context, id uint64) (*SubscriptionEntity, error) {
+	var entity SubscriptionEntity
+	err := w.db.Get(ctx, &entity, `
+		SELECT id, url, secret, event_types, is_active, created_at
+		FROM webhook_subscription
+		WHERE tenant_id=$1 AND id=$2
+	`, tenant.FromContext(ctx), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[WebhookRepo.getByID] subscription %d not found", id)
+			return nil, apperrors.ErrNotFound
+		}
+		log.Printf("[WebhookRepo.getByID] db error fetching subscription %d: %v", id, err)
+		return nil, apperrors.ErrDB
+	}
+	return &entity, nil
+}
+
+// createDelivery возвращает created=false, если событие уже было поставлено
+// в доставку этой подписке (повторная публикация из outbox).
+func (w *WebhookRepo) createDelivery(ctx context.Context, entity *DeliveryEntity) (*DeliveryEntity, bool, error) {
+	err := w.db.ExecQueryRow(ctx, `
+		INSERT INTO webhook_delivery (subscription_id, event_id, event_type, payload, status)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+		RETURNING id, created_at, updated_at
+	`, entity.SubscriptionID, entity.EventID, entity.EventType, entity.Payload, entity.Status).Scan(
+		&entity.ID,
+		&entity.CreatedAt,
+		&entity.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Printf("[WebhookRepo.createDelivery] event '%s' already enqueued for subscription %d", entity.EventID, entity.SubscriptionID)
+			return nil, false, nil
+		}
+		log.Printf("[WebhookRepo.createDelivery] db error inserting delivery of event '%s' to subscription %d: %v", entity.EventID, entity.SubscriptionID, err)
+		return nil, false, apperrors.ErrDB
+	}
+	return entity, true, nil
+}
+
+// claimDue забирает до limit доставок, которым пора уйти, и сдвигает их next_attempt_at на lease:
+// пока отправка идёт, другие реплики их не берут, а после падения реплики доставка вернётся по истечении lease
+func (w *WebhookRepo) claimDue(ctx context.Context, limit int, lease time.Duration) ([]*DueDeliveryEntity, error) {
+	var entities []*DueDeliveryEntity
+	err := w.db.Select(ctx, &entities, `
+		WITH due AS (
+			SELECT id
+			FROM webhook_delivery
+			WHERE status IN ('PENDING', 'FAILED')
+			  AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_delivery d
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due, webhook_subscription s
+		WHERE d.id = due.id AND s.id = d.subscription_id
+		RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+		          d.response_code, d.last_error, d.next_attempt_at, d.created_at, d.updated_at, s.url, s.secret
+	`, limit, lease.Seconds())
+	if err != nil {
+		log.Printf("[WebhookRepo.claimDue] db error claiming due deliveries: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	return entities, nil
+}
+
+func (w *WebhookRepo) updateDelivery(ctx context.Context, entity *DeliveryEntity, retryIn time.Duration) error {
+	err := w.db.ExecQueryRow(ctx, `
+		UPDATE webhook_delivery
+		SET status = $1, attempts = $2, response_code = $3, last_error = $4,
+		    next_attempt_at = NOW() + make_interval(secs => $5), updated_at = NOW()
+		WHERE id = $6
+		RETURNING next_attempt_at, updated_at
+	`, entity.Status, entity.Attempts, entity.ResponseCode, entity.LastError, retryIn.Seconds(), entity.ID).Scan(&entity.NextAttemptAt, &entity.UpdatedAt)
+	if err != nil {
+		log.Printf("[WebhookRepo.updateDelivery] db error updating delivery %d: %v", entity.ID, err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
+
+func (w *WebhookRepo) listDeliveries(ctx context.Context, subscriptionID uint64, status string) ([]*DeliveryEntity, error) {
+	var entities []*DeliveryEntity
+	err := w.db.Select(ctx, &entities, `
+		SELECT d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+		       d.response_code, d.last_error, d.next_attempt_at, d.created_at, d.updated_at
+		FROM webhook_delivery d
+		JOIN webhook_subscription s ON s.id = d.subscription_id
+		WHERE s.tenant_id = $1
+		  AND ($2 = 0 OR d.subscription_id = $2)
+		  AND ($3 = '' OR d.status = $3)
+		ORDER BY d.id DESC
+		LIMIT 100
+	`, tenant.FromContext(ctx), subscriptionID, status)
+	if err != nil {
+		log.Printf("[WebhookRepo.listDeliveries] db error fetching deliveries: %v", err)
+		return nil, apperrors.ErrDB
+	}
+	log.Printf("[WebhookRepo.listDeliveries] fetched %d deliveries", len(entities))
+	return entities, nil
+}
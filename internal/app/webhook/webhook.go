@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"avito-tech/internal/app/events"
+	"avito-tech/internal/apperrors"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type Repo interface {
+	create(ctx context.Context, entity *SubscriptionEntity) (*SubscriptionEntity, error)
+	list(ctx context.Context) ([]*SubscriptionEntity, error)
+	delete(ctx context.Context, id uint64) error
+	getByID(ctx context.Context, id uint64) (*SubscriptionEntity, error)
+	getSubscribed(ctx context.Context, eventType string) ([]*SubscriptionEntity, error)
+	createDelivery(ctx context.Context, entity *DeliveryEntity) (*DeliveryEntity, bool, error)
+	claimDue(ctx context.Context, limit int, lease time.Duration) ([]*DueDeliveryEntity, error)
+	// updateDelivery записывает исход попытки; следующая попытка - через retryIn от времени БД, как и lease
+	// в claimDue
+	updateDelivery(ctx context.Context, entity *DeliveryEntity, retryIn time.Duration) error
+	listDeliveries(ctx context.Context, subscriptionID uint64, status string) ([]*DeliveryEntity, error)
+}
+
+type Webhook struct {
+	repo       Repo
+	dispatcher *Dispatcher
+}
+
+func NewWebhook(repo Repo, client *http.Client, policy RetryPolicy) *Webhook {
+	return &Webhook{
+		repo:       repo,
+		dispatcher: newDispatcher(repo, client, policy),
+	}
+}
+
+func (w *Webhook) Create(ctx context.Context, dto *SubscriptionDTO) (*SubscriptionDTO, error) {
+	u, err := url.Parse(dto.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http(s) url", apperrors.ErrBadRequest)
+	}
+	for _, t := range dto.EventTypes {
+		if !events.IsKnownType(t) {
+			return nil, fmt.Errorf("%w: unknown event type '%s'", apperrors.ErrBadRequest, t)
+		}
+	}
+	if dto.EventTypes == nil {
+		dto.EventTypes = []string{}
+	}
+	if dto.Secret == "" {
+		dto.Secret = newSecret()
+	}
+
+	entity, err := w.repo.create(ctx, dto.MapToModel())
+	if err != nil {
+		return nil, err
+	}
+	var answer SubscriptionDTO
+	answer.MapFromModel(entity)
+	// секрет отдаём только при создании подписки
+	answer.Secret = entity.Secret
+	return &answer, nil
+}
+
+func (w *Webhook) List(ctx context.Context) ([]*SubscriptionDTO, error) {
+	entities, err := w.repo.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return MapFromSubscriptionModels(entities), nil
+}
+
+func (w *Webhook) Delete(ctx context.Context, id uint64) error {
+	return w.repo.delete(ctx, id)
+}
+
+func (w *Webhook) Deliveries(ctx context.Context, subscriptionID uint64, status string) ([]*DeliveryDTO, error) {
+	if subscriptionID != 0 {
+		if _, err := w.repo.getByID(ctx, subscriptionID); err != nil {
+			return nil, err
+		}
+	}
+	entities, err := w.repo.listDeliveries(ctx, subscriptionID, status)
+	if err != nil {
+		return nil, err
+	}
+	return MapFromDeliveryModels(entities), nil
+}
+
+func (w *Webhook) DeadLetters(ctx context.Context) ([]*DeliveryDTO, error) {
+	return w.Deliveries(ctx, 0, DeliveryDead)
+}
+
+// Publish ставит событие в доставку всем подходящим подпискам арендатора из контекста.
+// Доставку выполняет DeliverDue, ошибки доставки попадают в журнал доставок.
+// Повторная публикация того же события (at-least-once из outbox) не создаёт дублей.
+func (w *Webhook) Publish(ctx context.Context, ev *events.Event) error {
+	subs, err := w.repo.getSubscribed(ctx, ev.Type)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if err := w.dispatcher.enqueue(ctx, sub, ev); err != nil {
+			log.Printf("[Webhook.Publish] failed to enqueue event '%s' for subscription %d: %v", ev.ID, sub.ID, err)
+			return err
+		}
+	}
+	return nil
+}
+
+// DeliverDue отправляет доставки, которым подошло время (первая попытка или повтор);
+// запускается планировщиком
+func (w *Webhook) DeliverDue(ctx context.Context) (int, error) {
+	return w.dispatcher.deliverDue(ctx)
+}
+
+func newSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -3,11 +3,23 @@ package apperrors
 import "errors"
 
 var (
-	ErrNotFound    = errors.New("not found")
-	ErrDB          = errors.New("error with db")
-	ErrTeamExists  = errors.New("team already exists")
-	ErrPRExists    = errors.New("pr already exists")
-	ErrPRMerged    = errors.New("pr already merged")
-	ErrNotAssigned = errors.New("reviewer is not assigned to this PR")
-	ErrNoCandidate = errors.New("no active replacement candidate in team")
+	ErrNotFound     = errors.New("not found")
+	ErrDB           = errors.New("error with db")
+	ErrTeamExists   = errors.New("team already exists")
+	ErrPRExists     = errors.New("pr already exists")
+	ErrPRMerged     = errors.New("pr already merged")
+	ErrNotAssigned  = errors.New("reviewer is not assigned to this PR")
+	ErrNoCandidate  = errors.New("no active replacement candidate in team")
+	ErrNotApproved  = errors.New("pr does not satisfy the merge policy")
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrRateLimited  = errors.New("rate limit exceeded")
+	ErrOverloaded   = errors.New("service is overloaded")
+
+	ErrIdempotencyMismatch   = errors.New("idempotency key reused with a different request")
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
+	ErrPreconditionFailed    = errors.New("resource version does not match If-Match")
+	ErrNotSupported          = errors.New("not supported by the configured storage")
+	ErrInvalidTransition     = errors.New("invalid pr status transition")
 )
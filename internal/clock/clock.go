@@ -0,0 +1,49 @@
+// Package clock - источник текущего времени. Сервисы получают его снаружи, чтобы сценарии и
+// тесты могли зафиксировать момент, от которого зависят окна недоступности и рабочие часы.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+type Clock interface {
+	Now() time.Time
+}
+
+// System - системные часы
+type System struct{}
+
+func (System) Now() time.Time {
+	return time.Now()
+}
+
+// Manual - часы, которые стоят, пока их не передвинут
+type Manual struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewManual(now time.Time) *Manual {
+	return &Manual{
+		now: now,
+	}
+}
+
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+func (m *Manual) Set(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = now
+}
+
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+}
@@ -0,0 +1,335 @@
+// Package concurrency - нагрузочный сценарий для create/reassign/merge.
+// Запускает операции над одними и теми же PR параллельно и проверяет инварианты
+// назначения ревьюверов. Работает с любой реализацией хранилища через доменные сервисы;
+// подключение хранилищ и запуск через go test - в concurrency_test.go.
+package concurrency
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+)
+
+type Teams interface {
+	Create(ctx context.Context, dto *team.TeamDTO) error
+}
+
+type Users interface {
+	GetByID(ctx context.Context, id string) (*user.UserDTO, error)
+}
+
+type PullRequests interface {
+	GetByID(ctx context.Context, prID string) (*pullrequest.PullRequestDTOFromHttp, error)
+	Create(ctx context.Context, prShort *pullrequest.PullRequestShortDTOFromHttp) (*pullrequest.PullRequestDTOFromHttp, error)
+	Merge(ctx context.Context, prID string, ifMatch *uint64, policy *pullrequest.MergePolicy) (*pullrequest.PullRequestDTOFromHttp, error)
+	Reassign(ctx context.Context, prID string, oldUserID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, string, error)
+}
+
+type Config struct {
+	// Members - размер тестовой команды, каждый четвёртый участник неактивен
+	Members      int
+	PullRequests int
+	Workers      int
+	// Operations - число reassign в первой фазе, где PR ещё не мёржатся
+	Operations int
+	// MergeEvery - во второй фазе мёржится каждый MergeEvery-й PR, одновременно с reassign
+	MergeEvery   int
+	MaxReviewers int
+	// MaxOpenReviews - лимит открытых ревью команды, 0 - без лимита. Почти впритык к числу назначений,
+	// чтобы параллельные назначения боролись за последние свободные места
+	MaxOpenReviews int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		Members:      8,
+		PullRequests: 20,
+		Workers:      16,
+		Operations:   500,
+		MergeEvery:   2,
+		MaxReviewers: pullrequest.MaxReviewers,
+		// 6 активных по 7 - 42 места на 40 назначений
+		MaxOpenReviews: 7,
+	}
+}
+
+type Report struct {
+	Operations int
+	// Outcomes - число операций по исходу: "ok" или текст ожидаемой ошибки
+	Outcomes   map[string]int
+	Unexpected []string
+	Violations []string
+}
+
+func (r *Report) Failed() bool {
+	return len(r.Unexpected) > 0 || len(r.Violations) > 0
+}
+
+// ожидаемые отказы при гонках; всё остальное (в том числе ErrDB) - провал сценария
+var expected = []error{
+	apperrors.ErrPRExists,
+	apperrors.ErrPRMerged,
+	apperrors.ErrNotAssigned,
+	apperrors.ErrNoCandidate,
+	apperrors.ErrPreconditionFailed,
+}
+
+type Suite struct {
+	teams Teams
+	users Users
+	prs   PullRequests
+	cfg   Config
+
+	mu       sync.Mutex
+	report   *Report
+	snapshot map[string]*pullrequest.PullRequestDTOFromHttp
+}
+
+func NewSuite(teams Teams, users Users, prs PullRequests, cfg Config) *Suite {
+	return &Suite{
+		teams: teams,
+		users: users,
+		prs:   prs,
+		cfg:   cfg,
+	}
+}
+
+// Run создаёт отдельную команду с уникальным префиксом, поэтому его можно запускать
+// на непустой базе. Ошибка возвращается, если нарушен хотя бы один инвариант.
+func (s *Suite) Run(ctx context.Context) (*Report, error) {
+	s.report = &Report{Outcomes: map[string]int{}}
+	s.snapshot = map[string]*pullrequest.PullRequestDTOFromHttp{}
+
+	prefix := fmt.Sprintf("conc-%d", time.Now().UnixNano())
+	members := make([]team.TeamMemberDTO, s.cfg.Members)
+	for i := range members {
+		members[i] = team.TeamMemberDTO{
+			UserID:   fmt.Sprintf("%s-u%d", prefix, i),
+			Username: fmt.Sprintf("user %d", i),
+			IsActive: i%4 != 3,
+		}
+	}
+	teamDTO := &team.TeamDTO{TeamName: prefix, Members: members}
+	if s.cfg.MaxOpenReviews > 0 {
+		teamDTO.MaxOpenReviews = &s.cfg.MaxOpenReviews
+	}
+	if err := s.teams.Create(ctx, teamDTO); err != nil {
+		return nil, fmt.Errorf("create team: %w", err)
+	}
+
+	prIDs := make([]string, s.cfg.PullRequests)
+	for i := range prIDs {
+		prIDs[i] = fmt.Sprintf("%s-pr%d", prefix, i)
+	}
+
+	// каждый PR создаётся дважды одновременно: ровно одна попытка должна пройти
+	var wg sync.WaitGroup
+	created := make([]int, len(prIDs))
+	for i, id := range prIDs {
+		for range 2 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := s.prs.Create(ctx, &pullrequest.PullRequestShortDTOFromHttp{
+					PullRequestID:   id,
+					PullRequestName: id,
+					AuthorID:        members[i%len(members)].UserID,
+				})
+				if s.record("create", err) {
+					s.mu.Lock()
+					created[i]++
+					s.mu.Unlock()
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	for i, n := range created {
+		if n != 1 {
+			s.violation("PR '%s' created %d times", prIDs[i], n)
+		}
+	}
+
+	// первая фаза - только reassign: воркеры постоянно сталкиваются на одних PR и ревьюверах
+	var tasks []task
+	for range s.cfg.Operations {
+		tasks = append(tasks, task{prID: prIDs[rand.Intn(len(prIDs))]})
+	}
+	s.runTasks(ctx, tasks)
+
+	// вторая фаза - двойной merge части PR вперемешку с reassign тех же и остальных PR
+	tasks = tasks[:0]
+	for i, id := range prIDs {
+		if s.cfg.MergeEvery > 0 && i%s.cfg.MergeEvery == 0 {
+			tasks = append(tasks, task{prID: id, merge: true}, task{prID: id, merge: true})
+		}
+	}
+	for range s.cfg.Operations / 4 {
+		tasks = append(tasks, task{prID: prIDs[rand.Intn(len(prIDs))]})
+	}
+	rand.Shuffle(len(tasks), func(i, j int) { tasks[i], tasks[j] = tasks[j], tasks[i] })
+	s.runTasks(ctx, tasks)
+
+	open := map[string]int{}
+	for _, id := range prIDs {
+		s.check(ctx, id, prefix, open)
+	}
+	for userID, n := range open {
+		if s.cfg.MaxOpenReviews > 0 && n > s.cfg.MaxOpenReviews {
+			s.violation("reviewer '%s' has %d open reviews, limit is %d", userID, n, s.cfg.MaxOpenReviews)
+		}
+	}
+
+	log.Printf("[Suite.Run] %d operations, outcomes %v, %d unexpected errors, %d violations",
+		s.report.Operations, s.report.Outcomes, len(s.report.Unexpected), len(s.report.Violations))
+	if s.report.Failed() {
+		return s.report, errors.New("concurrency invariants violated")
+	}
+	return s.report, nil
+}
+
+type task struct {
+	prID  string
+	merge bool
+}
+
+// runTasks выполняет задачи на cfg.Workers воркерах и дожидается всех
+func (s *Suite) runTasks(ctx context.Context, tasks []task) {
+	var wg sync.WaitGroup
+	ops := make(chan task)
+	for range s.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range ops {
+				s.step(ctx, t)
+			}
+		}()
+	}
+	for _, t := range tasks {
+		ops <- t
+	}
+	close(ops)
+	wg.Wait()
+}
+
+// step - одна операция: merge или reassign случайного текущего ревьювера
+func (s *Suite) step(ctx context.Context, t task) {
+	prID := t.prID
+	if t.merge {
+		dto, err := s.prs.Merge(ctx, prID, nil, nil)
+		if s.record("merge", err) {
+			s.mu.Lock()
+			if _, ok := s.snapshot[prID]; !ok {
+				s.snapshot[prID] = dto
+			}
+			s.mu.Unlock()
+		}
+		return
+	}
+
+	dto, err := s.prs.GetByID(ctx, prID)
+	if !s.record("get", err) || len(dto.AssignedReviewers) == 0 {
+		return
+	}
+	old := dto.AssignedReviewers[rand.Intn(len(dto.AssignedReviewers))]
+	updated, newID, err := s.prs.Reassign(ctx, prID, old, nil)
+	if !s.record("reassign", err) {
+		return
+	}
+	if newID == old || !slices.Contains(updated.AssignedReviewers, newID) || slices.Contains(updated.AssignedReviewers, old) {
+		s.violation("PR '%s': reassign of '%s' returned '%s' with reviewers %v", prID, old, newID, updated.AssignedReviewers)
+	}
+}
+
+// check проверяет итоговое состояние PR и считает открытые ревью по ревьюверам в open
+func (s *Suite) check(ctx context.Context, prID string, teamName string, open map[string]int) {
+	dto, err := s.prs.GetByID(ctx, prID)
+	if err != nil {
+		s.unexpected("final get of PR '%s': %v", prID, err)
+		return
+	}
+
+	if len(dto.AssignedReviewers) > s.cfg.MaxReviewers {
+		s.violation("PR '%s' has %d reviewers, max is %d", prID, len(dto.AssignedReviewers), s.cfg.MaxReviewers)
+	}
+	seen := map[string]bool{}
+	for _, r := range dto.AssignedReviewers {
+		if seen[r] {
+			s.violation("PR '%s' has reviewer '%s' twice", prID, r)
+		}
+		seen[r] = true
+		if dto.Status == "OPEN" {
+			open[r]++
+		}
+		if r == dto.AuthorID {
+			s.violation("PR '%s' has its author '%s' as reviewer", prID, r)
+		}
+		reviewer, err := s.users.GetByID(ctx, r)
+		if err != nil {
+			s.unexpected("get reviewer '%s' of PR '%s': %v", r, prID, err)
+			continue
+		}
+		if !reviewer.IsActive || reviewer.TeamName != teamName {
+			s.violation("PR '%s' has inactive or foreign reviewer '%s'", prID, r)
+		}
+	}
+
+	merged, ok := s.snapshot[prID]
+	if !ok {
+		return
+	}
+	if dto.Status != "MERGED" {
+		s.violation("PR '%s' was merged but is now '%s'", prID, dto.Status)
+	}
+	before := slices.Clone(merged.AssignedReviewers)
+	after := slices.Clone(dto.AssignedReviewers)
+	slices.Sort(before)
+	slices.Sort(after)
+	if !slices.Equal(before, after) {
+		s.violation("PR '%s' reviewers changed after merge: %v -> %v", prID, before, after)
+	}
+	if merged.MergedAt == nil || dto.MergedAt == nil || !merged.MergedAt.Equal(*dto.MergedAt) {
+		s.violation("PR '%s' mergedAt changed after merge", prID)
+	}
+}
+
+// record учитывает исход операции и возвращает true, если она прошла успешно
+func (s *Suite) record(op string, err error) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report.Operations++
+	if err == nil {
+		s.report.Outcomes[op+": ok"]++
+		return true
+	}
+	for _, e := range expected {
+		if errors.Is(err, e) {
+			s.report.Outcomes[op+": "+e.Error()]++
+			return false
+		}
+	}
+	s.report.Unexpected = append(s.report.Unexpected, fmt.Sprintf("%s: %v", op, err))
+	return false
+}
+
+func (s *Suite) violation(format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report.Violations = append(s.report.Violations, fmt.Sprintf(format, args...))
+}
+
+func (s *Suite) unexpected(format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.report.Unexpected = append(s.report.Unexpected, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,100 @@
+package concurrency_test
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/concurrency"
+	"avito-tech/internal/db"
+	"avito-tech/internal/db/memory"
+	"avito-tech/internal/db/sqlite"
+	"flag"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// TestMain глушит журнал репозиториев; с -v он остаётся
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+func TestMemory(t *testing.T) {
+	store := memory.NewStore()
+	run(t, concurrency.NewSuite(
+		team.NewTeam(team.NewTeamMemoryRepo(store)),
+		user.NewUser(user.NewUserMemoryRepo(store)),
+		pullrequest.NewPullRequest(pullrequest.NewMemoryRepo(store), pullrequest.Assignment{}),
+		config(),
+	))
+}
+
+func TestSQLite(t *testing.T) {
+	database, err := sqlite.Open(t.Context(), filepath.Join(t.TempDir(), "concurrency.db"))
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	run(t, concurrency.NewSuite(
+		team.NewTeam(team.NewTeamSQLiteRepo(database)),
+		user.NewUser(user.NewUserSQLiteRepo(database)),
+		pullrequest.NewPullRequest(pullrequest.NewSQLiteRepo(database), pullrequest.Assignment{}),
+		config(),
+	))
+}
+
+// TestPostgres работает с уже мигрированной базой из TEST_POSTGRES_DSN
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	database, err := db.Connect(t.Context(), dsn)
+	if err != nil {
+		t.Fatalf("connect to postgres: %v", err)
+	}
+	t.Cleanup(func() { database.GetPool(t.Context()).Close() })
+	run(t, concurrency.NewSuite(
+		team.NewTeam(team.NewTeamRepo(database)),
+		user.NewUser(user.NewUserRepo(database)),
+		pullrequest.NewPullRequest(pullrequest.NewRepo(database), pullrequest.Assignment{}),
+		config(),
+	))
+}
+
+func config() concurrency.Config {
+	cfg := concurrency.DefaultConfig()
+	if testing.Short() {
+		cfg.Operations = 100
+	}
+	return cfg
+}
+
+func run(t *testing.T, suite *concurrency.Suite) {
+	t.Helper()
+	report, err := suite.Run(t.Context())
+	if report == nil {
+		t.Fatalf("suite failed to start: %v", err)
+	}
+	for _, outcome := range slices.Sorted(maps.Keys(report.Outcomes)) {
+		t.Logf("%-60s %d", outcome, report.Outcomes[outcome])
+	}
+	for _, v := range report.Unexpected {
+		t.Errorf("unexpected: %s", v)
+	}
+	for _, v := range report.Violations {
+		t.Errorf("violation: %s", v)
+	}
+	// без успешных reassign сценарий не проверяет гонки reassign против reassign
+	if ok := report.Outcomes["reassign: ok"]; ok < concurrency.DefaultConfig().Workers {
+		t.Errorf("only %d reassigns succeeded, the mix does not exercise concurrent reassigns", ok)
+	}
+}
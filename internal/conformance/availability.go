@@ -0,0 +1,205 @@
+package conformance
+
+import (
+	"avito-tech/internal/app/availability"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AvailabilityCases - окна недоступности и передача ревью при их начале
+var AvailabilityCases = []Case{
+	{Name: "unavailable_not_assigned", Run: unavailableNotAssigned},
+	{Name: "reassign_skips_unavailable", Run: reassignSkipsUnavailable},
+	{Name: "handover_reassigns_open_reviews", Run: handoverReassignsOpenReviews},
+	{Name: "invalid_window_rejected", Run: invalidWindowRejected},
+}
+
+// NewWindow создаёт окно недоступности пользователя со сдвигами от текущего момента по часам сценария
+func (s *Scenario) NewWindow(ctx context.Context, userID string, from, to time.Duration, handover bool) (*availability.WindowDTO, error) {
+	now := s.Clock.Now()
+	w, err := s.Availability.Create(ctx, &availability.WindowDTO{
+		UserID:   userID,
+		StartsAt: now.Add(from),
+		EndsAt:   now.Add(to),
+		Reason:   "vacation",
+		Handover: handover,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create window for %s: %w", userID, err)
+	}
+	return w, nil
+}
+
+func unavailableNotAssigned(ctx context.Context, s *Scenario) error {
+	author, away, later, back := s.Member("author", true), s.Member("away", true), s.Member("later", true), s.Member("back", true)
+	if _, err := s.NewTeam(ctx, "t", author, away, later, back); err != nil {
+		return err
+	}
+	if _, err := s.NewWindow(ctx, away.UserID, -time.Hour, time.Hour, false); err != nil {
+		return err
+	}
+	// будущие и прошедшие окна не мешают назначению
+	if _, err := s.NewWindow(ctx, later.UserID, time.Hour, 2*time.Hour, false); err != nil {
+		return err
+	}
+	if _, err := s.NewWindow(ctx, back.UserID, -2*time.Hour, -time.Hour, false); err != nil {
+		return err
+	}
+
+	for _, name := range []string{"pr1", "pr2", "pr3"} {
+		pr, err := s.NewPR(ctx, name, author.UserID)
+		if err != nil {
+			return err
+		}
+		if !sameSet(pr.AssignedReviewers, []string{later.UserID, back.UserID}) {
+			return fmt.Errorf("%s: expected only available reviewers, got %v", name, pr.AssignedReviewers)
+		}
+	}
+
+	windows, err := s.Availability.List(ctx, away.UserID)
+	if err != nil {
+		return err
+	}
+	if len(windows) != 1 || windows[0].Handover || windows[0].HandedOverAt != nil {
+		return fmt.Errorf("expected one window without handover, got %+v", windows)
+	}
+	return nil
+}
+
+func reassignSkipsUnavailable(ctx context.Context, s *Scenario) error {
+	author, spare := s.Member("author", true), s.Member("spare", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true), spare); err != nil {
+		return err
+	}
+	window, err := s.NewWindow(ctx, spare.UserID, -time.Minute, time.Hour, false)
+	if err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if len(pr.AssignedReviewers) != 2 || slices.Contains(pr.AssignedReviewers, spare.UserID) {
+		return fmt.Errorf("unavailable member should not be assigned, got %v", pr.AssignedReviewers)
+	}
+
+	_, _, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, pr.AssignedReviewers[0], nil)
+	if err := expectErr(err, apperrors.ErrNoCandidate); err != nil {
+		return err
+	}
+	if strings.Contains(err.Error(), "max_open_reviews") {
+		return fmt.Errorf("unavailability is not a capacity problem, got %q", err)
+	}
+
+	// удалённое окно больше не действует
+	if err := s.Availability.Delete(ctx, window.ID); err != nil {
+		return err
+	}
+	_, replacedBy, err := s.PullRequests.Reassign(ctx, pr.PullRequestID, pr.AssignedReviewers[0], nil)
+	if err != nil {
+		return err
+	}
+	if replacedBy != spare.UserID {
+		return fmt.Errorf("expected %s after the window was deleted, got %s", spare.UserID, replacedBy)
+	}
+	return nil
+}
+
+func handoverReassignsOpenReviews(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true), s.Member("spare", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	leaving, staying := pr.AssignedReviewers[0], pr.AssignedReviewers[1]
+
+	// окно без handover и будущее окно с handover ничего не передают
+	if _, err := s.NewWindow(ctx, staying, -time.Minute, time.Hour, false); err != nil {
+		return err
+	}
+	if _, err := s.NewWindow(ctx, staying, time.Hour, 2*time.Hour, true); err != nil {
+		return err
+	}
+	if _, err := s.NewWindow(ctx, leaving, -time.Minute, time.Hour, true); err != nil {
+		return err
+	}
+	if _, err := s.Handover.RunOnce(ctx); err != nil {
+		return err
+	}
+
+	pr, err = s.PullRequests.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(pr.AssignedReviewers, leaving) || !slices.Contains(pr.AssignedReviewers, staying) || len(pr.AssignedReviewers) != 2 {
+		return fmt.Errorf("only %s should be handed over, got %v", leaving, pr.AssignedReviewers)
+	}
+	reviews, err := s.Users.GetReview(ctx, leaving)
+	if err != nil {
+		return err
+	}
+	if len(reviews) != 0 {
+		return fmt.Errorf("%s should have no reviews after handover, got %d", leaving, len(reviews))
+	}
+
+	windows, err := s.Availability.List(ctx, leaving)
+	if err != nil {
+		return err
+	}
+	if len(windows) != 1 || windows[0].HandedOverAt == nil {
+		return fmt.Errorf("window should be marked as handed over, got %+v", windows)
+	}
+	windows, err = s.Availability.List(ctx, staying)
+	if err != nil {
+		return err
+	}
+	for _, w := range windows {
+		if w.HandedOverAt != nil {
+			return fmt.Errorf("window %d of %s should not be handed over", w.ID, staying)
+		}
+	}
+
+	// окно обрабатывается один раз: повторный проход не трогает PR
+	if _, err := s.Handover.RunOnce(ctx); err != nil {
+		return err
+	}
+	again, err := s.PullRequests.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(again.AssignedReviewers, pr.AssignedReviewers) {
+		return fmt.Errorf("second pass changed reviewers: %v -> %v", pr.AssignedReviewers, again.AssignedReviewers)
+	}
+	return nil
+}
+
+func invalidWindowRejected(ctx context.Context, s *Scenario) error {
+	a := s.Member("a", true)
+	if _, err := s.NewTeam(ctx, "t", a); err != nil {
+		return err
+	}
+	if _, err := s.NewWindow(ctx, a.UserID, time.Hour, time.Hour, false); expectErr(err, apperrors.ErrBadRequest) != nil {
+		return fmt.Errorf("empty window: expected %v, got %v", apperrors.ErrBadRequest, err)
+	}
+	if _, err := s.NewWindow(ctx, a.UserID, time.Hour, -time.Hour, false); expectErr(err, apperrors.ErrBadRequest) != nil {
+		return fmt.Errorf("reversed window: expected %v, got %v", apperrors.ErrBadRequest, err)
+	}
+	_, err := s.Availability.Create(ctx, &availability.WindowDTO{UserID: a.UserID, EndsAt: s.Clock.Now()})
+	if err := expectErr(err, apperrors.ErrBadRequest); err != nil {
+		return fmt.Errorf("missing starts_at: %w", err)
+	}
+	if _, err := s.NewWindow(ctx, s.ID("nobody"), 0, time.Hour, false); expectErr(err, apperrors.ErrNotFound) != nil {
+		return fmt.Errorf("unknown user: expected %v, got %v", apperrors.ErrNotFound, err)
+	}
+	if _, err := s.Availability.List(ctx, s.ID("nobody")); expectErr(err, apperrors.ErrNotFound) != nil {
+		return fmt.Errorf("list of unknown user: expected %v, got %v", apperrors.ErrNotFound, err)
+	}
+	return expectErr(s.Availability.Delete(ctx, 1<<62), apperrors.ErrNotFound)
+}
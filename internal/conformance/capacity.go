@@ -0,0 +1,230 @@
+package conformance
+
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// CapacityCases - лимит открытых ревью (max_open_reviews) при назначении и переназначении
+var CapacityCases = []Case{
+	{Name: "capacity_skips_full_reviewers", Run: capacitySkipsFullReviewers},
+	{Name: "personal_limit_overrides_team", Run: personalLimitOverridesTeam},
+	{Name: "reassign_no_candidate_capacity", Run: reassignNoCandidateCapacity},
+	{Name: "load_counts_open_reviews", Run: loadCountsOpenReviews},
+	{Name: "negative_limit_rejected", Run: negativeLimitRejected},
+}
+
+func limit(n int) *int {
+	return &n
+}
+
+func capacitySkipsFullReviewers(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	members := []string{s.ID("a"), s.ID("b"), s.ID("c")}
+	teamName, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true), s.Member("c", true))
+	if err != nil {
+		return err
+	}
+	if err := s.Teams.SetMaxOpenReviews(ctx, teamName, limit(1)); err != nil {
+		return err
+	}
+
+	first, err := s.NewPR(ctx, "pr1", author.UserID)
+	if err != nil {
+		return err
+	}
+	second, err := s.NewPR(ctx, "pr2", author.UserID)
+	if err != nil {
+		return err
+	}
+	if len(first.AssignedReviewers) != 2 || len(second.AssignedReviewers) != 1 {
+		return fmt.Errorf("with limit 1 expected 2 then 1 reviewers, got %v and %v", first.AssignedReviewers, second.AssignedReviewers)
+	}
+	if slices.Contains(first.AssignedReviewers, second.AssignedReviewers[0]) {
+		return fmt.Errorf("reviewer %s is over capacity", second.AssignedReviewers[0])
+	}
+	third, err := s.NewPR(ctx, "pr3", author.UserID)
+	if err != nil {
+		return err
+	}
+	if len(third.AssignedReviewers) != 0 {
+		return fmt.Errorf("everyone is at capacity: expected 0 reviewers, got %v", third.AssignedReviewers)
+	}
+	for _, id := range members {
+		load, err := s.Users.GetLoad(ctx, id)
+		if err != nil {
+			return err
+		}
+		if load.OpenReviews != 1 || !load.AtCapacity {
+			return fmt.Errorf("user %s: expected 1 open review at capacity, got %+v", id, load)
+		}
+	}
+
+	// merge освобождает место
+	if _, err := s.PullRequests.Merge(ctx, first.PullRequestID, nil, nil); err != nil {
+		return err
+	}
+	fourth, err := s.NewPR(ctx, "pr4", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(fourth.AssignedReviewers, first.AssignedReviewers) {
+		return fmt.Errorf("reviewers of the merged PR should be free again, got %v", fourth.AssignedReviewers)
+	}
+	return nil
+}
+
+func personalLimitOverridesTeam(ctx context.Context, s *Scenario) error {
+	author, a := s.Member("author", true), s.Member("a", true)
+	teamName, err := s.NewTeam(ctx, "t", author, a, s.Member("b", true))
+	if err != nil {
+		return err
+	}
+	if err := s.Teams.SetMaxOpenReviews(ctx, teamName, limit(0)); err != nil {
+		return err
+	}
+	if _, err := s.Users.SetMaxOpenReviews(ctx, a.UserID, limit(5)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{a.UserID}) {
+		return fmt.Errorf("only the member with a personal limit should be assigned, got %v", pr.AssignedReviewers)
+	}
+
+	// nil возвращает к лимиту команды
+	if _, err := s.Users.SetMaxOpenReviews(ctx, a.UserID, nil); err != nil {
+		return err
+	}
+	load, err := s.Users.GetLoad(ctx, a.UserID)
+	if err != nil {
+		return err
+	}
+	if load.MaxOpenReviews == nil || *load.MaxOpenReviews != 0 || load.OpenReviews != 1 {
+		return fmt.Errorf("expected team limit 0 with 1 open review, got %+v", load)
+	}
+	pr, err = s.NewPR(ctx, "pr2", author.UserID)
+	if err != nil {
+		return err
+	}
+	if len(pr.AssignedReviewers) != 0 {
+		return fmt.Errorf("team limit 0: expected no reviewers, got %v", pr.AssignedReviewers)
+	}
+	return nil
+}
+
+func reassignNoCandidateCapacity(ctx context.Context, s *Scenario) error {
+	author, spare := s.Member("author", true), s.Member("spare", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true), spare); err != nil {
+		return err
+	}
+	// свободный участник уже занят: лимит 0
+	if _, err := s.Users.SetMaxOpenReviews(ctx, spare.UserID, limit(0)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if len(pr.AssignedReviewers) != 2 || slices.Contains(pr.AssignedReviewers, spare.UserID) {
+		return fmt.Errorf("member with limit 0 should not be assigned, got %v", pr.AssignedReviewers)
+	}
+
+	_, _, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, pr.AssignedReviewers[0], nil)
+	if err := expectErr(err, apperrors.ErrNoCandidate); err != nil {
+		return err
+	}
+	if !strings.Contains(err.Error(), "max_open_reviews") {
+		return fmt.Errorf("NO_CANDIDATE should name capacity as the cause, got %q", err)
+	}
+
+	// причина - активность, а не лимит
+	if _, err := s.Users.SetIsActive(ctx, spare.UserID, false, nil); err != nil {
+		return err
+	}
+	_, _, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, pr.AssignedReviewers[0], nil)
+	if err := expectErr(err, apperrors.ErrNoCandidate); err != nil {
+		return err
+	}
+	if strings.Contains(err.Error(), "max_open_reviews") {
+		return fmt.Errorf("no active candidate is not a capacity problem, got %q", err)
+	}
+
+	if _, err := s.Users.SetIsActive(ctx, spare.UserID, true, nil); err != nil {
+		return err
+	}
+	if _, err := s.Users.SetMaxOpenReviews(ctx, spare.UserID, limit(1)); err != nil {
+		return err
+	}
+	_, replacedBy, err := s.PullRequests.Reassign(ctx, pr.PullRequestID, pr.AssignedReviewers[0], nil)
+	if err != nil {
+		return err
+	}
+	if replacedBy != spare.UserID {
+		return fmt.Errorf("expected %s after raising the limit, got %s", spare.UserID, replacedBy)
+	}
+	return nil
+}
+
+func loadCountsOpenReviews(ctx context.Context, s *Scenario) error {
+	author, reviewer := s.Member("author", true), s.Member("reviewer", true)
+	if _, err := s.NewTeam(ctx, "t", author, reviewer); err != nil {
+		return err
+	}
+	open, err := s.NewPR(ctx, "open", author.UserID)
+	if err != nil {
+		return err
+	}
+	closed, err := s.NewPR(ctx, "closed", author.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.NewDraft(ctx, "draft", author.UserID); err != nil {
+		return err
+	}
+	merged, err := s.NewPR(ctx, "merged", author.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Close(ctx, closed.PullRequestID, nil); err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Merge(ctx, merged.PullRequestID, nil, nil); err != nil {
+		return err
+	}
+
+	load, err := s.Users.GetLoad(ctx, reviewer.UserID)
+	if err != nil {
+		return err
+	}
+	if load.OpenReviews != 1 || load.MaxOpenReviews != nil || load.AtCapacity {
+		return fmt.Errorf("only %s is open and there is no limit, got %+v", open.PullRequestID, load)
+	}
+
+	_, err = s.Users.GetLoad(ctx, s.ID("nobody"))
+	return expectErr(err, apperrors.ErrNotFound)
+}
+
+func negativeLimitRejected(ctx context.Context, s *Scenario) error {
+	a := s.Member("a", true)
+	teamName, err := s.NewTeam(ctx, "t", a)
+	if err != nil {
+		return err
+	}
+	if err := expectErr(s.Teams.SetMaxOpenReviews(ctx, teamName, limit(-1)), apperrors.ErrBadRequest); err != nil {
+		return fmt.Errorf("team: %w", err)
+	}
+	if _, err := s.Users.SetMaxOpenReviews(ctx, a.UserID, limit(-1)); expectErr(err, apperrors.ErrBadRequest) != nil {
+		return fmt.Errorf("user: expected %v, got %v", apperrors.ErrBadRequest, err)
+	}
+	if err := expectErr(s.Teams.SetMaxOpenReviews(ctx, s.ID("missing"), limit(1)), apperrors.ErrNotFound); err != nil {
+		return fmt.Errorf("missing team: %w", err)
+	}
+	_, err = s.Users.SetMaxOpenReviews(ctx, s.ID("nobody"), limit(1))
+	return expectErr(err, apperrors.ErrNotFound)
+}
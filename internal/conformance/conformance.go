@@ -0,0 +1,163 @@
+// Package conformance - набор сценариев, которые должно проходить любое хранилище
+// (Postgres, SQLite, память). Сценарии работают через доменные сервисы team, user и
+// pull_request, каждый - со своим префиксом идентификаторов, поэтому база может быть непустой.
+// Запускаются через go test: подключение хранилищ лежит в *_test.go этого пакета.
+package conformance
+
+import (
+	"avito-tech/internal/app/availability"
+	"avito-tech/internal/app/escalation"
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/clock"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type Teams interface {
+	GetByTeamName(ctx context.Context, teamName string) (*team.TeamDTO, error)
+	Create(ctx context.Context, dto *team.TeamDTO) error
+	SetMaxOpenReviews(ctx context.Context, teamName string, limit *int) error
+	SetEscalationPolicy(ctx context.Context, teamName string, policy *team.EscalationPolicyDTO) error
+	SetFallbackTeams(ctx context.Context, teamName string, fallbackTeams []string) error
+	SetParent(ctx context.Context, teamName string, parentTeamName *string) error
+	Subtree(ctx context.Context, teamName string) (*team.TeamNodeDTO, error)
+	Stats(ctx context.Context, teamName string, subtree bool) (*team.DepartmentStatsDTO, error)
+	Organization(ctx context.Context) ([]*team.TeamNodeDTO, error)
+}
+
+type Users interface {
+	GetByID(ctx context.Context, id string) (*user.UserDTO, error)
+	SetIsActive(ctx context.Context, id string, isActive bool, ifMatch *uint64) (*user.UserDTO, error)
+	SetTeamIsActive(ctx context.Context, teamName string, subtree bool, isActive bool) ([]*user.UserDTO, error)
+	GetReview(ctx context.Context, userID string) ([]*pullrequest.PullRequestShortDTOFromHttp, error)
+	GetLoad(ctx context.Context, userID string) (*user.LoadDTO, error)
+	SetMaxOpenReviews(ctx context.Context, userID string, limit *int) (*user.UserDTO, error)
+	GetWorkingHours(ctx context.Context, userID string) (*user.WorkingHoursDTO, error)
+	SetWorkingHours(ctx context.Context, userID string, dto *user.WorkingHoursDTO) (*user.WorkingHoursDTO, error)
+}
+
+type PullRequests interface {
+	GetByID(ctx context.Context, prID string) (*pullrequest.PullRequestDTOFromHttp, error)
+	Create(ctx context.Context, prShort *pullrequest.PullRequestShortDTOFromHttp) (*pullrequest.PullRequestDTOFromHttp, error)
+	Merge(ctx context.Context, prID string, ifMatch *uint64, policy *pullrequest.MergePolicy) (*pullrequest.PullRequestDTOFromHttp, error)
+	Reassign(ctx context.Context, prID string, oldUserID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, string, error)
+	Review(ctx context.Context, prID string, userID string, decision string, comment string) (*pullrequest.PullRequestDTOFromHttp, error)
+	MarkReady(ctx context.Context, prID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, error)
+	Close(ctx context.Context, prID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, error)
+	Reopen(ctx context.Context, prID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, error)
+}
+
+type Availability interface {
+	Create(ctx context.Context, dto *availability.WindowDTO) (*availability.WindowDTO, error)
+	List(ctx context.Context, userID string) ([]*availability.WindowDTO, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+// Handover - один проход передачи ревью по начавшимся окнам недоступности
+type Handover interface {
+	RunOnce(ctx context.Context) (int, error)
+}
+
+type Escalation interface {
+	List(ctx context.Context, teamName string, subtree bool) ([]*escalation.EscalationDTO, error)
+}
+
+// Escalator - один проход эскалации зависших ревью
+type Escalator interface {
+	RunOnce(ctx context.Context) (int, error)
+}
+
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Services - хранилище, подключённое к доменным сервисам
+type Services struct {
+	Teams        Teams
+	Users        Users
+	PullRequests PullRequests
+	Availability Availability
+	Handover     Handover
+	Escalation   Escalation
+	Escalator    Escalator
+	// Clock - часы назначения, handover и эскалации; сценарии двигают их сами
+	Clock *clock.Manual
+	Tx    TxManager
+}
+
+// Case - один сценарий; ошибка сценария - это провал
+type Case struct {
+	Name string
+	Run  func(ctx context.Context, s *Scenario) error
+}
+
+// Run прогоняет cases (по умолчанию - Cases) подтестами t. open вызывается один раз и подключает
+// хранилище ко всем сценариям; пропустить хранилище можно через t.Skip внутри open.
+func Run(t *testing.T, open func(t *testing.T) Services, cases ...Case) {
+	t.Helper()
+	if len(cases) == 0 {
+		cases = Cases
+	}
+	svc := open(t)
+	run := time.Now().UnixNano()
+	for i, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			s := &Scenario{Services: svc, prefix: fmt.Sprintf("cf-%d-%d", run, i)}
+			if err := c.Run(t.Context(), s); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// Scenario даёт сценарию сервисы и уникальные идентификаторы
+type Scenario struct {
+	Services
+	prefix string
+}
+
+// ID возвращает идентификатор, уникальный для прогона сценария
+func (s *Scenario) ID(name string) string {
+	return s.prefix + "-" + name
+}
+
+// Member - активный (или нет) участник команды с уникальным id
+func (s *Scenario) Member(name string, active bool) team.TeamMemberDTO {
+	return team.TeamMemberDTO{UserID: s.ID(name), Username: name, IsActive: active}
+}
+
+// NewTeam создаёт команду и возвращает её уникальное имя
+func (s *Scenario) NewTeam(ctx context.Context, name string, members ...team.TeamMemberDTO) (string, error) {
+	teamName := s.ID(name)
+	if err := s.Teams.Create(ctx, &team.TeamDTO{TeamName: teamName, Members: members}); err != nil {
+		return "", fmt.Errorf("create team %s: %w", name, err)
+	}
+	return teamName, nil
+}
+
+// NewPR создаёт PR с уникальным id
+func (s *Scenario) NewPR(ctx context.Context, name string, authorID string) (*pullrequest.PullRequestDTOFromHttp, error) {
+	return s.newPR(ctx, name, authorID, "")
+}
+
+// NewDraft создаёт черновик PR с уникальным id
+func (s *Scenario) NewDraft(ctx context.Context, name string, authorID string) (*pullrequest.PullRequestDTOFromHttp, error) {
+	return s.newPR(ctx, name, authorID, pullrequest.StatusDraft)
+}
+
+func (s *Scenario) newPR(ctx context.Context, name string, authorID string, status string) (*pullrequest.PullRequestDTOFromHttp, error) {
+	pr, err := s.PullRequests.Create(ctx, &pullrequest.PullRequestShortDTOFromHttp{
+		PullRequestID:   s.ID(name),
+		PullRequestName: name,
+		AuthorID:        authorID,
+		Status:          status,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create PR %s: %w", name, err)
+	}
+	return pr, nil
+}
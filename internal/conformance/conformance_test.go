@@ -0,0 +1,46 @@
+package conformance_test
+
+import (
+	"avito-tech/internal/app/availability"
+	"avito-tech/internal/app/escalation"
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/clock"
+	"avito-tech/internal/conformance"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestMain глушит журнал репозиториев; с -v он остаётся
+func TestMain(m *testing.M) {
+	flag.Parse()
+	if !testing.Verbose() {
+		log.SetOutput(io.Discard)
+	}
+	os.Exit(m.Run())
+}
+
+// services собирает Services на общих ручных часах. Стратегия - working-hours: без заданных
+// рабочих часов она совпадает с default. Handover переназначает сразу, без фонового цикла.
+func services(teams *team.Team, users *user.User, prRepo pullrequest.Repo, availabilityRepo availability.Repo, escalationRepo escalation.Repo, tx conformance.TxManager) conformance.Services {
+	clk := clock.NewManual(time.Now())
+	prs := pullrequest.NewPullRequest(prRepo, pullrequest.Assignment{Strategy: pullrequest.StrategyWorkingHours, Clock: clk})
+	available := availability.NewAvailability(availabilityRepo)
+	escalations := escalation.NewEscalation(escalationRepo)
+	return conformance.Services{
+		Teams:        teams,
+		Users:        users,
+		PullRequests: prs,
+		Availability: available,
+		Handover:     availability.NewHandover(available, users, prs, clk, 100),
+		Escalation:   escalations,
+		Escalator:    escalation.NewEscalator(escalations, prs, clk, 100),
+		Clock:        clk,
+		Tx:           tx,
+	}
+}
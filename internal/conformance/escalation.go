@@ -0,0 +1,285 @@
+package conformance
+
+import (
+	"avito-tech/internal/app/escalation"
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// EscalationCases - эскалация ревьюверов, не оставивших вердикт за SLA команды
+var EscalationCases = []Case{
+	{Name: "escalation_remind_once", Run: escalationRemindOnce},
+	{Name: "escalation_reassigns_stale_reviewer", Run: escalationReassignsStaleReviewer},
+	{Name: "escalation_notify_lead", Run: escalationNotifyLead},
+	{Name: "escalation_only_open_prs_with_sla", Run: escalationOnlyOpenPRsWithSLA},
+	{Name: "escalation_policy_validated", Run: escalationPolicyValidated},
+}
+
+// NewEscalatingTeam создаёт команду с политикой эскалации
+func (s *Scenario) NewEscalatingTeam(ctx context.Context, name string, slaMinutes int, action string, members ...team.TeamMemberDTO) (string, error) {
+	teamName, err := s.NewTeam(ctx, name, members...)
+	if err != nil {
+		return "", err
+	}
+	policy := &team.EscalationPolicyDTO{ReviewSLAMinutes: slaMinutes, Action: action}
+	if err := s.Teams.SetEscalationPolicy(ctx, teamName, policy); err != nil {
+		return "", fmt.Errorf("set escalation policy of %s: %w", name, err)
+	}
+	return teamName, nil
+}
+
+// Escalations выполняет проход эскалации и возвращает эскалации команды
+func (s *Scenario) Escalations(ctx context.Context, teamName string) ([]*escalation.EscalationDTO, error) {
+	if _, err := s.Escalator.RunOnce(ctx); err != nil {
+		return nil, err
+	}
+	return s.Escalation.List(ctx, teamName, false)
+}
+
+func escalationRemindOnce(ctx context.Context, s *Scenario) error {
+	author, slow, fast := s.Member("author", true), s.Member("a", true), s.Member("b", true)
+	teamName, err := s.NewEscalatingTeam(ctx, "t", 30, team.EscalationRemind, author, slow, fast)
+	if err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Review(ctx, pr.PullRequestID, fast.UserID, pullrequest.ReviewApproved, ""); err != nil {
+		return err
+	}
+
+	// SLA ещё не истёк
+	s.Clock.Advance(29 * time.Minute)
+	got, err := s.Escalations(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(got) != 0 {
+		return fmt.Errorf("nothing is stale yet, got %+v", got)
+	}
+
+	s.Clock.Advance(2 * time.Minute)
+	got, err = s.Escalations(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(got) != 1 || got[0].UserID != slow.UserID || got[0].Action != team.EscalationRemind || got[0].PullRequestID != pr.PullRequestID {
+		return fmt.Errorf("expected one reminder for %s, got %+v", slow.UserID, got)
+	}
+
+	// назначение эскалируется один раз, ревьюверы не меняются
+	s.Clock.Advance(time.Hour)
+	again, err := s.Escalations(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(again) != 1 || again[0].ID != got[0].ID {
+		return fmt.Errorf("reminder should not repeat, got %+v", again)
+	}
+	current, err := s.PullRequests.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(current.AssignedReviewers, pr.AssignedReviewers) {
+		return fmt.Errorf("remind must not change reviewers: %v -> %v", pr.AssignedReviewers, current.AssignedReviewers)
+	}
+	return nil
+}
+
+func escalationReassignsStaleReviewer(ctx context.Context, s *Scenario) error {
+	author, slow, fast, spare := s.Member("author", true), s.Member("a", true), s.Member("b", true), s.Member("spare", true)
+	teamName, err := s.NewEscalatingTeam(ctx, "t", 60, team.EscalationReassign, author, slow, fast, spare)
+	if err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(pr.AssignedReviewers, []string{slow.UserID, fast.UserID}) {
+		return fmt.Errorf("expected %s and %s, got %v", slow.UserID, fast.UserID, pr.AssignedReviewers)
+	}
+	if _, err := s.PullRequests.Review(ctx, pr.PullRequestID, fast.UserID, pullrequest.ReviewApproved, ""); err != nil {
+		return err
+	}
+
+	s.Clock.Advance(61 * time.Minute)
+	got, err := s.Escalations(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(got) != 1 || got[0].UserID != slow.UserID || got[0].ReplacedBy == nil || *got[0].ReplacedBy != spare.UserID {
+		return fmt.Errorf("expected %s replaced by %s, got %+v", slow.UserID, spare.UserID, got)
+	}
+	current, err := s.PullRequests.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(current.AssignedReviewers, []string{spare.UserID, fast.UserID}) {
+		return fmt.Errorf("expected %s instead of %s, got %v", spare.UserID, slow.UserID, current.AssignedReviewers)
+	}
+
+	// у нового ревьювера свой отсчёт SLA
+	s.Clock.Advance(59 * time.Minute)
+	got, err = s.Escalations(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(got) != 1 {
+		return fmt.Errorf("replacement is not stale yet, got %+v", got)
+	}
+	return nil
+}
+
+func escalationNotifyLead(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	teamName, err := s.NewEscalatingTeam(ctx, "t", 15, team.EscalationNotifyLead, author, s.Member("a", true), s.Member("b", true), s.Member("spare", true))
+	if err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+
+	s.Clock.Advance(20 * time.Minute)
+	got, err := s.Escalations(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	var escalated []string
+	for _, e := range got {
+		if e.Action != team.EscalationNotifyLead || e.ReplacedBy != nil {
+			return fmt.Errorf("unexpected escalation %+v", e)
+		}
+		escalated = append(escalated, e.UserID)
+	}
+	if !sameSet(escalated, pr.AssignedReviewers) {
+		return fmt.Errorf("both reviewers should be escalated, got %v", escalated)
+	}
+	current, err := s.PullRequests.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(current.AssignedReviewers, pr.AssignedReviewers) {
+		return fmt.Errorf("notify_lead must not change reviewers: %v -> %v", pr.AssignedReviewers, current.AssignedReviewers)
+	}
+	return nil
+}
+
+func escalationOnlyOpenPRsWithSLA(ctx context.Context, s *Scenario) error {
+	author, other := s.Member("author", true), s.Member("other", true)
+	teamName, err := s.NewEscalatingTeam(ctx, "t", 10, team.EscalationRemind, author, s.Member("a", true), s.Member("b", true))
+	if err != nil {
+		return err
+	}
+	quietTeam, err := s.NewTeam(ctx, "quiet", other, s.Member("c", true))
+	if err != nil {
+		return err
+	}
+	closed, err := s.NewPR(ctx, "closed", author.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Close(ctx, closed.PullRequestID, nil); err != nil {
+		return err
+	}
+	merged, err := s.NewPR(ctx, "merged", author.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Merge(ctx, merged.PullRequestID, nil, nil); err != nil {
+		return err
+	}
+	if _, err := s.NewDraft(ctx, "draft", author.UserID); err != nil {
+		return err
+	}
+	if _, err := s.NewPR(ctx, "quiet", other.UserID); err != nil {
+		return err
+	}
+	open, err := s.NewPR(ctx, "open", author.UserID)
+	if err != nil {
+		return err
+	}
+	// выключенная политика останавливает эскалацию
+	if err := s.Teams.SetEscalationPolicy(ctx, teamName, nil); err != nil {
+		return err
+	}
+
+	s.Clock.Advance(time.Hour)
+	for _, name := range []string{teamName, quietTeam} {
+		got, err := s.Escalations(ctx, name)
+		if err != nil {
+			return err
+		}
+		if len(got) != 0 {
+			return fmt.Errorf("%s: expected no escalations, got %+v", name, got)
+		}
+	}
+
+	if err := s.Teams.SetEscalationPolicy(ctx, teamName, &team.EscalationPolicyDTO{ReviewSLAMinutes: 10}); err != nil {
+		return err
+	}
+	got, err := s.Escalations(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(got) != 2 || slices.ContainsFunc(got, func(e *escalation.EscalationDTO) bool { return e.PullRequestID != open.PullRequestID }) {
+		return fmt.Errorf("only reviewers of %s should be escalated, got %+v", open.PullRequestID, got)
+	}
+	return nil
+}
+
+func escalationPolicyValidated(ctx context.Context, s *Scenario) error {
+	teamName, err := s.NewTeam(ctx, "t", s.Member("a", true))
+	if err != nil {
+		return err
+	}
+	invalid := []*team.EscalationPolicyDTO{
+		{ReviewSLAMinutes: 0, Action: team.EscalationRemind},
+		{ReviewSLAMinutes: -5, Action: team.EscalationRemind},
+		{ReviewSLAMinutes: 30, Action: "shout"},
+	}
+	for _, p := range invalid {
+		if err := expectErr(s.Teams.SetEscalationPolicy(ctx, teamName, p), apperrors.ErrBadRequest); err != nil {
+			return fmt.Errorf("%+v: %w", p, err)
+		}
+	}
+	policy := &team.EscalationPolicyDTO{ReviewSLAMinutes: 30, Action: team.EscalationRemind}
+	if err := expectErr(s.Teams.SetEscalationPolicy(ctx, s.ID("missing"), policy), apperrors.ErrNotFound); err != nil {
+		return fmt.Errorf("missing team: %w", err)
+	}
+	if _, err := s.Escalation.List(ctx, s.ID("missing"), false); expectErr(err, apperrors.ErrNotFound) != nil {
+		return fmt.Errorf("escalations of missing team: expected %v, got %v", apperrors.ErrNotFound, err)
+	}
+
+	before, err := s.Teams.GetByTeamName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if before.Escalation != nil {
+		return fmt.Errorf("new team should have no escalation policy, got %+v", before.Escalation)
+	}
+	// пустое действие - remind
+	if err := s.Teams.SetEscalationPolicy(ctx, teamName, &team.EscalationPolicyDTO{ReviewSLAMinutes: 45}); err != nil {
+		return err
+	}
+	after, err := s.Teams.GetByTeamName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if after.Escalation == nil || *after.Escalation != (team.EscalationPolicyDTO{ReviewSLAMinutes: 45, Action: team.EscalationRemind}) {
+		return fmt.Errorf("unexpected stored policy %+v", after.Escalation)
+	}
+	if after.Version <= before.Version {
+		return fmt.Errorf("policy change should bump team version: %d -> %d", before.Version, after.Version)
+	}
+	return nil
+}
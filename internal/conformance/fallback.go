@@ -0,0 +1,224 @@
+package conformance
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// FallbackCases - назначение ревьюверов из запасных команд, когда в команде автора не хватает кандидатов
+var FallbackCases = []Case{
+	{Name: "fallback_fills_missing_reviewer", Run: fallbackFillsMissingReviewer},
+	{Name: "fallback_priority_order", Run: fallbackPriorityOrder},
+	{Name: "fallback_own_team_first", Run: fallbackOwnTeamFirst},
+	{Name: "fallback_reassign", Run: fallbackReassign},
+	{Name: "fallback_teams_validated", Run: fallbackTeamsValidated},
+}
+
+// reviewerTeam возвращает команду, из которой назначен ревьювер
+func reviewerTeam(pr *pullrequest.PullRequestDTOFromHttp, userID string) string {
+	i := slices.IndexFunc(pr.ReviewerTeams, func(t pullrequest.ReviewerTeamDTO) bool { return t.UserID == userID })
+	if i < 0 {
+		return ""
+	}
+	return pr.ReviewerTeams[i].TeamName
+}
+
+func fallbackFillsMissingReviewer(ctx context.Context, s *Scenario) error {
+	author, a, x := s.Member("author", true), s.Member("a", true), s.Member("x", true)
+	teamName, err := s.NewTeam(ctx, "t", author, a)
+	if err != nil {
+		return err
+	}
+	fallback, err := s.NewTeam(ctx, "f", x, s.Member("y", false))
+	if err != nil {
+		return err
+	}
+	if err := s.Teams.SetFallbackTeams(ctx, teamName, []string{fallback}); err != nil {
+		return err
+	}
+
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(pr.AssignedReviewers, []string{a.UserID, x.UserID}) {
+		return fmt.Errorf("expected reviewers %s and %s from the fallback team, got %v", a.UserID, x.UserID, pr.AssignedReviewers)
+	}
+	if got := reviewerTeam(pr, a.UserID); got != teamName {
+		return fmt.Errorf("reviewer %s should come from %s, got %q", a.UserID, teamName, got)
+	}
+	if got := reviewerTeam(pr, x.UserID); got != fallback {
+		return fmt.Errorf("reviewer %s should come from %s, got %q", x.UserID, fallback, got)
+	}
+
+	stored, err := s.PullRequests.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(stored.ReviewerTeams, pr.ReviewerTeams) {
+		return fmt.Errorf("stored reviewer teams %v differ from created %v", stored.ReviewerTeams, pr.ReviewerTeams)
+	}
+	return nil
+}
+
+func fallbackPriorityOrder(ctx context.Context, s *Scenario) error {
+	author, x, y := s.Member("author", true), s.Member("x", true), s.Member("y", true)
+	teamName, err := s.NewTeam(ctx, "t", author)
+	if err != nil {
+		return err
+	}
+	first, err := s.NewTeam(ctx, "f1", x)
+	if err != nil {
+		return err
+	}
+	second, err := s.NewTeam(ctx, "f2", y, s.Member("z", true))
+	if err != nil {
+		return err
+	}
+	// порядок в списке - приоритет, а не имена команд
+	if err := s.Teams.SetFallbackTeams(ctx, teamName, []string{second, first}); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if len(pr.AssignedReviewers) != 2 || slices.Contains(pr.AssignedReviewers, x.UserID) {
+		return fmt.Errorf("both reviewers should come from the first fallback %s, got %v", second, pr.AssignedReviewers)
+	}
+
+	// запасные команды не транзитивны
+	if err := s.Teams.SetFallbackTeams(ctx, teamName, []string{first}); err != nil {
+		return err
+	}
+	if err := s.Teams.SetFallbackTeams(ctx, first, []string{second}); err != nil {
+		return err
+	}
+	pr, err = s.NewPR(ctx, "pr2", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{x.UserID}) {
+		return fmt.Errorf("fallbacks of a fallback team must not be used, got %v", pr.AssignedReviewers)
+	}
+	return nil
+}
+
+func fallbackOwnTeamFirst(ctx context.Context, s *Scenario) error {
+	author, a, b := s.Member("author", true), s.Member("a", true), s.Member("b", true)
+	teamName, err := s.NewTeam(ctx, "t", author, a, b)
+	if err != nil {
+		return err
+	}
+	fallback, err := s.NewTeam(ctx, "f", s.Member("x", true), s.Member("y", true))
+	if err != nil {
+		return err
+	}
+	if err := s.Teams.SetFallbackTeams(ctx, teamName, []string{fallback}); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(pr.AssignedReviewers, []string{a.UserID, b.UserID}) {
+		return fmt.Errorf("own team has enough candidates: expected %s and %s, got %v", a.UserID, b.UserID, pr.AssignedReviewers)
+	}
+	return nil
+}
+
+func fallbackReassign(ctx context.Context, s *Scenario) error {
+	author, a, b, x := s.Member("author", true), s.Member("a", true), s.Member("b", true), s.Member("x", true)
+	teamName, err := s.NewTeam(ctx, "t", author, a, b)
+	if err != nil {
+		return err
+	}
+	fallback, err := s.NewTeam(ctx, "f", x)
+	if err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	// без запасной команды заменить некем
+	_, _, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, a.UserID, nil)
+	if err := expectErr(err, apperrors.ErrNoCandidate); err != nil {
+		return err
+	}
+
+	if err := s.Teams.SetFallbackTeams(ctx, teamName, []string{fallback}); err != nil {
+		return err
+	}
+	pr, replacedBy, err := s.PullRequests.Reassign(ctx, pr.PullRequestID, a.UserID, nil)
+	if err != nil {
+		return err
+	}
+	if replacedBy != x.UserID {
+		return fmt.Errorf("expected %s from the fallback team to replace %s, got %s", x.UserID, a.UserID, replacedBy)
+	}
+	if !sameSet(pr.AssignedReviewers, []string{b.UserID, x.UserID}) {
+		return fmt.Errorf("expected reviewers %s and %s, got %v", b.UserID, x.UserID, pr.AssignedReviewers)
+	}
+	if got := reviewerTeam(pr, x.UserID); got != fallback {
+		return fmt.Errorf("reviewer %s should come from %s, got %q", x.UserID, fallback, got)
+	}
+	if got := reviewerTeam(pr, a.UserID); got != "" {
+		return fmt.Errorf("replaced reviewer %s still has a source team %q", a.UserID, got)
+	}
+	return nil
+}
+
+func fallbackTeamsValidated(ctx context.Context, s *Scenario) error {
+	teamName, err := s.NewTeam(ctx, "t", s.Member("a", true))
+	if err != nil {
+		return err
+	}
+	fallback, err := s.NewTeam(ctx, "f", s.Member("x", true))
+	if err != nil {
+		return err
+	}
+	for _, invalid := range [][]string{{teamName}, {fallback, fallback}, {""}} {
+		if err := expectErr(s.Teams.SetFallbackTeams(ctx, teamName, invalid), apperrors.ErrBadRequest); err != nil {
+			return fmt.Errorf("fallback teams %q: %w", invalid, err)
+		}
+	}
+	if err := expectErr(s.Teams.SetFallbackTeams(ctx, teamName, []string{s.ID("missing")}), apperrors.ErrNotFound); err != nil {
+		return err
+	}
+	if err := expectErr(s.Teams.SetFallbackTeams(ctx, s.ID("missing"), []string{fallback}), apperrors.ErrNotFound); err != nil {
+		return err
+	}
+
+	before, err := s.Teams.GetByTeamName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(before.FallbackTeams) != 0 {
+		return fmt.Errorf("failed updates must not store fallback teams, got %v", before.FallbackTeams)
+	}
+	if err := s.Teams.SetFallbackTeams(ctx, teamName, []string{fallback}); err != nil {
+		return err
+	}
+	after, err := s.Teams.GetByTeamName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(after.FallbackTeams, []string{fallback}) || after.Version <= before.Version {
+		return fmt.Errorf("expected fallback teams [%s] with a new version, got %v (version %d -> %d)", fallback, after.FallbackTeams, before.Version, after.Version)
+	}
+	if err := s.Teams.SetFallbackTeams(ctx, teamName, nil); err != nil {
+		return err
+	}
+	cleared, err := s.Teams.GetByTeamName(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(cleared.FallbackTeams) != 0 {
+		return fmt.Errorf("empty list should clear fallback teams, got %v", cleared.FallbackTeams)
+	}
+	return nil
+}
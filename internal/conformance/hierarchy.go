@@ -0,0 +1,517 @@
+package conformance
+
+import (
+	"avito-tech/internal/app/escalation"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// HierarchyCases - отделы и команды: поддеревья, переносы, наследование политик назначения и эскалации,
+// статистика и массовые операции по отделу
+var HierarchyCases = []Case{
+	{Name: "hierarchy_subtree", Run: hierarchySubtree},
+	{Name: "hierarchy_rejects_cycles", Run: hierarchyRejectsCycles},
+	{Name: "hierarchy_inherits_capacity", Run: hierarchyInheritsCapacity},
+	{Name: "hierarchy_inherits_fallbacks", Run: hierarchyInheritsFallbacks},
+	{Name: "hierarchy_inherits_escalation", Run: hierarchyInheritsEscalation},
+	{Name: "hierarchy_department_stats", Run: hierarchyDepartmentStats},
+	{Name: "hierarchy_department_set_is_active", Run: hierarchyDepartmentSetIsActive},
+}
+
+// SetParent переносит команду под parent; пустой parent - в корень организации
+func (s *Scenario) SetParent(ctx context.Context, teamName string, parent string) error {
+	var parentTeamName *string
+	if parent != "" {
+		parentTeamName = &parent
+	}
+	if err := s.Teams.SetParent(ctx, teamName, parentTeamName); err != nil {
+		return fmt.Errorf("move %s under %q: %w", teamName, parent, err)
+	}
+	return nil
+}
+
+// render записывает поддерево в виде "a(b,c(d))" для сравнения
+func render(node *team.TeamNodeDTO) string {
+	if len(node.Children) == 0 {
+		return node.TeamName
+	}
+	out := node.TeamName + "("
+	for i, child := range node.Children {
+		if i > 0 {
+			out += ","
+		}
+		out += render(child)
+	}
+	return out + ")"
+}
+
+func hierarchySubtree(ctx context.Context, s *Scenario) error {
+	names := map[string]string{}
+	for _, name := range []string{"dept", "a", "b", "c"} {
+		teamName, err := s.NewTeam(ctx, name, s.Member(name+"-m", true))
+		if err != nil {
+			return err
+		}
+		names[name] = teamName
+	}
+	for child, parent := range map[string]string{"a": "dept", "b": "dept", "c": "a"} {
+		if err := s.SetParent(ctx, names[child], names[parent]); err != nil {
+			return err
+		}
+	}
+
+	subtree, err := s.Teams.Subtree(ctx, names["dept"])
+	if err != nil {
+		return err
+	}
+	want := fmt.Sprintf("%s(%s(%s),%s)", names["dept"], names["a"], names["c"], names["b"])
+	if got := render(subtree); got != want {
+		return fmt.Errorf("expected subtree %s, got %s", want, got)
+	}
+	a, err := s.Teams.GetByTeamName(ctx, names["a"])
+	if err != nil {
+		return err
+	}
+	if a.ParentTeamName == nil || *a.ParentTeamName != names["dept"] {
+		return fmt.Errorf("expected parent %s of team %s, got %v", names["dept"], names["a"], a.ParentTeamName)
+	}
+
+	roots, err := s.Teams.Organization(ctx)
+	if err != nil {
+		return err
+	}
+	isRoot := func(name string) bool {
+		return slices.ContainsFunc(roots, func(n *team.TeamNodeDTO) bool { return n.TeamName == name })
+	}
+	if !isRoot(names["dept"]) || isRoot(names["a"]) || isRoot(names["c"]) {
+		return fmt.Errorf("only %s should be an organization root among the scenario teams", names["dept"])
+	}
+
+	// перенос в корень забирает с собой всё поддерево
+	if err := s.SetParent(ctx, names["a"], ""); err != nil {
+		return err
+	}
+	subtree, err = s.Teams.Subtree(ctx, names["dept"])
+	if err != nil {
+		return err
+	}
+	if want := fmt.Sprintf("%s(%s)", names["dept"], names["b"]); render(subtree) != want {
+		return fmt.Errorf("expected subtree %s after moving %s out, got %s", want, names["a"], render(subtree))
+	}
+	subtree, err = s.Teams.Subtree(ctx, names["a"])
+	if err != nil {
+		return err
+	}
+	if want := fmt.Sprintf("%s(%s)", names["a"], names["c"]); render(subtree) != want {
+		return fmt.Errorf("expected subtree %s, got %s", want, render(subtree))
+	}
+	_, err = s.Teams.Subtree(ctx, s.ID("missing"))
+	return expectErr(err, apperrors.ErrNotFound)
+}
+
+func hierarchyRejectsCycles(ctx context.Context, s *Scenario) error {
+	dept, err := s.NewTeam(ctx, "dept", s.Member("d", true))
+	if err != nil {
+		return err
+	}
+	child, err := s.NewTeam(ctx, "child", s.Member("c", true))
+	if err != nil {
+		return err
+	}
+	grandchild, err := s.NewTeam(ctx, "grandchild", s.Member("g", true))
+	if err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, child, dept); err != nil {
+		return err
+	}
+	before, err := s.Teams.GetByTeamName(ctx, grandchild)
+	if err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, grandchild, child); err != nil {
+		return err
+	}
+	after, err := s.Teams.GetByTeamName(ctx, grandchild)
+	if err != nil {
+		return err
+	}
+	if after.Version <= before.Version {
+		return fmt.Errorf("moving a team should bump its version, got %d -> %d", before.Version, after.Version)
+	}
+
+	for _, parent := range []string{grandchild, dept} {
+		if err := expectErr(s.Teams.SetParent(ctx, dept, &parent), apperrors.ErrBadRequest); err != nil {
+			return fmt.Errorf("move %s under %s: %w", dept, parent, err)
+		}
+	}
+	missing := s.ID("missing")
+	if err := expectErr(s.Teams.SetParent(ctx, dept, &missing), apperrors.ErrNotFound); err != nil {
+		return err
+	}
+	if err := expectErr(s.Teams.SetParent(ctx, missing, &dept), apperrors.ErrNotFound); err != nil {
+		return err
+	}
+
+	subtree, err := s.Teams.Subtree(ctx, dept)
+	if err != nil {
+		return err
+	}
+	if want := fmt.Sprintf("%s(%s(%s))", dept, child, grandchild); render(subtree) != want {
+		return fmt.Errorf("rejected moves must not change the hierarchy: expected %s, got %s", want, render(subtree))
+	}
+	return nil
+}
+
+func hierarchyInheritsCapacity(ctx context.Context, s *Scenario) error {
+	author, a := s.Member("author", true), s.Member("a", true)
+	dept, err := s.NewTeam(ctx, "dept", s.Member("d", true))
+	if err != nil {
+		return err
+	}
+	group, err := s.NewTeam(ctx, "group", s.Member("g", true))
+	if err != nil {
+		return err
+	}
+	teamName, err := s.NewTeam(ctx, "t", author, a)
+	if err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, group, dept); err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, teamName, group); err != nil {
+		return err
+	}
+	if err := s.Teams.SetMaxOpenReviews(ctx, dept, limit(1)); err != nil {
+		return err
+	}
+
+	load, err := s.Users.GetLoad(ctx, a.UserID)
+	if err != nil {
+		return err
+	}
+	if load.MaxOpenReviews == nil || *load.MaxOpenReviews != 1 {
+		return fmt.Errorf("expected limit 1 inherited from %s, got %v", dept, load.MaxOpenReviews)
+	}
+	if _, err := s.NewPR(ctx, "pr1", author.UserID); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr2", author.UserID)
+	if err != nil {
+		return err
+	}
+	if len(pr.AssignedReviewers) != 0 {
+		return fmt.Errorf("inherited limit 1 is reached: expected no reviewers, got %v", pr.AssignedReviewers)
+	}
+
+	// ближайший предок с лимитом перекрывает дальнего
+	if err := s.Teams.SetMaxOpenReviews(ctx, group, limit(2)); err != nil {
+		return err
+	}
+	pr, err = s.NewPR(ctx, "pr3", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{a.UserID}) {
+		return fmt.Errorf("limit 2 of %s should apply, got %v", group, pr.AssignedReviewers)
+	}
+	load, err = s.Users.GetLoad(ctx, a.UserID)
+	if err != nil {
+		return err
+	}
+	if load.MaxOpenReviews == nil || *load.MaxOpenReviews != 2 || !load.AtCapacity {
+		return fmt.Errorf("expected limit 2 at capacity, got %+v", load)
+	}
+	return nil
+}
+
+func hierarchyInheritsFallbacks(ctx context.Context, s *Scenario) error {
+	author, a, x, y := s.Member("author", true), s.Member("a", true), s.Member("x", true), s.Member("y", true)
+	dept, err := s.NewTeam(ctx, "dept", s.Member("d", true))
+	if err != nil {
+		return err
+	}
+	teamName, err := s.NewTeam(ctx, "t", author, a)
+	if err != nil {
+		return err
+	}
+	deptFallback, err := s.NewTeam(ctx, "f", x)
+	if err != nil {
+		return err
+	}
+	ownFallback, err := s.NewTeam(ctx, "g", y)
+	if err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, teamName, dept); err != nil {
+		return err
+	}
+	// команда не становится запасной самой себе, даже если она в списке отдела
+	if err := s.Teams.SetFallbackTeams(ctx, dept, []string{teamName, deptFallback}); err != nil {
+		return err
+	}
+
+	pr, err := s.NewPR(ctx, "pr1", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(pr.AssignedReviewers, []string{a.UserID, x.UserID}) || reviewerTeam(pr, x.UserID) != deptFallback {
+		return fmt.Errorf("expected %s from the fallback of %s, got %v (%v)", x.UserID, dept, pr.AssignedReviewers, pr.ReviewerTeams)
+	}
+
+	// свои запасные команды перекрывают запасные команды отдела
+	if err := s.Teams.SetFallbackTeams(ctx, teamName, []string{ownFallback}); err != nil {
+		return err
+	}
+	pr, err = s.NewPR(ctx, "pr2", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(pr.AssignedReviewers, []string{a.UserID, y.UserID}) {
+		return fmt.Errorf("expected %s from the team's own fallback, got %v", y.UserID, pr.AssignedReviewers)
+	}
+	return nil
+}
+
+func hierarchyInheritsEscalation(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	dept, err := s.NewEscalatingTeam(ctx, "dept", 30, team.EscalationNotifyLead, s.Member("d", true))
+	if err != nil {
+		return err
+	}
+	group, err := s.NewTeam(ctx, "group", s.Member("g", true))
+	if err != nil {
+		return err
+	}
+	teamName, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true))
+	if err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, group, dept); err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, teamName, group); err != nil {
+		return err
+	}
+	pr1, err := s.NewPR(ctx, "pr1", author.UserID)
+	if err != nil {
+		return err
+	}
+
+	// эскалация записывается на команду автора, а SLA и действие - отдела
+	s.Clock.Advance(31 * time.Minute)
+	got, err := s.Escalations(ctx, teamName)
+	if err != nil {
+		return err
+	}
+	if len(got) != 2 || slices.ContainsFunc(got, func(e *escalation.EscalationDTO) bool {
+		return e.PullRequestID != pr1.PullRequestID || e.Action != team.EscalationNotifyLead || e.TeamName != teamName
+	}) {
+		return fmt.Errorf("expected notify_lead of %s inherited from %s, got %+v", pr1.PullRequestID, dept, got)
+	}
+	own, err := s.Escalation.List(ctx, dept, false)
+	if err != nil {
+		return err
+	}
+	if len(own) != 0 {
+		return fmt.Errorf("%s itself has no escalations, got %+v", dept, own)
+	}
+	all, err := s.Escalation.List(ctx, dept, true)
+	if err != nil {
+		return err
+	}
+	if len(all) != 2 {
+		return fmt.Errorf("expected 2 escalations in the subtree of %s, got %+v", dept, all)
+	}
+
+	// ближайший предок с политикой перекрывает дальнего
+	if err := s.Teams.SetEscalationPolicy(ctx, group, &team.EscalationPolicyDTO{ReviewSLAMinutes: 60, Action: team.EscalationRemind}); err != nil {
+		return err
+	}
+	pr2, err := s.NewPR(ctx, "pr2", author.UserID)
+	if err != nil {
+		return err
+	}
+	s.Clock.Advance(31 * time.Minute)
+	if got, err = s.Escalations(ctx, teamName); err != nil {
+		return err
+	}
+	if len(got) != 2 {
+		return fmt.Errorf("SLA 60 of %s is not over yet, got %+v", group, got)
+	}
+	s.Clock.Advance(30 * time.Minute)
+	if got, err = s.Escalations(ctx, teamName); err != nil {
+		return err
+	}
+	for _, e := range got {
+		if e.PullRequestID == pr2.PullRequestID && e.Action != team.EscalationRemind {
+			return fmt.Errorf("policy of %s should apply to %s, got %+v", group, pr2.PullRequestID, e)
+		}
+	}
+	if len(got) != 4 {
+		return fmt.Errorf("expected reviewers of %s escalated, got %+v", pr2.PullRequestID, got)
+	}
+	return nil
+}
+
+func hierarchyDepartmentStats(ctx context.Context, s *Scenario) error {
+	dept, err := s.NewTeam(ctx, "dept", s.Member("d1", true), s.Member("d2", false))
+	if err != nil {
+		return err
+	}
+	group, err := s.NewTeam(ctx, "group", s.Member("g1", true), s.Member("g2", true), s.Member("g3", true))
+	if err != nil {
+		return err
+	}
+	leaf, err := s.NewTeam(ctx, "leaf", s.Member("l1", true))
+	if err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, group, dept); err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, leaf, group); err != nil {
+		return err
+	}
+	// в счётчиках только OPEN PR: черновик и смёрженный PR не считаются
+	open, err := s.NewPR(ctx, "open", s.ID("g1"))
+	if err != nil {
+		return err
+	}
+	if len(open.AssignedReviewers) != 2 {
+		return fmt.Errorf("expected 2 reviewers from %s, got %v", group, open.AssignedReviewers)
+	}
+	if _, err := s.NewDraft(ctx, "draft", s.ID("g1")); err != nil {
+		return err
+	}
+	merged, err := s.NewPR(ctx, "merged", s.ID("l1"))
+	if err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Merge(ctx, merged.PullRequestID, nil, nil); err != nil {
+		return err
+	}
+
+	own, err := s.Teams.Stats(ctx, dept, false)
+	if err != nil {
+		return err
+	}
+	want := team.TeamCountersDTO{Members: 2, ActiveMembers: 1}
+	if len(own.Teams) != 1 || own.Teams[0].TeamName != dept || own.Total != want {
+		return fmt.Errorf("expected only %s with %+v, got %+v", dept, want, own)
+	}
+
+	all, err := s.Teams.Stats(ctx, dept, true)
+	if err != nil {
+		return err
+	}
+	wantTeams := []team.TeamStatsDTO{
+		{TeamName: dept, Depth: 0, TeamCountersDTO: team.TeamCountersDTO{Members: 2, ActiveMembers: 1}},
+		{TeamName: group, Depth: 1, TeamCountersDTO: team.TeamCountersDTO{Members: 3, ActiveMembers: 3, OpenPullRequests: 1, OpenReviews: 2}},
+		{TeamName: leaf, Depth: 2, TeamCountersDTO: team.TeamCountersDTO{Members: 1, ActiveMembers: 1}},
+	}
+	if !slices.Equal(all.Teams, wantTeams) {
+		return fmt.Errorf("expected department stats %+v, got %+v", wantTeams, all.Teams)
+	}
+	if want := (team.TeamCountersDTO{Members: 6, ActiveMembers: 5, OpenPullRequests: 1, OpenReviews: 2}); all.Total != want {
+		return fmt.Errorf("expected department total %+v, got %+v", want, all.Total)
+	}
+
+	_, err = s.Teams.Stats(ctx, s.ID("missing"), true)
+	return expectErr(err, apperrors.ErrNotFound)
+}
+
+func hierarchyDepartmentSetIsActive(ctx context.Context, s *Scenario) error {
+	dept, err := s.NewTeam(ctx, "dept", s.Member("d1", true))
+	if err != nil {
+		return err
+	}
+	group, err := s.NewTeam(ctx, "group", s.Member("g1", true), s.Member("g2", false))
+	if err != nil {
+		return err
+	}
+	outside, err := s.NewTeam(ctx, "outside", s.Member("o1", true))
+	if err != nil {
+		return err
+	}
+	if err := s.SetParent(ctx, group, dept); err != nil {
+		return err
+	}
+	version := func(teamName string) (uint64, error) {
+		dto, err := s.Teams.GetByTeamName(ctx, teamName)
+		if err != nil {
+			return 0, err
+		}
+		return dto.Version, nil
+	}
+	ids := func(users []*user.UserDTO) []string {
+		out := make([]string, len(users))
+		for i, u := range users {
+			out[i] = u.UserID
+		}
+		slices.Sort(out)
+		return out
+	}
+
+	// без subtree - только сама команда
+	changed, err := s.Users.SetTeamIsActive(ctx, dept, false, false)
+	if err != nil {
+		return err
+	}
+	if got := ids(changed); !slices.Equal(got, []string{s.ID("d1")}) {
+		return fmt.Errorf("expected only %s deactivated, got %v", s.ID("d1"), got)
+	}
+	groupBefore, err := version(group)
+	if err != nil {
+		return err
+	}
+	deptBefore, err := version(dept)
+	if err != nil {
+		return err
+	}
+
+	// с subtree возвращаются только те, у кого активность изменилась; версия растёт у их команд
+	changed, err = s.Users.SetTeamIsActive(ctx, dept, true, false)
+	if err != nil {
+		return err
+	}
+	if len(changed) != 1 || changed[0].UserID != s.ID("g1") || changed[0].TeamName != group || changed[0].IsActive {
+		return fmt.Errorf("expected %s of %s deactivated, got %+v", s.ID("g1"), group, changed)
+	}
+	groupAfter, err := version(group)
+	if err != nil {
+		return err
+	}
+	deptAfter, err := version(dept)
+	if err != nil {
+		return err
+	}
+	if groupAfter != groupBefore+1 || deptAfter != deptBefore || changed[0].TeamVersion != groupAfter {
+		return fmt.Errorf("expected version of %s bumped once and %s unchanged, got %d->%d and %d->%d",
+			group, dept, groupBefore, groupAfter, deptBefore, deptAfter)
+	}
+	o1, err := s.Users.GetByID(ctx, s.ID("o1"))
+	if err != nil {
+		return err
+	}
+	if !o1.IsActive {
+		return fmt.Errorf("%s outside the department must stay active", outside)
+	}
+
+	changed, err = s.Users.SetTeamIsActive(ctx, dept, true, true)
+	if err != nil {
+		return err
+	}
+	if got, want := ids(changed), []string{s.ID("d1"), s.ID("g1"), s.ID("g2")}; !slices.Equal(got, want) {
+		return fmt.Errorf("expected %v reactivated, got %v", want, got)
+	}
+
+	_, err = s.Users.SetTeamIsActive(ctx, s.ID("missing"), true, false)
+	return expectErr(err, apperrors.ErrNotFound)
+}
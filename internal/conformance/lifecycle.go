@@ -0,0 +1,194 @@
+package conformance
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+)
+
+// LifecycleCases - статусы DRAFT и CLOSED и переходы между статусами
+var LifecycleCases = []Case{
+	{Name: "draft_has_no_reviewers", Run: draftHasNoReviewers},
+	{Name: "close_releases_reviewers", Run: closeReleasesReviewers},
+	{Name: "draft_close_reopen", Run: draftCloseReopen},
+	{Name: "merged_is_final", Run: mergedIsFinal},
+	{Name: "transition_if_match", Run: transitionIfMatch},
+}
+
+// expectStatus проверяет статус и число ревьюверов PR
+func expectStatus(pr *pullrequest.PullRequestDTOFromHttp, status string, reviewers int) error {
+	if pr.Status != status || len(pr.AssignedReviewers) != reviewers {
+		return fmt.Errorf("expected %s with %d reviewers, got %s with %v", status, reviewers, pr.Status, pr.AssignedReviewers)
+	}
+	return nil
+}
+
+func draftHasNoReviewers(ctx context.Context, s *Scenario) error {
+	author, member := s.Member("author", true), s.Member("a", true)
+	if _, err := s.NewTeam(ctx, "t", author, member, s.Member("b", true)); err != nil {
+		return err
+	}
+	draft, err := s.NewDraft(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if err := expectStatus(draft, pullrequest.StatusDraft, 0); err != nil {
+		return err
+	}
+	queue, err := s.Users.GetReview(ctx, member.UserID)
+	if err != nil {
+		return err
+	}
+	if len(queue) != 0 {
+		return fmt.Errorf("draft should not reach review queues, got %d PRs", len(queue))
+	}
+
+	_, err = s.PullRequests.Merge(ctx, draft.PullRequestID, nil, nil)
+	if err := expectErr(err, apperrors.ErrInvalidTransition); err != nil {
+		return fmt.Errorf("merge draft: %w", err)
+	}
+	_, err = s.PullRequests.Reopen(ctx, draft.PullRequestID, nil)
+	if err := expectErr(err, apperrors.ErrInvalidTransition); err != nil {
+		return fmt.Errorf("reopen draft: %w", err)
+	}
+
+	ready, err := s.PullRequests.MarkReady(ctx, draft.PullRequestID, nil)
+	if err != nil {
+		return err
+	}
+	if err := expectStatus(ready, pullrequest.StatusOpen, 2); err != nil {
+		return fmt.Errorf("markReady: %w", err)
+	}
+	_, err = s.PullRequests.MarkReady(ctx, draft.PullRequestID, nil)
+	return expectErr(err, apperrors.ErrInvalidTransition)
+}
+
+func closeReleasesReviewers(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true), s.Member("c", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	reviewer := pr.AssignedReviewers[0]
+	if _, err := s.PullRequests.Review(ctx, pr.PullRequestID, reviewer, pullrequest.ReviewApproved, ""); err != nil {
+		return err
+	}
+
+	closed, err := s.PullRequests.Close(ctx, pr.PullRequestID, nil)
+	if err != nil {
+		return err
+	}
+	if err := expectStatus(closed, pullrequest.StatusClosed, 0); err != nil {
+		return err
+	}
+	if len(closed.Reviews) != 0 {
+		return fmt.Errorf("closing should drop verdicts, got %+v", closed.Reviews)
+	}
+	queue, err := s.Users.GetReview(ctx, reviewer)
+	if err != nil {
+		return err
+	}
+	if len(queue) != 0 {
+		return fmt.Errorf("closed PR should leave review queues, got %d PRs", len(queue))
+	}
+
+	_, err = s.PullRequests.Merge(ctx, pr.PullRequestID, nil, nil)
+	if err := expectErr(err, apperrors.ErrInvalidTransition); err != nil {
+		return fmt.Errorf("merge closed: %w", err)
+	}
+	_, _, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, reviewer, nil)
+	if err := expectErr(err, apperrors.ErrNotAssigned); err != nil {
+		return fmt.Errorf("reassign on closed: %w", err)
+	}
+	_, err = s.PullRequests.Close(ctx, pr.PullRequestID, nil)
+	if err := expectErr(err, apperrors.ErrInvalidTransition); err != nil {
+		return fmt.Errorf("close twice: %w", err)
+	}
+
+	reopened, err := s.PullRequests.Reopen(ctx, pr.PullRequestID, nil)
+	if err != nil {
+		return err
+	}
+	if err := expectStatus(reopened, pullrequest.StatusOpen, 2); err != nil {
+		return fmt.Errorf("reopen: %w", err)
+	}
+	if len(reopened.Reviews) != 0 {
+		return fmt.Errorf("reopened PR should start without verdicts, got %+v", reopened.Reviews)
+	}
+	return nil
+}
+
+func draftCloseReopen(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true)); err != nil {
+		return err
+	}
+	draft, err := s.NewDraft(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Close(ctx, draft.PullRequestID, nil); err != nil {
+		return fmt.Errorf("close draft: %w", err)
+	}
+	// переоткрытый черновик сразу становится OPEN
+	reopened, err := s.PullRequests.Reopen(ctx, draft.PullRequestID, nil)
+	if err != nil {
+		return err
+	}
+	return expectStatus(reopened, pullrequest.StatusOpen, 1)
+}
+
+func mergedIsFinal(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Merge(ctx, pr.PullRequestID, nil, nil); err != nil {
+		return err
+	}
+	ops := map[string]func(ctx context.Context, prID string, ifMatch *uint64) (*pullrequest.PullRequestDTOFromHttp, error){
+		"markReady": s.PullRequests.MarkReady,
+		"close":     s.PullRequests.Close,
+		"reopen":    s.PullRequests.Reopen,
+	}
+	for name, op := range ops {
+		_, err := op(ctx, pr.PullRequestID, nil)
+		if err := expectErr(err, apperrors.ErrInvalidTransition); err != nil {
+			return fmt.Errorf("%s merged: %w", name, err)
+		}
+	}
+	_, err = s.PullRequests.Merge(ctx, pr.PullRequestID, nil, nil)
+	return err
+}
+
+func transitionIfMatch(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	stale := pr.Version - 1
+	_, err = s.PullRequests.Close(ctx, pr.PullRequestID, &stale)
+	if err := expectErr(err, apperrors.ErrPreconditionFailed); err != nil {
+		return err
+	}
+	closed, err := s.PullRequests.Close(ctx, pr.PullRequestID, &pr.Version)
+	if err != nil {
+		return err
+	}
+	if closed.Version != pr.Version+1 {
+		return fmt.Errorf("close should bump version to %d, got %d", pr.Version+1, closed.Version)
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package conformance_test
+
+import (
+	"avito-tech/internal/app/availability"
+	"avito-tech/internal/app/escalation"
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/conformance"
+	"avito-tech/internal/db/memory"
+	"testing"
+)
+
+func TestMemory(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) conformance.Services {
+		store := memory.NewStore()
+		return services(
+			team.NewTeam(team.NewTeamMemoryRepo(store)),
+			user.NewUser(user.NewUserMemoryRepo(store)),
+			pullrequest.NewMemoryRepo(store),
+			availability.NewAvailabilityMemoryRepo(store),
+			escalation.NewEscalationMemoryRepo(store),
+			store,
+		)
+	})
+}
@@ -0,0 +1,37 @@
+package conformance_test
+
+import (
+	"avito-tech/internal/app/availability"
+	"avito-tech/internal/app/escalation"
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/conformance"
+	"avito-tech/internal/db"
+	"os"
+	"testing"
+)
+
+// TestPostgres работает с уже мигрированной базой из TEST_POSTGRES_DSN
+// (например, "host=localhost user=avito_test password=test dbname=avito_test sslmode=disable")
+func TestPostgres(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) conformance.Services {
+		dsn := os.Getenv("TEST_POSTGRES_DSN")
+		if dsn == "" {
+			t.Skip("TEST_POSTGRES_DSN is not set")
+		}
+		database, err := db.Connect(t.Context(), dsn)
+		if err != nil {
+			t.Fatalf("connect to postgres: %v", err)
+		}
+		t.Cleanup(func() { database.GetPool(t.Context()).Close() })
+		return services(
+			team.NewTeam(team.NewTeamRepo(database)),
+			user.NewUser(user.NewUserRepo(database)),
+			pullrequest.NewRepo(database),
+			availability.NewAvailabilityRepo(database),
+			escalation.NewEscalationRepo(database),
+			database,
+		)
+	})
+}
@@ -0,0 +1,196 @@
+package conformance
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+)
+
+// ReviewCases - вердикты ревьюверов и условие merge
+var ReviewCases = []Case{
+	{Name: "review_requires_assignment", Run: reviewRequiresAssignment},
+	{Name: "review_replaces_verdict", Run: reviewReplacesVerdict},
+	{Name: "reassign_clears_verdict", Run: reassignClearsVerdict},
+	{Name: "merge_gate", Run: mergeGate},
+	{Name: "merge_gate_scarce_reviewers", Run: mergeGateScarceReviewers},
+}
+
+func reviewRequiresAssignment(ctx context.Context, s *Scenario) error {
+	author, outsider := s.Member("author", true), s.Member("outsider", false)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), outsider); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.PullRequests.Review(ctx, pr.PullRequestID, outsider.UserID, pullrequest.ReviewApproved, "")
+	if err := expectErr(err, apperrors.ErrNotAssigned); err != nil {
+		return fmt.Errorf("unassigned user: %w", err)
+	}
+	_, err = s.PullRequests.Review(ctx, pr.PullRequestID, author.UserID, pullrequest.ReviewApproved, "")
+	if err := expectErr(err, apperrors.ErrNotAssigned); err != nil {
+		return fmt.Errorf("author: %w", err)
+	}
+	_, err = s.PullRequests.Review(ctx, pr.PullRequestID, pr.AssignedReviewers[0], "LGTM", "")
+	if err := expectErr(err, apperrors.ErrBadRequest); err != nil {
+		return fmt.Errorf("unknown decision: %w", err)
+	}
+	_, err = s.PullRequests.Review(ctx, s.ID("missing"), pr.AssignedReviewers[0], pullrequest.ReviewApproved, "")
+	return expectErr(err, apperrors.ErrNotFound)
+}
+
+func reviewReplacesVerdict(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	reviewer := pr.AssignedReviewers[0]
+
+	first, err := s.PullRequests.Review(ctx, pr.PullRequestID, reviewer, pullrequest.ReviewChangesRequested, "needs tests")
+	if err != nil {
+		return err
+	}
+	if first.Version != pr.Version+1 {
+		return fmt.Errorf("review should bump version to %d, got %d", pr.Version+1, first.Version)
+	}
+	second, err := s.PullRequests.Review(ctx, pr.PullRequestID, reviewer, pullrequest.ReviewApproved, "thanks")
+	if err != nil {
+		return err
+	}
+	if len(second.Reviews) != 1 {
+		return fmt.Errorf("one reviewer should have one verdict, got %+v", second.Reviews)
+	}
+	if r := second.Reviews[0]; r.UserID != reviewer || r.Decision != pullrequest.ReviewApproved || r.Comment != "thanks" {
+		return fmt.Errorf("latest verdict should win, got %+v", r)
+	}
+
+	stored, err := s.PullRequests.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if len(stored.Reviews) != 1 || stored.Reviews[0].Decision != pullrequest.ReviewApproved {
+		return fmt.Errorf("getByID should return the stored verdict, got %+v", stored.Reviews)
+	}
+	return nil
+}
+
+func reassignClearsVerdict(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true), s.Member("c", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	leaving, staying := pr.AssignedReviewers[0], pr.AssignedReviewers[1]
+	for _, r := range pr.AssignedReviewers {
+		if _, err := s.PullRequests.Review(ctx, pr.PullRequestID, r, pullrequest.ReviewApproved, ""); err != nil {
+			return err
+		}
+	}
+
+	updated, _, err := s.PullRequests.Reassign(ctx, pr.PullRequestID, leaving, nil)
+	if err != nil {
+		return err
+	}
+	if len(updated.Reviews) != 1 || updated.Reviews[0].UserID != staying {
+		return fmt.Errorf("only %s's verdict should remain, got %+v", staying, updated.Reviews)
+	}
+	return nil
+}
+
+func mergeGate(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	policy := &pullrequest.MergePolicy{RequiredApprovals: 2}
+	first, second := pr.AssignedReviewers[0], pr.AssignedReviewers[1]
+
+	steps := []struct {
+		reviewer string
+		decision string
+		mergeErr error
+	}{
+		{"", "", apperrors.ErrNotApproved},
+		{first, pullrequest.ReviewApproved, apperrors.ErrNotApproved},
+		{second, pullrequest.ReviewChangesRequested, apperrors.ErrNotApproved},
+		{second, pullrequest.ReviewApproved, nil},
+	}
+	for i, step := range steps {
+		if step.reviewer != "" {
+			if _, err := s.PullRequests.Review(ctx, pr.PullRequestID, step.reviewer, step.decision, ""); err != nil {
+				return fmt.Errorf("step %d: %w", i, err)
+			}
+		}
+		merged, err := s.PullRequests.Merge(ctx, pr.PullRequestID, nil, policy)
+		if step.mergeErr != nil {
+			if err := expectErr(err, step.mergeErr); err != nil {
+				return fmt.Errorf("step %d: %w", i, err)
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("step %d: %w", i, err)
+		}
+		if merged.Status != "MERGED" {
+			return fmt.Errorf("approved PR should merge, got %s", merged.Status)
+		}
+	}
+
+	if _, err := s.PullRequests.Merge(ctx, pr.PullRequestID, nil, policy); err != nil {
+		return fmt.Errorf("repeated merge: %w", err)
+	}
+	_, err = s.PullRequests.Review(ctx, pr.PullRequestID, first, pullrequest.ReviewChangesRequested, "")
+	return expectErr(err, apperrors.ErrPRMerged)
+}
+
+func mergeGateScarceReviewers(ctx context.Context, s *Scenario) error {
+	policy := &pullrequest.MergePolicy{RequiredApprovals: 2}
+
+	alone := s.Member("alone", true)
+	if _, err := s.NewTeam(ctx, "solo", alone); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "solo-pr", alone.UserID)
+	if err != nil {
+		return err
+	}
+	// без ревьюверов некому одобрить: merge отклоняется, а не проходит без одобрений
+	_, err = s.PullRequests.Merge(ctx, pr.PullRequestID, nil, policy)
+	if err := expectErr(err, apperrors.ErrNotApproved); err != nil {
+		return fmt.Errorf("PR without reviewers: %w", err)
+	}
+
+	author, peer := s.Member("author", true), s.Member("peer", true)
+	if _, err := s.NewTeam(ctx, "pair", author, peer); err != nil {
+		return err
+	}
+	pr, err = s.NewPR(ctx, "pair-pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	_, err = s.PullRequests.Merge(ctx, pr.PullRequestID, nil, policy)
+	if err := expectErr(err, apperrors.ErrNotApproved); err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Review(ctx, pr.PullRequestID, peer.UserID, pullrequest.ReviewApproved, ""); err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Merge(ctx, pr.PullRequestID, nil, policy); err != nil {
+		return fmt.Errorf("the only reviewer approved: %w", err)
+	}
+	return nil
+}
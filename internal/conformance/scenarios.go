@@ -0,0 +1,292 @@
+package conformance
+
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// AssignmentCases - правила назначения ревьюверов из условия задания
+var AssignmentCases = []Case{
+	{Name: "two_reviewers_author_excluded", Run: twoReviewersAuthorExcluded},
+	{Name: "scarce_candidates", Run: scarceCandidates},
+	{Name: "inactive_never_assigned", Run: inactiveNeverAssigned},
+	{Name: "deactivated_not_assigned", Run: deactivatedNotAssigned},
+	{Name: "reassign_within_team", Run: reassignWithinTeam},
+	{Name: "reassign_not_assigned", Run: reassignNotAssigned},
+	{Name: "reassign_no_candidate", Run: reassignNoCandidate},
+	{Name: "pr_merged_after_merge", Run: prMergedAfterMerge},
+	{Name: "merge_idempotent", Run: mergeIdempotent},
+	{Name: "get_review", Run: getReview},
+}
+
+func twoReviewersAuthorExcluded(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	teamName, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true), s.Member("c", true))
+	if err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if pr.Status != "OPEN" {
+		return fmt.Errorf("new PR should be OPEN, got %s", pr.Status)
+	}
+	if len(pr.AssignedReviewers) != 2 {
+		return fmt.Errorf("expected 2 reviewers, got %v", pr.AssignedReviewers)
+	}
+	if pr.AssignedReviewers[0] == pr.AssignedReviewers[1] {
+		return fmt.Errorf("reviewers should be distinct, got %v", pr.AssignedReviewers)
+	}
+	for _, r := range pr.AssignedReviewers {
+		if r == author.UserID {
+			return fmt.Errorf("author should not review own PR")
+		}
+		if err := s.inTeam(ctx, r, teamName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scarceCandidates(ctx context.Context, s *Scenario) error {
+	alone := s.Member("alone", true)
+	if _, err := s.NewTeam(ctx, "solo", alone); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "solo-pr", alone.UserID)
+	if err != nil {
+		return err
+	}
+	if len(pr.AssignedReviewers) != 0 {
+		return fmt.Errorf("author alone in team: expected 0 reviewers, got %v", pr.AssignedReviewers)
+	}
+
+	author, peer := s.Member("author", true), s.Member("peer", true)
+	if _, err := s.NewTeam(ctx, "pair", author, peer); err != nil {
+		return err
+	}
+	pr, err = s.NewPR(ctx, "pair-pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{peer.UserID}) {
+		return fmt.Errorf("one candidate: expected [%s], got %v", peer.UserID, pr.AssignedReviewers)
+	}
+	return nil
+}
+
+func inactiveNeverAssigned(ctx context.Context, s *Scenario) error {
+	author, active := s.Member("author", true), s.Member("active", true)
+	if _, err := s.NewTeam(ctx, "t", author, active, s.Member("off1", false), s.Member("off2", false)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{active.UserID}) {
+		return fmt.Errorf("only the active member should be assigned, got %v", pr.AssignedReviewers)
+	}
+	// заменить единственного активного можно только неактивным - такого кандидата нет
+	_, _, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, active.UserID, nil)
+	return expectErr(err, apperrors.ErrNoCandidate)
+}
+
+func deactivatedNotAssigned(ctx context.Context, s *Scenario) error {
+	author, a, b := s.Member("author", true), s.Member("a", true), s.Member("b", true)
+	if _, err := s.NewTeam(ctx, "t", author, a, b); err != nil {
+		return err
+	}
+	deactivated, err := s.Users.SetIsActive(ctx, a.UserID, false, nil)
+	if err != nil {
+		return err
+	}
+	if deactivated.IsActive {
+		return fmt.Errorf("setIsActive(false) should return an inactive user")
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{b.UserID}) {
+		return fmt.Errorf("deactivated user should not be assigned, got %v", pr.AssignedReviewers)
+	}
+	return nil
+}
+
+func reassignWithinTeam(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	teamName, err := s.NewTeam(ctx, "home", author, s.Member("a", true), s.Member("b", true), s.Member("c", true))
+	if err != nil {
+		return err
+	}
+	// активные участники другой команды не должны попадать в кандидаты
+	if _, err := s.NewTeam(ctx, "other", s.Member("x", true), s.Member("y", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+
+	old := pr.AssignedReviewers[0]
+	updated, replacedBy, err := s.PullRequests.Reassign(ctx, pr.PullRequestID, old, nil)
+	if err != nil {
+		return err
+	}
+	if replacedBy == old || replacedBy == author.UserID {
+		return fmt.Errorf("replacement should be a new non-author reviewer, got %s", replacedBy)
+	}
+	if err := s.inTeam(ctx, replacedBy, teamName); err != nil {
+		return err
+	}
+	if slices.Contains(updated.AssignedReviewers, old) || !slices.Contains(updated.AssignedReviewers, replacedBy) {
+		return fmt.Errorf("reviewers should swap %s for %s, got %v", old, replacedBy, updated.AssignedReviewers)
+	}
+	if len(updated.AssignedReviewers) != len(pr.AssignedReviewers) {
+		return fmt.Errorf("reassign should keep the number of reviewers, got %v", updated.AssignedReviewers)
+	}
+	return nil
+}
+
+func reassignNotAssigned(ctx context.Context, s *Scenario) error {
+	author, outsider := s.Member("author", true), s.Member("outsider", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true), outsider); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	notAssigned := outsider.UserID
+	if slices.Contains(pr.AssignedReviewers, notAssigned) {
+		notAssigned = author.UserID
+	}
+	_, _, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, notAssigned, nil)
+	return expectErr(err, apperrors.ErrNotAssigned)
+}
+
+func reassignNoCandidate(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	// оба возможных ревьювера уже назначены
+	_, _, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, pr.AssignedReviewers[0], nil)
+	if err := expectErr(err, apperrors.ErrNoCandidate); err != nil {
+		return err
+	}
+	after, err := s.PullRequests.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(after.AssignedReviewers, pr.AssignedReviewers) {
+		return fmt.Errorf("failed reassign should not change reviewers, got %v", after.AssignedReviewers)
+	}
+	return nil
+}
+
+func prMergedAfterMerge(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true), s.Member("b", true), s.Member("c", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.PullRequests.Merge(ctx, pr.PullRequestID, nil, nil); err != nil {
+		return err
+	}
+	_, _, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, pr.AssignedReviewers[0], nil)
+	if err := expectErr(err, apperrors.ErrPRMerged); err != nil {
+		return err
+	}
+	after, err := s.PullRequests.GetByID(ctx, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(after.AssignedReviewers, pr.AssignedReviewers) {
+		return fmt.Errorf("reviewers should not change after merge, got %v", after.AssignedReviewers)
+	}
+	return nil
+}
+
+func mergeIdempotent(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("a", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	first, err := s.PullRequests.Merge(ctx, pr.PullRequestID, nil, nil)
+	if err != nil {
+		return err
+	}
+	for range 3 {
+		again, err := s.PullRequests.Merge(ctx, pr.PullRequestID, nil, nil)
+		if err != nil {
+			return fmt.Errorf("repeated merge should succeed: %w", err)
+		}
+		if again.Status != "MERGED" || again.MergedAt == nil || !again.MergedAt.Equal(*first.MergedAt) {
+			return fmt.Errorf("repeated merge should return the same MERGED state")
+		}
+	}
+	return nil
+}
+
+func getReview(ctx context.Context, s *Scenario) error {
+	author, reviewer := s.Member("author", true), s.Member("reviewer", true)
+	if _, err := s.NewTeam(ctx, "t", author, reviewer); err != nil {
+		return err
+	}
+	for _, name := range []string{"pr1", "pr2"} {
+		if _, err := s.NewPR(ctx, name, author.UserID); err != nil {
+			return err
+		}
+	}
+
+	prs, err := s.Users.GetReview(ctx, reviewer.UserID)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for _, pr := range prs {
+		ids = append(ids, pr.PullRequestID)
+	}
+	if !sameSet(ids, []string{s.ID("pr1"), s.ID("pr2")}) {
+		return fmt.Errorf("getReview should list both PRs, got %v", ids)
+	}
+
+	prs, err = s.Users.GetReview(ctx, author.UserID)
+	if err != nil {
+		return err
+	}
+	if len(prs) != 0 {
+		return fmt.Errorf("author reviews nothing, got %d PRs", len(prs))
+	}
+
+	_, err = s.Users.GetReview(ctx, s.ID("nobody"))
+	return expectErr(err, apperrors.ErrNotFound)
+}
+
+// inTeam проверяет, что пользователь состоит в команде и активен
+func (s *Scenario) inTeam(ctx context.Context, userID string, teamName string) error {
+	u, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user %s: %w", userID, err)
+	}
+	if u.TeamName != teamName || !u.IsActive {
+		return fmt.Errorf("user %s should be an active member of %s, got team %s active %v", userID, teamName, u.TeamName, u.IsActive)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package conformance_test
+
+import (
+	"avito-tech/internal/app/availability"
+	"avito-tech/internal/app/escalation"
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/conformance"
+	"avito-tech/internal/db/sqlite"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLite(t *testing.T) {
+	conformance.Run(t, func(t *testing.T) conformance.Services {
+		database, err := sqlite.Open(t.Context(), filepath.Join(t.TempDir(), "conformance.db"))
+		if err != nil {
+			t.Fatalf("open sqlite: %v", err)
+		}
+		t.Cleanup(func() { _ = database.Close() })
+		return services(
+			team.NewTeam(team.NewTeamSQLiteRepo(database)),
+			user.NewUser(user.NewUserSQLiteRepo(database)),
+			pullrequest.NewSQLiteRepo(database),
+			availability.NewAvailabilitySQLiteRepo(database),
+			escalation.NewEscalationSQLiteRepo(database),
+			database,
+		)
+	})
+}
@@ -0,0 +1,193 @@
+package conformance
+
+import (
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/apperrors"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Cases - все сценарии. Новое хранилище подключается так: реализовать Repo в доменных
+// пакетах, собрать из них Services и вызвать Run; наборы можно гонять и по отдельности.
+var Cases = slices.Concat(StorageCases, AssignmentCases, ReviewCases, LifecycleCases, CapacityCases, AvailabilityCases, WorkingHoursCases, EscalationCases, FallbackCases, HierarchyCases, TenantCases)
+
+// StorageCases проверяют поведение самого хранилища: ограничения уникальности,
+// upsert участников, семантику merge ... RETURNING, версии и транзакции
+var StorageCases = []Case{
+	{Name: "team_exists", Run: teamExists},
+	{Name: "team_not_found", Run: teamNotFound},
+	{Name: "team_upsert_moves_member", Run: teamUpsertMovesMember},
+	{Name: "pr_exists", Run: prExists},
+	{Name: "pr_unknown_author", Run: prUnknownAuthor},
+	{Name: "merge_returns_state", Run: mergeReturnsState},
+	{Name: "merge_not_found", Run: mergeNotFound},
+	{Name: "versions", Run: versions},
+	{Name: "tx_rollback", Run: txRollback},
+}
+
+func expectErr(err error, want error) error {
+	if !errors.Is(err, want) {
+		return fmt.Errorf("expected %v, got %v", want, err)
+	}
+	return nil
+}
+
+func teamExists(ctx context.Context, s *Scenario) error {
+	name, err := s.NewTeam(ctx, "t", s.Member("a", true))
+	if err != nil {
+		return err
+	}
+	err = s.Teams.Create(ctx, &team.TeamDTO{TeamName: name, Members: nil})
+	return expectErr(err, apperrors.ErrTeamExists)
+}
+
+func teamNotFound(ctx context.Context, s *Scenario) error {
+	_, err := s.Teams.GetByTeamName(ctx, s.ID("missing"))
+	return expectErr(err, apperrors.ErrNotFound)
+}
+
+func teamUpsertMovesMember(ctx context.Context, s *Scenario) error {
+	moving := s.Member("moving", true)
+	first, err := s.NewTeam(ctx, "first", moving, s.Member("stays", true))
+	if err != nil {
+		return err
+	}
+	before, err := s.Teams.GetByTeamName(ctx, first)
+	if err != nil {
+		return err
+	}
+
+	moving.Username = "renamed"
+	second, err := s.NewTeam(ctx, "second", moving)
+	if err != nil {
+		return err
+	}
+
+	after, err := s.Teams.GetByTeamName(ctx, first)
+	if err != nil {
+		return err
+	}
+	if len(after.Members) != 1 {
+		return fmt.Errorf("first team should keep 1 member, has %d", len(after.Members))
+	}
+	if after.Version == before.Version {
+		return fmt.Errorf("first team version should change when a member leaves")
+	}
+	u, err := s.Users.GetByID(ctx, moving.UserID)
+	if err != nil {
+		return err
+	}
+	if u.TeamName != second || u.Username != "renamed" {
+		return fmt.Errorf("member should be upserted into %s, got team %s name %s", second, u.TeamName, u.Username)
+	}
+	return nil
+}
+
+func prExists(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author); err != nil {
+		return err
+	}
+	if _, err := s.NewPR(ctx, "pr", author.UserID); err != nil {
+		return err
+	}
+	_, err := s.NewPR(ctx, "pr", author.UserID)
+	return expectErr(err, apperrors.ErrPRExists)
+}
+
+func prUnknownAuthor(ctx context.Context, s *Scenario) error {
+	_, err := s.NewPR(ctx, "pr", s.ID("nobody"))
+	return expectErr(err, apperrors.ErrNotFound)
+}
+
+func mergeReturnsState(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("r1", true), s.Member("r2", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+
+	merged, err := s.PullRequests.Merge(ctx, pr.PullRequestID, nil, nil)
+	if err != nil {
+		return err
+	}
+	if merged.Status != "MERGED" || merged.MergedAt == nil {
+		return fmt.Errorf("merge should return MERGED with mergedAt, got %s %v", merged.Status, merged.MergedAt)
+	}
+	if merged.PullRequestName != "pr" || merged.AuthorID != author.UserID || !sameSet(merged.AssignedReviewers, pr.AssignedReviewers) {
+		return fmt.Errorf("merge should return the full PR, got %+v", merged)
+	}
+
+	again, err := s.PullRequests.Merge(ctx, pr.PullRequestID, nil, nil)
+	if err != nil {
+		return fmt.Errorf("repeated merge: %w", err)
+	}
+	if again.MergedAt == nil || !again.MergedAt.Equal(*merged.MergedAt) || again.Version != merged.Version {
+		return fmt.Errorf("repeated merge should not change the PR")
+	}
+	return nil
+}
+
+func mergeNotFound(ctx context.Context, s *Scenario) error {
+	_, err := s.PullRequests.Merge(ctx, s.ID("missing"), nil, nil)
+	return expectErr(err, apperrors.ErrNotFound)
+}
+
+func versions(ctx context.Context, s *Scenario) error {
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(ctx, "t", author, s.Member("r1", true), s.Member("r2", true), s.Member("r3", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if pr.Version != 1 {
+		return fmt.Errorf("new PR should have version 1, got %d", pr.Version)
+	}
+
+	stale := pr.Version
+	reassigned, _, err := s.PullRequests.Reassign(ctx, pr.PullRequestID, pr.AssignedReviewers[0], &stale)
+	if err != nil {
+		return err
+	}
+	if reassigned.Version != stale+1 {
+		return fmt.Errorf("reassign should bump version to %d, got %d", stale+1, reassigned.Version)
+	}
+	_, err = s.PullRequests.Merge(ctx, pr.PullRequestID, &stale, nil)
+	return expectErr(err, apperrors.ErrPreconditionFailed)
+}
+
+func txRollback(ctx context.Context, s *Scenario) error {
+	if s.Tx == nil {
+		return nil
+	}
+	boom := errors.New("boom")
+	name := s.ID("t")
+	err := s.Tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := s.Teams.Create(ctx, &team.TeamDTO{TeamName: name, Members: []team.TeamMemberDTO{s.Member("a", true)}}); err != nil {
+			return err
+		}
+		if _, err := s.Teams.GetByTeamName(ctx, name); err != nil {
+			return fmt.Errorf("team should be visible inside the transaction: %w", err)
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		return fmt.Errorf("WithTx should return the callback error, got %v", err)
+	}
+	_, err = s.Teams.GetByTeamName(ctx, name)
+	return expectErr(err, apperrors.ErrNotFound)
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
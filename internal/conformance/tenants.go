@@ -0,0 +1,137 @@
+package conformance
+
+import (
+	pullrequest "avito-tech/internal/app/pull_request"
+	"avito-tech/internal/app/tenant"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// TenantCases - арендаторы: одинаковые идентификаторы в разных арендаторах - независимые данные
+var TenantCases = []Case{
+	{Name: "tenant_isolates_ids", Run: tenantIsolatesIDs},
+	{Name: "tenant_scopes_handover", Run: tenantScopesHandover},
+}
+
+// Tenant возвращает контекст отдельного арендатора сценария
+func (s *Scenario) Tenant(ctx context.Context, name string) context.Context {
+	return tenant.WithTenant(ctx, s.ID(name))
+}
+
+func tenantIsolatesIDs(ctx context.Context, s *Scenario) error {
+	a, b := s.Tenant(ctx, "a"), s.Tenant(ctx, "b")
+	author, x, y, z := s.Member("author", true), s.Member("x", true), s.Member("y", true), s.Member("z", true)
+	teamName, err := s.NewTeam(a, "t", author, x, y, z)
+	if err != nil {
+		return err
+	}
+	pr, err := s.NewPR(a, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+
+	// во втором арендаторе ничего из первого не видно
+	_, err = s.Teams.GetByTeamName(b, teamName)
+	if err := expectErr(err, apperrors.ErrNotFound); err != nil {
+		return fmt.Errorf("team from another tenant: %w", err)
+	}
+	_, err = s.Users.GetByID(b, author.UserID)
+	if err := expectErr(err, apperrors.ErrNotFound); err != nil {
+		return fmt.Errorf("user from another tenant: %w", err)
+	}
+	_, err = s.PullRequests.GetByID(b, pr.PullRequestID)
+	if err := expectErr(err, apperrors.ErrNotFound); err != nil {
+		return fmt.Errorf("PR from another tenant: %w", err)
+	}
+
+	// те же идентификаторы свободны во втором арендаторе
+	if _, err := s.NewTeam(b, "t", author, y); err != nil {
+		return err
+	}
+	other, err := s.NewPR(b, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(other.AssignedReviewers, []string{y.UserID}) {
+		return fmt.Errorf("expected only %s from the second tenant, got %v", y.UserID, other.AssignedReviewers)
+	}
+	load, err := s.Users.GetLoad(b, y.UserID)
+	if err != nil {
+		return err
+	}
+	if load.OpenReviews != 1 {
+		return fmt.Errorf("expected 1 open review of %s in the second tenant, got %d", y.UserID, load.OpenReviews)
+	}
+
+	if _, err := s.PullRequests.Merge(a, pr.PullRequestID, nil, nil); err != nil {
+		return err
+	}
+	if _, err := s.Users.SetIsActive(a, author.UserID, false, nil); err != nil {
+		return err
+	}
+	other, err = s.PullRequests.GetByID(b, other.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if other.Status != pullrequest.StatusOpen {
+		return fmt.Errorf("merge in one tenant changed PR status in another to %s", other.Status)
+	}
+	u, err := s.Users.GetByID(b, author.UserID)
+	if err != nil {
+		return err
+	}
+	if !u.IsActive {
+		return fmt.Errorf("deactivation in one tenant deactivated %s in another", author.UserID)
+	}
+	t, err := s.Teams.GetByTeamName(b, teamName)
+	if err != nil {
+		return err
+	}
+	if len(t.Members) != 2 {
+		return fmt.Errorf("expected 2 members in the second tenant, got %d", len(t.Members))
+	}
+	return nil
+}
+
+func tenantScopesHandover(ctx context.Context, s *Scenario) error {
+	a, b := s.Tenant(ctx, "a"), s.Tenant(ctx, "b")
+	author := s.Member("author", true)
+	if _, err := s.NewTeam(a, "t", author, s.Member("r1", true), s.Member("r2", true), s.Member("spare", true)); err != nil {
+		return err
+	}
+	pr, err := s.NewPR(a, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	leaving := pr.AssignedReviewers[0]
+	if _, err := s.NewWindow(a, leaving, -time.Minute, time.Hour, true); err != nil {
+		return err
+	}
+
+	// проход по другому арендатору не трогает окна этого
+	if _, err := s.Handover.RunOnce(b); err != nil {
+		return err
+	}
+	pr, err = s.PullRequests.GetByID(a, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(pr.AssignedReviewers, leaving) {
+		return fmt.Errorf("handover of another tenant reassigned %s: %v", leaving, pr.AssignedReviewers)
+	}
+
+	if _, err := s.Handover.RunOnce(a); err != nil {
+		return err
+	}
+	pr, err = s.PullRequests.GetByID(a, pr.PullRequestID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(pr.AssignedReviewers, leaving) || len(pr.AssignedReviewers) != 2 {
+		return fmt.Errorf("%s should be handed over in its tenant, got %v", leaving, pr.AssignedReviewers)
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package conformance
+
+import (
+	"avito-tech/internal/app/team"
+	"avito-tech/internal/app/user"
+	"avito-tech/internal/apperrors"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// WorkingHoursCases - стратегия working-hours: сначала кандидаты в рабочее время, остальные - запасные
+var WorkingHoursCases = []Case{
+	{Name: "working_hours_preferred", Run: workingHoursPreferred},
+	{Name: "working_hours_fallback", Run: workingHoursFallback},
+	{Name: "reassign_prefers_working_hours", Run: reassignPrefersWorkingHours},
+	{Name: "working_hours_validated", Run: workingHoursValidated},
+}
+
+// зимой смещения постоянны: Белград UTC+1, Москва UTC+3, Ереван UTC+4
+var winterDay = time.Date(2026, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+func officeHours(timezone string) *user.WorkingHoursDTO {
+	return &user.WorkingHoursDTO{Timezone: timezone, Start: "09:00", End: "18:00"}
+}
+
+// SetWorkingHours задаёт рабочие часы нескольким пользователям
+func (s *Scenario) SetWorkingHours(ctx context.Context, hours map[string]*user.WorkingHoursDTO) error {
+	for userID, wh := range hours {
+		if _, err := s.Users.SetWorkingHours(ctx, userID, wh); err != nil {
+			return fmt.Errorf("set working hours of %s: %w", userID, err)
+		}
+	}
+	return nil
+}
+
+func workingHoursPreferred(ctx context.Context, s *Scenario) error {
+	author, belgrade, moscow, yerevan := s.Member("author", true), s.Member("a", true), s.Member("b", true), s.Member("c", true)
+	if _, err := s.NewTeam(ctx, "t", author, belgrade, moscow, yerevan); err != nil {
+		return err
+	}
+	err := s.SetWorkingHours(ctx, map[string]*user.WorkingHoursDTO{
+		belgrade.UserID: officeHours("Europe/Belgrade"),
+		moscow.UserID:   officeHours("Europe/Moscow"),
+		yerevan.UserID:  officeHours("Asia/Yerevan"),
+	})
+	if err != nil {
+		return err
+	}
+
+	// 06:30 UTC: в Белграде 07:30, в Москве 09:30, в Ереване 10:30
+	s.Clock.Set(winterDay.Add(6*time.Hour + 30*time.Minute))
+	pr, err := s.NewPR(ctx, "morning", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(pr.AssignedReviewers, []string{moscow.UserID, yerevan.UserID}) {
+		return fmt.Errorf("morning: expected Moscow and Yerevan, got %v", pr.AssignedReviewers)
+	}
+
+	// 16:30 UTC: работает только Белград, второй ревьювер - запасной по порядку
+	s.Clock.Set(winterDay.Add(16*time.Hour + 30*time.Minute))
+	pr, err = s.NewPR(ctx, "evening", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{belgrade.UserID, moscow.UserID}) {
+		return fmt.Errorf("evening: expected Belgrade then Moscow as fallback, got %v", pr.AssignedReviewers)
+	}
+	return nil
+}
+
+func workingHoursFallback(ctx context.Context, s *Scenario) error {
+	author, day, night, unset := s.Member("author", true), s.Member("a", true), s.Member("b", true), s.Member("c", true)
+	if _, err := s.NewTeam(ctx, "t", author, day, night, unset); err != nil {
+		return err
+	}
+	err := s.SetWorkingHours(ctx, map[string]*user.WorkingHoursDTO{
+		day.UserID:   officeHours("Europe/Moscow"),
+		night.UserID: {Timezone: "Europe/Moscow", Start: "22:00", End: "06:00"},
+	})
+	if err != nil {
+		return err
+	}
+
+	// 00:30 UTC = 03:30 в Москве: ночная смена на месте, без часов - считается на месте
+	s.Clock.Set(winterDay.Add(30 * time.Minute))
+	pr, err := s.NewPR(ctx, "night", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(pr.AssignedReviewers, []string{night.UserID, unset.UserID}) {
+		return fmt.Errorf("night: expected the night shift and the member without hours, got %v", pr.AssignedReviewers)
+	}
+
+	// никого нет на месте - назначение всё равно происходит
+	if _, err := s.Users.SetIsActive(ctx, unset.UserID, false, nil); err != nil {
+		return err
+	}
+	s.Clock.Set(winterDay.Add(4 * time.Hour))
+	pr, err = s.NewPR(ctx, "dawn", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !sameSet(pr.AssignedReviewers, []string{day.UserID, night.UserID}) {
+		return fmt.Errorf("nobody at work: expected both members anyway, got %v", pr.AssignedReviewers)
+	}
+	return nil
+}
+
+func reassignPrefersWorkingHours(ctx context.Context, s *Scenario) error {
+	author, a, b, off, on := s.Member("author", true), s.Member("a", true), s.Member("b", true), s.Member("c", true), s.Member("d", true)
+	if _, err := s.NewTeam(ctx, "t", author, a, b, off, on); err != nil {
+		return err
+	}
+	s.Clock.Set(winterDay.Add(6*time.Hour + 30*time.Minute))
+	pr, err := s.NewPR(ctx, "pr", author.UserID)
+	if err != nil {
+		return err
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{a.UserID, b.UserID}) {
+		return fmt.Errorf("without working hours the order is by user_id, got %v", pr.AssignedReviewers)
+	}
+
+	err = s.SetWorkingHours(ctx, map[string]*user.WorkingHoursDTO{
+		off.UserID: officeHours("Europe/Belgrade"),
+		on.UserID:  officeHours("Europe/Moscow"),
+	})
+	if err != nil {
+		return err
+	}
+	_, replacedBy, err := s.PullRequests.Reassign(ctx, pr.PullRequestID, a.UserID, nil)
+	if err != nil {
+		return err
+	}
+	if replacedBy != on.UserID {
+		return fmt.Errorf("expected %s who is at work, got %s", on.UserID, replacedBy)
+	}
+	// снятый ревьювер без рабочих часов обошёл бы запасного, поэтому он уходит из кандидатов
+	if _, err := s.Users.SetIsActive(ctx, a.UserID, false, nil); err != nil {
+		return err
+	}
+	_, replacedBy, err = s.PullRequests.Reassign(ctx, pr.PullRequestID, b.UserID, nil)
+	if err != nil {
+		return err
+	}
+	if replacedBy != off.UserID {
+		return fmt.Errorf("off-hours member is the only fallback, got %s", replacedBy)
+	}
+	return nil
+}
+
+func workingHoursValidated(ctx context.Context, s *Scenario) error {
+	a := s.Member("a", true)
+	teamName, err := s.NewTeam(ctx, "t", a)
+	if err != nil {
+		return err
+	}
+	invalid := []*user.WorkingHoursDTO{
+		{Timezone: "Mars/Olympus", Start: "09:00", End: "18:00"},
+		{Timezone: "", Start: "09:00", End: "18:00"},
+		{Timezone: "Europe/Moscow", Start: "9am", End: "18:00"},
+		{Timezone: "Europe/Moscow", Start: "09:00", End: "24:00"},
+		{Timezone: "Europe/Moscow", Start: "10:00", End: "10:00"},
+	}
+	for _, wh := range invalid {
+		if _, err := s.Users.SetWorkingHours(ctx, a.UserID, wh); expectErr(err, apperrors.ErrBadRequest) != nil {
+			return fmt.Errorf("%+v: expected %v, got %v", wh, apperrors.ErrBadRequest, err)
+		}
+	}
+	if _, err := s.Users.SetWorkingHours(ctx, s.ID("nobody"), officeHours("Europe/Moscow")); expectErr(err, apperrors.ErrNotFound) != nil {
+		return fmt.Errorf("unknown user: expected %v, got %v", apperrors.ErrNotFound, err)
+	}
+
+	set, err := s.Users.SetWorkingHours(ctx, a.UserID, &user.WorkingHoursDTO{Timezone: "Asia/Yerevan", Start: "8:30", End: "17:05"})
+	if err != nil {
+		return err
+	}
+	if *set != (user.WorkingHoursDTO{Timezone: "Asia/Yerevan", Start: "08:30", End: "17:05"}) {
+		return fmt.Errorf("unexpected stored working hours %+v", set)
+	}
+
+	// повторное добавление команды не сбрасывает рабочие часы
+	if err := s.Teams.Create(ctx, &team.TeamDTO{TeamName: teamName + "-moved", Members: []team.TeamMemberDTO{a}}); err != nil {
+		return err
+	}
+	got, err := s.Users.GetWorkingHours(ctx, a.UserID)
+	if err != nil {
+		return err
+	}
+	if got == nil || *got != *set {
+		return fmt.Errorf("working hours should survive moving between teams, got %+v", got)
+	}
+
+	if _, err := s.Users.SetWorkingHours(ctx, a.UserID, nil); err != nil {
+		return err
+	}
+	got, err = s.Users.GetWorkingHours(ctx, a.UserID)
+	if err != nil {
+		return err
+	}
+	if got != nil {
+		return fmt.Errorf("working hours should be cleared, got %+v", got)
+	}
+	return nil
+}
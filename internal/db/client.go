@@ -25,6 +25,15 @@ func CreateDB(ctx context.Context) (*Database, error) {
 	return newDB(pool), nil
 }
 
+// Connect подключается по строке соединения dsn; в отличие от CreateDB, ошибку возвращает вызывающему
+func Connect(ctx context.Context, dsn string) (*Database, error) {
+	pool, err := pgxpool.Connect(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return newDB(pool), nil
+}
+
 func GenerateConn() string {
 	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s", host, user, password, dbname, port, sslmode)
 }
@@ -19,22 +19,48 @@ func newDB(pool *pgxpool.Pool) *Database {
 	}
 }
 
+// conn - общее у пула и транзакции
+type conn interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, query string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, query string, args ...any) pgx.Row
+}
+
+// conn возвращает транзакцию из контекста (см. WithTx), иначе пул
+func (db *Database) conn(ctx context.Context) conn {
+	if tx := txFromContext(ctx); tx != nil {
+		return tx
+	}
+	return db.pool
+}
+
+// GetPool отдаёт пул напрямую, мимо транзакции из контекста
 func (db *Database) GetPool(_ context.Context) *pgxpool.Pool {
 	return db.pool
 }
 
+// Begin открывает транзакцию; внутри WithTx - точку сохранения во внешней транзакции
+func (db *Database) Begin(ctx context.Context) (pgx.Tx, error) {
+	return db.conn(ctx).Begin(ctx)
+}
+
 func (db *Database) Get(ctx context.Context, dest any, query string, args ...any) error {
-	return pgxscan.Get(ctx, db.pool, dest, query, args...)
+	return pgxscan.Get(ctx, db.conn(ctx), dest, query, args...)
 }
 
 func (db *Database) Select(ctx context.Context, dest any, query string, args ...any) error {
-	return pgxscan.Select(ctx, db.pool, dest, query, args...)
+	return pgxscan.Select(ctx, db.conn(ctx), dest, query, args...)
 }
 
 func (db *Database) Exec(ctx context.Context, query string, args ...any) (pgconn.CommandTag, error) {
-	return db.pool.Exec(ctx, query, args...)
+	return db.conn(ctx).Exec(ctx, query, args...)
+}
+
+func (db *Database) Query(ctx context.Context, query string, args ...any) (pgx.Rows, error) {
+	return db.conn(ctx).Query(ctx, query, args...)
 }
 
 func (db *Database) ExecQueryRow(ctx context.Context, query string, args ...any) pgx.Row {
-	return db.pool.QueryRow(ctx, query, args...)
+	return db.conn(ctx).QueryRow(ctx, query, args...)
 }
@@ -0,0 +1,402 @@
+// Package memory - хранилище в памяти для тестов и демо без Postgres.
+// Таблицы лежат в Data; репозитории доменных пакетов читают и меняют их через Store.
+package memory
+
+import (
+	"avito-tech/internal/app/tenant"
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Team struct {
+	ID      uint64
+	Name    string
+	Version uint64
+	// MaxOpenReviews - лимит открытых ревью участника по умолчанию, nil - без ограничения
+	MaxOpenReviews *int
+	// ReviewSLAMinutes - SLA ревью, nil - эскалация выключена
+	ReviewSLAMinutes *int
+	EscalationAction string
+	// FallbackTeamIDs - запасные команды в порядке приоритета
+	FallbackTeamIDs []uint64
+	// ParentID - родительская команда, nil - команда в корне организации
+	ParentID *uint64
+}
+
+type User struct {
+	ID       string
+	Username string
+	TeamID   uint64
+	IsActive bool
+	// MaxOpenReviews - личный лимит, перекрывает командный
+	MaxOpenReviews *int
+	// WorkingHours - рабочие часы, nil - не заданы
+	WorkingHours *WorkingHours
+}
+
+// WorkingHours - интервал [Start, End) в минутах от полуночи в зоне Timezone; Start > End - через полночь
+type WorkingHours struct {
+	Timezone string
+	Start    int
+	End      int
+}
+
+type PullRequest struct {
+	ID        string
+	Name      string
+	AuthorID  string
+	Status    string
+	CreatedAt time.Time
+	MergedAt  *time.Time
+	Version   uint64
+	Reviewers []string
+	// AssignedAt - момент назначения каждого ревьювера из Reviewers
+	AssignedAt map[string]time.Time
+	// SourceTeams - команда, из которой назначен каждый ревьювер из Reviewers
+	SourceTeams map[string]uint64
+	// Reviews - вердикты назначенных ревьюверов
+	Reviews []Review
+}
+
+type Review struct {
+	UserID    string
+	Decision  string
+	Comment   string
+	UpdatedAt time.Time
+}
+
+// Window - окно недоступности пользователя [StartsAt, EndsAt)
+type Window struct {
+	ID           uint64
+	UserID       string
+	StartsAt     time.Time
+	EndsAt       time.Time
+	Reason       string
+	Handover     bool
+	HandedOverAt *time.Time
+	CreatedAt    time.Time
+}
+
+// Escalation - эскалация назначения ревьювера; назначение (PullRequestID, UserID, AssignedAt) эскалируется один раз
+type Escalation struct {
+	ID            uint64
+	PullRequestID string
+	UserID        string
+	TeamID        uint64
+	AssignedAt    time.Time
+	Action        string
+	EscalatedAt   time.Time
+	ReplacedBy    *string
+}
+
+// Data - снимок всех таблиц
+type Data struct {
+	Teams        map[string]*Team
+	Users        map[string]*User
+	PullRequests map[string]*PullRequest
+	Windows      map[uint64]*Window
+	Escalations  map[uint64]*Escalation
+
+	lastTeamID       uint64
+	lastWindowID     uint64
+	lastEscalationID uint64
+}
+
+func newData() *Data {
+	return &Data{
+		Teams:        map[string]*Team{},
+		Users:        map[string]*User{},
+		PullRequests: map[string]*PullRequest{},
+		Windows:      map[uint64]*Window{},
+		Escalations:  map[uint64]*Escalation{},
+	}
+}
+
+func (d *Data) clone() *Data {
+	c := &Data{
+		Teams:        make(map[string]*Team, len(d.Teams)),
+		Users:        make(map[string]*User, len(d.Users)),
+		PullRequests: make(map[string]*PullRequest, len(d.PullRequests)),
+		Windows:      make(map[uint64]*Window, len(d.Windows)),
+		Escalations:  make(map[uint64]*Escalation, len(d.Escalations)),
+
+		lastTeamID:       d.lastTeamID,
+		lastWindowID:     d.lastWindowID,
+		lastEscalationID: d.lastEscalationID,
+	}
+	for k, v := range d.Teams {
+		t := *v
+		t.FallbackTeamIDs = slices.Clone(v.FallbackTeamIDs)
+		c.Teams[k] = &t
+	}
+	for k, v := range d.Users {
+		u := *v
+		c.Users[k] = &u
+	}
+	for k, v := range d.PullRequests {
+		pr := *v
+		pr.Reviewers = slices.Clone(v.Reviewers)
+		pr.AssignedAt = maps.Clone(v.AssignedAt)
+		pr.SourceTeams = maps.Clone(v.SourceTeams)
+		pr.Reviews = slices.Clone(v.Reviews)
+		if v.MergedAt != nil {
+			mergedAt := *v.MergedAt
+			pr.MergedAt = &mergedAt
+		}
+		c.PullRequests[k] = &pr
+	}
+	for k, v := range d.Windows {
+		w := *v
+		c.Windows[k] = &w
+	}
+	for k, v := range d.Escalations {
+		e := *v
+		c.Escalations[k] = &e
+	}
+	return c
+}
+
+// NextTeamID - аналог BIGSERIAL
+func (d *Data) NextTeamID() uint64 {
+	d.lastTeamID++
+	return d.lastTeamID
+}
+
+// NextWindowID - аналог BIGSERIAL для окон недоступности
+func (d *Data) NextWindowID() uint64 {
+	d.lastWindowID++
+	return d.lastWindowID
+}
+
+// NextEscalationID - аналог BIGSERIAL для эскалаций
+func (d *Data) NextEscalationID() uint64 {
+	d.lastEscalationID++
+	return d.lastEscalationID
+}
+
+func (d *Data) TeamByID(id uint64) *Team {
+	for _, t := range d.Teams {
+		if t.ID == id {
+			return t
+		}
+	}
+	return nil
+}
+
+// Ancestors - команда teamID и её предки от родителя к корню организации
+func (d *Data) Ancestors(teamID uint64) []*Team {
+	var chain []*Team
+	for t := d.TeamByID(teamID); t != nil; {
+		chain = append(chain, t)
+		if t.ParentID == nil {
+			break
+		}
+		t = d.TeamByID(*t.ParentID)
+	}
+	return chain
+}
+
+// Subtree - команда teamID и все её подкоманды с глубиной относительно неё, как строки team_closure
+// с ancestor_id = teamID
+func (d *Data) Subtree(teamID uint64) map[uint64]int {
+	subtree := map[uint64]int{}
+	for _, t := range d.Teams {
+		for depth, a := range d.Ancestors(t.ID) {
+			if a.ID == teamID {
+				subtree[t.ID] = depth
+				break
+			}
+		}
+	}
+	return subtree
+}
+
+// EscalationPolicy - команда, чья политика эскалации действует для teamID: сама команда или ближайший предок,
+// у которого задан SLA; nil - эскалация выключена
+func (d *Data) EscalationPolicy(teamID uint64) *Team {
+	for _, t := range d.Ancestors(teamID) {
+		if t.ReviewSLAMinutes != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+// SourceTeams - команда teamID и запасные команды в порядке приоритета: свои или ближайшего предка,
+// у которого они заданы
+func (d *Data) SourceTeams(teamID uint64) []uint64 {
+	teams := []uint64{teamID}
+	for _, t := range d.Ancestors(teamID) {
+		if len(t.FallbackTeamIDs) == 0 {
+			continue
+		}
+		for _, id := range t.FallbackTeamIDs {
+			if id != teamID {
+				teams = append(teams, id)
+			}
+		}
+		break
+	}
+	return teams
+}
+
+// TeamMembers возвращает участников команды, отсортированных по user_id
+func (d *Data) TeamMembers(teamID uint64) []*User {
+	var members []*User
+	for _, u := range d.Users {
+		if u.TeamID == teamID {
+			members = append(members, u)
+		}
+	}
+	slices.SortFunc(members, func(a, b *User) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return members
+}
+
+// OpenReviews - число OPEN PR, где пользователь назначен ревьювером
+func (d *Data) OpenReviews(userID string) int {
+	n := 0
+	for _, pr := range d.PullRequests {
+		if pr.Status == "OPEN" && slices.Contains(pr.Reviewers, userID) {
+			n++
+		}
+	}
+	return n
+}
+
+// Capacity - действующий лимит открытых ревью пользователя: личный, иначе ближайшей команды в цепочке
+// предков; nil - без ограничения
+func (d *Data) Capacity(u *User) *int {
+	if u.MaxOpenReviews != nil {
+		return u.MaxOpenReviews
+	}
+	for _, t := range d.Ancestors(u.TeamID) {
+		if t.MaxOpenReviews != nil {
+			return t.MaxOpenReviews
+		}
+	}
+	return nil
+}
+
+// AtCapacity сообщает, что пользователь не может взять ещё одно ревью
+func (d *Data) AtCapacity(u *User) bool {
+	limit := d.Capacity(u)
+	return limit != nil && d.OpenReviews(u.ID) >= *limit
+}
+
+// Unavailable сообщает, что момент now попадает в окно недоступности пользователя
+func (d *Data) Unavailable(userID string, now time.Time) bool {
+	for _, w := range d.Windows {
+		if w.UserID == userID && !now.Before(w.StartsAt) && now.Before(w.EndsAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// Store хранит данные каждого арендатора отдельно: Read, Write и WithTx работают с данными
+// арендатора из контекста (tenant.FromContext), поэтому идентификаторы уникальны только внутри него
+type Store struct {
+	mu      sync.RWMutex
+	tenants map[string]*Data
+}
+
+func NewStore() *Store {
+	return &Store{tenants: map[string]*Data{}}
+}
+
+// Tenants возвращает арендаторов, у которых есть хотя бы одна команда
+func (s *Store) Tenants() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var ids []string
+	for id, d := range s.tenants {
+		if len(d.Teams) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+type txKey struct{}
+
+// tx - рабочие копии данных арендаторов, затронутых транзакцией
+type tx struct {
+	store   *Store
+	working map[string]*Data
+}
+
+// data возвращает рабочую копию арендатора; вызывается под эксклюзивной блокировкой WithTx
+func (t *tx) data(id string) *Data {
+	if d, ok := t.working[id]; ok {
+		return d
+	}
+	d := t.store.committed(id).clone()
+	t.working[id] = d
+	return d
+}
+
+// committed - опубликованные данные арендатора; у нового арендатора данных нет
+func (s *Store) committed(id string) *Data {
+	if d, ok := s.tenants[id]; ok {
+		return d
+	}
+	return newData()
+}
+
+// WithTx выполняет fn под эксклюзивной блокировкой на рабочей копии данных;
+// копия публикуется, только если fn завершилась без ошибки.
+func (s *Store) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*tx); ok {
+		return fn(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := &tx{store: s, working: map[string]*Data{}}
+	if err := fn(context.WithValue(ctx, txKey{}, t)); err != nil {
+		return err
+	}
+	maps.Copy(s.tenants, t.working)
+	return nil
+}
+
+// Read даёт fn согласованный снимок; указатели из Data нельзя сохранять после возврата
+func (s *Store) Read(ctx context.Context, fn func(d *Data) error) error {
+	id := tenant.FromContext(ctx)
+	if t, ok := ctx.Value(txKey{}).(*tx); ok {
+		return fn(t.data(id))
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return fn(s.committed(id))
+}
+
+// Write атомарно применяет fn: при ошибке изменения отбрасываются.
+// Внутри WithTx работает как точка сохранения.
+func (s *Store) Write(ctx context.Context, fn func(d *Data) error) error {
+	id := tenant.FromContext(ctx)
+	if t, ok := ctx.Value(txKey{}).(*tx); ok {
+		working := t.data(id).clone()
+		if err := fn(working); err != nil {
+			return err
+		}
+		t.working[id] = working
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	working := s.committed(id).clone()
+	if err := fn(working); err != nil {
+		return err
+	}
+	s.tenants[id] = working
+	return nil
+}
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- связь PR с PR во внешнем forge; пишется до создания PR, поэтому без внешнего ключа
+CREATE TABLE forge_link (
+    pull_request_id VARCHAR(64) PRIMARY KEY,
+    forge VARCHAR(20) NOT NULL,
+    repository VARCHAR(255) NOT NULL,
+    number BIGINT NOT NULL
+);
+
+CREATE TABLE forge_sync (
+    id SERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(64) NOT NULL,
+    event_id VARCHAR(64) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    logins TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP DEFAULT NOW() NOT NULL
+);
+
+CREATE INDEX forge_sync_pull_request_idx ON forge_sync (pull_request_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS forge_sync;
+
+DROP TABLE IF EXISTS forge_link;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE team ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+ALTER TABLE pull_request ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_request DROP COLUMN IF EXISTS version;
+
+ALTER TABLE team DROP COLUMN IF EXISTS version;
+-- +goose StatementEnd
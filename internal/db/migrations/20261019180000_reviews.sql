@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- вердикт хранится, пока ревьювер назначен: переназначение удаляет строку pull_request_reviewer и каскадом вердикт
+CREATE TABLE pull_request_review (
+    pull_request_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED')),
+    comment TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT NOW() NOT NULL,
+    PRIMARY KEY (pull_request_id, user_id),
+    FOREIGN KEY (pull_request_id, user_id) REFERENCES pull_request_reviewer (pull_request_id, user_id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pull_request_review;
+-- +goose StatementEnd
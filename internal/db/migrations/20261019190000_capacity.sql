@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- лимит открытых ревью: личный у пользователя, иначе командный; NULL - без ограничения
+ALTER TABLE team ADD COLUMN max_open_reviews INT CHECK (max_open_reviews >= 0);
+
+ALTER TABLE users ADD COLUMN max_open_reviews INT CHECK (max_open_reviews >= 0);
+
+-- нагрузка ревьювера считается по user_id при каждом выборе кандидатов
+CREATE INDEX IF NOT EXISTS idx_pull_request_reviewer_user ON pull_request_reviewer (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_pull_request_reviewer_user;
+
+ALTER TABLE users DROP COLUMN IF EXISTS max_open_reviews;
+
+ALTER TABLE team DROP COLUMN IF EXISTS max_open_reviews;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- окна недоступности: в [starts_at, ends_at) пользователь не назначается ревьювером
+CREATE TABLE user_unavailability (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(64) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    handover BOOLEAN NOT NULL DEFAULT false,
+    handed_over_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT NOW() NOT NULL,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_user_unavailability_user ON user_unavailability (user_id, ends_at);
+
+-- окна, чьи ревью ещё не переданы
+CREATE INDEX idx_user_unavailability_handover ON user_unavailability (starts_at)
+    WHERE handover AND handed_over_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_unavailability;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- рабочие часы пользователя: IANA-зона и интервал [work_start, work_end) в минутах от полуночи по местному
+-- времени; work_start > work_end - смена через полночь. Все три поля заданы или все NULL.
+ALTER TABLE users ADD COLUMN timezone TEXT;
+
+ALTER TABLE users ADD COLUMN work_start SMALLINT CHECK (work_start BETWEEN 0 AND 1439);
+
+ALTER TABLE users ADD COLUMN work_end SMALLINT CHECK (work_end BETWEEN 0 AND 1439);
+
+ALTER TABLE users ADD CONSTRAINT users_working_hours_check CHECK (
+    (timezone IS NULL) = (work_start IS NULL)
+    AND (work_start IS NULL) = (work_end IS NULL)
+    AND work_start <> work_end
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_working_hours_check;
+
+ALTER TABLE users DROP COLUMN IF EXISTS work_end;
+
+ALTER TABLE users DROP COLUMN IF EXISTS work_start;
+
+ALTER TABLE users DROP COLUMN IF EXISTS timezone;
+-- +goose StatementEnd
@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- момент назначения ревьювера: от него отсчитывается SLA команды
+ALTER TABLE pull_request_reviewer ADD COLUMN assigned_at TIMESTAMPTZ;
+
+UPDATE pull_request_reviewer r SET assigned_at = pr.created_at
+FROM pull_request pr
+WHERE pr.pull_request_id = r.pull_request_id;
+
+ALTER TABLE pull_request_reviewer ALTER COLUMN assigned_at SET DEFAULT NOW();
+
+ALTER TABLE pull_request_reviewer ALTER COLUMN assigned_at SET NOT NULL;
+
+-- политика эскалации: review_sla_minutes NULL - эскалация выключена
+ALTER TABLE team ADD COLUMN review_sla_minutes INT CHECK (review_sla_minutes > 0);
+
+ALTER TABLE team ADD COLUMN escalation_action VARCHAR(20) NOT NULL DEFAULT 'remind'
+    CHECK (escalation_action IN ('remind', 'reassign', 'notify_lead'));
+
+-- эскалации назначений: одно назначение (PR, ревьювер, assigned_at) эскалируется не больше одного раза
+CREATE TABLE review_escalation (
+    id BIGSERIAL PRIMARY KEY,
+    pull_request_id VARCHAR(64) NOT NULL REFERENCES pull_request (pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    team_id BIGINT NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('remind', 'reassign', 'notify_lead')),
+    escalated_at TIMESTAMPTZ NOT NULL,
+    replaced_by VARCHAR(64) REFERENCES users (user_id) ON DELETE SET NULL,
+    UNIQUE (pull_request_id, user_id, assigned_at)
+);
+
+CREATE INDEX idx_review_escalation_team ON review_escalation (team_id, escalated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS review_escalation;
+
+ALTER TABLE team DROP COLUMN IF EXISTS escalation_action;
+
+ALTER TABLE team DROP COLUMN IF EXISTS review_sla_minutes;
+
+ALTER TABLE pull_request_reviewer DROP COLUMN IF EXISTS assigned_at;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- запасные команды: когда команда автора не набирает ревьюверов, они добираются из запасных по порядку priority
+CREATE TABLE team_fallback (
+    team_id BIGINT NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    fallback_team_id BIGINT NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    priority INT NOT NULL CHECK (priority > 0),
+    PRIMARY KEY (team_id, fallback_team_id),
+    UNIQUE (team_id, priority),
+    CHECK (team_id <> fallback_team_id)
+);
+
+-- команда, из которой назначен ревьювер: своя команда автора или запасная
+ALTER TABLE pull_request_reviewer ADD COLUMN source_team_id BIGINT REFERENCES team (id) ON DELETE CASCADE;
+
+UPDATE pull_request_reviewer r SET source_team_id = u.team_id
+FROM pull_request pr
+JOIN users u ON u.user_id = pr.author_id
+WHERE pr.pull_request_id = r.pull_request_id;
+
+ALTER TABLE pull_request_reviewer ALTER COLUMN source_team_id SET NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_request_reviewer DROP COLUMN IF EXISTS source_team_id;
+
+DROP TABLE IF EXISTS team_fallback;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- иерархия команд: отделы и команды; команда без родителя лежит в корне организации
+ALTER TABLE team ADD COLUMN parent_id BIGINT REFERENCES team (id) ON DELETE RESTRICT;
+ALTER TABLE team ADD CONSTRAINT team_parent_not_self CHECK (parent_id <> id);
+
+-- замыкание иерархии: пара (предок, потомок) на каждом расстоянии depth, включая саму команду с depth = 0.
+-- Репозитории обновляют его вместе с parent_id.
+CREATE TABLE team_closure (
+    ancestor_id BIGINT NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    team_id BIGINT NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    depth INT NOT NULL CHECK (depth >= 0),
+    PRIMARY KEY (ancestor_id, team_id)
+);
+
+CREATE INDEX team_closure_team_idx ON team_closure (team_id, depth);
+
+INSERT INTO team_closure (ancestor_id, team_id, depth)
+SELECT id, id, 0 FROM team;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS team_closure;
+
+ALTER TABLE team DROP COLUMN IF EXISTS parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,173 @@
+-- +goose Up
+-- +goose StatementBegin
+-- арендатор: команды, пользователи и PR одного арендатора не видны другим, а их идентификаторы
+-- уникальны только внутри арендатора. Существующие данные переходят в арендатора 'default';
+-- значение по умолчанию снимается, чтобы запись без арендатора не попала в чужие данные молча.
+ALTER TABLE team ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE users ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE pull_request ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE pull_request_reviewer ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE pull_request_review ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE user_unavailability ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE review_escalation ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE forge_link ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE forge_sync ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE outbox ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE webhook_subscription ADD COLUMN tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+-- NULL - ключ не привязан к арендатору (как статический токен без tenant)
+ALTER TABLE api_key ADD COLUMN tenant_id VARCHAR(64);
+
+ALTER TABLE team ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE users ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE pull_request ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE pull_request_reviewer ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE pull_request_review ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE user_unavailability ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE review_escalation ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE forge_link ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE forge_sync ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE outbox ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE webhook_subscription ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- внешние ключи на однострочные идентификаторы снимаются до смены ключей, на которые они ссылаются
+ALTER TABLE pull_request_review DROP CONSTRAINT pull_request_review_pull_request_id_user_id_fkey;
+ALTER TABLE pull_request_reviewer DROP CONSTRAINT pull_request_reviewer_pull_request_id_fkey;
+ALTER TABLE pull_request_reviewer DROP CONSTRAINT pull_request_reviewer_user_id_fkey;
+ALTER TABLE pull_request DROP CONSTRAINT pull_request_author_id_fkey;
+ALTER TABLE user_unavailability DROP CONSTRAINT user_unavailability_user_id_fkey;
+ALTER TABLE review_escalation DROP CONSTRAINT review_escalation_pull_request_id_fkey;
+ALTER TABLE review_escalation DROP CONSTRAINT review_escalation_user_id_fkey;
+ALTER TABLE review_escalation DROP CONSTRAINT review_escalation_replaced_by_fkey;
+ALTER TABLE users DROP CONSTRAINT users_team_id_fkey;
+
+ALTER TABLE team DROP CONSTRAINT team_team_name_key;
+ALTER TABLE team ADD CONSTRAINT team_tenant_name_key UNIQUE (tenant_id, team_name);
+ALTER TABLE team ADD CONSTRAINT team_tenant_id_key UNIQUE (tenant_id, id);
+
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users ADD CONSTRAINT users_pkey PRIMARY KEY (tenant_id, user_id);
+
+ALTER TABLE pull_request DROP CONSTRAINT pull_request_pkey;
+ALTER TABLE pull_request ADD CONSTRAINT pull_request_pkey PRIMARY KEY (tenant_id, pull_request_id);
+
+ALTER TABLE pull_request_reviewer DROP CONSTRAINT pull_request_reviewer_pull_request_id_user_id_key;
+ALTER TABLE pull_request_reviewer ADD CONSTRAINT pull_request_reviewer_tenant_pr_user_key UNIQUE (tenant_id, pull_request_id, user_id);
+
+ALTER TABLE pull_request_review DROP CONSTRAINT pull_request_review_pkey;
+ALTER TABLE pull_request_review ADD CONSTRAINT pull_request_review_pkey PRIMARY KEY (tenant_id, pull_request_id, user_id);
+
+ALTER TABLE review_escalation DROP CONSTRAINT review_escalation_pull_request_id_user_id_assigned_at_key;
+ALTER TABLE review_escalation ADD CONSTRAINT review_escalation_assignment_key UNIQUE (tenant_id, pull_request_id, user_id, assigned_at);
+
+ALTER TABLE forge_link DROP CONSTRAINT forge_link_pkey;
+ALTER TABLE forge_link ADD CONSTRAINT forge_link_pkey PRIMARY KEY (tenant_id, pull_request_id);
+
+-- составные внешние ключи не дают строке сослаться на данные другого арендатора
+ALTER TABLE users ADD CONSTRAINT users_team_fkey
+    FOREIGN KEY (tenant_id, team_id) REFERENCES team (tenant_id, id);
+ALTER TABLE pull_request ADD CONSTRAINT pull_request_author_fkey
+    FOREIGN KEY (tenant_id, author_id) REFERENCES users (tenant_id, user_id);
+ALTER TABLE pull_request_reviewer ADD CONSTRAINT pull_request_reviewer_pull_request_fkey
+    FOREIGN KEY (tenant_id, pull_request_id) REFERENCES pull_request (tenant_id, pull_request_id) ON DELETE CASCADE;
+ALTER TABLE pull_request_reviewer ADD CONSTRAINT pull_request_reviewer_user_fkey
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users (tenant_id, user_id);
+ALTER TABLE pull_request_review ADD CONSTRAINT pull_request_review_reviewer_fkey
+    FOREIGN KEY (tenant_id, pull_request_id, user_id) REFERENCES pull_request_reviewer (tenant_id, pull_request_id, user_id) ON DELETE CASCADE;
+ALTER TABLE user_unavailability ADD CONSTRAINT user_unavailability_user_fkey
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users (tenant_id, user_id) ON DELETE CASCADE;
+ALTER TABLE review_escalation ADD CONSTRAINT review_escalation_pull_request_fkey
+    FOREIGN KEY (tenant_id, pull_request_id) REFERENCES pull_request (tenant_id, pull_request_id) ON DELETE CASCADE;
+ALTER TABLE review_escalation ADD CONSTRAINT review_escalation_user_fkey
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users (tenant_id, user_id) ON DELETE CASCADE;
+-- ON DELETE SET NULL обнулил бы и tenant_id, а выборочный SET NULL (столбец) появился только в Postgres 15;
+-- пользователи не удаляются, поэтому ссылка просто запрещает удаление
+ALTER TABLE review_escalation ADD CONSTRAINT review_escalation_replaced_by_fkey
+    FOREIGN KEY (tenant_id, replaced_by) REFERENCES users (tenant_id, user_id);
+
+DROP INDEX IF EXISTS idx_pull_request_reviewer_user;
+CREATE INDEX idx_pull_request_reviewer_user ON pull_request_reviewer (tenant_id, user_id);
+
+DROP INDEX idx_user_unavailability_user;
+CREATE INDEX idx_user_unavailability_user ON user_unavailability (tenant_id, user_id, ends_at);
+
+DROP INDEX forge_sync_pull_request_idx;
+CREATE INDEX forge_sync_pull_request_idx ON forge_sync (tenant_id, pull_request_id);
+
+CREATE INDEX webhook_subscription_tenant_idx ON webhook_subscription (tenant_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- откат возможен, только пока идентификаторы не повторяются в разных арендаторах
+DROP INDEX IF EXISTS webhook_subscription_tenant_idx;
+
+DROP INDEX forge_sync_pull_request_idx;
+CREATE INDEX forge_sync_pull_request_idx ON forge_sync (pull_request_id);
+
+DROP INDEX idx_user_unavailability_user;
+CREATE INDEX idx_user_unavailability_user ON user_unavailability (user_id, ends_at);
+
+DROP INDEX idx_pull_request_reviewer_user;
+CREATE INDEX idx_pull_request_reviewer_user ON pull_request_reviewer (user_id);
+
+ALTER TABLE review_escalation DROP CONSTRAINT review_escalation_replaced_by_fkey;
+ALTER TABLE review_escalation DROP CONSTRAINT review_escalation_user_fkey;
+ALTER TABLE review_escalation DROP CONSTRAINT review_escalation_pull_request_fkey;
+ALTER TABLE user_unavailability DROP CONSTRAINT user_unavailability_user_fkey;
+ALTER TABLE pull_request_review DROP CONSTRAINT pull_request_review_reviewer_fkey;
+ALTER TABLE pull_request_reviewer DROP CONSTRAINT pull_request_reviewer_user_fkey;
+ALTER TABLE pull_request_reviewer DROP CONSTRAINT pull_request_reviewer_pull_request_fkey;
+ALTER TABLE pull_request DROP CONSTRAINT pull_request_author_fkey;
+ALTER TABLE users DROP CONSTRAINT users_team_fkey;
+
+ALTER TABLE forge_link DROP CONSTRAINT forge_link_pkey;
+ALTER TABLE forge_link ADD CONSTRAINT forge_link_pkey PRIMARY KEY (pull_request_id);
+
+ALTER TABLE review_escalation DROP CONSTRAINT review_escalation_assignment_key;
+ALTER TABLE review_escalation ADD CONSTRAINT review_escalation_pull_request_id_user_id_assigned_at_key UNIQUE (pull_request_id, user_id, assigned_at);
+
+ALTER TABLE pull_request_review DROP CONSTRAINT pull_request_review_pkey;
+ALTER TABLE pull_request_review ADD CONSTRAINT pull_request_review_pkey PRIMARY KEY (pull_request_id, user_id);
+
+ALTER TABLE pull_request_reviewer DROP CONSTRAINT pull_request_reviewer_tenant_pr_user_key;
+ALTER TABLE pull_request_reviewer ADD CONSTRAINT pull_request_reviewer_pull_request_id_user_id_key UNIQUE (pull_request_id, user_id);
+
+ALTER TABLE pull_request DROP CONSTRAINT pull_request_pkey;
+ALTER TABLE pull_request ADD CONSTRAINT pull_request_pkey PRIMARY KEY (pull_request_id);
+
+ALTER TABLE users DROP CONSTRAINT users_pkey;
+ALTER TABLE users ADD CONSTRAINT users_pkey PRIMARY KEY (user_id);
+
+ALTER TABLE team DROP CONSTRAINT team_tenant_id_key;
+ALTER TABLE team DROP CONSTRAINT team_tenant_name_key;
+ALTER TABLE team ADD CONSTRAINT team_team_name_key UNIQUE (team_name);
+
+ALTER TABLE users ADD CONSTRAINT users_team_id_fkey FOREIGN KEY (team_id) REFERENCES team (id);
+ALTER TABLE pull_request ADD CONSTRAINT pull_request_author_id_fkey FOREIGN KEY (author_id) REFERENCES users (user_id);
+ALTER TABLE pull_request_reviewer ADD CONSTRAINT pull_request_reviewer_pull_request_id_fkey
+    FOREIGN KEY (pull_request_id) REFERENCES pull_request (pull_request_id) ON DELETE CASCADE;
+ALTER TABLE pull_request_reviewer ADD CONSTRAINT pull_request_reviewer_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (user_id);
+ALTER TABLE pull_request_review ADD CONSTRAINT pull_request_review_pull_request_id_user_id_fkey
+    FOREIGN KEY (pull_request_id, user_id) REFERENCES pull_request_reviewer (pull_request_id, user_id) ON DELETE CASCADE;
+ALTER TABLE user_unavailability ADD CONSTRAINT user_unavailability_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE;
+ALTER TABLE review_escalation ADD CONSTRAINT review_escalation_pull_request_id_fkey
+    FOREIGN KEY (pull_request_id) REFERENCES pull_request (pull_request_id) ON DELETE CASCADE;
+ALTER TABLE review_escalation ADD CONSTRAINT review_escalation_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users (user_id) ON DELETE CASCADE;
+ALTER TABLE review_escalation ADD CONSTRAINT review_escalation_replaced_by_fkey
+    FOREIGN KEY (replaced_by) REFERENCES users (user_id) ON DELETE SET NULL;
+
+ALTER TABLE api_key DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE webhook_subscription DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE outbox DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE forge_sync DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE forge_link DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE review_escalation DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE user_unavailability DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE pull_request_review DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE pull_request_reviewer DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE pull_request DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE users DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE team DROP COLUMN IF EXISTS tenant_id;
+-- +goose StatementEnd
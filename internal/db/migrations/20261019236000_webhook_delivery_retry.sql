@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- доставки вебхуков отправляет фоновая задача: она забирает строки, у которых подошёл next_attempt_at,
+-- поэтому недоставленное переживает перезапуск
+ALTER TABLE webhook_delivery ADD COLUMN next_attempt_at TIMESTAMP DEFAULT NOW() NOT NULL;
+
+CREATE INDEX webhook_delivery_due_idx ON webhook_delivery (next_attempt_at) WHERE status IN ('PENDING', 'FAILED');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS webhook_delivery_due_idx;
+
+ALTER TABLE webhook_delivery DROP COLUMN IF EXISTS next_attempt_at;
+-- +goose StatementEnd
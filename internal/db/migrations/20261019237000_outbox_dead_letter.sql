@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- после исчерпания попыток событие остаётся в outbox с dead_at и больше не забирается relay'ем
+ALTER TABLE outbox ADD COLUMN dead_at TIMESTAMP;
+
+DROP INDEX IF EXISTS outbox_pending_idx;
+CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at) WHERE published_at IS NULL AND dead_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS outbox_pending_idx;
+CREATE INDEX outbox_pending_idx ON outbox (next_attempt_at) WHERE published_at IS NULL;
+
+ALTER TABLE outbox DROP COLUMN IF EXISTS dead_at;
+-- +goose StatementEnd
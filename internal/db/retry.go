@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+	uniqueViolation      = "23505"
+)
+
+// IsRetryable сообщает, что транзакция упала из-за гонки и её можно повторить целиком
+func IsRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	switch pgErr.Code {
+	case serializationFailure, deadlockDetected, uniqueViolation:
+		return true
+	}
+	return false
+}
+
+// Retry вызывает fn до attempts раз, пока она возвращает повторяемую ошибку.
+// fn должна сама открывать и завершать транзакцию.
+func Retry(ctx context.Context, attempts int, fn func() error) error {
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			delay := time.Duration(attempt)*5*time.Millisecond + time.Duration(rand.Int63n(int64(5*time.Millisecond)))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		err = fn()
+		if !IsRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
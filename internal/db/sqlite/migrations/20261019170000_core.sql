@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+
+CREATE TABLE team (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_name VARCHAR(100) NOT NULL UNIQUE,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE users (
+    user_id VARCHAR(64) PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    team_id INTEGER NOT NULL REFERENCES team (id),
+    is_active BOOLEAN NOT NULL DEFAULT 1
+);
+
+CREATE TABLE pull_request (
+    pull_request_id VARCHAR(64) PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id VARCHAR(64) NOT NULL REFERENCES users (user_id),
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+    created_at TIMESTAMP NOT NULL,
+    merged_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE pull_request_reviewer (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id VARCHAR(64) NOT NULL REFERENCES pull_request (pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL REFERENCES users (user_id),
+    UNIQUE (pull_request_id, user_id)
+);
+
+CREATE INDEX idx_users_team ON users (team_id);
+
+CREATE INDEX idx_pull_request_reviewer_user ON pull_request_reviewer (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS pull_request_reviewer;
+
+DROP TABLE IF EXISTS pull_request;
+
+DROP TABLE IF EXISTS users;
+
+DROP TABLE IF EXISTS team;
+-- +goose StatementEnd
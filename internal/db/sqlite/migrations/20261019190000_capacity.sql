@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE team ADD COLUMN max_open_reviews INTEGER CHECK (max_open_reviews >= 0);
+
+ALTER TABLE users ADD COLUMN max_open_reviews INTEGER CHECK (max_open_reviews >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN max_open_reviews;
+
+ALTER TABLE team DROP COLUMN max_open_reviews;
+-- +goose StatementEnd
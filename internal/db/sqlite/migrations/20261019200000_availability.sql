@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- время хранится в UTC, поэтому строки сравниваются в хронологическом порядке
+CREATE TABLE user_unavailability (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id VARCHAR(64) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    handover BOOLEAN NOT NULL DEFAULT 0,
+    handed_over_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_user_unavailability_user ON user_unavailability (user_id, ends_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_unavailability;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN timezone TEXT;
+
+ALTER TABLE users ADD COLUMN work_start INTEGER CHECK (work_start BETWEEN 0 AND 1439);
+
+ALTER TABLE users ADD COLUMN work_end INTEGER CHECK (
+    work_end BETWEEN 0 AND 1439
+    AND work_end <> work_start
+    AND (timezone IS NULL) = (work_start IS NULL)
+    AND (work_start IS NULL) = (work_end IS NULL)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN work_end;
+
+ALTER TABLE users DROP COLUMN work_start;
+
+ALTER TABLE users DROP COLUMN timezone;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ALTER TABLE не добавляет NOT NULL без константы по умолчанию: репозитории всегда пишут assigned_at сами
+ALTER TABLE pull_request_reviewer ADD COLUMN assigned_at TIMESTAMP;
+
+UPDATE pull_request_reviewer SET assigned_at = (
+    SELECT pr.created_at FROM pull_request pr WHERE pr.pull_request_id = pull_request_reviewer.pull_request_id
+);
+
+ALTER TABLE team ADD COLUMN review_sla_minutes INTEGER CHECK (review_sla_minutes > 0);
+
+ALTER TABLE team ADD COLUMN escalation_action VARCHAR(20) NOT NULL DEFAULT 'remind'
+    CHECK (escalation_action IN ('remind', 'reassign', 'notify_lead'));
+
+CREATE TABLE review_escalation (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id VARCHAR(64) NOT NULL REFERENCES pull_request (pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('remind', 'reassign', 'notify_lead')),
+    escalated_at TIMESTAMP NOT NULL,
+    replaced_by VARCHAR(64) REFERENCES users (user_id) ON DELETE SET NULL,
+    UNIQUE (pull_request_id, user_id, assigned_at)
+);
+
+CREATE INDEX idx_review_escalation_team ON review_escalation (team_id, escalated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS review_escalation;
+
+ALTER TABLE team DROP COLUMN escalation_action;
+
+ALTER TABLE team DROP COLUMN review_sla_minutes;
+
+ALTER TABLE pull_request_reviewer DROP COLUMN assigned_at;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE team_fallback (
+    team_id INTEGER NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    fallback_team_id INTEGER NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    priority INTEGER NOT NULL CHECK (priority > 0),
+    PRIMARY KEY (team_id, fallback_team_id),
+    UNIQUE (team_id, priority),
+    CHECK (team_id <> fallback_team_id)
+);
+
+-- ALTER TABLE не добавляет NOT NULL без константы по умолчанию: репозитории всегда пишут source_team_id сами.
+-- Без REFERENCES: SQLite не удаляет столбец внешнего ключа, и Down не прошёл бы
+ALTER TABLE pull_request_reviewer ADD COLUMN source_team_id INTEGER;
+
+UPDATE pull_request_reviewer SET source_team_id = (
+    SELECT u.team_id
+    FROM pull_request pr
+    JOIN users u ON u.user_id = pr.author_id
+    WHERE pr.pull_request_id = pull_request_reviewer.pull_request_id
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_request_reviewer DROP COLUMN source_team_id;
+
+DROP TABLE IF EXISTS team_fallback;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Без REFERENCES: SQLite не удаляет столбец внешнего ключа, и Down не прошёл бы
+ALTER TABLE team ADD COLUMN parent_id INTEGER;
+
+CREATE TABLE team_closure (
+    ancestor_id INTEGER NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    depth INTEGER NOT NULL CHECK (depth >= 0),
+    PRIMARY KEY (ancestor_id, team_id)
+);
+
+CREATE INDEX team_closure_team_idx ON team_closure (team_id, depth);
+
+INSERT INTO team_closure (ancestor_id, team_id, depth)
+SELECT id, id, 0 FROM team;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS team_closure;
+
+ALTER TABLE team DROP COLUMN parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,305 @@
+-- +goose Up
+-- +goose StatementBegin
+-- арендатор: идентификаторы команд, пользователей и PR уникальны только внутри арендатора.
+-- SQLite не меняет первичные ключи и ограничения на месте, поэтому таблицы пересобираются:
+-- новая таблица, копия данных, удаление старой, переименование. Мигратор выключает проверку
+-- внешних ключей на время миграции и проверяет их перед фиксацией.
+CREATE TABLE team_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id VARCHAR(64) NOT NULL,
+    team_name VARCHAR(100) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    max_open_reviews INTEGER CHECK (max_open_reviews >= 0),
+    review_sla_minutes INTEGER CHECK (review_sla_minutes > 0),
+    escalation_action VARCHAR(20) NOT NULL DEFAULT 'remind'
+        CHECK (escalation_action IN ('remind', 'reassign', 'notify_lead')),
+    parent_id INTEGER,
+    UNIQUE (tenant_id, team_name),
+    UNIQUE (tenant_id, id)
+);
+
+INSERT INTO team_new (id, tenant_id, team_name, version, max_open_reviews, review_sla_minutes, escalation_action, parent_id)
+SELECT id, 'default', team_name, version, max_open_reviews, review_sla_minutes, escalation_action, parent_id FROM team;
+
+DROP TABLE team;
+
+ALTER TABLE team_new RENAME TO team;
+
+CREATE TABLE users_new (
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    username VARCHAR(100) NOT NULL,
+    team_id INTEGER NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    max_open_reviews INTEGER CHECK (max_open_reviews >= 0),
+    timezone TEXT,
+    work_start INTEGER CHECK (work_start BETWEEN 0 AND 1439),
+    work_end INTEGER CHECK (
+        work_end BETWEEN 0 AND 1439
+        AND work_end <> work_start
+        AND (timezone IS NULL) = (work_start IS NULL)
+        AND (work_start IS NULL) = (work_end IS NULL)
+    ),
+    PRIMARY KEY (tenant_id, user_id),
+    FOREIGN KEY (tenant_id, team_id) REFERENCES team (tenant_id, id)
+);
+
+INSERT INTO users_new (tenant_id, user_id, username, team_id, is_active, max_open_reviews, timezone, work_start, work_end)
+SELECT 'default', user_id, username, team_id, is_active, max_open_reviews, timezone, work_start, work_end FROM users;
+
+DROP TABLE users;
+
+ALTER TABLE users_new RENAME TO users;
+
+CREATE INDEX idx_users_team ON users (team_id);
+
+CREATE TABLE pull_request_new (
+    tenant_id VARCHAR(64) NOT NULL,
+    pull_request_id VARCHAR(64) NOT NULL,
+    pull_request_name TEXT NOT NULL,
+    author_id VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+    created_at TIMESTAMP NOT NULL,
+    merged_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1,
+    PRIMARY KEY (tenant_id, pull_request_id),
+    FOREIGN KEY (tenant_id, author_id) REFERENCES users (tenant_id, user_id)
+);
+
+INSERT INTO pull_request_new (tenant_id, pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version)
+SELECT 'default', pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version FROM pull_request;
+
+DROP TABLE pull_request;
+
+ALTER TABLE pull_request_new RENAME TO pull_request;
+
+CREATE TABLE pull_request_reviewer_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id VARCHAR(64) NOT NULL,
+    pull_request_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    assigned_at TIMESTAMP,
+    source_team_id INTEGER,
+    UNIQUE (tenant_id, pull_request_id, user_id),
+    FOREIGN KEY (tenant_id, pull_request_id) REFERENCES pull_request (tenant_id, pull_request_id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users (tenant_id, user_id)
+);
+
+INSERT INTO pull_request_reviewer_new (id, tenant_id, pull_request_id, user_id, assigned_at, source_team_id)
+SELECT id, 'default', pull_request_id, user_id, assigned_at, source_team_id FROM pull_request_reviewer;
+
+DROP TABLE pull_request_reviewer;
+
+ALTER TABLE pull_request_reviewer_new RENAME TO pull_request_reviewer;
+
+CREATE INDEX idx_pull_request_reviewer_user ON pull_request_reviewer (tenant_id, user_id);
+
+CREATE TABLE pull_request_review_new (
+    tenant_id VARCHAR(64) NOT NULL,
+    pull_request_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED')),
+    comment TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (tenant_id, pull_request_id, user_id),
+    FOREIGN KEY (tenant_id, pull_request_id, user_id)
+        REFERENCES pull_request_reviewer (tenant_id, pull_request_id, user_id) ON DELETE CASCADE
+);
+
+INSERT INTO pull_request_review_new (tenant_id, pull_request_id, user_id, decision, comment, updated_at)
+SELECT 'default', pull_request_id, user_id, decision, comment, updated_at FROM pull_request_review;
+
+DROP TABLE pull_request_review;
+
+ALTER TABLE pull_request_review_new RENAME TO pull_request_review;
+
+CREATE TABLE user_unavailability_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    handover BOOLEAN NOT NULL DEFAULT 0,
+    handed_over_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (ends_at > starts_at),
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users (tenant_id, user_id) ON DELETE CASCADE
+);
+
+INSERT INTO user_unavailability_new (id, tenant_id, user_id, starts_at, ends_at, reason, handover, handed_over_at, created_at)
+SELECT id, 'default', user_id, starts_at, ends_at, reason, handover, handed_over_at, created_at FROM user_unavailability;
+
+DROP TABLE user_unavailability;
+
+ALTER TABLE user_unavailability_new RENAME TO user_unavailability;
+
+CREATE INDEX idx_user_unavailability_user ON user_unavailability (tenant_id, user_id, ends_at);
+
+-- replaced_by без ON DELETE SET NULL: он обнулил бы и tenant_id, а пользователи не удаляются
+CREATE TABLE review_escalation_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tenant_id VARCHAR(64) NOT NULL,
+    pull_request_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    team_id INTEGER NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('remind', 'reassign', 'notify_lead')),
+    escalated_at TIMESTAMP NOT NULL,
+    replaced_by VARCHAR(64),
+    UNIQUE (tenant_id, pull_request_id, user_id, assigned_at),
+    FOREIGN KEY (tenant_id, pull_request_id) REFERENCES pull_request (tenant_id, pull_request_id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, user_id) REFERENCES users (tenant_id, user_id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id, replaced_by) REFERENCES users (tenant_id, user_id)
+);
+
+INSERT INTO review_escalation_new (id, tenant_id, pull_request_id, user_id, team_id, assigned_at, action, escalated_at, replaced_by)
+SELECT id, 'default', pull_request_id, user_id, team_id, assigned_at, action, escalated_at, replaced_by FROM review_escalation;
+
+DROP TABLE review_escalation;
+
+ALTER TABLE review_escalation_new RENAME TO review_escalation;
+
+CREATE INDEX idx_review_escalation_team ON review_escalation (team_id, escalated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- откат возможен, только пока идентификаторы не повторяются в разных арендаторах
+CREATE TABLE review_escalation_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id VARCHAR(64) NOT NULL REFERENCES pull_request (pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    team_id INTEGER NOT NULL REFERENCES team (id) ON DELETE CASCADE,
+    assigned_at TIMESTAMP NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('remind', 'reassign', 'notify_lead')),
+    escalated_at TIMESTAMP NOT NULL,
+    replaced_by VARCHAR(64) REFERENCES users (user_id) ON DELETE SET NULL,
+    UNIQUE (pull_request_id, user_id, assigned_at)
+);
+
+INSERT INTO review_escalation_old (id, pull_request_id, user_id, team_id, assigned_at, action, escalated_at, replaced_by)
+SELECT id, pull_request_id, user_id, team_id, assigned_at, action, escalated_at, replaced_by FROM review_escalation;
+
+DROP TABLE review_escalation;
+
+ALTER TABLE review_escalation_old RENAME TO review_escalation;
+
+CREATE INDEX idx_review_escalation_team ON review_escalation (team_id, escalated_at);
+
+CREATE TABLE user_unavailability_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id VARCHAR(64) NOT NULL REFERENCES users (user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    handover BOOLEAN NOT NULL DEFAULT 0,
+    handed_over_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    CHECK (ends_at > starts_at)
+);
+
+INSERT INTO user_unavailability_old (id, user_id, starts_at, ends_at, reason, handover, handed_over_at, created_at)
+SELECT id, user_id, starts_at, ends_at, reason, handover, handed_over_at, created_at FROM user_unavailability;
+
+DROP TABLE user_unavailability;
+
+ALTER TABLE user_unavailability_old RENAME TO user_unavailability;
+
+CREATE INDEX idx_user_unavailability_user ON user_unavailability (user_id, ends_at);
+
+CREATE TABLE pull_request_review_old (
+    pull_request_id VARCHAR(64) NOT NULL,
+    user_id VARCHAR(64) NOT NULL,
+    decision VARCHAR(20) NOT NULL CHECK (decision IN ('APPROVED', 'CHANGES_REQUESTED')),
+    comment TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (pull_request_id, user_id),
+    FOREIGN KEY (pull_request_id, user_id) REFERENCES pull_request_reviewer (pull_request_id, user_id) ON DELETE CASCADE
+);
+
+INSERT INTO pull_request_review_old (pull_request_id, user_id, decision, comment, updated_at)
+SELECT pull_request_id, user_id, decision, comment, updated_at FROM pull_request_review;
+
+DROP TABLE pull_request_review;
+
+ALTER TABLE pull_request_review_old RENAME TO pull_request_review;
+
+CREATE TABLE pull_request_reviewer_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id VARCHAR(64) NOT NULL REFERENCES pull_request (pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(64) NOT NULL REFERENCES users (user_id),
+    assigned_at TIMESTAMP,
+    source_team_id INTEGER,
+    UNIQUE (pull_request_id, user_id)
+);
+
+INSERT INTO pull_request_reviewer_old (id, pull_request_id, user_id, assigned_at, source_team_id)
+SELECT id, pull_request_id, user_id, assigned_at, source_team_id FROM pull_request_reviewer;
+
+DROP TABLE pull_request_reviewer;
+
+ALTER TABLE pull_request_reviewer_old RENAME TO pull_request_reviewer;
+
+CREATE INDEX idx_pull_request_reviewer_user ON pull_request_reviewer (user_id);
+
+CREATE TABLE pull_request_old (
+    pull_request_id VARCHAR(64) PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id VARCHAR(64) NOT NULL REFERENCES users (user_id),
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+    created_at TIMESTAMP NOT NULL,
+    merged_at TIMESTAMP,
+    version INTEGER NOT NULL DEFAULT 1
+);
+
+INSERT INTO pull_request_old (pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version)
+SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version FROM pull_request;
+
+DROP TABLE pull_request;
+
+ALTER TABLE pull_request_old RENAME TO pull_request;
+
+CREATE TABLE users_old (
+    user_id VARCHAR(64) PRIMARY KEY,
+    username VARCHAR(100) NOT NULL,
+    team_id INTEGER NOT NULL REFERENCES team (id),
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    max_open_reviews INTEGER CHECK (max_open_reviews >= 0),
+    timezone TEXT,
+    work_start INTEGER CHECK (work_start BETWEEN 0 AND 1439),
+    work_end INTEGER CHECK (
+        work_end BETWEEN 0 AND 1439
+        AND work_end <> work_start
+        AND (timezone IS NULL) = (work_start IS NULL)
+        AND (work_start IS NULL) = (work_end IS NULL)
+    )
+);
+
+INSERT INTO users_old (user_id, username, team_id, is_active, max_open_reviews, timezone, work_start, work_end)
+SELECT user_id, username, team_id, is_active, max_open_reviews, timezone, work_start, work_end FROM users;
+
+DROP TABLE users;
+
+ALTER TABLE users_old RENAME TO users;
+
+CREATE INDEX idx_users_team ON users (team_id);
+
+CREATE TABLE team_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    team_name VARCHAR(100) NOT NULL UNIQUE,
+    version INTEGER NOT NULL DEFAULT 1,
+    max_open_reviews INTEGER CHECK (max_open_reviews >= 0),
+    review_sla_minutes INTEGER CHECK (review_sla_minutes > 0),
+    escalation_action VARCHAR(20) NOT NULL DEFAULT 'remind'
+        CHECK (escalation_action IN ('remind', 'reassign', 'notify_lead')),
+    parent_id INTEGER
+);
+
+INSERT INTO team_old (id, team_name, version, max_open_reviews, review_sla_minutes, escalation_action, parent_id)
+SELECT id, team_name, version, max_open_reviews, review_sla_minutes, escalation_action, parent_id FROM team;
+
+DROP TABLE team;
+
+ALTER TABLE team_old RENAME TO team;
+-- +goose StatementEnd
@@ -0,0 +1,243 @@
+// Package sqlite - встроенное хранилище на SQLite (pure Go, без cgo) для запуска
+// сервиса одним бинарником. Схема - в migrations, применяется при открытии базы.
+package sqlite
+
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Querier - общее у *sql.DB и *sql.Tx
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type DB struct {
+	db *sql.DB
+}
+
+// Open открывает (или создаёт) файл базы и применяет миграции.
+// SQLite допускает одного писателя, поэтому пул ограничен одним соединением.
+func Open(ctx context.Context, path string) (*DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		log.Printf("[sqlite.Open] failed to open '%s': %v", path, err)
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+
+	d := &DB{db: db}
+	if err := d.migrate(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// migrate применяет Up-часть ещё не применённых goose-миграций; учёт - в goose_db_version
+func (d *DB) migrate(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS goose_db_version (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			version_id INTEGER NOT NULL,
+			is_applied BOOLEAN NOT NULL,
+			tstamp TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		log.Printf("[sqlite.migrate] failed to create version table: %v", err)
+		return err
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var version int64
+		if _, err := fmt.Sscanf(strings.TrimPrefix(name, "migrations/"), "%d_", &version); err != nil {
+			return fmt.Errorf("migration %s: bad name: %w", name, err)
+		}
+
+		var applied bool
+		err := d.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM goose_db_version WHERE version_id = ? AND is_applied)", version).Scan(&applied)
+		if err != nil {
+			return err
+		}
+		if applied {
+			continue
+		}
+
+		body, err := migrations.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		up := string(body)
+		if i := strings.Index(up, "-- +goose Down"); i >= 0 {
+			up = up[:i]
+		}
+
+		if err := d.apply(ctx, up, version); err != nil {
+			log.Printf("[sqlite.migrate] migration %s failed: %v", name, err)
+			return err
+		}
+		log.Printf("[sqlite.migrate] applied %s", name)
+	}
+	return nil
+}
+
+// apply выполняет миграцию в одной транзакции. Проверка внешних ключей на время миграции выключена,
+// чтобы таблицы можно было пересобирать (создать новую, скопировать, удалить старую, переименовать);
+// перед фиксацией ссылки проверяются целиком. PRAGMA foreign_keys внутри транзакции не действует,
+// а соединение в пуле одно, поэтому переключение выполняется на нём до и после транзакции.
+func (d *DB) apply(ctx context.Context, up string, version int64) (err error) {
+	if _, err := d.db.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	defer func() {
+		if _, onErr := d.db.ExecContext(ctx, "PRAGMA foreign_keys = ON"); onErr != nil {
+			err = errors.Join(err, fmt.Errorf("re-enable foreign keys: %w", onErr))
+		}
+	}()
+
+	return d.Write(ctx, func(q Querier) error {
+		if _, err := q.ExecContext(ctx, up); err != nil {
+			return err
+		}
+		rows, err := q.QueryContext(ctx, "PRAGMA foreign_key_check")
+		if err != nil {
+			return err
+		}
+		violations := 0
+		for rows.Next() {
+			violations++
+		}
+		if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+			return err
+		}
+		if violations > 0 {
+			return fmt.Errorf("migration leaves %d rows with broken foreign keys", violations)
+		}
+		_, err = q.ExecContext(ctx, "INSERT INTO goose_db_version (version_id, is_applied) VALUES (?, 1)", version)
+		return err
+	})
+}
+
+type txKey struct{}
+
+type tx struct {
+	tx         *sql.Tx
+	savepoints int
+}
+
+// WithTx выполняет fn в одной транзакции, положив её в контекст (как db.Database.WithTx).
+// Вложенный WithTx присоединяется к внешней транзакции.
+func (d *DB) WithTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*tx); ok {
+		return fn(ctx)
+	}
+	sqlTx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("[sqlite.WithTx] failed to begin transaction: %v", err)
+		return apperrors.ErrDB
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = sqlTx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			_ = sqlTx.Rollback()
+			return
+		}
+		if commitErr := sqlTx.Commit(); commitErr != nil {
+			log.Printf("[sqlite.WithTx] failed to commit transaction: %v", commitErr)
+			err = apperrors.ErrDB
+		}
+	}()
+	return fn(context.WithValue(ctx, txKey{}, &tx{tx: sqlTx}))
+}
+
+// Read выполняет fn на транзакции из контекста или на пуле
+func (d *DB) Read(ctx context.Context, fn func(q Querier) error) error {
+	if t, ok := ctx.Value(txKey{}).(*tx); ok {
+		return fn(t.tx)
+	}
+	return fn(d.db)
+}
+
+// Write выполняет fn атомарно: в своей транзакции, а внутри WithTx - в точке сохранения.
+// Ошибка fn откатывает её изменения и возвращается как есть, сбой самой транзакции - ErrDB.
+func (d *DB) Write(ctx context.Context, fn func(q Querier) error) error {
+	if t, ok := ctx.Value(txKey{}).(*tx); ok {
+		t.savepoints++
+		name := fmt.Sprintf("sp%d", t.savepoints)
+		defer func() { t.savepoints-- }()
+
+		if _, err := t.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+			log.Printf("[sqlite.Write] failed to create savepoint: %v", err)
+			return apperrors.ErrDB
+		}
+		if err := fn(t.tx); err != nil {
+			_, _ = t.tx.ExecContext(ctx, "ROLLBACK TO "+name)
+			_, _ = t.tx.ExecContext(ctx, "RELEASE "+name)
+			return err
+		}
+		if _, err := t.tx.ExecContext(ctx, "RELEASE "+name); err != nil {
+			log.Printf("[sqlite.Write] failed to release savepoint: %v", err)
+			return apperrors.ErrDB
+		}
+		return nil
+	}
+
+	sqlTx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		log.Printf("[sqlite.Write] failed to begin transaction: %v", err)
+		return apperrors.ErrDB
+	}
+	if err := fn(sqlTx); err != nil {
+		_ = sqlTx.Rollback()
+		return err
+	}
+	if err := sqlTx.Commit(); err != nil {
+		log.Printf("[sqlite.Write] failed to commit transaction: %v", err)
+		return apperrors.ErrDB
+	}
+	return nil
+}
+
+// IsUniqueViolation - аналог кода 23505 в Postgres
+func IsUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"avito-tech/internal/apperrors"
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type txKey struct{}
+
+func txFromContext(ctx context.Context) pgx.Tx {
+	tx, _ := ctx.Value(txKey{}).(pgx.Tx)
+	return tx
+}
+
+// InTx сообщает, выполняется ли код внутри WithTx
+func InTx(ctx context.Context) bool {
+	return txFromContext(ctx) != nil
+}
+
+// WithTx выполняет fn в одной транзакции. Транзакция кладётся в контекст, и все вызовы
+// Database с этим контекстом (в том числе Begin в репозиториях) работают внутри неё.
+// Вложенный WithTx присоединяется к внешней транзакции. Ошибка или паника в fn - откат.
+func (db *Database) WithTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if InTx(ctx) {
+		return fn(ctx)
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		log.Printf("[Database.WithTx] failed to begin transaction: %v", err)
+		return apperrors.ErrDB
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback(ctx)
+			panic(p)
+		}
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return
+		}
+		if commitErr := tx.Commit(ctx); commitErr != nil {
+			log.Printf("[Database.WithTx] failed to commit transaction: %v", commitErr)
+			err = apperrors.ErrDB
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, tx))
+}